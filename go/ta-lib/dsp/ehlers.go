@@ -0,0 +1,229 @@
+package dsp
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// filterResult applies a filter to every value
+func filterResult(f Filter, inReal []float64) (*utils.Result, error) {
+	if err := utils.CheckSeries(inReal); err != nil {
+		return nil, err
+	}
+	return utils.NewResult(0, Apply(f, inReal)), nil
+}
+
+// SuperSmoother smooths the values with the Super Smoother of period bars
+func SuperSmoother(inReal []float64, period float64) (*utils.Result, error) {
+	f, err := NewSuperSmoother(period)
+	if err != nil {
+		return nil, err
+	}
+	return filterResult(f, inReal)
+}
+
+// RoofingFilter keeps the cycles of the values between lowPeriod and
+// highPeriod bars, such as 10 and 48, centred on zero
+func RoofingFilter(inReal []float64, highPeriod, lowPeriod float64) (*utils.Result, error) {
+	f, err := NewRoofing(highPeriod, lowPeriod)
+	if err != nil {
+		return nil, err
+	}
+	return filterResult(f, inReal)
+}
+
+// Decycler returns the values without their cycles shorter than period
+// bars, such as 125: the values minus their one pole high pass. It trends
+// like a moving average with almost no lag.
+func Decycler(inReal []float64, period float64) (*utils.Result, error) {
+	hp, err := NewOnePoleHighPass(period)
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.CheckSeries(inReal); err != nil {
+		return nil, err
+	}
+	out := Apply(hp, inReal)
+	for i, x := range inReal {
+		out[i] = x - out[i]
+	}
+	return utils.NewResult(0, out), nil
+}
+
+// FisherLookback returns the number of values consumed before the first
+// Fisher transform
+func FisherLookback(period int) int {
+	return period - 1
+}
+
+// Fisher returns the Fisher transform of Ehlers: the position of each value
+// in the range of the period values ending at it, scaled to (-1, 1),
+// smoothed and transformed by 0.5 ln((1 + x) / (1 - x)), so that turning
+// points stand out as sharp peaks. The previous value of the transform is
+// the usual trigger line. Ehlers applies it to the median price (high +
+// low) / 2 over 10 bars.
+func Fisher(inReal []float64, period int) (*utils.Result, error) {
+	if err := utils.CheckSeries(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", period, 2); err != nil {
+		return nil, err
+	}
+	lookback := FisherLookback(period)
+	if lookback >= len(inReal) {
+		return utils.NewResult(0, nil), nil
+	}
+	out := make([]float64, 0, len(inReal)-lookback)
+	var value, fisher float64
+	for today := lookback; today < len(inReal); today++ {
+		window := inReal[today-period+1 : today+1]
+		lowest, highest := utils.MinInSlice(window), utils.MaxInSlice(window)
+		position := 0.0
+		if highest > lowest {
+			position = (inReal[today]-lowest)/(highest-lowest) - 0.5
+		}
+		value = max(-0.999, min(0.999, 0.66*position+0.67*value))
+		fisher = 0.5*math.Log((1+value)/(1-value)) + 0.5*fisher
+		out = append(out, fisher)
+	}
+	return utils.NewResult(lookback, out), nil
+}
+
+// InverseFisher returns the inverse Fisher transform of the values,
+// (e^2x - 1) / (e^2x + 1), which pushes an oscillator towards -1 and 1 for
+// clearer signals. Ehlers applies it to 0.1 * (RSI - 50) smoothed by a
+// weighted moving average of 9 bars.
+func InverseFisher(inReal []float64) (*utils.Result, error) {
+	if err := utils.CheckSeries(inReal); err != nil {
+		return nil, err
+	}
+	out := make([]float64, len(inReal))
+	for i, x := range inReal {
+		out[i] = math.Tanh(x)
+	}
+	return utils.NewResult(0, out), nil
+}
+
+// EvenBetterSinewaveLookback returns the number of values consumed before
+// the first Even Better Sinewave
+func EvenBetterSinewaveLookback() int {
+	return 2
+}
+
+// EvenBetterSinewave returns the Even Better Sinewave indicator of Ehlers:
+// the values filtered by a one pole high pass of duration bars, such as 40,
+// and a Super Smoother of smoothing bars, such as 10, averaged over three
+// bars and normalized by their root mean square. It stays near 1 or -1
+// during a trend and swings between them in a cycling market.
+func EvenBetterSinewave(inReal []float64, duration, smoothing float64) (*utils.Result, error) {
+	hp, err := NewOnePoleHighPass(duration)
+	if err != nil {
+		return nil, err
+	}
+	ss, err := NewSuperSmoother(smoothing)
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.CheckSeries(inReal); err != nil {
+		return nil, err
+	}
+	filtered := Apply(Cascade{hp, ss}, inReal)
+	lookback := EvenBetterSinewaveLookback()
+	if lookback >= len(inReal) {
+		return utils.NewResult(0, nil), nil
+	}
+	out := make([]float64, 0, len(inReal)-lookback)
+	for today := lookback; today < len(inReal); today++ {
+		f0, f1, f2 := filtered[today], filtered[today-1], filtered[today-2]
+		wave := (f0 + f1 + f2) / 3
+		power := (f0*f0 + f1*f1 + f2*f2) / 3
+		sine := 0.0
+		if power > 0 {
+			sine = max(-1, min(1, wave/math.Sqrt(power)))
+		}
+		out = append(out, sine)
+	}
+	return utils.NewResult(lookback, out), nil
+}
+
+// DominantCycleLookback returns the number of values consumed before the
+// first dominant cycle
+func DominantCycleLookback(maxPeriod, averaging int) int {
+	if averaging == 0 {
+		return 2*maxPeriod - 1
+	}
+	return maxPeriod + averaging - 1
+}
+
+// DominantCycle estimates the dominant cycle period in bars with the
+// autocorrelation periodogram of Ehlers. The values go through a roofing
+// filter between minPeriod and maxPeriod, such as 10 and 48, then the
+// correlation of the filtered values with themselves lagged by 0 to
+// maxPeriod bars, over averaging bars or the lag when averaging is 0, gives
+// the power of every period by a discrete Fourier transform. The powers are
+// smoothed, normalized by their decaying maximum, and the dominant cycle is
+// the centre of gravity of the periods with at least half the maximum power.
+func DominantCycle(inReal []float64, minPeriod, maxPeriod, averaging int) (*utils.Result, error) {
+	if err := utils.CheckSeries(inReal); err != nil {
+		return nil, err
+	}
+	if minPeriod < 3 || maxPeriod <= minPeriod || maxPeriod > 1000 || averaging < 0 || averaging > 1000 {
+		return nil, fmt.Errorf("periodogram periods %d to %d averaged over %d: %w", minPeriod, maxPeriod, averaging, utils.ErrInvalidParameter)
+	}
+	roofing, err := NewRoofing(float64(maxPeriod), float64(minPeriod))
+	if err != nil {
+		return nil, err
+	}
+	filtered := Apply(roofing, inReal)
+	lookback := DominantCycleLookback(maxPeriod, averaging)
+	if lookback >= len(inReal) {
+		return utils.NewResult(0, nil), nil
+	}
+
+	correlation := make([]float64, maxPeriod+1)
+	smoothed := make([]float64, maxPeriod+1) // Smoothed power of each period
+	maxPower := 0.0
+	out := make([]float64, 0, len(inReal)-lookback)
+	for today := lookback; today < len(inReal); today++ {
+		for lag := 0; lag <= maxPeriod; lag++ {
+			m := averaging
+			if m == 0 {
+				m = lag
+			}
+			var c utils.RollingCovariance
+			for k := 0; k < m; k++ {
+				c.Add(filtered[today-k], filtered[today-lag-k])
+			}
+			correlation[lag] = c.Correlation()
+		}
+
+		maxPower *= 0.995
+		for period := minPeriod; period <= maxPeriod; period++ {
+			var cosine, sine float64
+			for n := 3; n <= maxPeriod; n++ {
+				w := 2 * math.Pi * float64(n) / float64(period)
+				cosine += correlation[n] * math.Cos(w)
+				sine += correlation[n] * math.Sin(w)
+			}
+			power := cosine*cosine + sine*sine
+			smoothed[period] = 0.2*power*power + 0.8*smoothed[period]
+			maxPower = max(maxPower, smoothed[period])
+		}
+
+		var weighted, total float64
+		for period := minPeriod; period <= maxPeriod; period++ {
+			if maxPower > 0 && smoothed[period]/maxPower >= 0.5 {
+				weighted += float64(period) * smoothed[period]
+				total += smoothed[period]
+			}
+		}
+		cycle := float64(minPeriod)
+		if total > 0 {
+			cycle = max(cycle, weighted/total)
+		}
+		out = append(out, cycle)
+	}
+	return utils.NewResult(lookback, out), nil
+}
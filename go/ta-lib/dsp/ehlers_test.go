@@ -0,0 +1,97 @@
+package dsp_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/dsp"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Batch indicators are the filters applied to the values
+func TestFilterIndicators(t *testing.T) {
+	close := utils.GetFieldSlice(testutil.MustOHLCV(t), utils.FieldClose)
+	smoother := biquads(t)(dsp.NewSuperSmoother(10))
+	roofing, err := dsp.NewRoofing(48, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ss, err := dsp.SuperSmoother(close, 10); err != nil || !slices.Equal(ss.Values, dsp.Apply(smoother, close)) {
+		t.Errorf("super smoother %v", err)
+	}
+	if rf, err := dsp.RoofingFilter(close, 48, 10); err != nil || !slices.Equal(rf.Values, dsp.Apply(roofing, close)) {
+		t.Errorf("roofing filter %v", err)
+	}
+
+	constant := make([]float64, 100)
+	for i := range constant {
+		constant[i] = 70000
+	}
+	if d, err := dsp.Decycler(constant, 125); err != nil || math.Abs(d.Values[99]-70000) > 1e-9 {
+		t.Errorf("decycler of a constant %v %v", d, err)
+	}
+
+	if _, err := dsp.SuperSmoother(nil, 10); !errors.Is(err, utils.ErrEmptyInputData) {
+		t.Errorf("super smoother of nothing: %v", err)
+	}
+}
+
+// Fisher transform of 1, 2, 3: position 0.5 in the range of 3 values
+func TestFisher(t *testing.T) {
+	fisher, err := dsp.Fisher([]float64{1, 2, 3, 3}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fisher.BeginIndex != 2 || fisher.NBElement != 2 {
+		t.Fatalf("Fisher %d values from %d", fisher.NBElement, fisher.BeginIndex)
+	}
+	value := 0.66 * 0.5
+	first := 0.5 * math.Log((1+value)/(1-value))
+	value = 0.66*0.5 + 0.67*value
+	testutil.Near(t, "Fisher 2", fisher.Values[0], first, 1e-15)
+	testutil.Near(t, "Fisher 3", fisher.Values[1], 0.5*math.Log((1+value)/(1-value))+0.5*first, 1e-15)
+
+	inverse, err := dsp.InverseFisher([]float64{0, 0.5, -20})
+	if err != nil || inverse.Values[0] != 0 || inverse.Values[2] != -1 {
+		t.Fatalf("inverse Fisher %v %v", inverse, err)
+	}
+	testutil.Near(t, "inverse Fisher of 0.5", inverse.Values[1], (math.Exp(1)-1)/(math.Exp(1)+1), 1e-15)
+}
+
+// On pure cycles the periodogram finds their period and the sinewave swings
+// between -1 and 1, while a trend holds it at 1
+func TestCycles(t *testing.T) {
+	for _, period := range []float64{15, 20, 30, 40} {
+		cycle := sine(800, period, 100)
+		dominant, err := dsp.DominantCycle(cycle, 10, 48, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dominant.BeginIndex != dsp.DominantCycleLookback(48, 3) {
+			t.Fatalf("dominant cycle from %d", dominant.BeginIndex)
+		}
+		testutil.Near(t, fmt.Sprintf("dominant cycle of %v bars", period), dominant.Values[dominant.NBElement-1], period, 0.03)
+		sinewave, err := dsp.EvenBetterSinewave(cycle, 40, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lo, hi := utils.MinInSlice(sinewave.Values[400:]), utils.MaxInSlice(sinewave.Values[400:]); lo > -0.99 || hi < 0.99 {
+			t.Errorf("sinewave of a cycle of %v bars between %v and %v", period, lo, hi)
+		}
+	}
+	ramp := make([]float64, 300)
+	for i := range ramp {
+		ramp[i] = float64(i)
+	}
+	if sinewave, err := dsp.EvenBetterSinewave(ramp, 40, 10); err != nil || sinewave.Values[sinewave.NBElement-1] < 0.999 {
+		t.Errorf("sinewave of a trend %v", err)
+	}
+
+	if _, err := dsp.DominantCycle(utils.GetFieldSlice(testutil.MustOHLCV(t), utils.FieldClose), 10, 10, 3); !errors.Is(err, utils.ErrInvalidParameter) {
+		t.Errorf("periodogram without periods: %v", err)
+	}
+}
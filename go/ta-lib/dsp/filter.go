@@ -0,0 +1,223 @@
+// Package dsp implements the digital signal processing indicators of John
+// Ehlers beyond the Hilbert transform family: the Super Smoother, the
+// roofing filter, the Fisher and inverse Fisher transforms, the dominant
+// cycle of the autocorrelation periodogram, the Even Better Sinewave and the
+// decycler.
+//
+// They are built from the filter primitives of this package, biquad and
+// general IIR sections chained in cascades, which can be combined into
+// custom filters. Filters process one value at a time, so they also work on
+// live data. Periods are in bars and may be fractional.
+//
+// The indicators return a utils.Result. Filters start in the steady state of
+// the first value rather than from zero, but still need a few cycles of their
+// period to settle.
+package dsp
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Filter is a causal filter processing one value at a time
+type Filter interface {
+	// Next filters a value and returns the output
+	Next(x float64) float64
+	// Reset puts the filter in its steady state for a constant input x and
+	// returns the output of that state
+	Reset(x float64) float64
+}
+
+// Apply resets a filter to the first value and filters the values
+func Apply(f Filter, in []float64) []float64 {
+	out := make([]float64, len(in))
+	if len(in) == 0 {
+		return out
+	}
+	f.Reset(in[0])
+	for i, x := range in {
+		out[i] = f.Next(x)
+	}
+	return out
+}
+
+// Biquad is a second order IIR section,
+// y = B0*x + B1*x[1] + B2*x[2] - A1*y[1] - A2*y[2]
+// where [k] is the value k bars earlier
+type Biquad struct {
+	B0, B1, B2 float64 // Feedforward coefficients
+	A1, A2     float64 // Feedback coefficients
+	x1, x2     float64
+	y1, y2     float64
+}
+
+// Next filters a value
+func (b *Biquad) Next(x float64) float64 {
+	y := b.B0*x + b.B1*b.x1 + b.B2*b.x2 - b.A1*b.y1 - b.A2*b.y2
+	b.x2, b.x1 = b.x1, x
+	b.y2, b.y1 = b.y1, y
+	return y
+}
+
+// Reset puts the section in its steady state for a constant input, with an
+// output of 0 when it has no finite gain at zero frequency
+func (b *Biquad) Reset(x float64) float64 {
+	y := 0.0
+	if den := 1 + b.A1 + b.A2; den != 0 {
+		y = x * (b.B0 + b.B1 + b.B2) / den
+	}
+	b.x1, b.x2, b.y1, b.y2 = x, x, y, y
+	return y
+}
+
+// IIR is a general IIR filter,
+// A[0]*y = B[0]*x + B[1]*x[1] + ... - A[1]*y[1] - A[2]*y[2] - ...
+type IIR struct {
+	b, a []float64
+	x, y []float64 // Previous inputs and outputs, latest first
+}
+
+// NewIIR creates an IIR filter from its feedforward coefficients b and its
+// feedback coefficients a, normalized by a[0]
+func NewIIR(b, a []float64) (*IIR, error) {
+	if len(b) == 0 || len(a) == 0 || a[0] == 0 {
+		return nil, fmt.Errorf("IIR coefficients %v / %v: %w", b, a, utils.ErrInvalidParameter)
+	}
+	f := &IIR{b: make([]float64, len(b)), a: make([]float64, len(a))}
+	for i := range b {
+		f.b[i] = b[i] / a[0]
+	}
+	for i := range a {
+		f.a[i] = a[i] / a[0]
+	}
+	f.x = make([]float64, len(b))
+	f.y = make([]float64, len(a))
+	return f, nil
+}
+
+// Next filters a value
+func (f *IIR) Next(x float64) float64 {
+	copy(f.x[1:], f.x)
+	f.x[0] = x
+	y := 0.0
+	for k, c := range f.b {
+		y += c * f.x[k]
+	}
+	for k := 1; k < len(f.a); k++ {
+		y -= f.a[k] * f.y[k-1]
+	}
+	copy(f.y[1:], f.y)
+	f.y[0] = y
+	return y
+}
+
+// Reset puts the filter in its steady state for a constant input, with an
+// output of 0 when it has no finite gain at zero frequency
+func (f *IIR) Reset(x float64) float64 {
+	var num, den float64
+	for _, c := range f.b {
+		num += c
+	}
+	for _, c := range f.a {
+		den += c
+	}
+	y := 0.0
+	if den != 0 {
+		y = x * num / den
+	}
+	for i := range f.x {
+		f.x[i] = x
+	}
+	for i := range f.y {
+		f.y[i] = y
+	}
+	return y
+}
+
+// Cascade chains filters, each one filtering the output of the previous
+type Cascade []Filter
+
+// Next filters a value through every stage
+func (c Cascade) Next(x float64) float64 {
+	for _, f := range c {
+		x = f.Next(x)
+	}
+	return x
+}
+
+// Reset resets every stage to the steady state of the previous one
+func (c Cascade) Reset(x float64) float64 {
+	for _, f := range c {
+		x = f.Reset(x)
+	}
+	return x
+}
+
+// NewSuperSmoother creates the two pole Super Smoother of Ehlers, a low pass
+// filter attenuating the cycles shorter than period bars with little lag
+func NewSuperSmoother(period float64) (*Biquad, error) {
+	if !(period >= 2) {
+		return nil, fmt.Errorf("super smoother period %v below 2: %w", period, utils.ErrInvalidParameter)
+	}
+	a := math.Exp(-math.Sqrt2 * math.Pi / period)
+	c2 := 2 * a * math.Cos(math.Sqrt2*math.Pi/period)
+	c3 := -a * a
+	c1 := 1 - c2 - c3
+	return &Biquad{B0: c1 / 2, B1: c1 / 2, A1: -c2, A2: -c3}, nil
+}
+
+// NewHighPass creates the two pole high pass filter of Ehlers, removing the
+// cycles longer than period bars
+func NewHighPass(period float64) (*Biquad, error) {
+	if !(period > 2*math.Sqrt2) {
+		return nil, fmt.Errorf("high pass period %v not above 2.83: %w", period, utils.ErrInvalidParameter)
+	}
+	w := math.Sqrt2 * math.Pi / period
+	alpha := (math.Cos(w) + math.Sin(w) - 1) / math.Cos(w)
+	gain := (1 - alpha/2) * (1 - alpha/2)
+	return &Biquad{B0: gain, B1: -2 * gain, B2: gain, A1: -2 * (1 - alpha), A2: (1 - alpha) * (1 - alpha)}, nil
+}
+
+// NewOnePoleHighPass creates the one pole high pass filter of Ehlers,
+// removing the cycles longer than period bars
+func NewOnePoleHighPass(period float64) (*Biquad, error) {
+	if !(period > 4) {
+		return nil, fmt.Errorf("one pole high pass period %v not above 4: %w", period, utils.ErrInvalidParameter)
+	}
+	w := 2 * math.Pi / period
+	alpha := (math.Cos(w) + math.Sin(w) - 1) / math.Cos(w)
+	return &Biquad{B0: 1 - alpha/2, B1: alpha/2 - 1, A1: alpha - 1}, nil
+}
+
+// NewBandPass creates the band pass filter of Ehlers centred on cycles of
+// period bars, bandwidth being the width of the pass band as a fraction of
+// the centre frequency, such as 0.3
+func NewBandPass(period, bandwidth float64) (*Biquad, error) {
+	if !(period >= 2) || !(bandwidth > 0 && bandwidth < 1) {
+		return nil, fmt.Errorf("band pass period %v and bandwidth %v: %w", period, bandwidth, utils.ErrInvalidParameter)
+	}
+	beta := math.Cos(2 * math.Pi / period)
+	gamma := 1 / math.Cos(2*math.Pi*bandwidth/period)
+	alpha := gamma - math.Sqrt(gamma*gamma-1)
+	return &Biquad{B0: 0.5 * (1 - alpha), B2: -0.5 * (1 - alpha), A1: -beta * (1 + alpha), A2: alpha}, nil
+}
+
+// NewRoofing creates the roofing filter of Ehlers, a band pass made of a two
+// pole high pass filter of highPeriod bars and a Super Smoother of lowPeriod
+// bars, keeping the cycles between the two periods
+func NewRoofing(highPeriod, lowPeriod float64) (Cascade, error) {
+	if !(highPeriod > lowPeriod) {
+		return nil, fmt.Errorf("roofing periods %v and %v: %w", highPeriod, lowPeriod, utils.ErrInvalidParameter)
+	}
+	hp, err := NewHighPass(highPeriod)
+	if err != nil {
+		return nil, err
+	}
+	ss, err := NewSuperSmoother(lowPeriod)
+	if err != nil {
+		return nil, err
+	}
+	return Cascade{hp, ss}, nil
+}
@@ -0,0 +1,127 @@
+package dsp_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"slices"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/dsp"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// gain is the magnitude of the response of a biquad to cycles of period bars
+func gain(b *dsp.Biquad, period float64) float64 {
+	z := cmplx.Exp(complex(0, -2*math.Pi/period)) // One bar of delay
+	num := complex(b.B0, 0) + complex(b.B1, 0)*z + complex(b.B2, 0)*z*z
+	den := 1 + complex(b.A1, 0)*z + complex(b.A2, 0)*z*z
+	return cmplx.Abs(num / den)
+}
+
+// sine returns n values of a cycle of period bars around level
+func sine(n int, period, level float64) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = level + math.Sin(2*math.Pi*float64(i)/period)
+	}
+	return out
+}
+
+// amplitude is the amplitude of the cycle of period bars in values covering
+// whole cycles, by correlation with a sine and a cosine
+func amplitude(values []float64, period float64) float64 {
+	var s, c float64
+	for i, v := range values {
+		s += v * math.Sin(2*math.Pi*float64(i)/period)
+		c += v * math.Cos(2*math.Pi*float64(i)/period)
+	}
+	return 2 * math.Hypot(s, c) / float64(len(values))
+}
+
+// biquads returns a constructor check failing the test on errors
+func biquads(t *testing.T) func(*dsp.Biquad, error) *dsp.Biquad {
+	return func(b *dsp.Biquad, err error) *dsp.Biquad {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+}
+
+// Steady cycles come out with the gain of the coefficients, and the designs
+// pass and stop the expected periods
+func TestFrequencyResponse(t *testing.T) {
+	biquad := biquads(t)
+	for _, tc := range []struct {
+		name             string
+		filter           *dsp.Biquad
+		pass, stop       float64 // Periods
+		passMin, stopMax float64 // Gains
+	}{
+		{"super smoother", biquad(dsp.NewSuperSmoother(10)), 50, 2.5, 0.95, 0.05},
+		{"high pass", biquad(dsp.NewHighPass(48)), 10, 300, 0.95, 0.05},
+		{"one pole high pass", biquad(dsp.NewOnePoleHighPass(40)), 10, 400, 0.95, 0.1},
+		{"band pass", biquad(dsp.NewBandPass(20, 0.3)), 20, 50, 0.99, 0.3},
+	} {
+		for _, period := range []float64{4, 10, 20, 50, 200, 400} {
+			out := dsp.Apply(tc.filter, sine(4000, period, 0))
+			testutil.Near(t, fmt.Sprintf("%s gain for %v bars", tc.name, period), amplitude(out[2000:], period), gain(tc.filter, period), 1e-6)
+		}
+		if g := gain(tc.filter, tc.pass); g < tc.passMin {
+			t.Errorf("%s passes %v of the cycles of %v bars", tc.name, g, tc.pass)
+		}
+		if g := gain(tc.filter, tc.stop); g > tc.stopMax {
+			t.Errorf("%s passes %v of the cycles of %v bars", tc.name, g, tc.stop)
+		}
+	}
+}
+
+// A general IIR with the coefficients of a biquad gives the same values,
+// and constant inputs stay in the steady state
+func TestSteadyState(t *testing.T) {
+	close := utils.GetFieldSlice(testutil.MustOHLCV(t), utils.FieldClose)
+	smoother := biquads(t)(dsp.NewSuperSmoother(10))
+	iir, err := dsp.NewIIR([]float64{2 * smoother.B0, 2 * smoother.B1}, []float64{2, 2 * smoother.A1, 2 * smoother.A2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(dsp.Apply(iir, close), dsp.Apply(smoother, close)) {
+		t.Error("IIR and biquad super smoothers differ")
+	}
+	roofing, err := dsp.NewRoofing(48, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	constant := make([]float64, 100)
+	for i := range constant {
+		constant[i] = 70000
+	}
+	for i, v := range dsp.Apply(smoother, constant) {
+		if math.Abs(v-70000) > 1e-9 {
+			t.Errorf("super smoother of a constant is %v at %d", v, i)
+			break
+		}
+	}
+	for i, v := range dsp.Apply(roofing, constant) {
+		if math.Abs(v) > 1e-9 {
+			t.Errorf("roofing filter of a constant is %v at %d", v, i)
+			break
+		}
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	if _, err := dsp.NewOnePoleHighPass(4); !errors.Is(err, utils.ErrInvalidParameter) {
+		t.Errorf("one pole high pass of 4 bars: %v", err)
+	}
+	if _, err := dsp.NewRoofing(10, 48); !errors.Is(err, utils.ErrInvalidParameter) {
+		t.Errorf("roofing periods reversed: %v", err)
+	}
+	if _, err := dsp.NewIIR([]float64{1}, []float64{0, 1}); !errors.Is(err, utils.ErrInvalidParameter) {
+		t.Errorf("IIR with a[0] = 0: %v", err)
+	}
+}
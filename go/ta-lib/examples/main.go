@@ -142,4 +142,34 @@ func main() {
 		log.Fatal("Error calculating APO:", err)
 	}
 	fmt.Printf("APO Values: %v\n", apo.Values)
+
+	// Calculate Keltner Channels
+	fmt.Println("\nKeltner Channels:")
+	kc, err := indicators.KELTNER(high, low, close, 20, 10, 2.0)
+	if err != nil {
+		log.Fatal("Error calculating Keltner Channels:", err)
+	}
+	fmt.Printf("Middle Band: %v\n", kc.Values)
+	fmt.Printf("Upper Band: %v\n", kc.UpperBand)
+	fmt.Printf("Lower Band: %v\n", kc.LowerBand)
+
+	// Calculate SuperTrend
+	fmt.Println("\nSuperTrend:")
+	st, err := indicators.SUPERTREND(high, low, close, 10, 3.0)
+	if err != nil {
+		log.Fatal("Error calculating SuperTrend:", err)
+	}
+	fmt.Printf("SuperTrend: %v\n", st.Values)
+	fmt.Printf("Direction: %v\n", st.Direction)
+
+	// Calculate Ichimoku
+	fmt.Println("\nIchimoku Kinko Hyo:")
+	ichimoku, err := indicators.ICHIMOKU(high, low, close, 9, 26, 52, 26)
+	if err != nil {
+		log.Fatal("Error calculating Ichimoku:", err)
+	}
+	fmt.Printf("Tenkan-sen: %v\n", ichimoku.Values)
+	fmt.Printf("Kijun-sen: %v\n", ichimoku.Kijun)
+	fmt.Printf("Senkou Span A: %v\n", ichimoku.SenkouA)
+	fmt.Printf("Senkou Span B: %v\n", ichimoku.SenkouB)
 }
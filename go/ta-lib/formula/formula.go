@@ -0,0 +1,402 @@
+// Package formula compiles indicator formulas such as
+//
+//	CROSSOVER(EMA(close, 12), EMA(close, 26)) AND RSI(close, 14) < 70
+//
+// into pipelines evaluated in batch or streaming mode.
+//
+// Functions are the TA-Lib functions of the pipeline package with their
+// names from ta_func_api.xml. The inputs come first, then the optional
+// inputs in TA-Lib order, which may also be given by name as in
+// BBANDS(close, TimePeriod=20, MAType=EMA). Without inputs a function reads
+// the bar fields, so RSI(14) is RSI(close, 14) and ATR(14) uses the high, low
+// and close. Outputs other than the first are selected with a dot, as in
+// MACD(close).MACDSignal or BBANDS(close, 20).LowerBand.
+//
+// The bar fields are open, high, low, close, volume, openinterest,
+// quotevolume, tradecount and vwap. The operators are + - * /, the
+// comparisons < <= > >= == !=, and AND, OR, NOT (or &&, ||, !). Comparisons
+// and logical operators give 1 for true and 0 for false. CROSSOVER(a, b) and
+// CROSSUNDER(a, b), also named CROSSUP and CROSSDOWN, are 1 on the bar where a
+// crosses b, and REF(x, n) is the value of x n bars earlier. Names are case
+// insensitive.
+package formula
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/petercool/ta-lib/go/ta-lib/pipeline"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Error is a compilation error at a position of the formula
+type Error struct {
+	Line   int // 1-based line
+	Column int // 1-based column, in characters
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Msg)
+}
+
+// Unwrap makes the errors match utils.ErrInvalidParameter
+func (e *Error) Unwrap() error {
+	return utils.ErrInvalidParameter
+}
+
+// errorAt creates an error at a byte offset of the source
+func errorAt(src string, pos int, format string, args ...any) *Error {
+	before := src[:pos]
+	line := strings.Count(before, "\n") + 1
+	column := len([]rune(before[strings.LastIndex(before, "\n")+1:])) + 1
+	return &Error{Line: line, Column: column, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Program is a compiled formula. It holds no state, so it can be run on
+// any number of bar sets and streams.
+type Program struct {
+	source   string
+	expr     pipeline.Expr
+	pipeline *pipeline.Pipeline
+}
+
+// Compile parses a formula and builds its pipeline
+func Compile(source string) (*Program, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{src: source, tokens: tokens}
+	tree, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	c := &compiler{src: source}
+	expr, err := c.compile(tree)
+	if err != nil {
+		return nil, err
+	}
+	compiled, err := pipeline.Compile(expr)
+	if err != nil {
+		return nil, errorAt(source, tree.position(), "%v", err)
+	}
+	return &Program{source: source, expr: expr, pipeline: compiled}, nil
+}
+
+// Source returns the formula as written
+func (p *Program) Source() string {
+	return p.source
+}
+
+// String returns the canonical form of the formula, with all parameters
+func (p *Program) String() string {
+	return p.expr.String()
+}
+
+// Expr returns the expression of the formula, to be combined with others in a pipeline
+func (p *Program) Expr() pipeline.Expr {
+	return p.expr
+}
+
+// Lookback returns the number of bars consumed before the first value
+func (p *Program) Lookback() int {
+	return p.pipeline.Lookback()
+}
+
+// Run evaluates the formula over all bars
+func (p *Program) Run(bars *utils.Bars) (*utils.Result, error) {
+	results, err := p.pipeline.Run(bars)
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// Stream evaluates a formula one bar at a time
+type Stream struct {
+	s *pipeline.Stream
+}
+
+// Stream creates a streaming evaluation of the formula
+func (p *Program) Stream() (*Stream, error) {
+	s, err := p.pipeline.Stream()
+	if err != nil {
+		return nil, err
+	}
+	return &Stream{s: s}, nil
+}
+
+// Update adds a bar and returns the value of the formula, false during the lookback period
+func (s *Stream) Update(bar utils.OHLCV) (float64, bool) {
+	v := s.s.Update(bar)[0]
+	return v, s.s.Ready(0)
+}
+
+// fields are the bar fields by lower case name
+var fields = map[string]utils.Field{
+	"open":         utils.FieldOpen,
+	"high":         utils.FieldHigh,
+	"low":          utils.FieldLow,
+	"close":        utils.FieldClose,
+	"volume":       utils.FieldVolume,
+	"openinterest": utils.FieldOpenInterest,
+	"quotevolume":  utils.FieldQuoteVolume,
+	"tradecount":   utils.FieldTradeCount,
+	"vwap":         utils.FieldVWAP,
+}
+
+// lookupField finds a bar field, ignoring case and underscores
+func lookupField(name string) (utils.Field, bool) {
+	f, ok := fields[strings.ToLower(strings.ReplaceAll(name, "_", ""))]
+	return f, ok
+}
+
+// lookupMAType finds a moving average type by its TA-Lib name
+func lookupMAType(name string) (utils.MAType, bool) {
+	for t := utils.MAType(0); t < 64; t++ {
+		if utils.ValidateMAType(t) == utils.Success && strings.EqualFold(t.String(), name) {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// compiler turns the syntax tree into pipeline expressions
+type compiler struct {
+	src string
+}
+
+func (c *compiler) errorAt(n node, format string, args ...any) error {
+	return errorAt(c.src, n.position(), format, args...)
+}
+
+// check reports the error of an expression at a node
+func (c *compiler) check(n node, e pipeline.Expr) (pipeline.Expr, error) {
+	if err := e.Err(); err != nil {
+		return e, c.errorAt(n, "%s", strings.TrimSuffix(err.Error(), ": "+utils.ErrInvalidParameter.Error()))
+	}
+	return e, nil
+}
+
+func (c *compiler) compile(n node) (pipeline.Expr, error) {
+	switch n := n.(type) {
+	case *numberNode:
+		return pipeline.Const(n.value), nil
+
+	case *identNode:
+		if f, ok := lookupField(n.name); ok {
+			return pipeline.Field(f), nil
+		}
+		if _, ok := pipeline.Describe(n.name); ok {
+			return c.check(n, pipeline.Call(n.name, nil))
+		}
+		return pipeline.Expr{}, c.errorAt(n, "unknown name %s", n.name)
+
+	case *callNode:
+		return c.compileCall(n)
+
+	case *unaryNode:
+		x, err := c.compile(n.x)
+		if err != nil {
+			return x, err
+		}
+		if n.op == "NOT" {
+			return c.check(n, pipeline.Not(x))
+		}
+		return c.check(n, pipeline.Neg(x))
+
+	case *binaryNode:
+		a, err := c.compile(n.a)
+		if err != nil {
+			return a, err
+		}
+		b, err := c.compile(n.b)
+		if err != nil {
+			return b, err
+		}
+		return c.check(n, pipeline.Binary(n.op, a, b))
+
+	case *outNode:
+		x, err := c.compile(n.x)
+		if err != nil {
+			return x, err
+		}
+		return c.check(n, x.Out(n.name))
+	}
+	return pipeline.Expr{}, errors.New("unknown syntax node")
+}
+
+// compileCall compiles the built-in functions and the TA-Lib functions
+func (c *compiler) compileCall(n *callNode) (pipeline.Expr, error) {
+	switch strings.ToUpper(n.name) {
+	case "CROSSOVER", "CROSSUP":
+		return c.compileCross(n, pipeline.CrossOver)
+	case "CROSSUNDER", "CROSSDOWN":
+		return c.compileCross(n, pipeline.CrossUnder)
+	case "REF":
+		if len(n.args) != 2 {
+			return pipeline.Expr{}, c.errorAt(n, "REF takes a series and a number of bars")
+		}
+		x, err := c.compileInput(n.args[0])
+		if err != nil {
+			return x, err
+		}
+		bars, err := c.integer(n.args[1].value)
+		if err != nil {
+			return x, err
+		}
+		return c.check(n, pipeline.Ref(x, bars))
+	}
+
+	info, ok := pipeline.Describe(n.name)
+	if !ok {
+		return pipeline.Expr{}, c.errorAt(n, "unknown function %s", n.name)
+	}
+
+	// Leading positional arguments are inputs, unless all of them are numbers
+	var positional []argNode
+	var named []argNode
+	for _, arg := range n.args {
+		if arg.name != "" {
+			named = append(named, arg)
+		} else if len(named) > 0 {
+			return pipeline.Expr{}, c.errorAt(arg.value, "positional argument after a named one")
+		} else {
+			positional = append(positional, arg)
+		}
+	}
+	nbInputs := len(info.Inputs)
+	if allParams(positional) {
+		nbInputs = 0
+	}
+	if nbInputs > 0 && len(positional) < nbInputs {
+		return pipeline.Expr{}, c.errorAt(n, "%s takes %d inputs (%s), got %d", info.Name, nbInputs, strings.Join(info.Inputs, ", "), len(positional))
+	}
+
+	var inputs []pipeline.Expr
+	for _, arg := range positional[:nbInputs] {
+		x, err := c.compileInput(arg)
+		if err != nil {
+			return x, err
+		}
+		inputs = append(inputs, x)
+	}
+
+	params := pipeline.Params{}
+	for i, arg := range positional[nbInputs:] {
+		if i >= len(info.Params) {
+			return pipeline.Expr{}, c.errorAt(arg.value, "too many arguments, %s takes %d optional inputs (%s)", info.Name, len(info.Params), strings.Join(info.Params, ", "))
+		}
+		v, err := c.param(arg.value)
+		if err != nil {
+			return pipeline.Expr{}, err
+		}
+		if err := c.checkParam(arg.value, info.Name, info.Params[i], v, inputs); err != nil {
+			return pipeline.Expr{}, err
+		}
+		params[info.Params[i]] = v
+	}
+	for _, arg := range named {
+		if !hasParam(info, arg.name) {
+			return pipeline.Expr{}, errorAt(c.src, arg.namePos, "%s has no optional input %s, it takes %s", info.Name, arg.name, strings.Join(info.Params, ", "))
+		}
+		v, err := c.param(arg.value)
+		if err != nil {
+			return pipeline.Expr{}, err
+		}
+		if err := c.checkParam(arg.value, info.Name, arg.name, v, inputs); err != nil {
+			return pipeline.Expr{}, err
+		}
+		params[arg.name] = v
+	}
+	return c.check(n, pipeline.Call(info.Name, params, inputs...))
+}
+
+// checkParam reports an invalid optional input at its argument, by calling
+// the function with this input alone. Inputs only invalid together are
+// reported at the call.
+func (c *compiler) checkParam(n node, function, name string, v any, inputs []pipeline.Expr) error {
+	_, err := c.check(n, pipeline.Call(function, pipeline.Params{name: v}, inputs...))
+	return err
+}
+
+func (c *compiler) compileCross(n *callNode, cross func(a, b pipeline.Expr) pipeline.Expr) (pipeline.Expr, error) {
+	if len(n.args) != 2 {
+		return pipeline.Expr{}, c.errorAt(n, "%s takes two series", strings.ToUpper(n.name))
+	}
+	a, err := c.compileInput(n.args[0])
+	if err != nil {
+		return a, err
+	}
+	b, err := c.compileInput(n.args[1])
+	if err != nil {
+		return b, err
+	}
+	return c.check(n, cross(a, b))
+}
+
+// compileInput compiles a series argument, which cannot be named
+func (c *compiler) compileInput(arg argNode) (pipeline.Expr, error) {
+	if arg.name != "" {
+		return pipeline.Expr{}, errorAt(c.src, arg.namePos, "unexpected named argument %s", arg.name)
+	}
+	return c.compile(arg.value)
+}
+
+// allParams reports whether the arguments are all numbers or MA type names
+func allParams(args []argNode) bool {
+	for _, arg := range args {
+		switch v := arg.value.(type) {
+		case *numberNode:
+		case *identNode:
+			if _, ok := lookupMAType(v.name); !ok {
+				return false
+			}
+			if _, ok := lookupField(v.name); ok {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func hasParam(info pipeline.FunctionInfo, name string) bool {
+	norm := func(s string) string { return strings.ToLower(strings.ReplaceAll(s, "_", "")) }
+	for _, p := range info.Params {
+		if norm(p) == norm(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// param evaluates an optional input, a number or an MA type name
+func (c *compiler) param(n node) (any, error) {
+	switch v := n.(type) {
+	case *numberNode:
+		return v.value, nil
+	case *identNode:
+		if t, ok := lookupMAType(v.name); ok {
+			return t, nil
+		}
+	}
+	return nil, c.errorAt(n, "optional inputs must be numbers or MA types")
+}
+
+// integer evaluates a whole number argument
+func (c *compiler) integer(n node) (int, error) {
+	v, ok := n.(*numberNode)
+	if !ok || v.value != math.Trunc(v.value) {
+		return 0, c.errorAt(n, "expected a whole number of bars")
+	}
+	// Range check before the conversion, which is undefined for huge values
+	if v.value < 0 || v.value > utils.MaxPeriod {
+		return 0, c.errorAt(n, "number of bars %g out of range [0, %d]", v.value, utils.MaxPeriod)
+	}
+	return int(v.value), nil
+}
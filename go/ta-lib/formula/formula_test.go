@@ -0,0 +1,186 @@
+package formula_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/formula"
+	"github.com/petercool/ta-lib/go/ta-lib/indicators"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// runFormula evaluates a formula in batch and streaming mode, which must agree
+func runFormula(t *testing.T, data []utils.OHLCV, source string) *utils.Result {
+	t.Helper()
+	p, err := formula.Compile(source)
+	if err != nil {
+		t.Fatalf("%s: %v", source, err)
+	}
+	batch, err := p.Run(utils.BarsFromOHLCV(data))
+	if err != nil {
+		t.Fatalf("%s: %v", source, err)
+	}
+	if batch.BeginIndex != p.Lookback() {
+		t.Fatalf("%s: first value at bar %d, lookback is %d", source, batch.BeginIndex, p.Lookback())
+	}
+	s, err := p.Stream()
+	if err != nil {
+		t.Fatalf("%s: %v", source, err)
+	}
+	for i, bar := range data {
+		v, ok := s.Update(bar)
+		if ok != (i >= batch.BeginIndex) {
+			t.Fatalf("%s: stream ready %v at bar %d, batch starts at %d", source, ok, i, batch.BeginIndex)
+		}
+		if ok && v != batch.Values[i-batch.BeginIndex] {
+			t.Fatalf("%s: stream gives %v at bar %d, batch %v", source, v, i, batch.Values[i-batch.BeginIndex])
+		}
+	}
+	return batch
+}
+
+// The optional fields of the bars are read in batch mode as when streaming
+func TestOptionalFields(t *testing.T) {
+	data := make([]utils.OHLCV, 8)
+	for i := range data {
+		x := float64(i + 1)
+		data[i] = utils.OHLCV{
+			Time: int64(i) * 60, Open: x, High: x + 1, Low: x - 1, Close: x, Volume: 10 * x,
+			OpenInterest: 15 + x, QuoteVolume: 100 * x, TradeCount: int64(i + 2), VWAP: x + 0.5,
+		}
+	}
+	for _, tc := range []struct {
+		source string
+		want   func(bar utils.OHLCV) float64
+	}{
+		{"vwap + openinterest", func(bar utils.OHLCV) float64 { return bar.VWAP + bar.OpenInterest }},
+		{"quotevolume / tradecount", func(bar utils.OHLCV) float64 { return bar.QuoteVolume / float64(bar.TradeCount) }},
+	} {
+		got := runFormula(t, data, tc.source)
+		if got.BeginIndex != 0 || len(got.Values) != len(data) {
+			t.Fatalf("%s: %d values from bar %d", tc.source, len(got.Values), got.BeginIndex)
+		}
+		for i, bar := range data {
+			if want := tc.want(bar); got.Values[i] != want {
+				t.Errorf("%s: %v at bar %d, want %v", tc.source, got.Values[i], i, want)
+			}
+		}
+	}
+	sma := runFormula(t, data, "SMA(vwap, 3)")
+	if sma.BeginIndex != 2 || sma.Values[0] != 2.5 {
+		t.Errorf("SMA(vwap, 3) starts at bar %d with %v", sma.BeginIndex, sma.Values[0])
+	}
+}
+
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Formulas give the values of the same rules written with the indicators
+func TestEvaluation(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	_, high, low, close, _ := utils.GetOHLCVSlices(data)
+	ema12, _ := indicators.EMA(close, 12)
+	ema26, _ := indicators.EMA(close, 26)
+	rsi, _ := indicators.RSI(close, 14)
+	atr, _ := indicators.ATR(high, low, close, 14)
+	at := func(r *utils.Result, i int) float64 { return r.Values[i-r.BeginIndex] }
+
+	for _, tc := range []struct {
+		source string
+		begIdx int
+		want   func(i int) float64
+	}{
+		{"CROSSOVER(EMA(close,12), EMA(close,26)) AND RSI(close,14) < 70", 26, func(i int) float64 {
+			cross := at(ema12, i) > at(ema26, i) && at(ema12, i-1) <= at(ema26, i-1)
+			return boolFloat(cross && at(rsi, i) < 70)
+		}},
+		{"crossdown(ema(12), ema(26)) || !(rsi(14) >= 30)", 26, func(i int) float64 {
+			cross := at(ema12, i) < at(ema26, i) && at(ema12, i-1) >= at(ema26, i-1)
+			return boolFloat(cross || !(at(rsi, i) >= 30))
+		}},
+		{"(close - REF(close, 5)) / ATR(14) * 2", 14, func(i int) float64 {
+			return (close[i] - close[i-5]) / at(atr, i) * 2
+		}},
+		{"-REF(high, 3) + low", 3, func(i int) float64 { return -high[i-3] + low[i] }},
+	} {
+		got := runFormula(t, data, tc.source)
+		if got.BeginIndex != tc.begIdx || len(got.Values) != len(data)-tc.begIdx {
+			t.Errorf("%s: %d values from bar %d, want them from bar %d", tc.source, len(got.Values), got.BeginIndex, tc.begIdx)
+			continue
+		}
+		for k, v := range got.Values {
+			if want := tc.want(tc.begIdx + k); math.Float64bits(v) != math.Float64bits(want) {
+				t.Errorf("%s: bar %d is %v, want %v", tc.source, tc.begIdx+k, v, want)
+				break
+			}
+		}
+	}
+
+	// Output selection and named optional inputs
+	bands := runFormula(t, data, "BBANDS(close, 20, MAType=EMA).LowerBand")
+	want, _ := indicators.BBANDS(close, 20, 2, 2, utils.EMA)
+	if bands.BeginIndex != want.BeginIndex || len(bands.Values) != len(want.LowerBand) {
+		t.Fatalf("BBANDS lower band: %d values from bar %d, want %d from bar %d", len(bands.Values), bands.BeginIndex, len(want.LowerBand), want.BeginIndex)
+	}
+	for k, v := range bands.Values {
+		if math.Float64bits(v) != math.Float64bits(want.LowerBand[k]) {
+			t.Fatalf("BBANDS lower band: bar %d is %v, want %v", want.BeginIndex+k, v, want.LowerBand[k])
+		}
+	}
+	if math.IsNaN(bands.Values[0]) {
+		t.Error("BBANDS lower band is NaN")
+	}
+}
+
+// MA takes the type of any average of a series, named as its own function
+func TestMATypes(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	for _, maType := range []utils.MAType{utils.HMA, utils.ALMA, utils.ZLEMA, utils.SMMA, utils.MCGINLEY} {
+		source := fmt.Sprintf("MA(close, 10, %v) - %v(close, 10)", maType, maType)
+		for i, v := range runFormula(t, data, source).Values {
+			if v != 0 {
+				t.Errorf("MA(10, %v) differs from %v(10) by %v at bar %d", maType, maType, v, i)
+				break
+			}
+		}
+	}
+}
+
+// Compile errors give the position of the faulty token
+func TestCompileErrors(t *testing.T) {
+	for _, tc := range []struct {
+		source       string
+		line, column int
+	}{
+		{"RSI(close, 1)", 1, 12},
+		{"RSI(close, 14.5)", 1, 12},
+		{"close > 1 AND\n  BBANDS(close, 20, NbDevUp=2, MAType=99)", 2, 39},
+		{"RSI(close, 14, 3)", 1, 16},
+		{"close > FOO(3)", 1, 9},
+		{"close >", 1, 8},
+		{"close = open", 1, 7},
+		{"MACD(close).Trigger", 1, 13},
+		{"EMA(close,\n  Period=3)", 2, 3},
+		{"REF(close, 1.5)", 1, 12},
+		{"REF(close, -1)", 1, 12},
+		{"REF(close, 1000000000000)", 1, 12},
+		{"close $ 2", 1, 7},
+	} {
+		_, err := formula.Compile(tc.source)
+		var ferr *formula.Error
+		if !errors.As(err, &ferr) || !errors.Is(err, utils.ErrInvalidParameter) {
+			t.Errorf("%q compiled with error %v", tc.source, err)
+			continue
+		}
+		if ferr.Line != tc.line || ferr.Column != tc.column {
+			t.Errorf("%q: error at %d:%d, want %d:%d (%v)", tc.source, ferr.Line, ferr.Column, tc.line, tc.column, err)
+		}
+	}
+}
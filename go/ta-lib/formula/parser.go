@@ -0,0 +1,414 @@
+package formula
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/petercool/ta-lib/go/ta-lib/pipeline"
+)
+
+// tokenKind classifies the tokens of a formula
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokIdent
+	tokOp // Operators and punctuation
+)
+
+// token is a lexical element with its byte offset in the source
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// operators are the symbols of the language, longest first
+var operators = []string{"<=", ">=", "==", "!=", "&&", "||", "+", "-", "*", "/", "<", ">", "!", "(", ")", ",", ".", "="}
+
+// lex splits a formula into tokens
+func lex(src string) ([]token, error) {
+	var tokens []token
+	pos := 0
+	for pos < len(src) {
+		r, size := utf8.DecodeRuneInString(src[pos:])
+		switch {
+		case unicode.IsSpace(r):
+			pos += size
+		case unicode.IsDigit(r) || r == '.' && pos+1 < len(src) && isDigit(src[pos+1]):
+			start := pos
+			for pos < len(src) && (isDigit(src[pos]) || src[pos] == '.') {
+				pos++
+			}
+			if pos < len(src) && (src[pos] == 'e' || src[pos] == 'E') {
+				pos++
+				if pos < len(src) && (src[pos] == '+' || src[pos] == '-') {
+					pos++
+				}
+				for pos < len(src) && isDigit(src[pos]) {
+					pos++
+				}
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: src[start:pos], pos: start})
+		case unicode.IsLetter(r) || r == '_':
+			start := pos
+			for pos < len(src) {
+				r, size := utf8.DecodeRuneInString(src[pos:])
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+					break
+				}
+				pos += size
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[start:pos], pos: start})
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(src[pos:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, errorAt(src, pos, "unexpected character %q", r)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: pos})
+			pos += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// node is an element of the syntax tree
+type node interface {
+	position() int
+}
+
+type numberNode struct {
+	pos   int
+	value float64
+}
+
+type identNode struct {
+	pos  int
+	name string
+}
+
+// argNode is an argument of a call, named for optional inputs given as name=value
+type argNode struct {
+	name    string
+	namePos int
+	value   node
+}
+
+type callNode struct {
+	pos  int
+	name string
+	args []argNode
+}
+
+type unaryNode struct {
+	pos int
+	op  string // "-" or "NOT"
+	x   node
+}
+
+type binaryNode struct {
+	pos  int
+	op   pipeline.Op
+	a, b node
+}
+
+// outNode selects an output of a multi-output function, as in MACD(close).Signal
+type outNode struct {
+	pos  int
+	x    node
+	name string
+}
+
+func (n *numberNode) position() int { return n.pos }
+func (n *identNode) position() int  { return n.pos }
+func (n *callNode) position() int   { return n.pos }
+func (n *unaryNode) position() int  { return n.pos }
+func (n *binaryNode) position() int { return n.pos }
+func (n *outNode) position() int    { return n.pos }
+
+// parser builds the syntax tree by recursive descent, from the lowest
+// precedence: OR, AND, NOT, comparisons, + and -, * and /, unary minus, then
+// output selection and primary expressions
+type parser struct {
+	src    string
+	tokens []token
+	next   int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) advance() token {
+	t := p.tokens[p.next]
+	if t.kind != tokEOF {
+		p.next++
+	}
+	return t
+}
+
+// accept consumes the next token if it is one of the operators or keywords
+func (p *parser) accept(ops ...string) (token, bool) {
+	t := p.peek()
+	for _, op := range ops {
+		if t.kind == tokOp && t.text == op || t.kind == tokIdent && strings.EqualFold(t.text, op) {
+			return p.advance(), true
+		}
+	}
+	return t, false
+}
+
+func (p *parser) expect(op string) (token, error) {
+	if t, ok := p.accept(op); ok {
+		return t, nil
+	}
+	return token{}, p.unexpected("expected " + op)
+}
+
+// unexpected reports the next token
+func (p *parser) unexpected(context string) error {
+	t := p.peek()
+	if t.kind == tokEOF {
+		return errorAt(p.src, t.pos, "%s, found end of formula", context)
+	}
+	return errorAt(p.src, t.pos, "%s, found %q", context, t.text)
+}
+
+func (p *parser) parse() (node, error) {
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, p.unexpected("expected an operator")
+	}
+	return n, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	a, err := p.parseAnd()
+	for err == nil {
+		t, ok := p.accept("OR", "||")
+		if !ok {
+			return a, nil
+		}
+		var b node
+		if b, err = p.parseAnd(); err == nil {
+			a = &binaryNode{pos: t.pos, op: pipeline.OpOr, a: a, b: b}
+		}
+	}
+	return nil, err
+}
+
+func (p *parser) parseAnd() (node, error) {
+	a, err := p.parseNot()
+	for err == nil {
+		t, ok := p.accept("AND", "&&")
+		if !ok {
+			return a, nil
+		}
+		var b node
+		if b, err = p.parseNot(); err == nil {
+			a = &binaryNode{pos: t.pos, op: pipeline.OpAnd, a: a, b: b}
+		}
+	}
+	return nil, err
+}
+
+func (p *parser) parseNot() (node, error) {
+	if t, ok := p.accept("NOT", "!"); ok {
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{pos: t.pos, op: "NOT", x: x}, nil
+	}
+	return p.parseComparison()
+}
+
+// comparisons maps the comparison symbols to operators
+var comparisons = map[string]pipeline.Op{
+	"<":  pipeline.OpLess,
+	"<=": pipeline.OpLessEqual,
+	">":  pipeline.OpGreater,
+	">=": pipeline.OpGreaterEqual,
+	"==": pipeline.OpEqual,
+	"!=": pipeline.OpNotEqual,
+}
+
+func (p *parser) parseComparison() (node, error) {
+	a, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := p.accept("<", "<=", ">", ">=", "==", "!="); ok {
+		b, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		a = &binaryNode{pos: t.pos, op: comparisons[t.text], a: a, b: b}
+	}
+	if t, ok := p.accept("<", "<=", ">", ">=", "==", "!="); ok {
+		return nil, errorAt(p.src, t.pos, "comparisons cannot be chained, use AND")
+	}
+	if t, ok := p.accept("="); ok {
+		return nil, errorAt(p.src, t.pos, "use == to compare")
+	}
+	return a, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	a, err := p.parseTerm()
+	for err == nil {
+		t, ok := p.accept("+", "-")
+		if !ok {
+			return a, nil
+		}
+		var b node
+		if b, err = p.parseTerm(); err == nil {
+			op := pipeline.OpAdd
+			if t.text == "-" {
+				op = pipeline.OpSub
+			}
+			a = &binaryNode{pos: t.pos, op: op, a: a, b: b}
+		}
+	}
+	return nil, err
+}
+
+func (p *parser) parseTerm() (node, error) {
+	a, err := p.parseUnary()
+	for err == nil {
+		t, ok := p.accept("*", "/")
+		if !ok {
+			return a, nil
+		}
+		var b node
+		if b, err = p.parseUnary(); err == nil {
+			op := pipeline.OpMul
+			if t.text == "/" {
+				op = pipeline.OpDiv
+			}
+			a = &binaryNode{pos: t.pos, op: op, a: a, b: b}
+		}
+	}
+	return nil, err
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if t, ok := p.accept("-", "+"); ok {
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if t.text == "+" {
+			return x, nil
+		}
+		// Negative literals stay numbers, so they can be parameters
+		if n, ok := x.(*numberNode); ok {
+			return &numberNode{pos: t.pos, value: -n.value}, nil
+		}
+		return &unaryNode{pos: t.pos, op: "-", x: x}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("."); !ok {
+			return x, nil
+		}
+		t := p.peek()
+		if t.kind != tokIdent {
+			return nil, p.unexpected("expected an output name")
+		}
+		p.advance()
+		x = &outNode{pos: t.pos, x: x, name: t.text}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokNumber:
+		p.advance()
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, errorAt(p.src, t.pos, "invalid number %s", t.text)
+		}
+		return &numberNode{pos: t.pos, value: v}, nil
+
+	case t.kind == tokIdent && !isKeyword(t.text):
+		p.advance()
+		if _, ok := p.accept("("); !ok {
+			return &identNode{pos: t.pos, name: t.text}, nil
+		}
+		call := &callNode{pos: t.pos, name: t.text}
+		if _, ok := p.accept(")"); ok {
+			return call, nil
+		}
+		for {
+			arg, err := p.parseArg()
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+			if _, ok := p.accept(")"); ok {
+				return call, nil
+			}
+			if _, ok := p.accept(","); !ok {
+				return nil, p.unexpected("expected , or )")
+			}
+		}
+
+	case t.kind == tokOp && t.text == "(":
+		p.advance()
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return x, nil
+	}
+	return nil, p.unexpected("expected a value")
+}
+
+// parseArg parses a call argument, either an expression or name=value
+func (p *parser) parseArg() (argNode, error) {
+	t := p.peek()
+	if t.kind == tokIdent && p.tokens[p.next+1].kind == tokOp && p.tokens[p.next+1].text == "=" {
+		p.advance()
+		p.advance()
+		value, err := p.parseOr()
+		return argNode{name: t.text, namePos: t.pos, value: value}, err
+	}
+	value, err := p.parseOr()
+	return argNode{value: value}, err
+}
+
+func isKeyword(name string) bool {
+	switch strings.ToUpper(name) {
+	case "AND", "OR", "NOT":
+		return true
+	}
+	return false
+}
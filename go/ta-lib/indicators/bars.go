@@ -0,0 +1,163 @@
+package indicators
+
+import (
+	"time"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Entry points taking utils.Bars for the indicators that need more than one
+// input series. Single series indicators take a column directly, e.g.
+// RSI(bars.Close, 14).
+
+// barsOrEmpty returns bars, or empty Bars when nil so the input checks report empty data
+func barsOrEmpty(bars *utils.Bars) *utils.Bars {
+	if bars == nil {
+		return &utils.Bars{}
+	}
+	return bars
+}
+
+// AVGPRICEBars calculates AVGPRICE on the open, high, low and close of bars
+func AVGPRICEBars(bars *utils.Bars) (*utils.Result, error) {
+	b := barsOrEmpty(bars)
+	return AVGPRICE(b.Open, b.High, b.Low, b.Close)
+}
+
+// MEDPRICEBars calculates MEDPRICE on the high and low of bars
+func MEDPRICEBars(bars *utils.Bars) (*utils.Result, error) {
+	b := barsOrEmpty(bars)
+	return MEDPRICE(b.High, b.Low)
+}
+
+// TYPPRICEBars calculates TYPPRICE on the high, low and close of bars
+func TYPPRICEBars(bars *utils.Bars) (*utils.Result, error) {
+	b := barsOrEmpty(bars)
+	return TYPPRICE(b.High, b.Low, b.Close)
+}
+
+// WCLPRICEBars calculates WCLPRICE on the high, low and close of bars
+func WCLPRICEBars(bars *utils.Bars) (*utils.Result, error) {
+	b := barsOrEmpty(bars)
+	return WCLPRICE(b.High, b.Low, b.Close)
+}
+
+// TRANGEBars calculates TRANGE on the high, low and close of bars
+func TRANGEBars(bars *utils.Bars) (*utils.Result, error) {
+	b := barsOrEmpty(bars)
+	return TRANGE(b.High, b.Low, b.Close)
+}
+
+// ATRBars calculates ATR on the high, low and close of bars
+func ATRBars(bars *utils.Bars, optInTimePeriod int) (*utils.Result, error) {
+	b := barsOrEmpty(bars)
+	return ATR(b.High, b.Low, b.Close, optInTimePeriod)
+}
+
+// STOCHBars calculates STOCH on the high, low and close of bars
+func STOCHBars(bars *utils.Bars, optInFastKPeriod, optInSlowKPeriod, optInSlowDPeriod int, optInMATypes ...utils.MAType) (*STOCHResult, error) {
+	b := barsOrEmpty(bars)
+	return STOCH(b.High, b.Low, b.Close, optInFastKPeriod, optInSlowKPeriod, optInSlowDPeriod, optInMATypes...)
+}
+
+// STOCHFBars calculates STOCHF on the high, low and close of bars
+func STOCHFBars(bars *utils.Bars, optInFastKPeriod, optInFastDPeriod int, optInFastDMAType ...utils.MAType) (*STOCHFResult, error) {
+	b := barsOrEmpty(bars)
+	return STOCHF(b.High, b.Low, b.Close, optInFastKPeriod, optInFastDPeriod, optInFastDMAType...)
+}
+
+// CCIBars calculates CCI on the high, low and close of bars
+func CCIBars(bars *utils.Bars, optInTimePeriod int) (*utils.Result, error) {
+	b := barsOrEmpty(bars)
+	return CCI(b.High, b.Low, b.Close, optInTimePeriod)
+}
+
+// WILLRBars calculates WILLR on the high, low and close of bars
+func WILLRBars(bars *utils.Bars, optInTimePeriod int) (*utils.Result, error) {
+	b := barsOrEmpty(bars)
+	return WILLR(b.High, b.Low, b.Close, optInTimePeriod)
+}
+
+// PLUS_DIBars calculates PLUS_DI on the high, low and close of bars
+func PLUS_DIBars(bars *utils.Bars, optInTimePeriod int) (*utils.Result, error) {
+	b := barsOrEmpty(bars)
+	return PLUS_DI(b.High, b.Low, b.Close, optInTimePeriod)
+}
+
+// MINUS_DIBars calculates MINUS_DI on the high, low and close of bars
+func MINUS_DIBars(bars *utils.Bars, optInTimePeriod int) (*utils.Result, error) {
+	b := barsOrEmpty(bars)
+	return MINUS_DI(b.High, b.Low, b.Close, optInTimePeriod)
+}
+
+// DXBars calculates DX on the high, low and close of bars
+func DXBars(bars *utils.Bars, optInTimePeriod int) (*utils.Result, error) {
+	b := barsOrEmpty(bars)
+	return DX(b.High, b.Low, b.Close, optInTimePeriod)
+}
+
+// ADXBars calculates ADX on the high, low and close of bars
+func ADXBars(bars *utils.Bars, optInTimePeriod int) (*ADXResult, error) {
+	b := barsOrEmpty(bars)
+	return ADX(b.High, b.Low, b.Close, optInTimePeriod)
+}
+
+// OBVBars calculates OBV on the close and volume of bars
+func OBVBars(bars *utils.Bars) (*utils.Result, error) {
+	b := barsOrEmpty(bars)
+	return OBV(b.Close, b.Volume)
+}
+
+// VWMABars calculates VWMA on the close and volume of bars
+func VWMABars(bars *utils.Bars, optInTimePeriod int) (*utils.Result, error) {
+	b := barsOrEmpty(bars)
+	return VWMA(b.Close, b.Volume, optInTimePeriod)
+}
+
+// ADBars calculates AD on the high, low, close and volume of bars
+func ADBars(bars *utils.Bars) (*utils.Result, error) {
+	b := barsOrEmpty(bars)
+	return AD(b.High, b.Low, b.Close, b.Volume)
+}
+
+// MFIBars calculates MFI on the high, low, close and volume of bars
+func MFIBars(bars *utils.Bars, optInTimePeriod int) (*utils.Result, error) {
+	b := barsOrEmpty(bars)
+	return MFI(b.High, b.Low, b.Close, b.Volume, optInTimePeriod)
+}
+
+// ICHIMOKUBars calculates ICHIMOKU on the high, low and close of bars
+func ICHIMOKUBars(bars *utils.Bars, optInTenkanPeriod, optInKijunPeriod, optInSenkouBPeriod, optInDisplacement int) (*ICHIMOKUResult, error) {
+	b := barsOrEmpty(bars)
+	return ICHIMOKU(b.High, b.Low, b.Close, optInTenkanPeriod, optInKijunPeriod, optInSenkouBPeriod, optInDisplacement)
+}
+
+// KELTNERBars calculates KELTNER on the high, low and close of bars
+func KELTNERBars(bars *utils.Bars, optInTimePeriod, optInATRPeriod int, optInMultiplier float64, optInMAType ...utils.MAType) (*KELTNERResult, error) {
+	b := barsOrEmpty(bars)
+	return KELTNER(b.High, b.Low, b.Close, optInTimePeriod, optInATRPeriod, optInMultiplier, optInMAType...)
+}
+
+// DONCHIANBars calculates DONCHIAN on the high and low of bars
+func DONCHIANBars(bars *utils.Bars, optInTimePeriod int) (*DONCHIANResult, error) {
+	b := barsOrEmpty(bars)
+	return DONCHIAN(b.High, b.Low, optInTimePeriod)
+}
+
+// SUPERTRENDBars calculates SUPERTREND on the high, low and close of bars
+func SUPERTRENDBars(bars *utils.Bars, optInTimePeriod int, optInMultiplier float64) (*SUPERTRENDResult, error) {
+	b := barsOrEmpty(bars)
+	return SUPERTREND(b.High, b.Low, b.Close, optInTimePeriod, optInMultiplier)
+}
+
+// SessionVWAPBars calculates SessionVWAP on the time, high, low, close and volume of bars
+func SessionVWAPBars(bars *utils.Bars, loc *time.Location, optInNbDev float64) (*VWAPResult, error) {
+	b := barsOrEmpty(bars)
+	return SessionVWAP(b.Time, b.High, b.Low, b.Close, b.Volume, loc, optInNbDev)
+}
+
+// AnchoredVWAPBars calculates AnchoredVWAP on the time, high, low, close and volume of bars
+func AnchoredVWAPBars(bars *utils.Bars, anchor int64, optInNbDev float64) (*VWAPResult, error) {
+	b := barsOrEmpty(bars)
+	return AnchoredVWAP(b.Time, b.High, b.Low, b.Close, b.Volume, anchor, optInNbDev)
+}
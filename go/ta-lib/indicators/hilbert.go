@@ -0,0 +1,391 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// smoothPriceSize is the number of smoothed prices kept for the dominant
+// cycle phase, the longest period measured being 50 bars
+const smoothPriceSize = 50
+
+// dominantCycle holds the state shared by the Hilbert transform functions:
+// the in-phase and quadrature components of the smoothed price and the
+// period of its dominant cycle, measured with the homodyne discriminator
+type dominantCycle struct {
+	wma                           priceWMA
+	detrender, q1, jI, jQ         hilbertTransform
+	hilbertIdx                    int
+	period, smoothPeriod          float64
+	prevI2, prevQ2                float64
+	re, im                        float64
+	i1ForOddPrev3, i1ForEvenPrev3 float64
+	i1ForOddPrev2, i1ForEvenPrev2 float64
+
+	inPhase     float64 // In-phase component of the last bar
+	smoothed    float64 // Smoothed price of the last bar
+	smoothIdx   int     // Position of the last smoothed price in smoothPrice
+	smoothFull  bool    // Whether smoothPrice was written once
+	smoothPrice [smoothPriceSize]float64
+}
+
+// step updates the cycle with the price of bar today
+func (c *dominantCycle) step(inReal []float64, today int) {
+	adjustedPrevPeriod := (0.075 * c.period) + 0.54
+	c.smoothed = c.wma.next(inReal, inReal[today])
+	if today%2 == 0 {
+		c.detrender.do(c.smoothed, true, c.hilbertIdx, adjustedPrevPeriod)
+		c.q1.do(c.detrender.value, true, c.hilbertIdx, adjustedPrevPeriod)
+		c.inPhase = c.i1ForEvenPrev3
+		c.jI.do(c.i1ForEvenPrev3, true, c.hilbertIdx, adjustedPrevPeriod)
+		c.jQ.do(c.q1.value, true, c.hilbertIdx, adjustedPrevPeriod)
+		c.hilbertIdx++
+		if c.hilbertIdx == 3 {
+			c.hilbertIdx = 0
+		}
+	} else {
+		c.detrender.do(c.smoothed, false, c.hilbertIdx, adjustedPrevPeriod)
+		c.q1.do(c.detrender.value, false, c.hilbertIdx, adjustedPrevPeriod)
+		c.inPhase = c.i1ForOddPrev3
+		c.jI.do(c.i1ForOddPrev3, false, c.hilbertIdx, adjustedPrevPeriod)
+		c.jQ.do(c.q1.value, false, c.hilbertIdx, adjustedPrevPeriod)
+	}
+	q2 := (0.2 * (c.q1.value + c.jI.value)) + (0.8 * c.prevQ2)
+	i2 := (0.2 * (c.inPhase - c.jQ.value)) + (0.8 * c.prevI2)
+
+	// The in-phase component is the detrender delayed by 3 bars, of the
+	// same parity
+	if today%2 == 0 {
+		c.i1ForOddPrev3 = c.i1ForOddPrev2
+		c.i1ForOddPrev2 = c.detrender.value
+	} else {
+		c.i1ForEvenPrev3 = c.i1ForEvenPrev2
+		c.i1ForEvenPrev2 = c.detrender.value
+	}
+
+	// Homodyne discriminator
+	c.re = (0.2 * ((i2 * c.prevI2) + (q2 * c.prevQ2))) + (0.8 * c.re)
+	c.im = (0.2 * ((i2 * c.prevQ2) - (q2 * c.prevI2))) + (0.8 * c.im)
+	c.prevQ2 = q2
+	c.prevI2 = i2
+	tempReal := c.period
+	if c.im != 0.0 && c.re != 0.0 {
+		c.period = 360.0 / (math.Atan(c.im/c.re) * rad2Deg)
+	}
+	tempReal2 := 1.5 * tempReal
+	if c.period > tempReal2 {
+		c.period = tempReal2
+	}
+	tempReal2 = 0.67 * tempReal
+	if c.period < tempReal2 {
+		c.period = tempReal2
+	}
+	if c.period < 6 {
+		c.period = 6
+	} else if c.period > 50 {
+		c.period = 50
+	}
+	c.period = (0.2 * c.period) + (0.8 * tempReal)
+	c.smoothPeriod = (0.33 * c.period) + (0.67 * c.smoothPeriod)
+}
+
+// rad2Deg converts radians to degrees
+var rad2Deg = 45.0 / math.Atan(1)
+
+// dcPeriodInt returns the dominant cycle period rounded to whole bars
+func (c *dominantCycle) dcPeriodInt() int {
+	return int(c.smoothPeriod + 0.5)
+}
+
+// dcPhase returns the phase of the dominant cycle in degrees, from the
+// smoothed prices of the last period and the previous phase
+func dcPhase(smoothPrice *[smoothPriceSize]float64, idx int, smoothPeriod, prevPhase float64) float64 {
+	constDeg2RadBy360 := math.Atan(1) * 8.0
+	dcPeriodInt := int(smoothPeriod + 0.5)
+	realPart, imagPart := 0.0, 0.0
+	for i := 0; i < dcPeriodInt; i++ {
+		tempReal := (float64(i) * constDeg2RadBy360) / float64(dcPeriodInt)
+		tempReal2 := smoothPrice[idx]
+		realPart += math.Sin(tempReal) * tempReal2
+		imagPart += math.Cos(tempReal) * tempReal2
+		if idx == 0 {
+			idx = smoothPriceSize - 1
+		} else {
+			idx--
+		}
+	}
+
+	phase := prevPhase
+	tempReal := math.Abs(imagPart)
+	if tempReal > 0.0 {
+		phase = math.Atan(realPart/imagPart) * rad2Deg
+	} else if tempReal <= 0.01 {
+		if realPart < 0.0 {
+			phase -= 90.0
+		} else if realPart > 0.0 {
+			phase += 90.0
+		}
+	}
+	phase += 90.0
+
+	// Compensate for the one bar lag of the weighted moving average
+	phase += 360.0 / smoothPeriod
+	if imagPart < 0.0 {
+		phase += 180.0
+	}
+	if phase > 315.0 {
+		phase -= 360.0
+	}
+	return phase
+}
+
+// trendlineAverage returns the average of the prices of the last dominant cycle
+// period ending at bar today, 0 for a period under one bar
+func trendlineAverage(inReal []float64, today, dcPeriodInt int) float64 {
+	tempReal := 0.0
+	for i := 0; i < dcPeriodInt; i++ {
+		tempReal += inReal[today-i]
+	}
+	if dcPeriodInt > 0 {
+		tempReal /= float64(dcPeriodInt)
+	}
+	return tempReal
+}
+
+// runDominantCycle runs the dominant cycle on the bars from startIdx -
+// lookbackTotal to endIdx, the price smoother being warmed up on the first
+// 3 + wmaBars bars. Each following bar is passed to the callback with the
+// smoothed price stored at the position smoothIdx of smoothPrice.
+func runDominantCycle(startIdx, endIdx int, inReal []float64, lookbackTotal, wmaBars int, each func(today int, c *dominantCycle)) {
+	var c dominantCycle
+	today := startIdx - lookbackTotal
+	c.wma.init(inReal, today)
+	today += 3
+	for i := 0; i < wmaBars; i++ {
+		c.wma.next(inReal, inReal[today])
+		today++
+	}
+
+	for ; today <= endIdx; today++ {
+		c.step(inReal, today)
+		c.smoothPrice[c.smoothIdx] = c.smoothed
+		each(today, &c)
+		c.smoothIdx++
+		if c.smoothIdx == smoothPriceSize {
+			c.smoothIdx = 0
+		}
+	}
+}
+
+// HTDCPERIODLookback returns the number of input bars consumed before the first HT_DCPERIOD value
+func HTDCPERIODLookback() int {
+	return 32
+}
+
+// HT_DCPERIOD calculates the Hilbert Transform - Dominant Cycle Period
+func HT_DCPERIOD(inReal []float64) (*utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	startIdx, endIdx := HTDCPERIODLookback(), len(inReal)-1
+	if startIdx > endIdx {
+		return utils.NewResult(0, nil), nil
+	}
+	out := make([]float64, 0, endIdx-startIdx+1)
+	runDominantCycle(startIdx, endIdx, inReal, HTDCPERIODLookback(), 9, func(today int, c *dominantCycle) {
+		if today >= startIdx {
+			out = append(out, c.smoothPeriod)
+		}
+	})
+	return utils.NewResult(startIdx, out), nil
+}
+
+// HTPHASORLookback returns the number of input bars consumed before the first HT_PHASOR value
+func HTPHASORLookback() int {
+	return 32
+}
+
+// HT_PHASORResult represents the output of the Hilbert Transform - Phasor Components
+type HT_PHASORResult struct {
+	utils.Result           // In-phase component
+	Quadrature   []float64 // Quadrature component
+}
+
+// HT_PHASOR calculates the Hilbert Transform - Phasor Components
+func HT_PHASOR(inReal []float64) (*HT_PHASORResult, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	startIdx, endIdx := HTPHASORLookback(), len(inReal)-1
+	if startIdx > endIdx {
+		return &HT_PHASORResult{Result: *utils.NewResult(0, nil), Quadrature: []float64{}}, nil
+	}
+	inPhase := make([]float64, 0, endIdx-startIdx+1)
+	quadrature := make([]float64, 0, endIdx-startIdx+1)
+	runDominantCycle(startIdx, endIdx, inReal, HTPHASORLookback(), 9, func(today int, c *dominantCycle) {
+		if today >= startIdx {
+			inPhase = append(inPhase, c.inPhase)
+			quadrature = append(quadrature, c.q1.value)
+		}
+	})
+	return &HT_PHASORResult{Result: *utils.NewResult(startIdx, inPhase), Quadrature: quadrature}, nil
+}
+
+// HTDCPHASELookback returns the number of input bars consumed before the first HT_DCPHASE value
+func HTDCPHASELookback() int {
+	return 63
+}
+
+// HT_DCPHASE calculates the Hilbert Transform - Dominant Cycle Phase, in degrees
+func HT_DCPHASE(inReal []float64) (*utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	startIdx, endIdx := HTDCPHASELookback(), len(inReal)-1
+	if startIdx > endIdx {
+		return utils.NewResult(0, nil), nil
+	}
+	out := make([]float64, 0, endIdx-startIdx+1)
+	phase := 0.0
+	runDominantCycle(startIdx, endIdx, inReal, HTDCPHASELookback(), 34, func(today int, c *dominantCycle) {
+		phase = dcPhase(&c.smoothPrice, c.smoothIdx, c.smoothPeriod, phase)
+		if today >= startIdx {
+			out = append(out, phase)
+		}
+	})
+	return utils.NewResult(startIdx, out), nil
+}
+
+// HTSINELookback returns the number of input bars consumed before the first HT_SINE value
+func HTSINELookback() int {
+	return 63
+}
+
+// HT_SINEResult represents the output of the Hilbert Transform - SineWave
+type HT_SINEResult struct {
+	utils.Result           // Sine of the dominant cycle phase
+	LeadSine     []float64 // Sine of the phase advanced by 45 degrees
+}
+
+// HT_SINE calculates the Hilbert Transform - SineWave
+func HT_SINE(inReal []float64) (*HT_SINEResult, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	startIdx, endIdx := HTSINELookback(), len(inReal)-1
+	if startIdx > endIdx {
+		return &HT_SINEResult{Result: *utils.NewResult(0, nil), LeadSine: []float64{}}, nil
+	}
+	deg2Rad := 1.0 / rad2Deg
+	sine := make([]float64, 0, endIdx-startIdx+1)
+	leadSine := make([]float64, 0, endIdx-startIdx+1)
+	phase := 0.0
+	runDominantCycle(startIdx, endIdx, inReal, HTSINELookback(), 34, func(today int, c *dominantCycle) {
+		phase = dcPhase(&c.smoothPrice, c.smoothIdx, c.smoothPeriod, phase)
+		if today >= startIdx {
+			sine = append(sine, math.Sin(phase*deg2Rad))
+			leadSine = append(leadSine, math.Sin((phase+45)*deg2Rad))
+		}
+	})
+	return &HT_SINEResult{Result: *utils.NewResult(startIdx, sine), LeadSine: leadSine}, nil
+}
+
+// HTTRENDLINELookback returns the number of input bars consumed before the first HT_TRENDLINE value
+func HTTRENDLINELookback() int {
+	return 63
+}
+
+// HT_TRENDLINE calculates the Hilbert Transform - Instantaneous Trendline
+func HT_TRENDLINE(inReal []float64) (*utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	startIdx, endIdx := HTTRENDLINELookback(), len(inReal)-1
+	if startIdx > endIdx {
+		return utils.NewResult(0, nil), nil
+	}
+	out := make([]float64, 0, endIdx-startIdx+1)
+	var iTrend1, iTrend2, iTrend3 float64
+	runDominantCycle(startIdx, endIdx, inReal, HTTRENDLINELookback(), 34, func(today int, c *dominantCycle) {
+		tempReal := trendlineAverage(inReal, today, c.dcPeriodInt())
+		tempReal2 := (4.0*tempReal + 3.0*iTrend1 + 2.0*iTrend2 + iTrend3) / 10.0
+		iTrend3, iTrend2, iTrend1 = iTrend2, iTrend1, tempReal
+		if today >= startIdx {
+			out = append(out, tempReal2)
+		}
+	})
+	return utils.NewResult(startIdx, out), nil
+}
+
+// HTTRENDMODELookback returns the number of input bars consumed before the first HT_TRENDMODE value
+func HTTRENDMODELookback() int {
+	return 63
+}
+
+// HT_TRENDMODE calculates the Hilbert Transform - Trend vs Cycle Mode,
+// 1 when the prices trend and 0 when they follow a cycle
+func HT_TRENDMODE(inReal []float64) (*utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	startIdx, endIdx := HTTRENDMODELookback(), len(inReal)-1
+	if startIdx > endIdx {
+		return utils.NewResult(0, nil), nil
+	}
+	deg2Rad := 1.0 / rad2Deg
+	out := make([]float64, 0, endIdx-startIdx+1)
+	var state trendMode
+	runDominantCycle(startIdx, endIdx, inReal, HTTRENDMODELookback(), 34, func(today int, c *dominantCycle) {
+		trend := state.next(c, trendlineAverage(inReal, today, c.dcPeriodInt()), deg2Rad)
+		if today >= startIdx {
+			out = append(out, float64(trend))
+		}
+	})
+	return utils.NewResult(startIdx, out), nil
+}
+
+// trendMode holds the state of HT_TRENDMODE besides the dominant cycle
+type trendMode struct {
+	iTrend1, iTrend2, iTrend3 float64
+	daysInTrend               int
+	dcPhase                   float64
+	sine, leadSine            float64
+}
+
+// next returns 1 in a trend and 0 in a cycle, from the dominant cycle of the
+// bar and the average price of its last period
+func (s *trendMode) next(c *dominantCycle, average, deg2Rad float64) int {
+	prevDCPhase := s.dcPhase
+	s.dcPhase = dcPhase(&c.smoothPrice, c.smoothIdx, c.smoothPeriod, s.dcPhase)
+	prevSine, prevLeadSine := s.sine, s.leadSine
+	s.sine = math.Sin(s.dcPhase * deg2Rad)
+	s.leadSine = math.Sin((s.dcPhase + 45) * deg2Rad)
+
+	trendline := (4.0*average + 3.0*s.iTrend1 + 2.0*s.iTrend2 + s.iTrend3) / 10.0
+	s.iTrend3, s.iTrend2, s.iTrend1 = s.iTrend2, s.iTrend1, average
+
+	// A crossing of the sine and the lead sine starts a cycle
+	trend := 1
+	if (s.sine > s.leadSine && prevSine <= prevLeadSine) || (s.sine < s.leadSine && prevSine >= prevLeadSine) {
+		s.daysInTrend = 0
+		trend = 0
+	}
+	s.daysInTrend++
+	if float64(s.daysInTrend) < 0.5*c.smoothPeriod {
+		trend = 0
+	}
+
+	// A phase moving at the cycle rate is a cycle
+	tempReal := s.dcPhase - prevDCPhase
+	if c.smoothPeriod != 0.0 && tempReal > 0.67*360.0/c.smoothPeriod && tempReal < 1.5*360.0/c.smoothPeriod {
+		trend = 0
+	}
+
+	// Prices 1.5% away from the trendline are a trend
+	tempReal = c.smoothPrice[c.smoothIdx]
+	if trendline != 0.0 && math.Abs((tempReal-trendline)/trendline) >= 0.015 {
+		trend = 1
+	}
+	return trend
+}
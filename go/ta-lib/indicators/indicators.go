@@ -0,0 +1,54 @@
+// Package indicators implements the TA-Lib technical analysis functions.
+//
+// Every function works on the whole input and returns results following the
+// TA-Lib conventions: BeginIndex is the index in the input of the first output
+// value, and no value is produced for the lookback period before it.
+package indicators
+
+import (
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// checkReal validates a single input series
+func checkReal(inReal []float64) error {
+	if len(inReal) == 0 {
+		return utils.ErrEmptyInputData
+	}
+	code, _, _ := utils.ValidateParams(0, len(inReal)-1, inReal, 1)
+	return code.Err()
+}
+
+// checkPrice validates high, low and close series of equal length
+func checkPrice(high, low, close []float64) error {
+	if len(high) == 0 || len(low) == 0 || len(close) == 0 {
+		return utils.ErrEmptyInputData
+	}
+	if len(high) != len(low) || len(high) != len(close) {
+		return utils.ErrMismatchedInputLengths
+	}
+	code, _, _ := utils.ValidatePrice(0, len(high)-1, high, low, close)
+	return code.Err()
+}
+
+// checkHighLow validates high and low series of equal length
+func checkHighLow(high, low []float64) error {
+	if len(high) == 0 || len(low) == 0 {
+		return utils.ErrEmptyInputData
+	}
+	if len(high) != len(low) {
+		return utils.ErrMismatchedInputLengths
+	}
+	return nil
+}
+
+// checkVolume validates a price and a volume series of equal length
+func checkVolume(price, volume []float64) error {
+	if len(price) == 0 || len(volume) == 0 {
+		return utils.ErrEmptyInputData
+	}
+	if len(price) != len(volume) {
+		return utils.ErrMismatchedInputLengths
+	}
+	code, _, _ := utils.ValidateVolume(0, len(price)-1, price, volume)
+	return code.Err()
+}
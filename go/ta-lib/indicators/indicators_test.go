@@ -0,0 +1,113 @@
+package indicators_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/indicators"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testdata"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// The functions follow the TA-Lib conventions for their outputs and errors
+
+// checkValues compares a result with its expected first bar and values
+func checkValues(t *testing.T, name string, res *utils.Result, begIdx int, want []float64) {
+	t.Helper()
+	if res.BeginIndex != begIdx || res.NBElement != len(want) || len(res.Values) != len(want) {
+		t.Fatalf("%s: got %d values from bar %d, want %d from bar %d", name, len(res.Values), res.BeginIndex, len(want), begIdx)
+	}
+	for i, v := range want {
+		if math.Abs(res.Values[i]-v) > 1e-12 {
+			t.Errorf("%s[%d] = %v, want %v", name, i, res.Values[i], v)
+		}
+	}
+}
+
+func TestSmallSeries(t *testing.T) {
+	in := []float64{1, 2, 3, 4, 5}
+	tests := []struct {
+		name   string
+		run    func() (*utils.Result, error)
+		begIdx int
+		want   []float64
+	}{
+		{"SMA", func() (*utils.Result, error) { return indicators.SMA(in, 3) }, 2, []float64{2, 3, 4}},
+		{"EMA", func() (*utils.Result, error) { return indicators.EMA(in, 3) }, 2, []float64{2, 3, 4}},
+		{"WMA", func() (*utils.Result, error) { return indicators.WMA(in, 3) }, 2, []float64{14.0 / 6, 20.0 / 6, 26.0 / 6}},
+		{"ROC", func() (*utils.Result, error) { return indicators.ROC(in, 2) }, 2, []float64{200, 100, 200.0 / 3}},
+		{"RSI", func() (*utils.Result, error) { return indicators.RSI(in, 2) }, 2, []float64{100, 100, 100}},
+		{"MEDPRICE", func() (*utils.Result, error) { return indicators.MEDPRICE(in, in) }, 0, in},
+		{"TRANGE", func() (*utils.Result, error) {
+			return indicators.TRANGE([]float64{2, 4, 3}, []float64{1, 2, 1}, []float64{1.5, 3, 2})
+		}, 1, []float64{2.5, 2}},
+		{"OBV", func() (*utils.Result, error) {
+			return indicators.OBV([]float64{1, 2, 1, 1}, []float64{10, 20, 5, 7})
+		}, 0, []float64{10, 30, 25, 25}},
+	}
+	for _, tt := range tests {
+		res, err := tt.run()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		checkValues(t, tt.name, res, tt.begIdx, tt.want)
+	}
+}
+
+func TestLookbackMatchesBeginIndex(t *testing.T) {
+	_, high, low, close, volume, err := testdata.GetOHLCVSlices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		lookback int
+		run      func() (*utils.Result, error)
+	}{
+		{"SMA", indicators.SMALookback(20), func() (*utils.Result, error) { return indicators.SMA(close, 20) }},
+		{"EMA", indicators.EMALookback(20), func() (*utils.Result, error) { return indicators.EMA(close, 20) }},
+		{"DEMA", indicators.DEMALookback(10), func() (*utils.Result, error) { return indicators.DEMA(close, 10) }},
+		{"TEMA", indicators.TEMALookback(10), func() (*utils.Result, error) { return indicators.TEMA(close, 10) }},
+		{"TRIMA", indicators.TRIMALookback(10), func() (*utils.Result, error) { return indicators.TRIMA(close, 10) }},
+		{"KAMA", indicators.KAMALookback(10), func() (*utils.Result, error) { return indicators.KAMA(close, 10) }},
+		{"RSI", indicators.RSILookback(14), func() (*utils.Result, error) { return indicators.RSI(close, 14) }},
+		{"ATR", indicators.ATRLookback(14), func() (*utils.Result, error) { return indicators.ATR(high, low, close, 14) }},
+		{"CCI", indicators.CCILookback(14), func() (*utils.Result, error) { return indicators.CCI(high, low, close, 14) }},
+		{"MFI", indicators.MFILookback(14), func() (*utils.Result, error) { return indicators.MFI(high, low, close, volume, 14) }},
+	}
+	for _, tt := range tests {
+		res, err := tt.run()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if res.BeginIndex != tt.lookback || res.NBElement != len(close)-tt.lookback {
+			t.Errorf("%s: %d values from bar %d, want %d from bar %d", tt.name, res.NBElement, res.BeginIndex, len(close)-tt.lookback, tt.lookback)
+		}
+	}
+}
+
+func TestInputErrors(t *testing.T) {
+	in := []float64{1, 2, 3, 4, 5}
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"empty input", second(indicators.SMA(nil, 3)), utils.ErrEmptyInputData},
+		{"period too small", second(indicators.SMA(in, 1)), utils.ErrInvalidParameter},
+		{"period too large", second(indicators.EMA(in, 100001)), utils.ErrInvalidParameter},
+		{"mismatched lengths", second(indicators.ATR(in, in[:4], in, 3)), utils.ErrMismatchedInputLengths},
+		{"unknown MA type", second(indicators.BBANDS(in, 3, 2, 2, utils.MAType(99))), utils.ErrInvalidParameter},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.err, tt.want)
+		}
+	}
+}
+
+// second returns the error of a function call
+func second[T any](_ T, err error) error {
+	return err
+}
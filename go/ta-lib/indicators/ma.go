@@ -0,0 +1,1086 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// SMALookback returns the number of input bars consumed before the first SMA value
+func SMALookback(optInTimePeriod int) int {
+	return optInTimePeriod - 1
+}
+
+// SMA calculates the Simple Moving Average
+func SMA(inReal []float64, optInTimePeriod int) (*utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	begIdx, out := sma(0, len(inReal)-1, inReal, optInTimePeriod)
+	return utils.NewResult(begIdx, out), nil
+}
+
+func sma(startIdx, endIdx int, inReal []float64, optInTimePeriod int) (int, []float64) {
+	lookbackTotal := SMALookback(optInTimePeriod)
+	if startIdx < lookbackTotal {
+		startIdx = lookbackTotal
+	}
+	if startIdx > endIdx {
+		return 0, nil
+	}
+
+	// Add-up the initial period, except for the last value
+	periodTotal := 0.0
+	trailingIdx := startIdx - lookbackTotal
+	i := trailingIdx
+	for i < startIdx {
+		periodTotal += inReal[i]
+		i++
+	}
+
+	out := make([]float64, 0, endIdx-startIdx+1)
+	for i <= endIdx {
+		periodTotal += inReal[i]
+		i++
+		tempReal := periodTotal
+		periodTotal -= inReal[trailingIdx]
+		trailingIdx++
+		out = append(out, tempReal/float64(optInTimePeriod))
+	}
+	return startIdx, out
+}
+
+// EMALookback returns the number of input bars consumed before the first EMA value
+func EMALookback(optInTimePeriod int) int {
+	return optInTimePeriod - 1
+}
+
+// EMA calculates the Exponential Moving Average
+func EMA(inReal []float64, optInTimePeriod int) (*utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	begIdx, out := ema(0, len(inReal)-1, inReal, optInTimePeriod, utils.PerToK(optInTimePeriod))
+	return utils.NewResult(begIdx, out), nil
+}
+
+// ema is seeded with the simple average of the first period, as in TA_MA_CLASSIC
+func ema(startIdx, endIdx int, inReal []float64, optInTimePeriod int, k float64) (int, []float64) {
+	lookbackTotal := EMALookback(optInTimePeriod)
+	if startIdx < lookbackTotal {
+		startIdx = lookbackTotal
+	}
+	if startIdx > endIdx {
+		return 0, nil
+	}
+
+	today := startIdx - lookbackTotal
+	tempReal := 0.0
+	for i := 0; i < optInTimePeriod; i++ {
+		tempReal += inReal[today]
+		today++
+	}
+	prevMA := tempReal / float64(optInTimePeriod)
+
+	// Skip the unstable period
+	for today <= startIdx {
+		prevMA = ((inReal[today] - prevMA) * k) + prevMA
+		today++
+	}
+
+	out := make([]float64, 1, endIdx-startIdx+1)
+	out[0] = prevMA
+	for today <= endIdx {
+		prevMA = ((inReal[today] - prevMA) * k) + prevMA
+		today++
+		out = append(out, prevMA)
+	}
+	return startIdx, out
+}
+
+// WMALookback returns the number of input bars consumed before the first WMA value
+func WMALookback(optInTimePeriod int) int {
+	return optInTimePeriod - 1
+}
+
+// WMA calculates the Weighted Moving Average
+func WMA(inReal []float64, optInTimePeriod int) (*utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	begIdx, out := wma(0, len(inReal)-1, inReal, optInTimePeriod)
+	return utils.NewResult(begIdx, out), nil
+}
+
+func wma(startIdx, endIdx int, inReal []float64, optInTimePeriod int) (int, []float64) {
+	lookbackTotal := WMALookback(optInTimePeriod)
+	if startIdx < lookbackTotal {
+		startIdx = lookbackTotal
+	}
+	if startIdx > endIdx {
+		return 0, nil
+	}
+	if optInTimePeriod == 1 {
+		return startIdx, append([]float64(nil), inReal[startIdx:endIdx+1]...)
+	}
+
+	divider := float64((optInTimePeriod * (optInTimePeriod + 1)) >> 1)
+
+	// Evaluate the initial periodSum/periodSub
+	trailingIdx := startIdx - lookbackTotal
+	periodSum, periodSub := 0.0, 0.0
+	inIdx := trailingIdx
+	for i := 1; inIdx < startIdx; i++ {
+		tempReal := inReal[inIdx]
+		inIdx++
+		periodSub += tempReal
+		periodSum += tempReal * float64(i)
+	}
+	trailingValue := 0.0
+
+	out := make([]float64, 0, endIdx-startIdx+1)
+	for inIdx <= endIdx {
+		tempReal := inReal[inIdx]
+		inIdx++
+		periodSub += tempReal
+		periodSub -= trailingValue
+		periodSum += tempReal * float64(optInTimePeriod)
+		trailingValue = inReal[trailingIdx]
+		trailingIdx++
+		out = append(out, periodSum/divider)
+		periodSum -= periodSub
+	}
+	return startIdx, out
+}
+
+// DEMALookback returns the number of input bars consumed before the first DEMA value
+func DEMALookback(optInTimePeriod int) int {
+	return EMALookback(optInTimePeriod) * 2
+}
+
+// DEMA calculates the Double Exponential Moving Average
+func DEMA(inReal []float64, optInTimePeriod int) (*utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	begIdx, out := dema(0, len(inReal)-1, inReal, optInTimePeriod)
+	return utils.NewResult(begIdx, out), nil
+}
+
+func dema(startIdx, endIdx int, inReal []float64, optInTimePeriod int) (int, []float64) {
+	lookbackEMA := EMALookback(optInTimePeriod)
+	if startIdx < lookbackEMA*2 {
+		startIdx = lookbackEMA * 2
+	}
+	if startIdx > endIdx {
+		return 0, nil
+	}
+
+	k := utils.PerToK(optInTimePeriod)
+	firstBegIdx, firstEMA := ema(startIdx-lookbackEMA, endIdx, inReal, optInTimePeriod, k)
+	if len(firstEMA) == 0 {
+		return 0, nil
+	}
+	secondBegIdx, secondEMA := ema(0, len(firstEMA)-1, firstEMA, optInTimePeriod, k)
+	if len(secondEMA) == 0 {
+		return 0, nil
+	}
+
+	out := make([]float64, len(secondEMA))
+	for i := range secondEMA {
+		out[i] = (2.0 * firstEMA[secondBegIdx+i]) - secondEMA[i]
+	}
+	return firstBegIdx + secondBegIdx, out
+}
+
+// TEMALookback returns the number of input bars consumed before the first TEMA value
+func TEMALookback(optInTimePeriod int) int {
+	return EMALookback(optInTimePeriod) * 3
+}
+
+// TEMA calculates the Triple Exponential Moving Average
+func TEMA(inReal []float64, optInTimePeriod int) (*utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	begIdx, out := tema(0, len(inReal)-1, inReal, optInTimePeriod)
+	return utils.NewResult(begIdx, out), nil
+}
+
+func tema(startIdx, endIdx int, inReal []float64, optInTimePeriod int) (int, []float64) {
+	lookbackEMA := EMALookback(optInTimePeriod)
+	if startIdx < lookbackEMA*3 {
+		startIdx = lookbackEMA * 3
+	}
+	if startIdx > endIdx {
+		return 0, nil
+	}
+
+	k := utils.PerToK(optInTimePeriod)
+	firstBegIdx, firstEMA := ema(startIdx-(lookbackEMA*2), endIdx, inReal, optInTimePeriod, k)
+	if len(firstEMA) == 0 {
+		return 0, nil
+	}
+	secondBegIdx, secondEMA := ema(0, len(firstEMA)-1, firstEMA, optInTimePeriod, k)
+	if len(secondEMA) == 0 {
+		return 0, nil
+	}
+	thirdBegIdx, out := ema(0, len(secondEMA)-1, secondEMA, optInTimePeriod, k)
+	if len(out) == 0 {
+		return 0, nil
+	}
+
+	firstEMAIdx := thirdBegIdx + secondBegIdx
+	secondEMAIdx := thirdBegIdx
+	for i := range out {
+		out[i] += (3.0 * firstEMA[firstEMAIdx+i]) - (3.0 * secondEMA[secondEMAIdx+i])
+	}
+	return firstEMAIdx + firstBegIdx, out
+}
+
+// TRIMALookback returns the number of input bars consumed before the first TRIMA value
+func TRIMALookback(optInTimePeriod int) int {
+	return optInTimePeriod - 1
+}
+
+// TRIMA calculates the Triangular Moving Average
+func TRIMA(inReal []float64, optInTimePeriod int) (*utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	begIdx, out := trima(0, len(inReal)-1, inReal, optInTimePeriod)
+	return utils.NewResult(begIdx, out), nil
+}
+
+func trima(startIdx, endIdx int, inReal []float64, optInTimePeriod int) (int, []float64) {
+	lookbackTotal := TRIMALookback(optInTimePeriod)
+	if startIdx < lookbackTotal {
+		startIdx = lookbackTotal
+	}
+	if startIdx > endIdx {
+		return 0, nil
+	}
+
+	odd := optInTimePeriod%2 == 1
+	half := optInTimePeriod >> 1
+	var factor float64
+	trailingIdx := startIdx - lookbackTotal
+	var middleIdx int
+	if odd {
+		factor = 1.0 / float64((half+1)*(half+1))
+		middleIdx = trailingIdx + half
+	} else {
+		factor = 1.0 / float64(half*(half+1))
+		middleIdx = trailingIdx + half - 1
+	}
+	todayIdx := middleIdx + half
+
+	numerator, numeratorSub := 0.0, 0.0
+	for i := middleIdx; i >= trailingIdx; i-- {
+		numeratorSub += inReal[i]
+		numerator += numeratorSub
+	}
+	numeratorAdd := 0.0
+	middleIdx++
+	for i := middleIdx; i <= todayIdx; i++ {
+		numeratorAdd += inReal[i]
+		numerator += numeratorAdd
+	}
+
+	out := make([]float64, 1, endIdx-startIdx+1)
+	tempReal := inReal[trailingIdx]
+	trailingIdx++
+	out[0] = numerator * factor
+	todayIdx++
+	for todayIdx <= endIdx {
+		numerator -= numeratorSub
+		numeratorSub -= tempReal
+		tempReal = inReal[middleIdx]
+		middleIdx++
+		numeratorSub += tempReal
+		if odd {
+			numerator += numeratorAdd
+			numeratorAdd -= tempReal
+		} else {
+			numeratorAdd -= tempReal
+			numerator += numeratorAdd
+		}
+		tempReal = inReal[todayIdx]
+		todayIdx++
+		numeratorAdd += tempReal
+		numerator += tempReal
+		tempReal = inReal[trailingIdx]
+		trailingIdx++
+		out = append(out, numerator*factor)
+	}
+	return startIdx, out
+}
+
+// KAMALookback returns the number of input bars consumed before the first KAMA value
+func KAMALookback(optInTimePeriod int) int {
+	return optInTimePeriod
+}
+
+// KAMA calculates the Kaufman Adaptive Moving Average
+func KAMA(inReal []float64, optInTimePeriod int) (*utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	begIdx, out := kama(0, len(inReal)-1, inReal, optInTimePeriod)
+	return utils.NewResult(begIdx, out), nil
+}
+
+// KAMA smoothing constants for the fastest (2) and slowest (30) periods,
+// rounded to float64 before the subtraction as in the C implementation
+var (
+	kamaConstMax  = 2.0 / (30.0 + 1.0)
+	kamaConstDiff = 2.0/(2.0+1.0) - kamaConstMax
+)
+
+// kamaSmoothing converts an efficiency ratio input into the KAMA smoothing factor
+func kamaSmoothing(periodROC, sumROC1 float64) float64 {
+	var tempReal float64
+	if sumROC1 <= periodROC || utils.TAIsZero(sumROC1) {
+		tempReal = 1.0
+	} else {
+		tempReal = math.Abs(periodROC / sumROC1)
+	}
+	tempReal = (tempReal * kamaConstDiff) + kamaConstMax
+	return tempReal * tempReal
+}
+
+func kama(startIdx, endIdx int, inReal []float64, optInTimePeriod int) (int, []float64) {
+	lookbackTotal := KAMALookback(optInTimePeriod)
+	if startIdx < lookbackTotal {
+		startIdx = lookbackTotal
+	}
+	if startIdx > endIdx {
+		return 0, nil
+	}
+
+	sumROC1 := 0.0
+	today := startIdx - lookbackTotal
+	trailingIdx := today
+	for i := 0; i < optInTimePeriod; i++ {
+		tempReal := inReal[today]
+		today++
+		sumROC1 += math.Abs(tempReal - inReal[today])
+	}
+
+	// First KAMA value is seeded with the previous price
+	prevKAMA := inReal[today-1]
+	tempReal := inReal[today]
+	tempReal2 := inReal[trailingIdx]
+	trailingIdx++
+	periodROC := tempReal - tempReal2
+	trailingValue := tempReal2
+	prevKAMA = ((inReal[today] - prevKAMA) * kamaSmoothing(periodROC, sumROC1)) + prevKAMA
+	today++
+
+	step := func() {
+		tempReal := inReal[today]
+		tempReal2 := inReal[trailingIdx]
+		trailingIdx++
+		periodROC := tempReal - tempReal2
+		sumROC1 -= math.Abs(trailingValue - tempReal2)
+		sumROC1 += math.Abs(tempReal - inReal[today-1])
+		trailingValue = tempReal2
+		prevKAMA = ((inReal[today] - prevKAMA) * kamaSmoothing(periodROC, sumROC1)) + prevKAMA
+		today++
+	}
+
+	// Skip the unstable period
+	for today <= startIdx {
+		step()
+	}
+
+	out := make([]float64, 1, endIdx-startIdx+1)
+	out[0] = prevKAMA
+	begIdx := today - 1
+	for today <= endIdx {
+		step()
+		out = append(out, prevKAMA)
+	}
+	return begIdx, out
+}
+
+// MAMALookback returns the number of input bars consumed before the first MAMA value
+func MAMALookback(optInFastLimit, optInSlowLimit float64) int {
+	return 32
+}
+
+// MAMAResult represents the output of the MESA Adaptive Moving Average
+type MAMAResult struct {
+	utils.Result           // MAMA line
+	FAMA         []float64 // Following Adaptive Moving Average
+}
+
+// MAMA calculates the MESA Adaptive Moving Average
+func MAMA(inReal []float64, optInFastLimit, optInSlowLimit float64) (*MAMAResult, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if optInFastLimit < 0.01 || optInFastLimit > 0.99 || optInSlowLimit < 0.01 || optInSlowLimit > 0.99 {
+		return nil, utils.ErrInvalidParameter
+	}
+	begIdx, outMAMA, outFAMA := mama(0, len(inReal)-1, inReal, optInFastLimit, optInSlowLimit)
+	return &MAMAResult{Result: *utils.NewResult(begIdx, outMAMA), FAMA: nonNil(outFAMA)}, nil
+}
+
+// Hilbert transform coefficients used by the MESA functions
+const (
+	hilbertA = 0.0962
+	hilbertB = 0.5769
+)
+
+// hilbertTransform holds the state of one Hilbert transform variable,
+// mirroring the HILBERT_VARIABLES macros of the C implementation
+type hilbertTransform struct {
+	odd, even                   [3]float64
+	value                       float64
+	prevOdd, prevEven           float64
+	prevInputOdd, prevInputEven float64
+}
+
+// do runs one transform step on the odd or even half of the state
+func (h *hilbertTransform) do(input float64, isEven bool, hilbertIdx int, adjustedPrevPeriod float64) {
+	hilbertTempReal := hilbertA * input
+	if isEven {
+		h.value = -h.even[hilbertIdx]
+		h.even[hilbertIdx] = hilbertTempReal
+		h.value += hilbertTempReal
+		h.value -= h.prevEven
+		h.prevEven = hilbertB * h.prevInputEven
+		h.value += h.prevEven
+		h.prevInputEven = input
+	} else {
+		h.value = -h.odd[hilbertIdx]
+		h.odd[hilbertIdx] = hilbertTempReal
+		h.value += hilbertTempReal
+		h.value -= h.prevOdd
+		h.prevOdd = hilbertB * h.prevInputOdd
+		h.value += h.prevOdd
+		h.prevInputOdd = input
+	}
+	h.value *= adjustedPrevPeriod
+}
+
+// priceWMA is the 4 bar weighted moving average used to smooth prices
+// before the Hilbert transform
+type priceWMA struct {
+	sub, sum, trailingValue float64
+	trailingIdx             int
+}
+
+// init seeds the smoother with the first three prices starting at idx
+func (w *priceWMA) init(inReal []float64, idx int) {
+	w.trailingIdx = idx
+	w.sub = inReal[idx]
+	w.sum = inReal[idx]
+	w.sub += inReal[idx+1]
+	w.sum += inReal[idx+1] * 2.0
+	w.sub += inReal[idx+2]
+	w.sum += inReal[idx+2] * 3.0
+	w.trailingValue = 0.0
+}
+
+// next adds a price and returns the smoothed value
+func (w *priceWMA) next(inReal []float64, price float64) float64 {
+	w.sub += price
+	w.sub -= w.trailingValue
+	w.sum += price * 4.0
+	w.trailingValue = inReal[w.trailingIdx]
+	w.trailingIdx++
+	smoothed := w.sum * 0.1
+	w.sum -= w.sub
+	return smoothed
+}
+
+func mama(startIdx, endIdx int, inReal []float64, optInFastLimit, optInSlowLimit float64) (int, []float64, []float64) {
+	rad2Deg := 180.0 / (4.0 * math.Atan(1))
+	lookbackTotal := MAMALookback(optInFastLimit, optInSlowLimit)
+	if startIdx < lookbackTotal {
+		startIdx = lookbackTotal
+	}
+	if startIdx > endIdx {
+		return 0, nil, nil
+	}
+
+	var wma priceWMA
+	today := startIdx - lookbackTotal
+	wma.init(inReal, today)
+	today += 3
+	for i := 0; i < 9; i++ {
+		wma.next(inReal, inReal[today])
+		today++
+	}
+
+	var detrender, q1, jI, jQ hilbertTransform
+	hilbertIdx := 0
+	period := 0.0
+	prevI2, prevQ2 := 0.0, 0.0
+	re, im := 0.0, 0.0
+	mama, fama := 0.0, 0.0
+	i1ForOddPrev3, i1ForEvenPrev3 := 0.0, 0.0
+	i1ForOddPrev2, i1ForEvenPrev2 := 0.0, 0.0
+	prevPhase := 0.0
+
+	outMAMA := make([]float64, 0, endIdx-startIdx+1)
+	outFAMA := make([]float64, 0, endIdx-startIdx+1)
+	for today <= endIdx {
+		adjustedPrevPeriod := (0.075 * period) + 0.54
+		todayValue := inReal[today]
+		smoothedValue := wma.next(inReal, todayValue)
+
+		var q2, i2, tempReal2 float64
+		if today%2 == 0 {
+			detrender.do(smoothedValue, true, hilbertIdx, adjustedPrevPeriod)
+			q1.do(detrender.value, true, hilbertIdx, adjustedPrevPeriod)
+			jI.do(i1ForEvenPrev3, true, hilbertIdx, adjustedPrevPeriod)
+			jQ.do(q1.value, true, hilbertIdx, adjustedPrevPeriod)
+			hilbertIdx++
+			if hilbertIdx == 3 {
+				hilbertIdx = 0
+			}
+			q2 = (0.2 * (q1.value + jI.value)) + (0.8 * prevQ2)
+			i2 = (0.2 * (i1ForEvenPrev3 - jQ.value)) + (0.8 * prevI2)
+			i1ForOddPrev3 = i1ForOddPrev2
+			i1ForOddPrev2 = detrender.value
+			if i1ForEvenPrev3 != 0.0 {
+				tempReal2 = math.Atan(q1.value/i1ForEvenPrev3) * rad2Deg
+			}
+		} else {
+			detrender.do(smoothedValue, false, hilbertIdx, adjustedPrevPeriod)
+			q1.do(detrender.value, false, hilbertIdx, adjustedPrevPeriod)
+			jI.do(i1ForOddPrev3, false, hilbertIdx, adjustedPrevPeriod)
+			jQ.do(q1.value, false, hilbertIdx, adjustedPrevPeriod)
+			q2 = (0.2 * (q1.value + jI.value)) + (0.8 * prevQ2)
+			i2 = (0.2 * (i1ForOddPrev3 - jQ.value)) + (0.8 * prevI2)
+			i1ForEvenPrev3 = i1ForEvenPrev2
+			i1ForEvenPrev2 = detrender.value
+			if i1ForOddPrev3 != 0.0 {
+				tempReal2 = math.Atan(q1.value/i1ForOddPrev3) * rad2Deg
+			}
+		}
+
+		// Adaptive alpha from the phase rate of change
+		tempReal := prevPhase - tempReal2
+		prevPhase = tempReal2
+		if tempReal < 1.0 {
+			tempReal = 1.0
+		}
+		if tempReal > 1.0 {
+			tempReal = optInFastLimit / tempReal
+			if tempReal < optInSlowLimit {
+				tempReal = optInSlowLimit
+			}
+		} else {
+			tempReal = optInFastLimit
+		}
+
+		mama = (tempReal * todayValue) + ((1 - tempReal) * mama)
+		tempReal *= 0.5
+		fama = (tempReal * mama) + ((1 - tempReal) * fama)
+		if today >= startIdx {
+			outMAMA = append(outMAMA, mama)
+			outFAMA = append(outFAMA, fama)
+		}
+
+		// Homodyne discriminator
+		re = (0.2 * ((i2 * prevI2) + (q2 * prevQ2))) + (0.8 * re)
+		im = (0.2 * ((i2 * prevQ2) - (q2 * prevI2))) + (0.8 * im)
+		prevQ2 = q2
+		prevI2 = i2
+		tempReal = period
+		if im != 0.0 && re != 0.0 {
+			period = 360.0 / (math.Atan(im/re) * rad2Deg)
+		}
+		tempReal2 = 1.5 * tempReal
+		if period > tempReal2 {
+			period = tempReal2
+		}
+		tempReal2 = 0.67 * tempReal
+		if period < tempReal2 {
+			period = tempReal2
+		}
+		if period < 6 {
+			period = 6
+		} else if period > 50 {
+			period = 50
+		}
+		period = (0.2 * period) + (0.8 * tempReal)
+		today++
+	}
+	return startIdx, outMAMA, outFAMA
+}
+
+// T3Lookback returns the number of input bars consumed before the first T3 value
+func T3Lookback(optInTimePeriod int, optInVFactor float64) int {
+	return 6 * (optInTimePeriod - 1)
+}
+
+// T3 calculates the Triple Exponential Moving Average of Tillson, a
+// generalized DEMA applied three times with the volume factor optInVFactor
+// in [0, 1], usually 0.7
+func T3(inReal []float64, optInTimePeriod int, optInVFactor float64) (*utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	if !(optInVFactor >= 0 && optInVFactor <= 1) {
+		return nil, fmt.Errorf("volume factor %v out of range [0, 1]: %w", optInVFactor, utils.ErrInvalidParameter)
+	}
+	begIdx, out := t3(0, len(inReal)-1, inReal, optInTimePeriod, optInVFactor)
+	return utils.NewResult(begIdx, out), nil
+}
+
+// t3Coefficients returns the weights of the last four EMAs in T3
+func t3Coefficients(vFactor float64) (c1, c2, c3, c4 float64) {
+	tempReal := vFactor * vFactor
+	c1 = -(tempReal * vFactor)
+	c2 = 3.0 * (tempReal - c1)
+	c3 = -6.0*tempReal - 3.0*(vFactor-c1)
+	c4 = 1.0 + 3.0*vFactor - c1 + 3.0*tempReal
+	return c1, c2, c3, c4
+}
+
+func t3(startIdx, endIdx int, inReal []float64, optInTimePeriod int, optInVFactor float64) (int, []float64) {
+	lookbackTotal := T3Lookback(optInTimePeriod, optInVFactor)
+	if startIdx < lookbackTotal {
+		startIdx = lookbackTotal
+	}
+	if startIdx > endIdx {
+		return 0, nil
+	}
+
+	today := startIdx - lookbackTotal
+	k := 2.0 / (float64(optInTimePeriod) + 1.0)
+	oneMinusK := 1.0 - k
+
+	// Each EMA is seeded with the average of the first period of its input,
+	// the EMAs before it in the chain being updated meanwhile
+	var e [6]float64
+	tempReal := 0.0
+	for i := 0; i < optInTimePeriod; i++ {
+		tempReal += inReal[today]
+		today++
+	}
+	e[0] = tempReal / float64(optInTimePeriod)
+	for level := 1; level < len(e); level++ {
+		tempReal = e[level-1]
+		for i := optInTimePeriod - 1; i > 0; i-- {
+			e[0] = (k * inReal[today]) + (oneMinusK * e[0])
+			today++
+			for j := 1; j < level; j++ {
+				e[j] = (k * e[j-1]) + (oneMinusK * e[j])
+			}
+			tempReal += e[level-1]
+		}
+		e[level] = tempReal / float64(optInTimePeriod)
+	}
+
+	step := func() {
+		e[0] = (k * inReal[today]) + (oneMinusK * e[0])
+		today++
+		for j := 1; j < len(e); j++ {
+			e[j] = (k * e[j-1]) + (oneMinusK * e[j])
+		}
+	}
+
+	// Skip the unstable period
+	for today <= startIdx {
+		step()
+	}
+
+	c1, c2, c3, c4 := t3Coefficients(optInVFactor)
+	out := make([]float64, 1, endIdx-startIdx+1)
+	out[0] = c1*e[5] + c2*e[4] + c3*e[3] + c4*e[2]
+	for today <= endIdx {
+		step()
+		out = append(out, c1*e[5]+c2*e[4]+c3*e[3]+c4*e[2])
+	}
+	return startIdx, out
+}
+
+// HMALookback returns the number of input bars consumed before the first HMA value
+func HMALookback(optInTimePeriod int) int {
+	return optInTimePeriod - 1 + int(math.Sqrt(float64(optInTimePeriod))) - 1
+}
+
+// HMA calculates the Hull Moving Average, the WMA over the square root of
+// the period of 2*WMA(period/2) - WMA(period)
+func HMA(inReal []float64, optInTimePeriod int) (*utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	begIdx, out := hma(0, len(inReal)-1, inReal, optInTimePeriod)
+	return utils.NewResult(begIdx, out), nil
+}
+
+func hma(startIdx, endIdx int, inReal []float64, optInTimePeriod int) (int, []float64) {
+	lookbackTotal := HMALookback(optInTimePeriod)
+	if startIdx < lookbackTotal {
+		startIdx = lookbackTotal
+	}
+	if startIdx > endIdx {
+		return 0, nil
+	}
+
+	// Raw Hull values from the start of the window of the first output. Both
+	// averages start on the same bar.
+	sqrtPeriod := int(math.Sqrt(float64(optInTimePeriod)))
+	half := optInTimePeriod / 2
+	first := startIdx - (sqrtPeriod - 1)
+	fastIdx, fast := ma(startIdx-lookbackTotal+half-1, endIdx, inReal, half, utils.WMA)
+	_, slow := wma(first, endIdx, inReal, optInTimePeriod)
+	raw := make([]float64, len(slow))
+	for i := range slow {
+		raw[i] = 2*fast[first+i-fastIdx] - slow[i]
+	}
+	_, out := ma(sqrtPeriod-1, len(raw)-1, raw, sqrtPeriod, utils.WMA)
+	return startIdx, out
+}
+
+// ALMALookback returns the number of input bars consumed before the first ALMA value
+func ALMALookback(optInTimePeriod int) int {
+	return optInTimePeriod - 1
+}
+
+// ALMA calculates the Arnaud Legoux Moving Average, weighting the period with
+// a Gaussian centred at optInOffset (0 the oldest bar, 1 the latest) of
+// width period/optInSigma. The usual values are 0.85 and 6.
+func ALMA(inReal []float64, optInTimePeriod int, optInOffset, optInSigma float64) (*utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	if optInOffset < 0 || optInOffset > 1 || !(optInSigma > 0) {
+		return nil, fmt.Errorf("ALMA offset %v and sigma %v: %w", optInOffset, optInSigma, utils.ErrInvalidParameter)
+	}
+	begIdx, out := alma(0, len(inReal)-1, inReal, optInTimePeriod, optInOffset, optInSigma)
+	return utils.NewResult(begIdx, out), nil
+}
+
+// almaWeights returns the Gaussian weights of ALMA, oldest bar first, and their sum
+func almaWeights(period int, offset, sigma float64) ([]float64, float64) {
+	m := offset * float64(period-1)
+	s := float64(period) / sigma
+	weights := make([]float64, period)
+	sum := 0.0
+	for i := range weights {
+		d := float64(i) - m
+		weights[i] = math.Exp(-(d * d) / (2 * s * s))
+		sum += weights[i]
+	}
+	return weights, sum
+}
+
+func alma(startIdx, endIdx int, inReal []float64, optInTimePeriod int, optInOffset, optInSigma float64) (int, []float64) {
+	lookbackTotal := ALMALookback(optInTimePeriod)
+	if startIdx < lookbackTotal {
+		startIdx = lookbackTotal
+	}
+	if startIdx > endIdx {
+		return 0, nil
+	}
+
+	weights, norm := almaWeights(optInTimePeriod, optInOffset, optInSigma)
+	out := make([]float64, 0, endIdx-startIdx+1)
+	for today := startIdx; today <= endIdx; today++ {
+		sum := 0.0
+		for i, w := range weights {
+			sum += w * inReal[today-lookbackTotal+i]
+		}
+		out = append(out, sum/norm)
+	}
+	return startIdx, out
+}
+
+// ZLEMALookback returns the number of input bars consumed before the first ZLEMA value
+func ZLEMALookback(optInTimePeriod int) int {
+	return (optInTimePeriod-1)/2 + optInTimePeriod - 1
+}
+
+// ZLEMA calculates the Zero Lag Exponential Moving Average, the EMA of
+// 2*price - price (period-1)/2 bars earlier
+func ZLEMA(inReal []float64, optInTimePeriod int) (*utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	begIdx, out := zlema(0, len(inReal)-1, inReal, optInTimePeriod)
+	return utils.NewResult(begIdx, out), nil
+}
+
+func zlema(startIdx, endIdx int, inReal []float64, optInTimePeriod int) (int, []float64) {
+	lookbackTotal := ZLEMALookback(optInTimePeriod)
+	if startIdx < lookbackTotal {
+		startIdx = lookbackTotal
+	}
+	if startIdx > endIdx {
+		return 0, nil
+	}
+
+	// De-lagged prices, delagged[i] being the one of bar first+i
+	lag := (optInTimePeriod - 1) / 2
+	first := startIdx - EMALookback(optInTimePeriod)
+	delagged := make([]float64, endIdx-first+1)
+	for i := range delagged {
+		delagged[i] = 2*inReal[first+i] - inReal[first+i-lag]
+	}
+	_, out := ema(EMALookback(optInTimePeriod), len(delagged)-1, delagged, optInTimePeriod, utils.PerToK(optInTimePeriod))
+	return startIdx, out
+}
+
+// VWMALookback returns the number of input bars consumed before the first VWMA value
+func VWMALookback(optInTimePeriod int) int {
+	return optInTimePeriod - 1
+}
+
+// VWMA calculates the Volume Weighted Moving Average of a series. A period
+// without volume gets the simple average.
+func VWMA(inReal, inVolume []float64, optInTimePeriod int) (*utils.Result, error) {
+	if err := checkVolume(inReal, inVolume); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+
+	lookbackTotal := VWMALookback(optInTimePeriod)
+	if lookbackTotal >= len(inReal) {
+		return utils.NewResult(0, nil), nil
+	}
+	// The sums slide with the window in compensated sums, as the products
+	// of prices and volumes are large and would cancel out
+	var sumPV, sumV, sum utils.KahanSum
+	for i := 0; i < lookbackTotal; i++ {
+		sumPV.Add(inReal[i] * inVolume[i])
+		sumV.Add(inVolume[i])
+		sum.Add(inReal[i])
+	}
+	out := make([]float64, 0, len(inReal)-lookbackTotal)
+	for today := lookbackTotal; today < len(inReal); today++ {
+		sumPV.Add(inReal[today] * inVolume[today])
+		sumV.Add(inVolume[today])
+		sum.Add(inReal[today])
+		if volume := sumV.Total(); utils.TAIsZeroOrNeg(volume) {
+			out = append(out, sum.Total()/float64(optInTimePeriod))
+		} else {
+			out = append(out, sumPV.Total()/volume)
+		}
+		trailingIdx := today - lookbackTotal
+		sumPV.Add(-inReal[trailingIdx] * inVolume[trailingIdx])
+		sumV.Add(-inVolume[trailingIdx])
+		sum.Add(-inReal[trailingIdx])
+	}
+	return utils.NewResult(lookbackTotal, out), nil
+}
+
+// SMMALookback returns the number of input bars consumed before the first SMMA value
+func SMMALookback(optInTimePeriod int) int {
+	return optInTimePeriod - 1
+}
+
+// SMMA calculates the Smoothed Moving Average of Wilder, an EMA with a
+// smoothing factor of 1/period seeded with the simple average
+func SMMA(inReal []float64, optInTimePeriod int) (*utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	begIdx, out := ema(0, len(inReal)-1, inReal, optInTimePeriod, 1.0/float64(optInTimePeriod))
+	return utils.NewResult(begIdx, out), nil
+}
+
+// MCGINLEYLookback returns the number of input bars consumed before the first McGinley Dynamic value
+func MCGINLEYLookback(optInTimePeriod int) int {
+	return optInTimePeriod - 1
+}
+
+// MCGINLEY calculates the McGinley Dynamic, seeded with the simple average:
+// MD += (price - MD) / (period * (price/MD)^4)
+func MCGINLEY(inReal []float64, optInTimePeriod int) (*utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	begIdx, out := mcginley(0, len(inReal)-1, inReal, optInTimePeriod)
+	return utils.NewResult(begIdx, out), nil
+}
+
+// mcginleyStep moves the McGinley Dynamic towards a price. It jumps to the
+// price when the ratio is undefined, at a zero price or average.
+func mcginleyStep(prevMD, price float64, period int) float64 {
+	ratio := price / prevMD
+	divider := float64(period) * ratio * ratio * ratio * ratio
+	if prevMD == 0 || divider == 0 || math.IsInf(divider, 0) {
+		return price
+	}
+	return prevMD + (price-prevMD)/divider
+}
+
+func mcginley(startIdx, endIdx int, inReal []float64, optInTimePeriod int) (int, []float64) {
+	lookbackTotal := MCGINLEYLookback(optInTimePeriod)
+	if startIdx < lookbackTotal {
+		startIdx = lookbackTotal
+	}
+	if startIdx > endIdx {
+		return 0, nil
+	}
+
+	today := startIdx - lookbackTotal
+	tempReal := 0.0
+	for i := 0; i < optInTimePeriod; i++ {
+		tempReal += inReal[today]
+		today++
+	}
+	prevMD := tempReal / float64(optInTimePeriod)
+
+	out := make([]float64, 1, endIdx-startIdx+1)
+	out[0] = prevMD
+	for today <= endIdx {
+		prevMD = mcginleyStep(prevMD, inReal[today], optInTimePeriod)
+		today++
+		out = append(out, prevMD)
+	}
+	return startIdx, out
+}
+
+// MALookback returns the number of input bars consumed before the first MA value
+func MALookback(optInTimePeriod int, optInMAType utils.MAType) int {
+	if optInTimePeriod <= 1 {
+		return 0
+	}
+	switch optInMAType {
+	case utils.SMA:
+		return SMALookback(optInTimePeriod)
+	case utils.EMA:
+		return EMALookback(optInTimePeriod)
+	case utils.WMA:
+		return WMALookback(optInTimePeriod)
+	case utils.DEMA:
+		return DEMALookback(optInTimePeriod)
+	case utils.TEMA:
+		return TEMALookback(optInTimePeriod)
+	case utils.TRIMA:
+		return TRIMALookback(optInTimePeriod)
+	case utils.KAMA:
+		return KAMALookback(optInTimePeriod)
+	case utils.MAMA:
+		return MAMALookback(0.5, 0.05)
+	case utils.T3:
+		return T3Lookback(optInTimePeriod, 0.7)
+	case utils.HMA:
+		return HMALookback(optInTimePeriod)
+	case utils.ALMA:
+		return ALMALookback(optInTimePeriod)
+	case utils.ZLEMA:
+		return ZLEMALookback(optInTimePeriod)
+	case utils.SMMA:
+		return SMMALookback(optInTimePeriod)
+	case utils.MCGINLEY:
+		return MCGINLEYLookback(optInTimePeriod)
+	default:
+		return 0
+	}
+}
+
+// MA calculates a moving average of the given type
+func MA(inReal []float64, optInTimePeriod int, optInMAType utils.MAType) (*utils.MAResult, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 1); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckMAType(optInMAType); err != nil {
+		return nil, err
+	}
+	begIdx, out := ma(0, len(inReal)-1, inReal, optInTimePeriod, optInMAType)
+	return &utils.MAResult{Result: *utils.NewResult(begIdx, out), MAType: optInMAType}, nil
+}
+
+// ma dispatches to the moving average of the given type, the type must be valid
+func ma(startIdx, endIdx int, inReal []float64, optInTimePeriod int, optInMAType utils.MAType) (int, []float64) {
+	if optInTimePeriod == 1 {
+		return startIdx, append([]float64(nil), inReal[startIdx:endIdx+1]...)
+	}
+
+	switch optInMAType {
+	case utils.SMA:
+		return sma(startIdx, endIdx, inReal, optInTimePeriod)
+	case utils.EMA:
+		return ema(startIdx, endIdx, inReal, optInTimePeriod, utils.PerToK(optInTimePeriod))
+	case utils.WMA:
+		return wma(startIdx, endIdx, inReal, optInTimePeriod)
+	case utils.DEMA:
+		return dema(startIdx, endIdx, inReal, optInTimePeriod)
+	case utils.TEMA:
+		return tema(startIdx, endIdx, inReal, optInTimePeriod)
+	case utils.TRIMA:
+		return trima(startIdx, endIdx, inReal, optInTimePeriod)
+	case utils.KAMA:
+		return kama(startIdx, endIdx, inReal, optInTimePeriod)
+	case utils.MAMA:
+		begIdx, out, _ := mama(startIdx, endIdx, inReal, 0.5, 0.05)
+		return begIdx, out
+	case utils.T3:
+		return t3(startIdx, endIdx, inReal, optInTimePeriod, 0.7)
+	case utils.HMA:
+		return hma(startIdx, endIdx, inReal, optInTimePeriod)
+	case utils.ALMA:
+		return alma(startIdx, endIdx, inReal, optInTimePeriod, 0.85, 6)
+	case utils.ZLEMA:
+		return zlema(startIdx, endIdx, inReal, optInTimePeriod)
+	case utils.SMMA:
+		return ema(startIdx, endIdx, inReal, optInTimePeriod, 1.0/float64(optInTimePeriod))
+	case utils.MCGINLEY:
+		return mcginley(startIdx, endIdx, inReal, optInTimePeriod)
+	default:
+		return 0, nil
+	}
+}
+
+// nonNil returns an empty slice instead of nil
+func nonNil(values []float64) []float64 {
+	if values == nil {
+		return []float64{}
+	}
+	return values
+}
@@ -0,0 +1,232 @@
+package indicators_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/indicators"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testdata"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// The averages added to the TA-Lib ones match their textbook definitions
+
+// naiveWMA is the weighted average of the period ending at bar i
+func naiveWMA(in []float64, i, period int) float64 {
+	sum, weights := 0.0, 0.0
+	for k := 0; k < period; k++ {
+		sum += float64(period-k) * in[i-k]
+		weights += float64(period - k)
+	}
+	return sum / weights
+}
+
+// definition computes an average from its textbook formula, returning its
+// first bar and its values indexed by bar
+type definition func(close, volume []float64, period int) (int, []float64)
+
+// hull is the WMA over sqrt(period) of 2*WMA(period/2) - WMA(period)
+func hull(close, _ []float64, period int) (int, []float64) {
+	half, sqrtPeriod := period/2, int(math.Sqrt(float64(period)))
+	raw := make([]float64, len(close))
+	for i := period - 1; i < len(close); i++ {
+		raw[i] = 2*naiveWMA(close, i, half) - naiveWMA(close, i, period)
+	}
+	begIdx := period + sqrtPeriod - 2
+	out := make([]float64, len(close))
+	for i := begIdx; i < len(close); i++ {
+		out[i] = naiveWMA(raw, i, sqrtPeriod)
+	}
+	return begIdx, out
+}
+
+// arnaudLegoux weighs the window with a Gaussian centred at 85% of it
+func arnaudLegoux(close, _ []float64, period int) (int, []float64) {
+	m, s := 0.85*float64(period-1), float64(period)/6
+	out := make([]float64, len(close))
+	for i := period - 1; i < len(close); i++ {
+		sum, weights := 0.0, 0.0
+		for j := 0; j < period; j++ {
+			w := math.Exp(-(float64(j) - m) * (float64(j) - m) / (2 * s * s))
+			sum += w * close[i-period+1+j]
+			weights += w
+		}
+		out[i] = sum / weights
+	}
+	return period - 1, out
+}
+
+// zeroLag is the EMA of the de-lagged price, seeded with its average
+func zeroLag(close, _ []float64, period int) (int, []float64) {
+	lag := (period - 1) / 2
+	begIdx := lag + period - 1
+	out := make([]float64, len(close))
+	for i := lag; i <= begIdx; i++ {
+		out[begIdx] += (2*close[i] - close[i-lag]) / float64(period)
+	}
+	for i := begIdx + 1; i < len(close); i++ {
+		out[i] = out[i-1] + (2*close[i]-close[i-lag]-out[i-1])*2/float64(period+1)
+	}
+	return begIdx, out
+}
+
+// volumeWeighted is the price times volume over volume
+func volumeWeighted(close, volume []float64, period int) (int, []float64) {
+	out := make([]float64, len(close))
+	for i := period - 1; i < len(close); i++ {
+		pv, vol := 0.0, 0.0
+		for j := i - period + 1; j <= i; j++ {
+			pv += close[j] * volume[j]
+			vol += volume[j]
+		}
+		out[i] = pv / vol
+	}
+	return period - 1, out
+}
+
+// seeded applies a recursive average from the mean of the first period
+func seeded(next func(prev, price float64, period int) float64) definition {
+	return func(close, _ []float64, period int) (int, []float64) {
+		out := make([]float64, len(close))
+		for i := 0; i < period; i++ {
+			out[period-1] += close[i] / float64(period)
+		}
+		for i := period; i < len(close); i++ {
+			out[i] = next(out[i-1], close[i], period)
+		}
+		return period - 1, out
+	}
+}
+
+func TestExtendedAverages(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	close := utils.GetFieldSlice(data, utils.FieldClose)
+	volume := utils.GetFieldSlice(data, utils.FieldVolume)
+	for _, tc := range []struct {
+		name string
+		run  func(period int) (*utils.Result, error)
+		want definition
+	}{
+		{"HMA", func(period int) (*utils.Result, error) { return indicators.HMA(close, period) }, hull},
+		{"ALMA", func(period int) (*utils.Result, error) { return indicators.ALMA(close, period, 0.85, 6) }, arnaudLegoux},
+		{"ZLEMA", func(period int) (*utils.Result, error) { return indicators.ZLEMA(close, period) }, zeroLag},
+		{"VWMA", func(period int) (*utils.Result, error) { return indicators.VWMA(close, volume, period) }, volumeWeighted},
+		// Wilder's (prev*(n-1) + price)/n
+		{"SMMA", func(period int) (*utils.Result, error) { return indicators.SMMA(close, period) }, seeded(func(prev, price float64, period int) float64 {
+			return (prev*float64(period-1) + price) / float64(period)
+		})},
+		// MD + (price - MD) / (n * (price/MD)^4)
+		{"MCGINLEY", func(period int) (*utils.Result, error) { return indicators.MCGINLEY(close, period) }, seeded(func(prev, price float64, period int) float64 {
+			return prev + (price-prev)/(float64(period)*math.Pow(price/prev, 4))
+		})},
+	} {
+		for _, period := range []int{2, 3, 9, 16, 30} {
+			name := fmt.Sprintf("%s(%d)", tc.name, period)
+			got, err := tc.run(period)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			begIdx, want := tc.want(close, volume, period)
+			if got.BeginIndex != begIdx || len(got.Values) != len(close)-begIdx {
+				t.Errorf("%s: %d values from bar %d, want them from bar %d", name, len(got.Values), got.BeginIndex, begIdx)
+				continue
+			}
+			for k, v := range got.Values {
+				i := begIdx + k
+				if math.Abs(v-want[i]) > 1e-9*max(math.Abs(want[i]), 1) {
+					t.Errorf("%s: bar %d is %v, want %v", name, i, v, want[i])
+					break
+				}
+			}
+		}
+	}
+}
+
+// spikyVolumes returns the bars near 70,000 with a volume spiking now and
+// then, where the sums of p*v lose the small volumes once a spike has left
+// the window
+func spikyVolumes(n int) []utils.OHLCV {
+	bars := testdata.TightOHLCV(n)
+	for i := 0; i < n; i += 50 {
+		bars[i].Volume *= 1e8
+	}
+	return bars
+}
+
+func TestVWMAPrecision(t *testing.T) {
+	const period = 20
+	b := utils.BarsFromOHLCV(spikyVolumes(100000))
+	got, err := indicators.VWMA(b.Close, b.Volume, period)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newFloat := func() *big.Float { return new(big.Float).SetPrec(256) }
+	worstNaive := 0.0
+	var sumPV, sumV float64
+	for i := range b.Close {
+		sumPV += b.Close[i] * b.Volume[i]
+		sumV += b.Volume[i]
+		if i >= period {
+			sumPV -= b.Close[i-period] * b.Volume[i-period]
+			sumV -= b.Volume[i-period]
+		}
+		if i < period-1 || i%997 != 0 {
+			continue
+		}
+		pv, v := newFloat(), newFloat()
+		for k := i - period + 1; k <= i; k++ {
+			pv.Add(pv, newFloat().Mul(newFloat().SetFloat64(b.Close[k]), newFloat().SetFloat64(b.Volume[k])))
+			v.Add(v, newFloat().SetFloat64(b.Volume[k]))
+		}
+		want, _ := pv.Quo(pv, v).Float64()
+		if value := got.Values[i-got.BeginIndex]; math.Abs(value-want) > 1e-13*want {
+			t.Fatalf("VWMA(%d) of 70,000 at bar %d is %v, want %v", period, i, value, want)
+		}
+		worstNaive = max(worstNaive, math.Abs(sumPV/sumV-want)/want)
+	}
+	if worstNaive < 1e-11 {
+		t.Errorf("running sums keep the VWMA within %v, the series is too easy", worstNaive)
+	}
+}
+
+// The added types go through MA and the MA-typed indicators
+func TestExtendedMATypes(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	close := utils.GetFieldSlice(data, utils.FieldClose)
+	for _, maType := range []utils.MAType{utils.HMA, utils.ALMA, utils.ZLEMA, utils.SMMA, utils.MCGINLEY} {
+		for _, apo := range []func([]float64, int, int, ...utils.MAType) (*utils.Result, error){indicators.APO, indicators.PPO} {
+			if _, err := apo(close, 12, 26, maType); err != nil {
+				t.Errorf("price oscillator with %v: %v", maType, err)
+			}
+		}
+		if _, err := indicators.BBANDS(close, 20, 2, 2, maType); err != nil {
+			t.Errorf("BBANDS with %v: %v", maType, err)
+		}
+	}
+
+	// T3 has its TA-Lib value and MA uses the TA-Lib volume factor of 0.7
+	if utils.T3 != 8 || utils.HMA != 9 || utils.MCGINLEY != 13 {
+		t.Errorf("T3 is %d, HMA %d and MCGINLEY %d", utils.T3, utils.HMA, utils.MCGINLEY)
+	}
+	ma, err := indicators.MA(close, 10, utils.T3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t3, err := indicators.T3(close, 10, 0.7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ma.BeginIndex != t3.BeginIndex || !slices.Equal(ma.Values, t3.Values) {
+		t.Errorf("MA of type T3 differs from T3 with a volume factor of 0.7")
+	}
+	for _, vFactor := range []float64{-0.1, 1.1, math.NaN()} {
+		if _, err := indicators.T3(close, 10, vFactor); !errors.Is(err, utils.ErrInvalidParameter) {
+			t.Errorf("T3 volume factor %v: %v", vFactor, err)
+		}
+	}
+}
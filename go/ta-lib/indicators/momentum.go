@@ -0,0 +1,883 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// RSILookback returns the number of input bars consumed before the first RSI value
+func RSILookback(optInTimePeriod int) int {
+	return optInTimePeriod
+}
+
+// RSI calculates the Relative Strength Index
+func RSI(inReal []float64, optInTimePeriod int) (*utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	begIdx, out := rsi(0, len(inReal)-1, inReal, optInTimePeriod)
+	return utils.NewResult(begIdx, out), nil
+}
+
+func rsi(startIdx, endIdx int, inReal []float64, optInTimePeriod int) (int, []float64) {
+	lookbackTotal := RSILookback(optInTimePeriod)
+	if startIdx < lookbackTotal {
+		startIdx = lookbackTotal
+	}
+	if startIdx > endIdx {
+		return 0, nil
+	}
+	if optInTimePeriod == 1 {
+		return startIdx, append([]float64(nil), inReal[startIdx:endIdx+1]...)
+	}
+
+	period := float64(optInTimePeriod)
+	today := startIdx - lookbackTotal
+	prevValue := inReal[today]
+
+	// Accumulate the initial gains and losses
+	prevGain, prevLoss := 0.0, 0.0
+	today++
+	for i := optInTimePeriod; i > 0; i-- {
+		tempValue1 := inReal[today]
+		today++
+		tempValue2 := tempValue1 - prevValue
+		prevValue = tempValue1
+		if tempValue2 < 0 {
+			prevLoss -= tempValue2
+		} else {
+			prevGain += tempValue2
+		}
+	}
+	prevLoss /= period
+	prevGain /= period
+
+	out := make([]float64, 0, endIdx-startIdx+1)
+	if today > startIdx {
+		out = append(out, rsiValue(prevGain, prevLoss))
+	} else {
+		// Skip the unstable period
+		for today < startIdx {
+			tempValue2 := inReal[today] - prevValue
+			prevValue = inReal[today]
+			prevLoss *= period - 1
+			prevGain *= period - 1
+			if tempValue2 < 0 {
+				prevLoss -= tempValue2
+			} else {
+				prevGain += tempValue2
+			}
+			prevLoss /= period
+			prevGain /= period
+			today++
+		}
+	}
+
+	for today <= endIdx {
+		tempValue2 := inReal[today] - prevValue
+		prevValue = inReal[today]
+		today++
+		prevLoss *= period - 1
+		prevGain *= period - 1
+		if tempValue2 < 0 {
+			prevLoss -= tempValue2
+		} else {
+			prevGain += tempValue2
+		}
+		prevLoss /= period
+		prevGain /= period
+		out = append(out, rsiValue(prevGain, prevLoss))
+	}
+	return startIdx, out
+}
+
+// rsiValue converts average gain and loss into an RSI value
+func rsiValue(avgGain, avgLoss float64) float64 {
+	total := avgGain + avgLoss
+	if utils.TAIsZero(total) {
+		return 0.0
+	}
+	return 100.0 * (avgGain / total)
+}
+
+// MACDLookback returns the number of input bars consumed before the first MACD value
+func MACDLookback(optInFastPeriod, optInSlowPeriod, optInSignalPeriod int) int {
+	if optInSlowPeriod < optInFastPeriod {
+		optInSlowPeriod = optInFastPeriod
+	}
+	return EMALookback(optInSlowPeriod) + EMALookback(optInSignalPeriod)
+}
+
+// MACD calculates the Moving Average Convergence/Divergence
+func MACD(inReal []float64, optInFastPeriod, optInSlowPeriod, optInSignalPeriod int) (*utils.MACDResult, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("fast period", optInFastPeriod, 2); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("slow period", optInSlowPeriod, 2); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("signal period", optInSignalPeriod, 1); err != nil {
+		return nil, err
+	}
+	return macd(0, len(inReal)-1, inReal, optInFastPeriod, optInSlowPeriod, optInSignalPeriod), nil
+}
+
+func macd(startIdx, endIdx int, inReal []float64, optInFastPeriod, optInSlowPeriod, optInSignalPeriod int) *utils.MACDResult {
+	if optInSlowPeriod < optInFastPeriod {
+		optInSlowPeriod, optInFastPeriod = optInFastPeriod, optInSlowPeriod
+	}
+
+	lookbackSignal := EMALookback(optInSignalPeriod)
+	lookbackTotal := lookbackSignal + EMALookback(optInSlowPeriod)
+	if startIdx < lookbackTotal {
+		startIdx = lookbackTotal
+	}
+	if startIdx > endIdx {
+		return newMACDResult(0, nil, nil, nil)
+	}
+
+	// Both EMAs start at the same bar so the difference is aligned
+	tempInteger := startIdx - lookbackSignal
+	_, slowEMA := ema(tempInteger, endIdx, inReal, optInSlowPeriod, utils.PerToK(optInSlowPeriod))
+	_, fastEMA := ema(tempInteger, endIdx, inReal, optInFastPeriod, utils.PerToK(optInFastPeriod))
+	for i := range fastEMA {
+		fastEMA[i] -= slowEMA[i]
+	}
+
+	outMACD := append([]float64(nil), fastEMA[lookbackSignal:]...)
+	_, outSignal := ema(0, len(fastEMA)-1, fastEMA, optInSignalPeriod, utils.PerToK(optInSignalPeriod))
+	outHist := make([]float64, len(outSignal))
+	for i := range outSignal {
+		outHist[i] = outMACD[i] - outSignal[i]
+	}
+	return newMACDResult(startIdx, outMACD, outSignal, outHist)
+}
+
+// MACDEXTLookback returns the number of input bars consumed before the first MACDEXT value
+func MACDEXTLookback(optInFastPeriod int, optInFastMAType utils.MAType, optInSlowPeriod int, optInSlowMAType utils.MAType, optInSignalPeriod int, optInSignalMAType utils.MAType) int {
+	return max(MALookback(optInFastPeriod, optInFastMAType), MALookback(optInSlowPeriod, optInSlowMAType)) +
+		MALookback(optInSignalPeriod, optInSignalMAType)
+}
+
+// MACDEXT calculates the MACD with a moving average type for each of the
+// fast, slow and signal lines. With EMA for all three it is the classic MACD.
+func MACDEXT(inReal []float64, optInFastPeriod int, optInFastMAType utils.MAType, optInSlowPeriod int, optInSlowMAType utils.MAType, optInSignalPeriod int, optInSignalMAType utils.MAType) (*utils.MACDResult, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("fast period", optInFastPeriod, 2); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("slow period", optInSlowPeriod, 2); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("signal period", optInSignalPeriod, 1); err != nil {
+		return nil, err
+	}
+	for _, maType := range []utils.MAType{optInFastMAType, optInSlowMAType, optInSignalMAType} {
+		if err := utils.CheckMAType(maType); err != nil {
+			return nil, err
+		}
+	}
+
+	if optInSlowPeriod < optInFastPeriod {
+		optInSlowPeriod, optInFastPeriod = optInFastPeriod, optInSlowPeriod
+		optInSlowMAType, optInFastMAType = optInFastMAType, optInSlowMAType
+	}
+	lookbackSignal := MALookback(optInSignalPeriod, optInSignalMAType)
+	startIdx := MACDEXTLookback(optInFastPeriod, optInFastMAType, optInSlowPeriod, optInSlowMAType, optInSignalPeriod, optInSignalMAType)
+	endIdx := len(inReal) - 1
+	if startIdx > endIdx {
+		return newMACDResult(0, nil, nil, nil), nil
+	}
+
+	// Both MAs start at the same bar so the difference is aligned
+	tempInteger := startIdx - lookbackSignal
+	_, slowMA := ma(tempInteger, endIdx, inReal, optInSlowPeriod, optInSlowMAType)
+	_, fastMA := ma(tempInteger, endIdx, inReal, optInFastPeriod, optInFastMAType)
+	for i := range fastMA {
+		fastMA[i] -= slowMA[i]
+	}
+
+	outMACD := append([]float64(nil), fastMA[lookbackSignal:]...)
+	_, outSignal := ma(0, len(fastMA)-1, fastMA, optInSignalPeriod, optInSignalMAType)
+	outHist := make([]float64, len(outSignal))
+	for i := range outSignal {
+		outHist[i] = outMACD[i] - outSignal[i]
+	}
+	return newMACDResult(startIdx, outMACD, outSignal, outHist), nil
+}
+
+// newMACDResult wraps the three MACD outputs in a MACDResult
+func newMACDResult(begIdx int, outMACD, outSignal, outHist []float64) *utils.MACDResult {
+	return &utils.MACDResult{
+		Result:     *utils.NewResult(begIdx, outMACD),
+		MACDSignal: nonNil(outSignal),
+		MACDHist:   nonNil(outHist),
+	}
+}
+
+// STOCHResult represents the output of the Stochastic oscillator
+type STOCHResult struct {
+	utils.Result           // Slow %K, same as SlowK
+	SlowK        []float64 // Slow %K line
+	SlowD        []float64 // Slow %D line
+}
+
+// STOCHLookback returns the number of input bars consumed before the first STOCH value
+func STOCHLookback(optInFastKPeriod, optInSlowKPeriod int, optInSlowKMAType utils.MAType, optInSlowDPeriod int, optInSlowDMAType utils.MAType) int {
+	return (optInFastKPeriod - 1) + MALookback(optInSlowKPeriod, optInSlowKMAType) + MALookback(optInSlowDPeriod, optInSlowDMAType)
+}
+
+// STOCH calculates the Stochastic oscillator, the optional MA types apply to
+// slow %K and slow %D and default to SMA
+func STOCH(inHigh, inLow, inClose []float64, optInFastKPeriod, optInSlowKPeriod, optInSlowDPeriod int, optInMATypes ...utils.MAType) (*STOCHResult, error) {
+	if err := checkPrice(inHigh, inLow, inClose); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("fast-K period", optInFastKPeriod, 1); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("slow-K period", optInSlowKPeriod, 1); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("slow-D period", optInSlowDPeriod, 1); err != nil {
+		return nil, err
+	}
+	slowKMAType := utils.OptionalMAType(optInMATypes, 0)
+	slowDMAType := utils.OptionalMAType(optInMATypes, 1)
+	if err := utils.CheckMAType(slowKMAType); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckMAType(slowDMAType); err != nil {
+		return nil, err
+	}
+
+	lookbackK := optInFastKPeriod - 1
+	lookbackDSlow := MALookback(optInSlowDPeriod, slowDMAType)
+	startIdx := STOCHLookback(optInFastKPeriod, optInSlowKPeriod, slowKMAType, optInSlowDPeriod, slowDMAType)
+	endIdx := len(inHigh) - 1
+	if startIdx > endIdx {
+		return newSTOCHResult(0, nil, nil), nil
+	}
+
+	fastK := fastStochastic(lookbackK, endIdx, inHigh, inLow, inClose, optInFastKPeriod)
+	_, slowK := ma(0, len(fastK)-1, fastK, optInSlowKPeriod, slowKMAType)
+	if len(slowK) == 0 {
+		return newSTOCHResult(0, nil, nil), nil
+	}
+	_, slowD := ma(0, len(slowK)-1, slowK, optInSlowDPeriod, slowDMAType)
+	return newSTOCHResult(startIdx, slowK[lookbackDSlow:lookbackDSlow+len(slowD)], slowD), nil
+}
+
+// newSTOCHResult wraps slow %K and %D in a STOCHResult
+func newSTOCHResult(begIdx int, slowK, slowD []float64) *STOCHResult {
+	result := utils.NewResult(begIdx, slowK)
+	return &STOCHResult{
+		Result: *result,
+		SlowK:  result.Values,
+		SlowD:  nonNil(slowD),
+	}
+}
+
+// fastStochastic calculates the raw %K from today to endIdx
+func fastStochastic(today, endIdx int, inHigh, inLow, inClose []float64, optInFastKPeriod int) []float64 {
+	highest, lowest := windowExtremes(today, endIdx, inHigh, inLow, optInFastKPeriod)
+	out := make([]float64, len(highest))
+	for i := range out {
+		diff := (highest[i] - lowest[i]) / 100.0
+		if diff != 0.0 {
+			out[i] = (inClose[today+i] - lowest[i]) / diff
+		}
+	}
+	return out
+}
+
+// windowExtremes returns the highest high and lowest low of the optInTimePeriod
+// bars ending at each bar from today to endIdx. A full rescan only happens when
+// the previous extreme falls out of the window.
+func windowExtremes(today, endIdx int, inHigh, inLow []float64, optInTimePeriod int) ([]float64, []float64) {
+	trailingIdx := today - (optInTimePeriod - 1)
+	lowestIdx, highestIdx := -1, -1
+	highest, lowest := 0.0, 0.0
+
+	highs := make([]float64, 0, endIdx-today+1)
+	lows := make([]float64, 0, endIdx-today+1)
+	for today <= endIdx {
+		// Set the lowest low
+		if lowestIdx < trailingIdx {
+			lowestIdx = trailingIdx
+			lowest = inLow[lowestIdx]
+			for i := lowestIdx + 1; i <= today; i++ {
+				if inLow[i] < lowest {
+					lowestIdx = i
+					lowest = inLow[i]
+				}
+			}
+		} else if inLow[today] <= lowest {
+			lowestIdx = today
+			lowest = inLow[today]
+		}
+
+		// Set the highest high
+		if highestIdx < trailingIdx {
+			highestIdx = trailingIdx
+			highest = inHigh[highestIdx]
+			for i := highestIdx + 1; i <= today; i++ {
+				if inHigh[i] > highest {
+					highestIdx = i
+					highest = inHigh[i]
+				}
+			}
+		} else if inHigh[today] >= highest {
+			highestIdx = today
+			highest = inHigh[today]
+		}
+
+		highs = append(highs, highest)
+		lows = append(lows, lowest)
+		trailingIdx++
+		today++
+	}
+	return highs, lows
+}
+
+// STOCHFResult represents the output of the Fast Stochastic oscillator
+type STOCHFResult struct {
+	utils.Result           // Fast %K, same as FastK
+	FastK        []float64 // Fast %K line
+	FastD        []float64 // Fast %D line
+}
+
+// STOCHFLookback returns the number of input bars consumed before the first STOCHF value
+func STOCHFLookback(optInFastKPeriod, optInFastDPeriod int, optInFastDMAType utils.MAType) int {
+	return (optInFastKPeriod - 1) + MALookback(optInFastDPeriod, optInFastDMAType)
+}
+
+// STOCHF calculates the Fast Stochastic oscillator, the optional MA type
+// applies to fast %D and defaults to SMA
+func STOCHF(inHigh, inLow, inClose []float64, optInFastKPeriod, optInFastDPeriod int, optInFastDMAType ...utils.MAType) (*STOCHFResult, error) {
+	if err := checkPrice(inHigh, inLow, inClose); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("fast-K period", optInFastKPeriod, 1); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("fast-D period", optInFastDPeriod, 1); err != nil {
+		return nil, err
+	}
+	fastDMAType := utils.OptionalMAType(optInFastDMAType, 0)
+	if err := utils.CheckMAType(fastDMAType); err != nil {
+		return nil, err
+	}
+	begIdx, fastK, fastD := stochf(0, len(inHigh)-1, inHigh, inLow, inClose, optInFastKPeriod, optInFastDPeriod, fastDMAType)
+	result := utils.NewResult(begIdx, fastK)
+	return &STOCHFResult{Result: *result, FastK: result.Values, FastD: nonNil(fastD)}, nil
+}
+
+func stochf(startIdx, endIdx int, inHigh, inLow, inClose []float64, optInFastKPeriod, optInFastDPeriod int, optInFastDMAType utils.MAType) (int, []float64, []float64) {
+	lookbackK := optInFastKPeriod - 1
+	lookbackFastD := MALookback(optInFastDPeriod, optInFastDMAType)
+	lookbackTotal := lookbackK + lookbackFastD
+	if startIdx < lookbackTotal {
+		startIdx = lookbackTotal
+	}
+	if startIdx > endIdx {
+		return 0, nil, nil
+	}
+
+	fastK := fastStochastic(startIdx-lookbackFastD, endIdx, inHigh, inLow, inClose, optInFastKPeriod)
+	_, fastD := ma(0, len(fastK)-1, fastK, optInFastDPeriod, optInFastDMAType)
+	if len(fastD) == 0 {
+		return 0, nil, nil
+	}
+	return startIdx, fastK[lookbackFastD : lookbackFastD+len(fastD)], fastD
+}
+
+// STOCHRSILookback returns the number of input bars consumed before the first STOCHRSI value
+func STOCHRSILookback(optInTimePeriod, optInFastKPeriod, optInFastDPeriod int, optInFastDMAType utils.MAType) int {
+	return RSILookback(optInTimePeriod) + STOCHFLookback(optInFastKPeriod, optInFastDPeriod, optInFastDMAType)
+}
+
+// STOCHRSI calculates the Stochastic RSI, returning fast %K and fast %D
+func STOCHRSI(inReal []float64, optInTimePeriod, optInFastKPeriod, optInFastDPeriod int, optInFastDMAType utils.MAType) (*utils.Result, *utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, nil, err
+	}
+	if err := utils.CheckPeriod("fast-K period", optInFastKPeriod, 1); err != nil {
+		return nil, nil, err
+	}
+	if err := utils.CheckPeriod("fast-D period", optInFastDPeriod, 1); err != nil {
+		return nil, nil, err
+	}
+	if err := utils.CheckMAType(optInFastDMAType); err != nil {
+		return nil, nil, err
+	}
+
+	endIdx := len(inReal) - 1
+	lookbackSTOCHF := STOCHFLookback(optInFastKPeriod, optInFastDPeriod, optInFastDMAType)
+	startIdx := STOCHRSILookback(optInTimePeriod, optInFastKPeriod, optInFastDPeriod, optInFastDMAType)
+	if startIdx > endIdx {
+		return utils.NewResult(0, nil), utils.NewResult(0, nil), nil
+	}
+
+	_, tempRSI := rsi(startIdx-lookbackSTOCHF, endIdx, inReal, optInTimePeriod)
+	if len(tempRSI) == 0 {
+		return utils.NewResult(0, nil), utils.NewResult(0, nil), nil
+	}
+	_, fastK, fastD := stochf(0, len(tempRSI)-1, tempRSI, tempRSI, tempRSI, optInFastKPeriod, optInFastDPeriod, optInFastDMAType)
+	if len(fastK) == 0 {
+		return utils.NewResult(0, nil), utils.NewResult(0, nil), nil
+	}
+	return utils.NewResult(startIdx, fastK), utils.NewResult(startIdx, fastD), nil
+}
+
+// ROCLookback returns the number of input bars consumed before the first ROC value
+func ROCLookback(optInTimePeriod int) int {
+	return optInTimePeriod
+}
+
+// ROC calculates the Rate of Change: ((price/prevPrice)-1)*100
+func ROC(inReal []float64, optInTimePeriod int) (*utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 1); err != nil {
+		return nil, err
+	}
+
+	startIdx := ROCLookback(optInTimePeriod)
+	out := make([]float64, 0, len(inReal))
+	for inIdx := startIdx; inIdx < len(inReal); inIdx++ {
+		tempReal := inReal[inIdx-optInTimePeriod]
+		if tempReal != 0.0 {
+			out = append(out, ((inReal[inIdx]/tempReal)-1.0)*100.0)
+		} else {
+			out = append(out, 0.0)
+		}
+	}
+	return utils.NewResult(startIdx, out), nil
+}
+
+// CCILookback returns the number of input bars consumed before the first CCI value
+func CCILookback(optInTimePeriod int) int {
+	return optInTimePeriod - 1
+}
+
+// CCI calculates the Commodity Channel Index
+func CCI(inHigh, inLow, inClose []float64, optInTimePeriod int) (*utils.Result, error) {
+	if err := checkPrice(inHigh, inLow, inClose); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+
+	lookbackTotal := CCILookback(optInTimePeriod)
+	endIdx := len(inHigh) - 1
+	if lookbackTotal > endIdx {
+		return utils.NewResult(0, nil), nil
+	}
+
+	// Circular buffer of the typical prices in the window
+	circBuffer := make([]float64, optInTimePeriod)
+	circIdx := 0
+	i := 0
+	for ; i < lookbackTotal; i++ {
+		circBuffer[circIdx] = (inHigh[i] + inLow[i] + inClose[i]) / 3
+		circIdx++
+	}
+
+	period := float64(optInTimePeriod)
+	out := make([]float64, 0, endIdx-lookbackTotal+1)
+	for ; i <= endIdx; i++ {
+		lastValue := (inHigh[i] + inLow[i] + inClose[i]) / 3
+		circBuffer[circIdx] = lastValue
+
+		theAverage := 0.0
+		for _, v := range circBuffer {
+			theAverage += v
+		}
+		theAverage /= period
+
+		tempReal2 := 0.0
+		for _, v := range circBuffer {
+			tempReal2 += math.Abs(v - theAverage)
+		}
+
+		tempReal := lastValue - theAverage
+		if tempReal != 0.0 && tempReal2 != 0.0 {
+			out = append(out, tempReal/(0.015*(tempReal2/period)))
+		} else {
+			out = append(out, 0.0)
+		}
+
+		circIdx++
+		if circIdx == optInTimePeriod {
+			circIdx = 0
+		}
+	}
+	return utils.NewResult(lookbackTotal, out), nil
+}
+
+// WILLRLookback returns the number of input bars consumed before the first WILLR value
+func WILLRLookback(optInTimePeriod int) int {
+	return optInTimePeriod - 1
+}
+
+// WILLR calculates Williams' %R
+func WILLR(inHigh, inLow, inClose []float64, optInTimePeriod int) (*utils.Result, error) {
+	if err := checkPrice(inHigh, inLow, inClose); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+
+	startIdx := WILLRLookback(optInTimePeriod)
+	if startIdx > len(inHigh)-1 {
+		return utils.NewResult(0, nil), nil
+	}
+
+	highest, lowest := windowExtremes(startIdx, len(inHigh)-1, inHigh, inLow, optInTimePeriod)
+	out := make([]float64, len(highest))
+	for i := range out {
+		diff := (highest[i] - lowest[i]) / (-100.0)
+		if diff != 0.0 {
+			out[i] = (highest[i] - inClose[startIdx+i]) / diff
+		}
+	}
+	return utils.NewResult(startIdx, out), nil
+}
+
+// directionalMovement tracks Wilder-smoothed +DM, -DM and true range,
+// shared by ADX, DX, PLUS_DI and MINUS_DI
+type directionalMovement struct {
+	prevHigh, prevLow, prevClose float64
+	plusDM, minusDM, tr          float64
+}
+
+// deltas returns the plus and minus directional movements of the bar at today
+func (d *directionalMovement) deltas(today int, inHigh, inLow []float64) (float64, float64) {
+	diffP := inHigh[today] - d.prevHigh
+	d.prevHigh = inHigh[today]
+	diffM := d.prevLow - inLow[today]
+	d.prevLow = inLow[today]
+	return diffP, diffM
+}
+
+// accumulate adds the bar at today to the initial sums
+func (d *directionalMovement) accumulate(today int, inHigh, inLow, inClose []float64) {
+	diffP, diffM := d.deltas(today, inHigh, inLow)
+	if diffM > 0 && diffP < diffM {
+		d.minusDM += diffM
+	} else if diffP > 0 && diffP > diffM {
+		d.plusDM += diffP
+	}
+	d.tr += trueRange(d.prevHigh, d.prevLow, d.prevClose)
+	d.prevClose = inClose[today]
+}
+
+// smooth applies Wilder's smoothing with the bar at today
+func (d *directionalMovement) smooth(today int, inHigh, inLow, inClose []float64, period float64) {
+	diffP, diffM := d.deltas(today, inHigh, inLow)
+	d.minusDM -= d.minusDM / period
+	d.plusDM -= d.plusDM / period
+	if diffM > 0 && diffP < diffM {
+		d.minusDM += diffM
+	} else if diffP > 0 && diffP > diffM {
+		d.plusDM += diffP
+	}
+	d.tr = d.tr - (d.tr / period) + trueRange(d.prevHigh, d.prevLow, d.prevClose)
+	d.prevClose = inClose[today]
+}
+
+// plusDI returns the current +DI, or 0 when the true range is zero
+func (d *directionalMovement) plusDI() float64 {
+	if utils.TAIsZero(d.tr) {
+		return 0.0
+	}
+	return 100.0 * (d.plusDM / d.tr)
+}
+
+// minusDI returns the current -DI, or 0 when the true range is zero
+func (d *directionalMovement) minusDI() float64 {
+	if utils.TAIsZero(d.tr) {
+		return 0.0
+	}
+	return 100.0 * (d.minusDM / d.tr)
+}
+
+// dx returns the current directional movement index and whether it is defined
+func (d *directionalMovement) dx() (float64, bool) {
+	if utils.TAIsZero(d.tr) {
+		return 0.0, false
+	}
+	minusDI := 100.0 * (d.minusDM / d.tr)
+	plusDI := 100.0 * (d.plusDM / d.tr)
+	tempReal := minusDI + plusDI
+	if utils.TAIsZero(tempReal) {
+		return 0.0, false
+	}
+	return 100.0 * (math.Abs(minusDI-plusDI) / tempReal), true
+}
+
+// newDirectionalMovement accumulates the first optInTimePeriod-1 bars starting at today
+func newDirectionalMovement(today int, inHigh, inLow, inClose []float64, optInTimePeriod int) (*directionalMovement, int) {
+	d := &directionalMovement{
+		prevHigh:  inHigh[today],
+		prevLow:   inLow[today],
+		prevClose: inClose[today],
+	}
+	for i := optInTimePeriod - 1; i > 0; i-- {
+		today++
+		d.accumulate(today, inHigh, inLow, inClose)
+	}
+	return d, today
+}
+
+// PLUSDILookback returns the number of input bars consumed before the first PLUS_DI value
+func PLUSDILookback(optInTimePeriod int) int {
+	if optInTimePeriod > 1 {
+		return optInTimePeriod
+	}
+	return 1
+}
+
+// PLUS_DI calculates the Plus Directional Indicator
+func PLUS_DI(inHigh, inLow, inClose []float64, optInTimePeriod int) (*utils.Result, error) {
+	return directionalIndicator(inHigh, inLow, inClose, optInTimePeriod, true)
+}
+
+// MINUSDILookback returns the number of input bars consumed before the first MINUS_DI value
+func MINUSDILookback(optInTimePeriod int) int {
+	return PLUSDILookback(optInTimePeriod)
+}
+
+// MINUS_DI calculates the Minus Directional Indicator
+func MINUS_DI(inHigh, inLow, inClose []float64, optInTimePeriod int) (*utils.Result, error) {
+	return directionalIndicator(inHigh, inLow, inClose, optInTimePeriod, false)
+}
+
+// directionalIndicator implements PLUS_DI and MINUS_DI
+func directionalIndicator(inHigh, inLow, inClose []float64, optInTimePeriod int, plus bool) (*utils.Result, error) {
+	if err := checkPrice(inHigh, inLow, inClose); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 1); err != nil {
+		return nil, err
+	}
+
+	startIdx := PLUSDILookback(optInTimePeriod)
+	endIdx := len(inHigh) - 1
+	if startIdx > endIdx {
+		return utils.NewResult(0, nil), nil
+	}
+
+	out := make([]float64, 0, endIdx-startIdx+1)
+	if optInTimePeriod <= 1 {
+		// Without smoothing the indicator is the raw movement over the true range
+		d := &directionalMovement{prevHigh: inHigh[startIdx-1], prevLow: inLow[startIdx-1], prevClose: inClose[startIdx-1]}
+		for today := startIdx; today <= endIdx; today++ {
+			diffP, diffM := d.deltas(today, inHigh, inLow)
+			tr := trueRange(d.prevHigh, d.prevLow, d.prevClose)
+			value := 0.0
+			if plus && diffP > 0 && diffP > diffM && !utils.TAIsZero(tr) {
+				value = diffP / tr
+			} else if !plus && diffM > 0 && diffP < diffM && !utils.TAIsZero(tr) {
+				value = diffM / tr
+			}
+			out = append(out, value)
+			d.prevClose = inClose[today]
+		}
+		return utils.NewResult(startIdx, out), nil
+	}
+
+	period := float64(optInTimePeriod)
+	d, today := newDirectionalMovement(0, inHigh, inLow, inClose, optInTimePeriod)
+	for today < endIdx {
+		today++
+		d.smooth(today, inHigh, inLow, inClose, period)
+		if plus {
+			out = append(out, d.plusDI())
+		} else {
+			out = append(out, d.minusDI())
+		}
+	}
+	return utils.NewResult(startIdx, out), nil
+}
+
+// DXLookback returns the number of input bars consumed before the first DX value
+func DXLookback(optInTimePeriod int) int {
+	if optInTimePeriod > 1 {
+		return optInTimePeriod
+	}
+	return 2
+}
+
+// DX calculates the Directional Movement Index
+func DX(inHigh, inLow, inClose []float64, optInTimePeriod int) (*utils.Result, error) {
+	if err := checkPrice(inHigh, inLow, inClose); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+
+	startIdx := DXLookback(optInTimePeriod)
+	endIdx := len(inHigh) - 1
+	if startIdx > endIdx {
+		return utils.NewResult(0, nil), nil
+	}
+
+	period := float64(optInTimePeriod)
+	d, today := newDirectionalMovement(0, inHigh, inLow, inClose, optInTimePeriod)
+	out := make([]float64, 0, endIdx-startIdx+1)
+	for today < endIdx {
+		today++
+		d.smooth(today, inHigh, inLow, inClose, period)
+		value, ok := d.dx()
+		if !ok {
+			// Undefined values repeat the previous one
+			if len(out) > 0 {
+				value = out[len(out)-1]
+			}
+		}
+		out = append(out, value)
+	}
+	return utils.NewResult(startIdx, out), nil
+}
+
+// ADXResult represents the output of the Average Directional Movement Index
+type ADXResult struct {
+	utils.Result           // ADX line
+	PlusDI       []float64 // +DI aligned with the ADX values
+	MinusDI      []float64 // -DI aligned with the ADX values
+}
+
+// ADXLookback returns the number of input bars consumed before the first ADX value
+func ADXLookback(optInTimePeriod int) int {
+	return (2 * optInTimePeriod) - 1
+}
+
+// ADX calculates the Average Directional Movement Index, together with
+// the +DI and -DI lines over the same bars
+func ADX(inHigh, inLow, inClose []float64, optInTimePeriod int) (*ADXResult, error) {
+	if err := checkPrice(inHigh, inLow, inClose); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+
+	startIdx := ADXLookback(optInTimePeriod)
+	endIdx := len(inHigh) - 1
+	if startIdx > endIdx {
+		return &ADXResult{Result: *utils.NewResult(0, nil), PlusDI: []float64{}, MinusDI: []float64{}}, nil
+	}
+
+	period := float64(optInTimePeriod)
+	d, today := newDirectionalMovement(0, inHigh, inLow, inClose, optInTimePeriod)
+
+	// The first ADX is the average of the first period of DX values
+	sumDX := 0.0
+	for i := optInTimePeriod; i > 0; i-- {
+		today++
+		d.smooth(today, inHigh, inLow, inClose, period)
+		if value, ok := d.dx(); ok {
+			sumDX += value
+		}
+	}
+	prevADX := sumDX / period
+
+	n := endIdx - startIdx + 1
+	out := make([]float64, 1, n)
+	plusDI := make([]float64, 1, n)
+	minusDI := make([]float64, 1, n)
+	out[0] = prevADX
+	plusDI[0] = d.plusDI()
+	minusDI[0] = d.minusDI()
+	for today < endIdx {
+		today++
+		d.smooth(today, inHigh, inLow, inClose, period)
+		if value, ok := d.dx(); ok {
+			prevADX = ((prevADX * (period - 1)) + value) / period
+		}
+		out = append(out, prevADX)
+		plusDI = append(plusDI, d.plusDI())
+		minusDI = append(minusDI, d.minusDI())
+	}
+
+	return &ADXResult{
+		Result:  *utils.NewResult(startIdx, out),
+		PlusDI:  plusDI,
+		MinusDI: minusDI,
+	}, nil
+}
+
+// APOLookback returns the number of input bars consumed before the first APO value
+func APOLookback(optInFastPeriod, optInSlowPeriod int, optInMAType utils.MAType) int {
+	return MALookback(max(optInSlowPeriod, optInFastPeriod), optInMAType)
+}
+
+// APO calculates the Absolute Price Oscillator, the MA type defaults to SMA
+func APO(inReal []float64, optInFastPeriod, optInSlowPeriod int, optInMAType ...utils.MAType) (*utils.Result, error) {
+	return priceOscillator(inReal, optInFastPeriod, optInSlowPeriod, utils.OptionalMAType(optInMAType, 0), false)
+}
+
+// PPOLookback returns the number of input bars consumed before the first PPO value
+func PPOLookback(optInFastPeriod, optInSlowPeriod int, optInMAType utils.MAType) int {
+	return APOLookback(optInFastPeriod, optInSlowPeriod, optInMAType)
+}
+
+// PPO calculates the Percentage Price Oscillator, the MA type defaults to SMA
+func PPO(inReal []float64, optInFastPeriod, optInSlowPeriod int, optInMAType ...utils.MAType) (*utils.Result, error) {
+	return priceOscillator(inReal, optInFastPeriod, optInSlowPeriod, utils.OptionalMAType(optInMAType, 0), true)
+}
+
+// priceOscillator implements APO and PPO as the difference between a fast and a slow MA
+func priceOscillator(inReal []float64, optInFastPeriod, optInSlowPeriod int, optInMAType utils.MAType, doPercentageOutput bool) (*utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("fast period", optInFastPeriod, 2); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("slow period", optInSlowPeriod, 2); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckMAType(optInMAType); err != nil {
+		return nil, err
+	}
+
+	if optInSlowPeriod < optInFastPeriod {
+		optInSlowPeriod, optInFastPeriod = optInFastPeriod, optInSlowPeriod
+	}
+	endIdx := len(inReal) - 1
+	fastBegIdx, fastMA := ma(0, endIdx, inReal, optInFastPeriod, optInMAType)
+	slowBegIdx, out := ma(0, endIdx, inReal, optInSlowPeriod, optInMAType)
+
+	j := slowBegIdx - fastBegIdx
+	for i := range out {
+		if doPercentageOutput {
+			if !utils.TAIsZero(out[i]) {
+				out[i] = ((fastMA[j+i] - out[i]) / out[i]) * 100.0
+			} else {
+				out[i] = 0.0
+			}
+		} else {
+			out[i] = fastMA[j+i] - out[i]
+		}
+	}
+	return utils.NewResult(slowBegIdx, out), nil
+}
@@ -0,0 +1,265 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Price overlays that are not part of the TA-Lib catalogue. They follow the
+// same conventions: BeginIndex is the first bar where every output has a
+// value and the optional inputs are validated like the TA-Lib ones.
+
+// ICHIMOKUResult represents the output of Ichimoku Kinko Hyo
+type ICHIMOKUResult struct {
+	utils.Result           // Tenkan-sen, the conversion line
+	Kijun        []float64 // Kijun-sen, the base line
+	SenkouA      []float64 // Senkou span A, with Displacement values past the last bar
+	SenkouB      []float64 // Senkou span B, with Displacement values past the last bar
+	Chikou       []float64 // Chikou span, the close Displacement bars later, shorter by Displacement values
+	Displacement int
+}
+
+// ICHIMOKULookback returns the number of input bars consumed before the first ICHIMOKU value
+func ICHIMOKULookback(optInTenkanPeriod, optInKijunPeriod, optInSenkouBPeriod, optInDisplacement int) int {
+	return max(optInTenkanPeriod, optInKijunPeriod, optInSenkouBPeriod) - 1 + optInDisplacement
+}
+
+// ICHIMOKU calculates Ichimoku Kinko Hyo, usually with periods 9, 26 and 52
+// and a displacement of 26.
+//
+// The lines are midpoints of the highest high and lowest low: Tenkan over
+// the tenkan period, Kijun over the kijun period. Senkou A, the average of
+// Tenkan and Kijun, and Senkou B, the midpoint over the senkou B period, are
+// plotted Displacement bars ahead, so SenkouA[i] is the value computed on bar
+// BeginIndex+i-Displacement and the spans continue Displacement bars past
+// the last input bar. Chikou[i] is the close of bar BeginIndex+i+Displacement.
+func ICHIMOKU(inHigh, inLow, inClose []float64, optInTenkanPeriod, optInKijunPeriod, optInSenkouBPeriod, optInDisplacement int) (*ICHIMOKUResult, error) {
+	if err := checkPrice(inHigh, inLow, inClose); err != nil {
+		return nil, err
+	}
+	for _, p := range []struct {
+		name   string
+		period int
+	}{{"tenkan period", optInTenkanPeriod}, {"kijun period", optInKijunPeriod}, {"senkou B period", optInSenkouBPeriod}} {
+		if err := utils.CheckPeriod(p.name, p.period, 1); err != nil {
+			return nil, err
+		}
+	}
+	if err := utils.CheckPeriod("displacement", optInDisplacement, 0); err != nil {
+		return nil, err
+	}
+
+	begIdx := ICHIMOKULookback(optInTenkanPeriod, optInKijunPeriod, optInSenkouBPeriod, optInDisplacement)
+	endIdx := len(inHigh) - 1
+	if begIdx > endIdx {
+		return &ICHIMOKUResult{Result: *utils.NewResult(0, nil), Kijun: []float64{}, SenkouA: []float64{}, SenkouB: []float64{}, Chikou: []float64{}, Displacement: optInDisplacement}, nil
+	}
+
+	// Midpoints from the first bar whose displaced value is needed
+	first := begIdx - optInDisplacement
+	midpoints := func(period int) []float64 {
+		highest, lowest := windowExtremes(first, endIdx, inHigh, inLow, period)
+		for i := range highest {
+			highest[i] = (highest[i] + lowest[i]) / 2
+		}
+		return highest
+	}
+	tenkan := midpoints(optInTenkanPeriod)
+	kijun := midpoints(optInKijunPeriod)
+	senkouB := midpoints(optInSenkouBPeriod)
+	senkouA := make([]float64, len(tenkan))
+	for i := range senkouA {
+		senkouA[i] = (tenkan[i] + kijun[i]) / 2
+	}
+
+	chikou := []float64{}
+	if start := begIdx + optInDisplacement; start <= endIdx {
+		chikou = append(chikou, inClose[start:]...)
+	}
+	return &ICHIMOKUResult{
+		Result:       *utils.NewResult(begIdx, tenkan[optInDisplacement:]),
+		Kijun:        kijun[optInDisplacement:],
+		SenkouA:      senkouA,
+		SenkouB:      senkouB,
+		Chikou:       chikou,
+		Displacement: optInDisplacement,
+	}, nil
+}
+
+// checkMultiplier validates the number of ATRs between a line and its bands
+func checkMultiplier(multiplier float64) error {
+	if !(multiplier > 0) || math.IsInf(multiplier, 1) {
+		return fmt.Errorf("multiplier %v must be positive and finite: %w", multiplier, utils.ErrInvalidParameter)
+	}
+	return nil
+}
+
+// KELTNERResult represents the output of Keltner Channels
+type KELTNERResult struct {
+	utils.Result           // Middle band
+	UpperBand    []float64 // Upper band
+	LowerBand    []float64 // Lower band
+}
+
+// KELTNERLookback returns the number of input bars consumed before the first KELTNER value
+func KELTNERLookback(optInTimePeriod, optInATRPeriod int, optInMAType utils.MAType) int {
+	return max(MALookback(optInTimePeriod, optInMAType), ATRLookback(optInATRPeriod))
+}
+
+// KELTNER calculates Keltner Channels: a moving average of the close, an EMA
+// unless an MA type is given, with bands optInMultiplier ATRs away
+func KELTNER(inHigh, inLow, inClose []float64, optInTimePeriod, optInATRPeriod int, optInMultiplier float64, optInMAType ...utils.MAType) (*KELTNERResult, error) {
+	if err := checkPrice(inHigh, inLow, inClose); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("ATR period", optInATRPeriod, 1); err != nil {
+		return nil, err
+	}
+	if err := checkMultiplier(optInMultiplier); err != nil {
+		return nil, err
+	}
+	maType := utils.EMA
+	if len(optInMAType) > 0 {
+		maType = optInMAType[0]
+	}
+	if err := utils.CheckMAType(maType); err != nil {
+		return nil, err
+	}
+
+	begIdx := KELTNERLookback(optInTimePeriod, optInATRPeriod, maType)
+	endIdx := len(inHigh) - 1
+	if begIdx > endIdx {
+		return &KELTNERResult{Result: *utils.NewResult(0, nil), UpperBand: []float64{}, LowerBand: []float64{}}, nil
+	}
+	maBegIdx, middle := ma(0, endIdx, inClose, optInTimePeriod, maType)
+	atrBegIdx, ranges := atr(0, endIdx, inHigh, inLow, inClose, optInATRPeriod)
+
+	// MAMA may start later than its lookback says
+	begIdx = max(begIdx, maBegIdx)
+	if begIdx > endIdx || len(middle) == 0 {
+		return &KELTNERResult{Result: *utils.NewResult(0, nil), UpperBand: []float64{}, LowerBand: []float64{}}, nil
+	}
+	middle = middle[begIdx-maBegIdx:]
+	ranges = ranges[begIdx-atrBegIdx:]
+	upper := make([]float64, len(middle))
+	lower := make([]float64, len(middle))
+	for i := range middle {
+		upper[i] = middle[i] + optInMultiplier*ranges[i]
+		lower[i] = middle[i] - optInMultiplier*ranges[i]
+	}
+	return &KELTNERResult{Result: *utils.NewResult(begIdx, middle), UpperBand: upper, LowerBand: lower}, nil
+}
+
+// DONCHIANResult represents the output of Donchian Channels
+type DONCHIANResult struct {
+	utils.Result           // Middle band, halfway between the others
+	UpperBand    []float64 // Highest high
+	LowerBand    []float64 // Lowest low
+}
+
+// DONCHIANLookback returns the number of input bars consumed before the first DONCHIAN value
+func DONCHIANLookback(optInTimePeriod int) int {
+	return optInTimePeriod - 1
+}
+
+// DONCHIAN calculates Donchian Channels, the highest high and lowest low
+// over optInTimePeriod bars including the current one
+func DONCHIAN(inHigh, inLow []float64, optInTimePeriod int) (*DONCHIANResult, error) {
+	if err := checkHighLow(inHigh, inLow); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 1); err != nil {
+		return nil, err
+	}
+
+	begIdx := DONCHIANLookback(optInTimePeriod)
+	if begIdx > len(inHigh)-1 {
+		return &DONCHIANResult{Result: *utils.NewResult(0, nil), UpperBand: []float64{}, LowerBand: []float64{}}, nil
+	}
+	upper, lower := windowExtremes(begIdx, len(inHigh)-1, inHigh, inLow, optInTimePeriod)
+	middle := make([]float64, len(upper))
+	for i := range middle {
+		middle[i] = (upper[i] + lower[i]) / 2
+	}
+	return &DONCHIANResult{Result: *utils.NewResult(begIdx, middle), UpperBand: upper, LowerBand: lower}, nil
+}
+
+// SUPERTRENDResult represents the output of SuperTrend
+type SUPERTRENDResult struct {
+	utils.Result           // SuperTrend line, the lower band in an uptrend and the upper band in a downtrend
+	UpperBand    []float64 // Final upper band
+	LowerBand    []float64 // Final lower band
+	Direction    []int     // 1 in an uptrend, -1 in a downtrend
+}
+
+// SUPERTRENDLookback returns the number of input bars consumed before the first SUPERTREND value
+func SUPERTRENDLookback(optInTimePeriod int) int {
+	return ATRLookback(optInTimePeriod)
+}
+
+// SUPERTREND calculates SuperTrend, usually with an ATR period of 10 and a
+// multiplier of 3.
+//
+// The basic bands are the median price plus and minus optInMultiplier ATRs.
+// The final upper band only moves down, unless the previous close broke
+// above it, and the final lower band only moves up, unless the previous
+// close broke below it. The trend starts down, turns up when the close
+// breaks above the upper band and turns down when it breaks below the lower
+// band.
+func SUPERTREND(inHigh, inLow, inClose []float64, optInTimePeriod int, optInMultiplier float64) (*SUPERTRENDResult, error) {
+	if err := checkPrice(inHigh, inLow, inClose); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 1); err != nil {
+		return nil, err
+	}
+	if err := checkMultiplier(optInMultiplier); err != nil {
+		return nil, err
+	}
+
+	begIdx, ranges := atr(0, len(inHigh)-1, inHigh, inLow, inClose, optInTimePeriod)
+	if len(ranges) == 0 {
+		return &SUPERTRENDResult{Result: *utils.NewResult(0, nil), UpperBand: []float64{}, LowerBand: []float64{}, Direction: []int{}}, nil
+	}
+
+	line := make([]float64, len(ranges))
+	upper := make([]float64, len(ranges))
+	lower := make([]float64, len(ranges))
+	direction := make([]int, len(ranges))
+	for i := range ranges {
+		today := begIdx + i
+		median := (inHigh[today] + inLow[today]) / 2
+		upper[i] = median + optInMultiplier*ranges[i]
+		lower[i] = median - optInMultiplier*ranges[i]
+		direction[i] = -1
+		if i == 0 {
+			line[i] = upper[i]
+			continue
+		}
+
+		prevClose := inClose[today-1]
+		if !(upper[i] < upper[i-1] || prevClose > upper[i-1]) {
+			upper[i] = upper[i-1]
+		}
+		if !(lower[i] > lower[i-1] || prevClose < lower[i-1]) {
+			lower[i] = lower[i-1]
+		}
+		if direction[i-1] < 0 {
+			if inClose[today] > upper[i] {
+				direction[i] = 1
+			}
+		} else if inClose[today] >= lower[i] {
+			direction[i] = 1
+		}
+		line[i] = upper[i]
+		if direction[i] > 0 {
+			line[i] = lower[i]
+		}
+	}
+	return &SUPERTRENDResult{Result: *utils.NewResult(begIdx, line), UpperBand: upper, LowerBand: lower, Direction: direction}, nil
+}
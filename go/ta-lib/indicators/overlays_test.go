@@ -0,0 +1,157 @@
+package indicators_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/indicators"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// The overlays outside the TA-Lib catalogue match their definitions
+// computed naively from the sample bars
+
+// midpoint returns the middle of the highest high and lowest low of the period bars ending at bar i
+func midpoint(high, low []float64, i, period int) float64 {
+	highest, lowest := high[i], low[i]
+	for k := i - period + 1; k < i; k++ {
+		highest, lowest = max(highest, high[k]), min(lowest, low[k])
+	}
+	return (highest + lowest) / 2
+}
+
+// Ichimoku with the spans displaced forward and the chikou backward
+func TestICHIMOKU(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	_, high, low, close, _ := utils.GetOHLCVSlices(data)
+	n := len(data)
+	ichimoku, err := indicators.ICHIMOKU(high, low, close, 9, 26, 52, 26)
+	if err != nil {
+		t.Fatal(err)
+	}
+	begIdx := ichimoku.BeginIndex
+	if begIdx != 77 || len(ichimoku.Values) != n-77 || len(ichimoku.SenkouA) != n-51 || len(ichimoku.Chikou) != n-103 {
+		t.Fatalf("ichimoku from bar %d with %d values, %d span and %d chikou values", begIdx, len(ichimoku.Values), len(ichimoku.SenkouA), len(ichimoku.Chikou))
+	}
+	for j := range ichimoku.SenkouA {
+		i := begIdx + j // Bar where the span is plotted
+		computed := i - 26
+		tenkan, kijun := midpoint(high, low, computed, 9), midpoint(high, low, computed, 26)
+		if ichimoku.SenkouA[j] != (tenkan+kijun)/2 || ichimoku.SenkouB[j] != midpoint(high, low, computed, 52) {
+			t.Fatalf("ichimoku spans at bar %d", i)
+		}
+		if i >= n {
+			continue
+		}
+		if ichimoku.Values[j] != midpoint(high, low, i, 9) || ichimoku.Kijun[j] != midpoint(high, low, i, 26) {
+			t.Fatalf("ichimoku lines at bar %d", i)
+		}
+		if j < len(ichimoku.Chikou) && ichimoku.Chikou[j] != close[i+26] {
+			t.Fatalf("chikou at bar %d", i)
+		}
+	}
+}
+
+// Keltner channels from the EMA and ATR, or another MA type
+func TestKELTNER(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	_, high, low, close, _ := utils.GetOHLCVSlices(data)
+	for _, maType := range []utils.MAType{utils.EMA, utils.SMA, utils.KAMA} {
+		keltner, err := indicators.KELTNER(high, low, close, 20, 10, 2, maType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		middle, _ := indicators.MA(close, 20, maType)
+		atr, _ := indicators.ATR(high, low, close, 10)
+		begIdx := max(middle.BeginIndex, atr.BeginIndex)
+		if keltner.BeginIndex != begIdx || len(keltner.Values) != len(data)-begIdx {
+			t.Errorf("keltner %v from bar %d, want %d", maType, keltner.BeginIndex, begIdx)
+			continue
+		}
+		for j, v := range keltner.Values {
+			i := begIdx + j
+			m, r := middle.Values[i-middle.BeginIndex], atr.Values[i-atr.BeginIndex]
+			if v != m || keltner.UpperBand[j] != m+2*r || keltner.LowerBand[j] != m-2*r {
+				t.Errorf("keltner %v at bar %d", maType, i)
+				break
+			}
+		}
+	}
+}
+
+func TestDONCHIAN(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	_, high, low, _, _ := utils.GetOHLCVSlices(data)
+	donchian, err := indicators.DONCHIAN(high, low, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if donchian.BeginIndex != 19 || len(donchian.Values) != len(data)-19 {
+		t.Fatalf("donchian from bar %d", donchian.BeginIndex)
+	}
+	for j, v := range donchian.Values {
+		i := 19 + j
+		highest, lowest := high[i], low[i]
+		for k := i - 19; k < i; k++ {
+			highest, lowest = max(highest, high[k]), min(lowest, low[k])
+		}
+		if v != midpoint(high, low, i, 20) || donchian.UpperBand[j] != highest || donchian.LowerBand[j] != lowest {
+			t.Fatalf("donchian at bar %d", i)
+		}
+	}
+}
+
+// SuperTrend follows its bands and stays on the right side of the close
+func TestSUPERTREND(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	_, high, low, close, _ := utils.GetOHLCVSlices(data)
+	st, err := indicators.SUPERTREND(high, low, close, 10, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	atr, _ := indicators.ATR(high, low, close, 10)
+	if st.BeginIndex != atr.BeginIndex || len(st.Values) != len(atr.Values) {
+		t.Fatalf("supertrend from bar %d, ATR from %d", st.BeginIndex, atr.BeginIndex)
+	}
+	flips := 0
+	for j, v := range st.Values {
+		i := st.BeginIndex + j
+		median := (high[i] + low[i]) / 2
+		up := st.Direction[j] > 0
+		switch {
+		case up && (v != st.LowerBand[j] || close[i] < v), !up && (v != st.UpperBand[j] || close[i] > v):
+			t.Fatalf("supertrend %v at bar %d with close %v", v, i, close[i])
+		case st.UpperBand[j] > median+3*atr.Values[j] || st.LowerBand[j] < median-3*atr.Values[j]:
+			t.Fatalf("supertrend bands at bar %d are wider than the basic bands", i)
+		}
+		if j > 0 && st.Direction[j] != st.Direction[j-1] {
+			flips++
+		}
+	}
+	if flips == 0 {
+		t.Errorf("supertrend never changed direction in %d bars", len(data))
+	}
+}
+
+func TestOverlayErrors(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	_, high, low, close, _ := utils.GetOHLCVSlices(data)
+
+	// A multiplier that would invert or blow up the bands is rejected
+	for _, m := range []float64{0, -2, math.NaN(), math.Inf(1)} {
+		if _, err := indicators.KELTNER(high, low, close, 20, 10, m); !errors.Is(err, utils.ErrInvalidParameter) {
+			t.Errorf("keltner with a multiplier of %v gave %v", m, err)
+		}
+		if _, err := indicators.SUPERTREND(high, low, close, 10, m); !errors.Is(err, utils.ErrInvalidParameter) {
+			t.Errorf("supertrend with a multiplier of %v gave %v", m, err)
+		}
+	}
+	if _, err := indicators.DONCHIAN(high, low[1:], 20); !errors.Is(err, utils.ErrMismatchedInputLengths) {
+		t.Errorf("donchian with a short low gave %v", err)
+	}
+	if _, err := indicators.DONCHIAN(nil, nil, 20); !errors.Is(err, utils.ErrEmptyInputData) {
+		t.Errorf("donchian without bars gave %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package indicators
+
+import (
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// AVGPRICE calculates the Average Price: (open + high + low + close) / 4
+func AVGPRICE(inOpen, inHigh, inLow, inClose []float64) (*utils.Result, error) {
+	if err := checkPrice(inHigh, inLow, inClose); err != nil {
+		return nil, err
+	}
+	if len(inOpen) != len(inHigh) {
+		return nil, utils.ErrMismatchedInputLengths
+	}
+
+	out := make([]float64, len(inHigh))
+	for i := range out {
+		out[i] = (inHigh[i] + inLow[i] + inClose[i] + inOpen[i]) / 4
+	}
+	return utils.NewResult(0, out), nil
+}
+
+// MEDPRICE calculates the Median Price: (high + low) / 2
+func MEDPRICE(inHigh, inLow []float64) (*utils.Result, error) {
+	if err := checkHighLow(inHigh, inLow); err != nil {
+		return nil, err
+	}
+
+	out := make([]float64, len(inHigh))
+	for i := range out {
+		out[i] = (inHigh[i] + inLow[i]) / 2.0
+	}
+	return utils.NewResult(0, out), nil
+}
+
+// TYPPRICE calculates the Typical Price: (high + low + close) / 3
+func TYPPRICE(inHigh, inLow, inClose []float64) (*utils.Result, error) {
+	if err := checkPrice(inHigh, inLow, inClose); err != nil {
+		return nil, err
+	}
+
+	out := make([]float64, len(inHigh))
+	for i := range out {
+		out[i] = (inHigh[i] + inLow[i] + inClose[i]) / 3.0
+	}
+	return utils.NewResult(0, out), nil
+}
+
+// WCLPRICE calculates the Weighted Close Price: (high + low + 2 * close) / 4
+func WCLPRICE(inHigh, inLow, inClose []float64) (*utils.Result, error) {
+	if err := checkPrice(inHigh, inLow, inClose); err != nil {
+		return nil, err
+	}
+
+	out := make([]float64, len(inHigh))
+	for i := range out {
+		out[i] = (inHigh[i] + inLow[i] + (inClose[i] * 2.0)) / 4.0
+	}
+	return utils.NewResult(0, out), nil
+}
@@ -0,0 +1,423 @@
+package indicators_test
+
+// The cases of ta_regtest, the regression tests of the C library in
+// src/tools/ta_regtest, run on its reference bars: every function ported to
+// Go gives the values expected from the C build, within the 0.01 of its
+// checks, and runs on its profiling bars.
+//
+// The cases come from test_ma.c, test_rsi.c, test_macd.c, test_po.c,
+// test_stoch.c, test_adx.c, test_per_hlc.c, test_per_hlcv.c, test_per_ohlc.c,
+// test_mom.c, test_stddev.c, test_trange.c, test_bbands.c, test_1in_1out.c
+// and test_1in_2out.c. These suites are skipped as none of their functions
+// has a Go port:
+//
+//   - test_avgdev.c: AVGDEV
+//   - test_candlestick.c: the CDL* candlestick patterns
+//   - test_imi.c: IMI
+//   - test_minmax.c: MIN, MAX, MINMAX and their index variants
+//   - test_per_ema.c: TRIX
+//   - test_per_hl.c: AROON, AROONOSC, BETA and CORREL
+//   - test_sar.c: SAR and SAREXT
+//
+// Within the ported suites, the cases of SIN, CMO, MACDFIX, MOM, ROCP, ROCR,
+// ROCR100, ADXR, PLUS_DM, MINUS_DM, ULTOSC, NATR, ACCBANDS, ADOSC and BOP
+// are skipped for the same reason, as are the cases with an unstable period
+// or the Metastock compatibility, which the Go port does not have.
+// test_abstract.c, test_internals.c and test_util.c check the C interfaces
+// and are not ported.
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/indicators"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testdata"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// taTolerance is the absolute difference accepted by ta_regtest
+const taTolerance = 0.01
+
+// taInputs are the price series a function is called on
+type taInputs struct {
+	open, high, low, close, volume []float64
+}
+
+func newTAInputs(data []utils.OHLCV) taInputs {
+	var in taInputs
+	in.open, in.high, in.low, in.close, in.volume = utils.GetOHLCVSlices(data)
+	return in
+}
+
+// bars returns the inputs of the bars from start to end, excluded
+func (in taInputs) bars(start, end int) taInputs {
+	return taInputs{in.open[start:end], in.high[start:end], in.low[start:end], in.close[start:end], in.volume[start:end]}
+}
+
+// taFunction calls a function with the parameters of a case and returns the
+// index of its first value and its outputs in the order of the C library
+type taFunction func(in taInputs, p []float64) (int, [][]float64, error)
+
+// outputs adapts a single output function to taFunction
+func outputs(r *utils.Result, err error) (int, [][]float64, error) {
+	if err != nil {
+		return 0, nil, err
+	}
+	return r.BeginIndex, [][]float64{r.Values}, nil
+}
+
+// taFunctions are the functions of ta_regtest with a Go port, by TA-Lib name
+var taFunctions = map[string]taFunction{
+	"MA": func(in taInputs, p []float64) (int, [][]float64, error) {
+		r, err := indicators.MA(in.close, int(p[0]), utils.MAType(p[1]))
+		if err != nil {
+			return 0, nil, err
+		}
+		return outputs(&r.Result, nil)
+	},
+	"MAMA": func(in taInputs, p []float64) (int, [][]float64, error) {
+		r, err := indicators.MAMA(in.close, p[0], p[1])
+		if err != nil {
+			return 0, nil, err
+		}
+		return r.BeginIndex, [][]float64{r.Values, r.FAMA}, nil
+	},
+	"RSI": func(in taInputs, p []float64) (int, [][]float64, error) {
+		return outputs(indicators.RSI(in.close, int(p[0])))
+	},
+	"MACD": func(in taInputs, p []float64) (int, [][]float64, error) {
+		r, err := indicators.MACD(in.close, int(p[0]), int(p[1]), int(p[2]))
+		if err != nil {
+			return 0, nil, err
+		}
+		return r.BeginIndex, [][]float64{r.Values, r.MACDSignal, r.MACDHist}, nil
+	},
+	"MACDEXT": func(in taInputs, p []float64) (int, [][]float64, error) {
+		r, err := indicators.MACDEXT(in.close, int(p[0]), utils.MAType(p[1]), int(p[2]), utils.MAType(p[3]), int(p[4]), utils.MAType(p[5]))
+		if err != nil {
+			return 0, nil, err
+		}
+		return r.BeginIndex, [][]float64{r.Values, r.MACDSignal, r.MACDHist}, nil
+	},
+	"APO": func(in taInputs, p []float64) (int, [][]float64, error) {
+		return outputs(indicators.APO(in.close, int(p[0]), int(p[1]), utils.MAType(p[2])))
+	},
+	"PPO": func(in taInputs, p []float64) (int, [][]float64, error) {
+		return outputs(indicators.PPO(in.close, int(p[0]), int(p[1]), utils.MAType(p[2])))
+	},
+	"STOCH": func(in taInputs, p []float64) (int, [][]float64, error) {
+		r, err := indicators.STOCH(in.high, in.low, in.close, int(p[0]), int(p[1]), int(p[3]), utils.MAType(p[2]), utils.MAType(p[4]))
+		if err != nil {
+			return 0, nil, err
+		}
+		return r.BeginIndex, [][]float64{r.SlowK, r.SlowD}, nil
+	},
+	"STOCHRSI": func(in taInputs, p []float64) (int, [][]float64, error) {
+		fastK, fastD, err := indicators.STOCHRSI(in.close, int(p[0]), int(p[1]), int(p[2]), utils.MAType(p[3]))
+		if err != nil {
+			return 0, nil, err
+		}
+		return fastK.BeginIndex, [][]float64{fastK.Values, fastD.Values}, nil
+	},
+	"PLUS_DI": func(in taInputs, p []float64) (int, [][]float64, error) {
+		return outputs(indicators.PLUS_DI(in.high, in.low, in.close, int(p[0])))
+	},
+	"MINUS_DI": func(in taInputs, p []float64) (int, [][]float64, error) {
+		return outputs(indicators.MINUS_DI(in.high, in.low, in.close, int(p[0])))
+	},
+	"DX": func(in taInputs, p []float64) (int, [][]float64, error) {
+		return outputs(indicators.DX(in.high, in.low, in.close, int(p[0])))
+	},
+	"ADX": func(in taInputs, p []float64) (int, [][]float64, error) {
+		r, err := indicators.ADX(in.high, in.low, in.close, int(p[0]))
+		if err != nil {
+			return 0, nil, err
+		}
+		return outputs(&r.Result, nil)
+	},
+	"CCI": func(in taInputs, p []float64) (int, [][]float64, error) {
+		return outputs(indicators.CCI(in.high, in.low, in.close, int(p[0])))
+	},
+	"WILLR": func(in taInputs, p []float64) (int, [][]float64, error) {
+		return outputs(indicators.WILLR(in.high, in.low, in.close, int(p[0])))
+	},
+	"AD": func(in taInputs, p []float64) (int, [][]float64, error) {
+		return outputs(indicators.AD(in.high, in.low, in.close, in.volume))
+	},
+	"MFI": func(in taInputs, p []float64) (int, [][]float64, error) {
+		return outputs(indicators.MFI(in.high, in.low, in.close, in.volume, int(p[0])))
+	},
+	"AVGPRICE": func(in taInputs, p []float64) (int, [][]float64, error) {
+		return outputs(indicators.AVGPRICE(in.open, in.high, in.low, in.close))
+	},
+	"ROC": func(in taInputs, p []float64) (int, [][]float64, error) {
+		return outputs(indicators.ROC(in.close, int(p[0])))
+	},
+	"STDDEV": func(in taInputs, p []float64) (int, [][]float64, error) {
+		return outputs(indicators.STDDEV(in.close, int(p[0]), p[1]))
+	},
+	"TRANGE": func(in taInputs, p []float64) (int, [][]float64, error) {
+		return outputs(indicators.TRANGE(in.high, in.low, in.close))
+	},
+	"ATR": func(in taInputs, p []float64) (int, [][]float64, error) {
+		return outputs(indicators.ATR(in.high, in.low, in.close, int(p[0])))
+	},
+	"BBANDS": func(in taInputs, p []float64) (int, [][]float64, error) {
+		r, err := indicators.BBANDS(in.close, int(p[0]), p[1], p[2], utils.MAType(p[3]))
+		if err != nil {
+			return 0, nil, err
+		}
+		return r.BeginIndex, [][]float64{r.UpperBand, r.Values, r.LowerBand}, nil
+	},
+	"HT_DCPERIOD": func(in taInputs, p []float64) (int, [][]float64, error) {
+		return outputs(indicators.HT_DCPERIOD(in.close))
+	},
+	"HT_DCPHASE": func(in taInputs, p []float64) (int, [][]float64, error) {
+		return outputs(indicators.HT_DCPHASE(in.close))
+	},
+	"HT_TRENDLINE": func(in taInputs, p []float64) (int, [][]float64, error) {
+		return outputs(indicators.HT_TRENDLINE(in.close))
+	},
+	"HT_TRENDMODE": func(in taInputs, p []float64) (int, [][]float64, error) {
+		return outputs(indicators.HT_TRENDMODE(in.close))
+	},
+	"HT_PHASOR": func(in taInputs, p []float64) (int, [][]float64, error) {
+		r, err := indicators.HT_PHASOR(in.close)
+		if err != nil {
+			return 0, nil, err
+		}
+		return r.BeginIndex, [][]float64{r.Values, r.Quadrature}, nil
+	},
+	"HT_SINE": func(in taInputs, p []float64) (int, [][]float64, error) {
+		r, err := indicators.HT_SINE(in.close)
+		if err != nil {
+			return 0, nil, err
+		}
+		return r.BeginIndex, [][]float64{r.Values, r.LeadSine}, nil
+	},
+}
+
+// taExpected is a value expected from an output of a case, index being
+// relative to the first value
+type taExpected struct {
+	output, index int
+	value         float64
+}
+
+// taCase is a call of ta_regtest on the bars from start to end, with the
+// return code, the first index and the number of values of the C library.
+// Functions take the closes as real input unless input names another price.
+type taCase struct {
+	source, function, params string
+	input                    string
+	start, end               int
+	retCode                  string
+	begin, count             int
+	expected                 []taExpected
+}
+
+func (c *taCase) String() string {
+	return fmt.Sprintf("%s %s(%s) on bars %d to %d", c.source, c.function, c.params, c.start, c.end)
+}
+
+// taParams parses the parameters of a case, moving average types by name
+func taParams(params string) ([]float64, error) {
+	var p []float64
+	for _, field := range strings.Fields(params) {
+		v, err := strconv.ParseFloat(field, 64)
+		for maType := utils.SMA; maType <= utils.MCGINLEY; maType++ {
+			if field == maType.String() {
+				v, err = float64(maType), nil
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		p = append(p, v)
+	}
+	return p, nil
+}
+
+// loadTACases reads ta_regtest_cases.csv, one line per expected value, into
+// the calls of ta_regtest
+func loadTACases(t *testing.T) []*taCase {
+	t.Helper()
+	file, err := os.Open(testdata.Path("ta_regtest_cases.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cases []*taCase
+	for line, record := range records[1:] {
+		ints := make([]int, 0, 6)
+		for _, field := range []string{record[4], record[5], record[7], record[8], record[9], record[10]} {
+			v, err := strconv.Atoi(field)
+			if err != nil && field != "" {
+				t.Fatalf("ta_regtest_cases.csv line %d: %v", line+2, err)
+			}
+			ints = append(ints, v)
+		}
+		c := &taCase{source: record[0], function: record[1], params: record[2], input: record[3], start: ints[0], end: ints[1], retCode: record[6], begin: ints[2], count: ints[3]}
+		if n := len(cases); n == 0 || cases[n-1].String() != c.String() || cases[n-1].retCode != c.retCode || cases[n-1].begin != c.begin || cases[n-1].count != c.count {
+			cases = append(cases, c)
+		}
+		if record[11] != "" {
+			value, err := strconv.ParseFloat(record[11], 64)
+			if err != nil {
+				t.Fatalf("ta_regtest_cases.csv line %d: %v", line+2, err)
+			}
+			last := cases[len(cases)-1]
+			last.expected = append(last.expected, taExpected{output: ints[4], index: ints[5], value: value})
+		}
+	}
+	return cases
+}
+
+// realInput replaces the closes by the input price of a case, the median
+// price for MAMA as in the book of Ehlers
+func (c *taCase) realInput(in taInputs) (taInputs, error) {
+	switch c.input {
+	case "":
+		return in, nil
+	case "MEDPRICE":
+		median, err := indicators.MEDPRICE(in.high, in.low)
+		if err != nil {
+			return in, err
+		}
+		in.close = median.Values
+		return in, nil
+	}
+	return in, fmt.Errorf("unknown input %s", c.input)
+}
+
+// run calls the function of a case like the C library does for bars from
+// start to end: from lookback bars before start, or the first bar
+func (c *taCase) run(t *testing.T, in taInputs) {
+	function, ok := taFunctions[c.function]
+	if !ok {
+		t.Fatalf("no function %s", c.function)
+	}
+	p, err := taParams(c.params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in, err = c.realInput(in); err != nil {
+		t.Fatal(err)
+	}
+	lookback, _, err := function(in, p)
+	if c.retCode == "TA_BAD_PARAM" {
+		if !errors.Is(err, utils.ErrInvalidParameter) {
+			t.Errorf("expected an invalid parameter, got %v", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := max(c.start-lookback, 0)
+	begin, out, err := function(in.bars(from, c.end+1), p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out[0]) != c.count {
+		t.Fatalf("%d values, expected %d", len(out[0]), c.count)
+	}
+	if c.count == 0 {
+		return
+	}
+	if begin+from != c.begin {
+		t.Errorf("first value at %d, expected %d", begin+from, c.begin)
+	}
+	for _, e := range c.expected {
+		if got := out[e.output][e.index]; !(math.Abs(got-e.value) < taTolerance) {
+			t.Errorf("output %d value %d is %v, expected %v", e.output, e.index, got, e.value)
+		}
+	}
+}
+
+func TestTARegtest(t *testing.T) {
+	data, err := testdata.GetReferenceOHLCV()
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := newTAInputs(data)
+	for _, c := range loadTACases(t) {
+		t.Run(c.String(), func(t *testing.T) {
+			c.run(t, in)
+		})
+	}
+}
+
+// TestTAProfile calls every function with the parameters of its cases on
+// the profiling bars, with the closes as volumes like ta_regtest: the values
+// must start after the same lookback and be finite
+func TestTAProfile(t *testing.T) {
+	reference, err := testdata.GetReferenceOHLCV()
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile, err := testdata.GetProfileOHLCV()
+	if err != nil {
+		t.Fatal(err)
+	}
+	profileIn := newTAInputs(profile)
+	profileIn.volume = profileIn.close
+	referenceIn := newTAInputs(reference)
+
+	done := map[string]bool{}
+	for _, c := range loadTACases(t) {
+		call := c.function + "(" + c.params + ")"
+		if c.retCode != "TA_SUCCESS" || done[call] {
+			continue
+		}
+		done[call] = true
+		t.Run(call, func(t *testing.T) {
+			p, err := taParams(c.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ref, err := c.realInput(referenceIn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			in, err := c.realInput(profileIn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lookback, _, err := taFunctions[c.function](ref, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			begin, out, err := taFunctions[c.function](in, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if begin != lookback {
+				t.Errorf("first value at %d, expected %d", begin, lookback)
+			}
+			for o, values := range out {
+				if len(values) != len(profile)-begin {
+					t.Errorf("output %d has %d values from %d", o, len(values), begin)
+				}
+				for i, v := range values {
+					if math.IsNaN(v) || math.IsInf(v, 0) {
+						t.Errorf("output %d value %d is %v", o, i, v)
+						break
+					}
+				}
+			}
+		})
+	}
+}
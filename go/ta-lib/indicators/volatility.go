@@ -0,0 +1,221 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// TRANGELookback returns the number of input bars consumed before the first TRANGE value
+func TRANGELookback() int {
+	return 1
+}
+
+// TRANGE calculates the True Range
+func TRANGE(inHigh, inLow, inClose []float64) (*utils.Result, error) {
+	if err := checkPrice(inHigh, inLow, inClose); err != nil {
+		return nil, err
+	}
+	begIdx, out := trange(0, len(inHigh)-1, inHigh, inLow, inClose)
+	return utils.NewResult(begIdx, out), nil
+}
+
+// trueRange returns the greatest of the bar range and the distances to the previous close
+func trueRange(high, low, prevClose float64) float64 {
+	greatest := high - low
+	if val2 := math.Abs(prevClose - high); val2 > greatest {
+		greatest = val2
+	}
+	if val3 := math.Abs(prevClose - low); val3 > greatest {
+		greatest = val3
+	}
+	return greatest
+}
+
+func trange(startIdx, endIdx int, inHigh, inLow, inClose []float64) (int, []float64) {
+	if startIdx < 1 {
+		startIdx = 1
+	}
+	if startIdx > endIdx {
+		return 0, nil
+	}
+
+	out := make([]float64, 0, endIdx-startIdx+1)
+	for today := startIdx; today <= endIdx; today++ {
+		out = append(out, trueRange(inHigh[today], inLow[today], inClose[today-1]))
+	}
+	return startIdx, out
+}
+
+// ATRLookback returns the number of input bars consumed before the first ATR value
+func ATRLookback(optInTimePeriod int) int {
+	return optInTimePeriod
+}
+
+// ATR calculates the Average True Range
+func ATR(inHigh, inLow, inClose []float64, optInTimePeriod int) (*utils.Result, error) {
+	if err := checkPrice(inHigh, inLow, inClose); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 1); err != nil {
+		return nil, err
+	}
+	begIdx, out := atr(0, len(inHigh)-1, inHigh, inLow, inClose, optInTimePeriod)
+	return utils.NewResult(begIdx, out), nil
+}
+
+func atr(startIdx, endIdx int, inHigh, inLow, inClose []float64, optInTimePeriod int) (int, []float64) {
+	lookbackTotal := ATRLookback(optInTimePeriod)
+	if startIdx < lookbackTotal {
+		startIdx = lookbackTotal
+	}
+	if startIdx > endIdx {
+		return 0, nil
+	}
+	if optInTimePeriod <= 1 {
+		return trange(startIdx, endIdx, inHigh, inLow, inClose)
+	}
+
+	// The first ATR is the simple average of the true ranges,
+	// then Wilder's smoothing is applied
+	_, tempBuffer := trange(startIdx-lookbackTotal+1, endIdx, inHigh, inLow, inClose)
+	_, prevATRTemp := sma(optInTimePeriod-1, optInTimePeriod-1, tempBuffer, optInTimePeriod)
+	prevATR := prevATRTemp[0]
+	today := optInTimePeriod
+
+	out := make([]float64, 1, endIdx-startIdx+1)
+	out[0] = prevATR
+	for nbATR := endIdx - startIdx; nbATR > 0; nbATR-- {
+		prevATR *= float64(optInTimePeriod - 1)
+		prevATR += tempBuffer[today]
+		today++
+		prevATR /= float64(optInTimePeriod)
+		out = append(out, prevATR)
+	}
+	return startIdx, out
+}
+
+// VARLookback returns the number of input bars consumed before the first VAR value
+func VARLookback(optInTimePeriod int) int {
+	return optInTimePeriod - 1
+}
+
+// VAR calculates the population Variance over a rolling window
+func VAR(inReal []float64, optInTimePeriod int, optInNbDev float64) (*utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 1); err != nil {
+		return nil, err
+	}
+	begIdx, out := variance(0, len(inReal)-1, inReal, optInTimePeriod)
+	return utils.NewResult(begIdx, out), nil
+}
+
+func variance(startIdx, endIdx int, inReal []float64, optInTimePeriod int) (int, []float64) {
+	nbInitialElementNeeded := optInTimePeriod - 1
+	if startIdx < nbInitialElementNeeded {
+		startIdx = nbInitialElementNeeded
+	}
+	if startIdx > endIdx {
+		return 0, nil
+	}
+
+	// Compensated Welford updates instead of the running sums of x and x*x
+	// of the C implementation, which lose the variance of large values
+	var moments utils.RollingMoments
+	trailingIdx := startIdx - nbInitialElementNeeded
+	for i := trailingIdx; i <= startIdx; i++ {
+		moments.Add(inReal[i])
+	}
+
+	out := make([]float64, 1, endIdx-startIdx+1)
+	out[0] = moments.Variance(0)
+	for i := startIdx + 1; i <= endIdx; i++ {
+		moments.Replace(inReal[trailingIdx], inReal[i])
+		trailingIdx++
+		out = append(out, moments.Variance(0))
+	}
+	return startIdx, out
+}
+
+// STDDEVLookback returns the number of input bars consumed before the first STDDEV value
+func STDDEVLookback(optInTimePeriod int) int {
+	return VARLookback(optInTimePeriod)
+}
+
+// STDDEV calculates the population Standard Deviation over a rolling window
+func STDDEV(inReal []float64, optInTimePeriod int, optInNbDev float64) (*utils.Result, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	begIdx, out := stddev(0, len(inReal)-1, inReal, optInTimePeriod, optInNbDev)
+	return utils.NewResult(begIdx, out), nil
+}
+
+func stddev(startIdx, endIdx int, inReal []float64, optInTimePeriod int, optInNbDev float64) (int, []float64) {
+	begIdx, out := variance(startIdx, endIdx, inReal, optInTimePeriod)
+	for i, tempReal := range out {
+		out[i] = math.Sqrt(tempReal) * optInNbDev
+	}
+	return begIdx, out
+}
+
+// BBANDSResult represents the output of Bollinger Bands
+type BBANDSResult struct {
+	utils.Result           // Middle band
+	UpperBand    []float64 // Upper band
+	LowerBand    []float64 // Lower band
+}
+
+// BBANDSLookback returns the number of input bars consumed before the first BBANDS value
+func BBANDSLookback(optInTimePeriod int, optInMAType utils.MAType) int {
+	return MALookback(optInTimePeriod, optInMAType)
+}
+
+// BBANDS calculates Bollinger Bands, the middle band uses an SMA unless an MA type is given
+func BBANDS(inReal []float64, optInTimePeriod int, optInNbDevUp, optInNbDevDn float64, optInMAType ...utils.MAType) (*BBANDSResult, error) {
+	if err := checkReal(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	maType := utils.OptionalMAType(optInMAType, 0)
+	if err := utils.CheckMAType(maType); err != nil {
+		return nil, err
+	}
+
+	begIdx, middle := ma(0, len(inReal)-1, inReal, optInTimePeriod, maType)
+	if len(middle) == 0 {
+		return &BBANDSResult{Result: *utils.NewResult(0, nil), UpperBand: []float64{}, LowerBand: []float64{}}, nil
+	}
+
+	// The deviation is around the mean of the window, which is the SMA middle band
+	_, dev := stddev(begIdx, len(inReal)-1, inReal, optInTimePeriod, 1.0)
+
+	// A middle band starting before a full deviation window (MAMA) is trimmed
+	if trim := len(middle) - len(dev); trim > 0 {
+		middle = middle[trim:]
+		begIdx += trim
+	}
+	if len(middle) == 0 {
+		return &BBANDSResult{Result: *utils.NewResult(0, nil), UpperBand: []float64{}, LowerBand: []float64{}}, nil
+	}
+
+	upper := make([]float64, len(middle))
+	lower := make([]float64, len(middle))
+	for i := range middle {
+		upper[i] = middle[i] + dev[i]*optInNbDevUp
+		lower[i] = middle[i] - dev[i]*optInNbDevDn
+	}
+
+	return &BBANDSResult{
+		Result:    *utils.NewResult(begIdx, middle),
+		UpperBand: upper,
+		LowerBand: lower,
+	}, nil
+}
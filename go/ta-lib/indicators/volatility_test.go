@@ -0,0 +1,51 @@
+package indicators_test
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/indicators"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testdata"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// bigVariance returns the population variance of x computed with 256 bit floats
+func bigVariance(x []float64) float64 {
+	newFloat := func() *big.Float { return new(big.Float).SetPrec(256) }
+	n := newFloat().SetInt64(int64(len(x)))
+	mean := newFloat()
+	for _, v := range x {
+		mean.Add(mean, newFloat().SetFloat64(v))
+	}
+	mean.Quo(mean, n)
+	m2 := newFloat()
+	for _, v := range x {
+		d := newFloat().Sub(newFloat().SetFloat64(v), mean)
+		m2.Add(m2, newFloat().Mul(d, d))
+	}
+	variance, _ := m2.Quo(m2, n).Float64()
+	return variance
+}
+
+// VAR keeps its precision on large values with a tiny spread over long series
+func TestVARPrecision(t *testing.T) {
+	close := utils.GetFieldSlice(testdata.TightOHLCV(100000), utils.FieldClose)
+	for _, period := range []int{20, 500} {
+		name := fmt.Sprintf("VAR(%d)", period)
+		got, err := indicators.VAR(close, period, 1)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for i := period - 1; i < len(close); i++ {
+			if i%997 != 0 && i != len(close)-1 {
+				continue
+			}
+			want := bigVariance(close[i-period+1 : i+1])
+			if v := got.Values[i-got.BeginIndex]; math.Abs(v-want) > 1e-7*want {
+				t.Fatalf("%s of 70,000 at bar %d is %v, want %v", name, i, v, want)
+			}
+		}
+	}
+}
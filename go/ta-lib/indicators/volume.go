@@ -0,0 +1,139 @@
+package indicators
+
+import (
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// OBVLookback returns the number of input bars consumed before the first OBV value
+func OBVLookback() int {
+	return 0
+}
+
+// OBV calculates On Balance Volume
+func OBV(inReal, inVolume []float64) (*utils.Result, error) {
+	if err := checkVolume(inReal, inVolume); err != nil {
+		return nil, err
+	}
+
+	out := make([]float64, len(inReal))
+	prevOBV := inVolume[0]
+	prevReal := inReal[0]
+	for i, tempReal := range inReal {
+		if tempReal > prevReal {
+			prevOBV += inVolume[i]
+		} else if tempReal < prevReal {
+			prevOBV -= inVolume[i]
+		}
+		out[i] = prevOBV
+		prevReal = tempReal
+	}
+	return utils.NewResult(0, out), nil
+}
+
+// ADLookback returns the number of input bars consumed before the first AD value
+func ADLookback() int {
+	return 0
+}
+
+// AD calculates the Chaikin Accumulation/Distribution Line
+func AD(inHigh, inLow, inClose, inVolume []float64) (*utils.Result, error) {
+	if err := checkPrice(inHigh, inLow, inClose); err != nil {
+		return nil, err
+	}
+	if err := checkVolume(inClose, inVolume); err != nil {
+		return nil, err
+	}
+
+	out := make([]float64, len(inHigh))
+	ad := 0.0
+	for i := range inHigh {
+		high, low, close := inHigh[i], inLow[i], inClose[i]
+		if tmp := high - low; tmp > 0.0 {
+			ad += (((close - low) - (high - close)) / tmp) * inVolume[i]
+		}
+		out[i] = ad
+	}
+	return utils.NewResult(0, out), nil
+}
+
+// MFILookback returns the number of input bars consumed before the first MFI value
+func MFILookback(optInTimePeriod int) int {
+	return optInTimePeriod
+}
+
+// MFI calculates the Money Flow Index
+func MFI(inHigh, inLow, inClose, inVolume []float64, optInTimePeriod int) (*utils.Result, error) {
+	if err := checkPrice(inHigh, inLow, inClose); err != nil {
+		return nil, err
+	}
+	if err := checkVolume(inClose, inVolume); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+
+	startIdx := MFILookback(optInTimePeriod)
+	endIdx := len(inHigh) - 1
+	if startIdx > endIdx {
+		return utils.NewResult(0, nil), nil
+	}
+
+	// Circular buffer of the positive and negative money flows in the window
+	type moneyFlow struct{ positive, negative float64 }
+	mflow := make([]moneyFlow, optInTimePeriod)
+	mflowIdx := 0
+	posSumMF, negSumMF := 0.0, 0.0
+
+	today := 0
+	prevValue := (inHigh[today] + inLow[today] + inClose[today]) / 3.0
+	today++
+
+	addFlow := func() {
+		tempValue1 := (inHigh[today] + inLow[today] + inClose[today]) / 3.0
+		tempValue2 := tempValue1 - prevValue
+		prevValue = tempValue1
+		tempValue1 *= inVolume[today]
+		today++
+		if tempValue2 < 0 {
+			mflow[mflowIdx] = moneyFlow{negative: tempValue1}
+			negSumMF += tempValue1
+		} else if tempValue2 > 0 {
+			mflow[mflowIdx] = moneyFlow{positive: tempValue1}
+			posSumMF += tempValue1
+		} else {
+			mflow[mflowIdx] = moneyFlow{}
+		}
+	}
+	nextFlow := func() {
+		mflowIdx++
+		if mflowIdx == optInTimePeriod {
+			mflowIdx = 0
+		}
+	}
+
+	for i := optInTimePeriod; i > 0; i-- {
+		addFlow()
+		nextFlow()
+	}
+
+	out := make([]float64, 0, endIdx-startIdx+1)
+	out = append(out, mfiValue(posSumMF, negSumMF))
+	for today <= endIdx {
+		posSumMF -= mflow[mflowIdx].positive
+		negSumMF -= mflow[mflowIdx].negative
+		addFlow()
+		out = append(out, mfiValue(posSumMF, negSumMF))
+		nextFlow()
+	}
+	return utils.NewResult(startIdx, out), nil
+}
+
+// mfiValue converts positive and negative money flow sums into an MFI value
+func mfiValue(posSumMF, negSumMF float64) float64 {
+	tempValue1 := posSumMF + negSumMF
+	if tempValue1 < 1.0 {
+		return 0.0
+	}
+	return 100.0 * (posSumMF / tempValue1)
+}
@@ -0,0 +1,105 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// VWAPResult represents the output of VWAP
+type VWAPResult struct {
+	utils.Result           // Volume-weighted average of the typical price
+	UpperBand    []float64 // VWAP plus NbDev standard deviations
+	LowerBand    []float64 // VWAP minus NbDev standard deviations
+	StdDev       []float64 // Volume-weighted standard deviation of the typical price
+}
+
+// vwapSums accumulates the volume-weighted moments of the typical price
+type vwapSums struct {
+	moments utils.WeightedMoments
+}
+
+func (s *vwapSums) add(high, low, close, volume float64) float64 {
+	price := (high + low + close) / 3.0
+	s.moments.Add(price, volume)
+	return price
+}
+
+// value returns the VWAP and the deviation, the typical price until some volume traded
+func (s *vwapSums) value(price float64) (float64, float64) {
+	if utils.TAIsZeroOrNeg(s.moments.Weight.Total()) {
+		return price, 0
+	}
+	variance := s.moments.Variance()
+	if utils.TAIsZeroOrNeg(variance) {
+		return s.moments.Mean(), 0
+	}
+	return s.moments.Mean(), math.Sqrt(variance)
+}
+
+// SessionVWAP calculates the VWAP of the typical price, (high + low +
+// close) / 3 as in TYPPRICE, restarting on the first bar of every calendar
+// day in loc, or in UTC when loc is nil. The bands are optInNbDev
+// volume-weighted standard deviations away.
+func SessionVWAP(inTime []int64, inHigh, inLow, inClose, inVolume []float64, loc *time.Location, optInNbDev float64) (*VWAPResult, error) {
+	if err := checkVWAP(inTime, inHigh, inLow, inClose, inVolume, optInNbDev); err != nil {
+		return nil, err
+	}
+	return vwap(0, inTime, inHigh, inLow, inClose, inVolume, optInNbDev, func(i int) bool {
+		return i == 0 || utils.Daily.PeriodStart(inTime[i], loc) != utils.Daily.PeriodStart(inTime[i-1], loc)
+	}), nil
+}
+
+// AnchoredVWAP calculates the VWAP of the typical price from the first bar
+// at or after the anchor time, in Unix seconds, without restarting. The
+// values start on that bar.
+func AnchoredVWAP(inTime []int64, inHigh, inLow, inClose, inVolume []float64, anchor int64, optInNbDev float64) (*VWAPResult, error) {
+	if err := checkVWAP(inTime, inHigh, inLow, inClose, inVolume, optInNbDev); err != nil {
+		return nil, err
+	}
+	begIdx := sort.Search(len(inTime), func(i int) bool { return inTime[i] >= anchor })
+	return vwap(begIdx, inTime, inHigh, inLow, inClose, inVolume, optInNbDev, func(i int) bool { return i == begIdx }), nil
+}
+
+func checkVWAP(inTime []int64, inHigh, inLow, inClose, inVolume []float64, optInNbDev float64) error {
+	if math.IsNaN(optInNbDev) || optInNbDev < 0 {
+		return fmt.Errorf("deviations %v: %w", optInNbDev, utils.ErrInvalidParameter)
+	}
+	if err := checkPrice(inHigh, inLow, inClose); err != nil {
+		return err
+	}
+	if err := checkVolume(inClose, inVolume); err != nil {
+		return err
+	}
+	if len(inTime) != len(inClose) {
+		return utils.ErrMismatchedInputLengths
+	}
+	return nil
+}
+
+// vwap accumulates from startIdx, restarting on the bars where reset is true
+func vwap(startIdx int, inTime []int64, inHigh, inLow, inClose, inVolume []float64, optInNbDev float64, reset func(i int) bool) *VWAPResult {
+	if startIdx >= len(inTime) {
+		return &VWAPResult{Result: *utils.NewResult(0, nil), UpperBand: []float64{}, LowerBand: []float64{}, StdDev: []float64{}}
+	}
+	n := len(inTime) - startIdx
+	out := make([]float64, n)
+	upper := make([]float64, n)
+	lower := make([]float64, n)
+	dev := make([]float64, n)
+	var sums vwapSums
+	for j := range out {
+		i := startIdx + j
+		if reset(i) {
+			sums = vwapSums{}
+		}
+		price := sums.add(inHigh[i], inLow[i], inClose[i], inVolume[i])
+		out[j], dev[j] = sums.value(price)
+		upper[j] = out[j] + optInNbDev*dev[j]
+		lower[j] = out[j] - optInNbDev*dev[j]
+	}
+	return &VWAPResult{Result: *utils.NewResult(startIdx, out), UpperBand: upper, LowerBand: lower, StdDev: dev}
+}
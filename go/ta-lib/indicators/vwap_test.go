@@ -0,0 +1,145 @@
+package indicators_test
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/petercool/ta-lib/go/ta-lib/indicators"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testdata"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// hourly turns the sample bars into consecutive hourly bars
+func hourly(data []utils.OHLCV) []utils.OHLCV {
+	out := append([]utils.OHLCV(nil), data...)
+	for i := range out {
+		out[i].Time = data[0].Time + int64(i)*3600
+	}
+	return out
+}
+
+// naiveVWAP sums the typical price from the first bar of the session up to bar i
+func naiveVWAP(data []utils.OHLCV, first, i int) (float64, float64) {
+	var pv, pv2, v float64
+	for k := first; k <= i; k++ {
+		price := (data[k].High + data[k].Low + data[k].Close) / 3.0
+		pv += price * data[k].Volume
+		pv2 += price * price * data[k].Volume
+		v += data[k].Volume
+	}
+	vwap := pv / v
+	return vwap, math.Sqrt(max(pv2/v-vwap*vwap, 0))
+}
+
+// The VWAP restarts on the sessions of the chosen time zone
+func TestSessionVWAP(t *testing.T) {
+	bars := hourly(testutil.MustOHLCV(t))
+	b := utils.BarsFromOHLCV(bars)
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		newYork = time.FixedZone("EST", -5*3600)
+	}
+	for _, loc := range []*time.Location{nil, newYork} {
+		batch, err := indicators.SessionVWAPBars(b, loc, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		first, sessions := 0, 0
+		for i := range bars {
+			if i == 0 || utils.Daily.PeriodStart(b.Time[i], loc) != utils.Daily.PeriodStart(b.Time[i-1], loc) {
+				first = i
+				sessions++
+			}
+			vwap, dev := naiveVWAP(bars, first, i)
+			if math.Abs(batch.Values[i]-vwap) > 1e-9*vwap || math.Abs(batch.StdDev[i]-dev) > 1e-6*vwap {
+				t.Fatalf("session VWAP in %v at bar %d: %v ± %v, want %v ± %v", loc, i, batch.Values[i], batch.StdDev[i], vwap, dev)
+			}
+			if batch.UpperBand[i] != batch.Values[i]+2*batch.StdDev[i] {
+				t.Fatalf("session VWAP in %v at bar %d: upper band %v", loc, i, batch.UpperBand[i])
+			}
+		}
+		if sessions < len(bars)/24 {
+			t.Errorf("%d sessions in %d hourly bars", sessions, len(bars))
+		}
+	}
+}
+
+// An anchored VWAP starts from the middle of a session and never restarts
+func TestAnchoredVWAP(t *testing.T) {
+	bars := hourly(testutil.MustOHLCV(t))
+	b := utils.BarsFromOHLCV(bars)
+	batch, err := indicators.AnchoredVWAPBars(b, b.Time[100]-1800, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batch.BeginIndex != 100 {
+		t.Fatalf("anchored VWAP starts at bar %d", batch.BeginIndex)
+	}
+	if vwap, _ := naiveVWAP(bars, 100, len(bars)-1); math.Abs(batch.Values[len(batch.Values)-1]-vwap) > 1e-9*vwap {
+		t.Errorf("anchored VWAP ends at %v, want %v", batch.Values[len(batch.Values)-1], vwap)
+	}
+
+	// The bands are a positive number of deviations away
+	for _, nbDev := range []float64{-1, math.NaN()} {
+		if _, err := indicators.AnchoredVWAPBars(b, 0, nbDev); !errors.Is(err, utils.ErrInvalidParameter) {
+			t.Errorf("anchored VWAP with %v deviations: %v", nbDev, err)
+		}
+		if _, err := indicators.SessionVWAPBars(b, nil, nbDev); !errors.Is(err, utils.ErrInvalidParameter) {
+			t.Errorf("session VWAP with %v deviations: %v", nbDev, err)
+		}
+	}
+}
+
+// bigWeightedDeviation returns the weighted mean and standard deviation of
+// x, computed with 256 bit floats
+func bigWeightedDeviation(x, w []float64) (mean, deviation float64) {
+	newFloat := func() *big.Float { return new(big.Float).SetPrec(256) }
+	sw, m := newFloat(), newFloat()
+	for i := range x {
+		sw.Add(sw, newFloat().SetFloat64(w[i]))
+		m.Add(m, newFloat().Mul(newFloat().SetFloat64(x[i]), newFloat().SetFloat64(w[i])))
+	}
+	m.Quo(m, sw)
+	m2 := newFloat()
+	for i := range x {
+		d := newFloat().Sub(newFloat().SetFloat64(x[i]), m)
+		m2.Add(m2, newFloat().Mul(newFloat().Mul(d, d), newFloat().SetFloat64(w[i])))
+	}
+	mean, _ = m.Float64()
+	variance, _ := m2.Quo(m2, sw).Float64()
+	return mean, math.Sqrt(variance)
+}
+
+// The VWAP bands of typical prices near 70,000 moving by a cent keep the
+// deviation that the sums of p*v and p*p*v lose
+func TestVWAPPrecision(t *testing.T) {
+	bars := testdata.TightOHLCV(20000)
+	b := utils.BarsFromOHLCV(bars)
+	batch, err := indicators.AnchoredVWAPBars(b, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worstNaive := 0.0
+	for i := 1; i < len(bars); i += 2003 {
+		price := make([]float64, i+1)
+		for k := range price {
+			price[k] = (bars[k].High + bars[k].Low + bars[k].Close) / 3.0
+		}
+		mean, deviation := bigWeightedDeviation(price, b.Volume[:i+1])
+		if math.Abs(batch.Values[i]-mean) > 1e-12*mean || math.Abs(batch.StdDev[i]-deviation) > 1e-7*deviation {
+			t.Fatalf("VWAP of 70,000 at bar %d is %v ± %v, want %v ± %v", i, batch.Values[i], batch.StdDev[i], mean, deviation)
+		}
+		if math.Abs(batch.UpperBand[i]-(mean+2*deviation)) > 1e-12*mean {
+			t.Fatalf("VWAP of 70,000 at bar %d has an upper band %v, want %v", i, batch.UpperBand[i], mean+2*deviation)
+		}
+		_, naive := naiveVWAP(bars, 0, i)
+		worstNaive = max(worstNaive, math.Abs(naive-deviation)/deviation)
+	}
+	if worstNaive < 1e-3 {
+		t.Errorf("running sums keep the VWAP deviation within %v, the series is too easy", worstNaive)
+	}
+}
@@ -0,0 +1,87 @@
+package levels
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/petercool/ta-lib/go/ta-lib/signals"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Fibonacci ratios of the retracement and extension levels
+var (
+	RetracementRatios = []float64{0.236, 0.382, 0.5, 0.618, 0.786}
+	ExtensionRatios   = []float64{1.272, 1.618, 2.0, 2.618}
+)
+
+// FibonacciLevel is a price at a ratio of a move
+type FibonacciLevel struct {
+	Ratio float64
+	Price float64
+}
+
+// FibonacciLevels are the retracements and extensions of a move from one
+// swing to the next
+type FibonacciLevels struct {
+	Start, End   int     // Bars of the swings
+	From, To     float64 // Prices at the swings
+	Retracements []FibonacciLevel
+	Extensions   []FibonacciLevel
+}
+
+// Fibonacci computes the levels of a move from one price to another.
+// Retracements go back from To towards From, at To - ratio * (To - From).
+// Extensions continue the move beyond To, at From + ratio * (To - From).
+func Fibonacci(from, to float64) FibonacciLevels {
+	f := FibonacciLevels{From: from, To: to}
+	move := to - from
+	for _, ratio := range RetracementRatios {
+		f.Retracements = append(f.Retracements, FibonacciLevel{Ratio: ratio, Price: to - ratio*move})
+	}
+	for _, ratio := range ExtensionRatios {
+		f.Extensions = append(f.Extensions, FibonacciLevel{Ratio: ratio, Price: from + ratio*move})
+	}
+	return f
+}
+
+// FibonacciSwings computes the levels of every move between alternating
+// swing highs and lows, the highs on High and the lows on Low. Of several
+// swings of the same side in a row only the most extreme one is kept.
+func FibonacciSwings(data []utils.OHLCV, strength int) ([]FibonacciLevels, error) {
+	if strength < 1 {
+		return nil, fmt.Errorf("swing strength %d: %w", strength, utils.ErrInvalidParameter)
+	}
+	_, high, low, _, _ := utils.GetOHLCVSlices(data)
+	type point struct {
+		signals.Swing
+		high bool
+	}
+	var points []point
+	for _, s := range signals.SwingHighs(utils.NewResult(0, high), strength) {
+		points = append(points, point{s, true})
+	}
+	for _, s := range signals.SwingLows(utils.NewResult(0, low), strength) {
+		points = append(points, point{s, false})
+	}
+	sort.SliceStable(points, func(a, b int) bool { return points[a].Index < points[b].Index })
+
+	var turns []point
+	for _, p := range points {
+		n := len(turns)
+		if n == 0 || turns[n-1].high != p.high {
+			turns = append(turns, p)
+			continue
+		}
+		if last := turns[n-1]; p.high && p.Value > last.Value || !p.high && p.Value < last.Value {
+			turns[n-1] = p
+		}
+	}
+
+	var out []FibonacciLevels
+	for k := 1; k < len(turns); k++ {
+		f := Fibonacci(turns[k-1].Value, turns[k].Value)
+		f.Start, f.End = turns[k-1].Index, turns[k].Index
+		out = append(out, f)
+	}
+	return out, nil
+}
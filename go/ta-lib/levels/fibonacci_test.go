@@ -0,0 +1,36 @@
+package levels_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/levels"
+)
+
+func TestFibonacciSwings(t *testing.T) {
+	legs, err := levels.FibonacciSwings(zigzag(4), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Tops at bars 5, 15, 25 and 35, bottoms at 10, 20 and 30 are confirmed
+	if len(legs) != 6 {
+		t.Fatalf("%d legs on the zigzag, want 6", len(legs))
+	}
+	for k, f := range legs {
+		up := k%2 == 1
+		from, to := 110.2, 99.8
+		if up {
+			from, to = to, from
+		}
+		if f.Start != 5+5*k || f.End != 10+5*k || f.From != from || f.To != to {
+			t.Errorf("leg %d: %+v", k, f)
+			continue
+		}
+		if half := f.Retracements[2]; half.Ratio != 0.5 || math.Abs(half.Price-105) > 1e-9 {
+			t.Errorf("leg %d: half retracement %+v", k, half)
+		}
+		if ext := f.Extensions[1].Price; up != (ext > to) {
+			t.Errorf("leg %d: 1.618 extension at %v", k, ext)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package levels
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// PivotMethod selects the pivot point formulas
+type PivotMethod int
+
+const (
+	Classic         PivotMethod = iota // Floor pivots, three levels each side
+	Woodie                             // Weights the open of the current period, four levels
+	Camarilla                          // Levels around the prior close, four levels
+	DeMark                             // Depends on the prior close against its open, one level
+	FibonacciPivots                    // Fibonacci ratios of the prior range, three levels
+)
+
+// String returns the name of the pivot method
+func (m PivotMethod) String() string {
+	switch m {
+	case Classic:
+		return "classic"
+	case Woodie:
+		return "woodie"
+	case Camarilla:
+		return "camarilla"
+	case DeMark:
+		return "demark"
+	case FibonacciPivots:
+		return "fibonacci"
+	}
+	return fmt.Sprintf("PivotMethod(%d)", int(m))
+}
+
+// PivotLevels are the levels of one period
+type PivotLevels struct {
+	Time       int64     // Start of the period the levels apply to
+	Pivot      float64   // Central pivot
+	Resistance []float64 // R1, R2, ... going up
+	Support    []float64 // S1, S2, ... going down
+}
+
+// PivotPoints computes the levels of a period from the bar of the prior
+// period. Woodie pivots also use the open of the current period, which the
+// other methods ignore.
+func PivotPoints(prior utils.OHLCV, open float64, method PivotMethod) (PivotLevels, error) {
+	h, l, c := prior.High, prior.Low, prior.Close
+	r := h - l
+	var p PivotLevels
+	switch method {
+	case Classic:
+		p.Pivot = (h + l + c) / 3
+		p.Resistance = []float64{2*p.Pivot - l, p.Pivot + r, h + 2*(p.Pivot-l)}
+		p.Support = []float64{2*p.Pivot - h, p.Pivot - r, l - 2*(h-p.Pivot)}
+	case Woodie:
+		p.Pivot = (h + l + 2*open) / 4
+		r3, s3 := h+2*(p.Pivot-l), l-2*(h-p.Pivot)
+		p.Resistance = []float64{2*p.Pivot - l, p.Pivot + r, r3, r3 + r}
+		p.Support = []float64{2*p.Pivot - h, p.Pivot - r, s3, s3 - r}
+	case Camarilla:
+		p.Pivot = (h + l + c) / 3
+		for _, div := range []float64{12, 6, 4, 2} {
+			p.Resistance = append(p.Resistance, c+r*1.1/div)
+			p.Support = append(p.Support, c-r*1.1/div)
+		}
+	case DeMark:
+		x := h + l + 2*c
+		if c < prior.Open {
+			x = h + 2*l + c
+		} else if c > prior.Open {
+			x = 2*h + l + c
+		}
+		p.Pivot = x / 4
+		p.Resistance = []float64{x/2 - l}
+		p.Support = []float64{x/2 - h}
+	case FibonacciPivots:
+		p.Pivot = (h + l + c) / 3
+		for _, ratio := range []float64{0.382, 0.618, 1} {
+			p.Resistance = append(p.Resistance, p.Pivot+ratio*r)
+			p.Support = append(p.Support, p.Pivot-ratio*r)
+		}
+	default:
+		return p, fmt.Errorf("unknown pivot method %d: %w", method, utils.ErrInvalidParameter)
+	}
+	return p, nil
+}
+
+// PivotResult holds the pivot levels on every input bar. Values holds the
+// central pivot, and Resistance[k] and Support[k] the level k+1 on each side.
+type PivotResult struct {
+	utils.Result
+	Resistance [][]float64
+	Support    [][]float64
+	Periods    []PivotLevels // Levels of each period from the second one
+}
+
+// PeriodPivots resamples intraday bars to the timeframe and gives every bar
+// the pivot levels computed from the prior period. The values start on the
+// first bar of the second period.
+func PeriodPivots(data []utils.OHLCV, timeframe utils.Timeframe, method PivotMethod, loc *time.Location) (*PivotResult, error) {
+	periods, err := utils.Resample(data, timeframe, loc)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := PivotPoints(utils.OHLCV{}, 0, method); err != nil {
+		return nil, err
+	}
+
+	out := &PivotResult{Result: utils.Result{Values: []float64{}}}
+	if len(periods) < 2 {
+		return out, nil
+	}
+	for k := 1; k < len(periods); k++ {
+		p, _ := PivotPoints(periods[k-1], periods[k].Open, method)
+		p.Time = periods[k].Time
+		out.Periods = append(out.Periods, p)
+	}
+	nbLevels := len(out.Periods[0].Resistance)
+	out.Resistance = make([][]float64, nbLevels)
+	out.Support = make([][]float64, nbLevels)
+
+	k := -1
+	for i, d := range data {
+		for k+1 < len(out.Periods) && out.Periods[k+1].Time <= timeframe.PeriodStart(d.Time, loc) {
+			k++
+		}
+		if k < 0 {
+			continue
+		}
+		if len(out.Values) == 0 {
+			out.BeginIndex = i
+		}
+		p := out.Periods[k]
+		out.Values = append(out.Values, p.Pivot)
+		for j := range nbLevels {
+			out.Resistance[j] = append(out.Resistance[j], p.Resistance[j])
+			out.Support[j] = append(out.Support[j], p.Support[j])
+		}
+	}
+	out.NBElement = len(out.Values)
+	return out, nil
+}
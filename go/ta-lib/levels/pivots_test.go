@@ -0,0 +1,78 @@
+package levels_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/levels"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Pivots on hand-computed values, with the formulas depending on the open
+func TestPivotPoints(t *testing.T) {
+	prior := utils.OHLCV{Open: 100, High: 110, Low: 90, Close: 105}
+	for _, tc := range []struct {
+		method        levels.PivotMethod
+		pivot, r1, s1 float64
+	}{
+		{levels.Classic, 305.0 / 3, 2*305.0/3 - 90, 2*305.0/3 - 110},
+		{levels.Woodie, (110 + 90 + 2*104.0) / 4, 2*102.0 - 90, 2*102.0 - 110},
+		{levels.Camarilla, 305.0 / 3, 105 + 20*1.1/12, 105 - 20*1.1/12},
+		{levels.DeMark, (2*110 + 90 + 105.0) / 4, (2*110+90+105.0)/2 - 90, (2*110+90+105.0)/2 - 110},
+		{levels.FibonacciPivots, 305.0 / 3, 305.0/3 + 0.382*20, 305.0/3 - 0.382*20},
+	} {
+		p, err := levels.PivotPoints(prior, 104, tc.method)
+		if err != nil {
+			t.Fatalf("%v: %v", tc.method, err)
+		}
+		if math.Abs(p.Pivot-tc.pivot) > 1e-9 || math.Abs(p.Resistance[0]-tc.r1) > 1e-9 || math.Abs(p.Support[0]-tc.s1) > 1e-9 {
+			t.Errorf("%v pivots %+v, want pivot %v, R1 %v and S1 %v", tc.method, p, tc.pivot, tc.r1, tc.s1)
+		}
+	}
+}
+
+// Weekly pivots of the daily sample bars come from the previous week
+func TestPeriodPivots(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	if start := utils.Weekly.PeriodStart(1737504000, nil); start != 1737331200 {
+		t.Fatalf("week of Tuesday 2025-01-21 starts at %d", start)
+	}
+	result, err := levels.PeriodPivots(data, utils.Weekly, levels.Classic, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weeks, err := utils.Resample(data, utils.Weekly, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	week, high, low := -1, 0.0, 0.0
+	var close float64
+	var prev [3]float64 // High, low and close of the previous week
+	for i, d := range data {
+		if start := utils.Weekly.PeriodStart(d.Time, nil); week < 0 || weeks[week].Time != start {
+			if week >= 0 {
+				prev = [3]float64{high, low, close}
+			}
+			week++
+			high, low = d.High, d.Low
+			if weeks[week].Time != start || weeks[week].Open != d.Open {
+				t.Fatalf("week %d starts at %d, resampled to %+v", week, start, weeks[week])
+			}
+			if week == 1 && result.BeginIndex != i {
+				t.Fatalf("weekly pivots start at bar %d, the second week at %d", result.BeginIndex, i)
+			}
+		}
+		high, low, close = max(high, d.High), min(low, d.Low), d.Close
+		if week == 0 {
+			continue
+		}
+		pivot := (prev[0] + prev[1] + prev[2]) / 3
+		if got := result.Values[i-result.BeginIndex]; got != pivot || result.Resistance[2][i-result.BeginIndex] != prev[0]+2*(pivot-prev[1]) {
+			t.Fatalf("bar %d: weekly pivot %v, want %v", i, got, pivot)
+		}
+	}
+	if len(result.Values) != len(data)-result.BeginIndex || len(result.Periods) != len(weeks)-1 {
+		t.Errorf("%d weekly pivots on %d bars from bar %d", len(result.Values), len(data), result.BeginIndex)
+	}
+}
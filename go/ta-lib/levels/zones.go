@@ -0,0 +1,319 @@
+// Package levels finds the price levels traders watch: support and
+// resistance zones, pivot points and Fibonacci retracements.
+package levels
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/petercool/ta-lib/go/ta-lib/signals"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Source records which detectors found a zone, as a bit set
+type Source int
+
+const (
+	FromSwings      Source = 1 << iota // Clustered swing highs and lows
+	FromVolume                         // Peak of the volume traded at price
+	FromRoundNumber                    // Multiple of the round number step
+)
+
+// String lists the detectors of the zone
+func (s Source) String() string {
+	names := ""
+	for _, n := range []struct {
+		bit  Source
+		name string
+	}{{FromSwings, "swings"}, {FromVolume, "volume"}, {FromRoundNumber, "round number"}} {
+		if s&n.bit != 0 {
+			if names != "" {
+				names += "+"
+			}
+			names += n.name
+		}
+	}
+	return names
+}
+
+// ZoneKind tells whether a zone is below or above the last close
+type ZoneKind int
+
+const (
+	Support    ZoneKind = iota // Below the last close
+	Resistance                 // At or above the last close
+)
+
+// String returns the name of the zone kind
+func (k ZoneKind) String() string {
+	if k == Support {
+		return "support"
+	}
+	return "resistance"
+}
+
+// Zone is a price range where the market turned or traded heavily
+type Zone struct {
+	Low, High  float64 // Price range of the zone
+	Level      float64 // Representative price, the mean of the merged levels
+	Kind       ZoneKind
+	Sources    Source
+	Touches    int     // Number of separate visits, consecutive bars counting once
+	FirstTouch int64   // Time of the first bar touching the zone
+	LastTouch  int64   // Time of the last bar touching the zone
+	Volume     float64 // Volume of the bars touching the zone
+	Strength   float64 // Ranking score, see Zones
+}
+
+// ZoneConfig configures the zone detection
+type ZoneConfig struct {
+	SwingStrength int     // Bars on each side of a swing, 0 disables swing zones
+	Width         float64 // Half width of a zone as a fraction of its price, such as 0.005
+	VolumeBins    int     // Price bins of the volume profile, at most MaxVolumeBins, 0 disables volume zones
+	RoundStep     float64 // Spacing of round numbers, at most MaxRoundNumbers in the range, 0 disables round number zones
+	MinTouches    int     // Fewest touches for a zone to be kept
+}
+
+// validate checks the configuration and the bars
+func (c ZoneConfig) validate(data []utils.OHLCV) error {
+	if len(data) == 0 {
+		return utils.ErrEmptyInputData
+	}
+	if c.SwingStrength < 0 || c.VolumeBins < 0 || c.RoundStep < 0 || c.MinTouches < 0 || !(c.Width > 0) {
+		return fmt.Errorf("zone config %+v: %w", c, utils.ErrInvalidParameter)
+	}
+	if c.VolumeBins > MaxVolumeBins {
+		return fmt.Errorf("volume bins %d, more than %d: %w", c.VolumeBins, MaxVolumeBins, utils.ErrInvalidParameter)
+	}
+	return nil
+}
+
+// Zones finds support and resistance zones with every enabled detector,
+// merges the overlapping ones and sorts them by price.
+//
+// Strength ranks the zones: the number of touches, multiplied by the number
+// of detectors that found the zone and by one plus the share of the total
+// volume traded in it, then by a recency weight going from 0.5 for a zone
+// last touched on the first bar to 1 for one touched on the last bar.
+func Zones(data []utils.OHLCV, config ZoneConfig) ([]Zone, error) {
+	if err := config.validate(data); err != nil {
+		return nil, err
+	}
+	var candidates []Zone
+	if config.SwingStrength > 0 {
+		candidates = append(candidates, swingZones(data, config)...)
+	}
+	if config.VolumeBins > 0 {
+		candidates = append(candidates, volumeZones(data, config)...)
+	}
+	if config.RoundStep > 0 {
+		zones, err := roundZones(data, config)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, zones...)
+	}
+	return finish(data, config, merge(candidates)), nil
+}
+
+// SwingZones clusters the swing highs and lows that lie within the zone width of each other
+func SwingZones(data []utils.OHLCV, config ZoneConfig) ([]Zone, error) {
+	config.VolumeBins, config.RoundStep = 0, 0
+	if config.SwingStrength < 1 {
+		return nil, fmt.Errorf("swing strength %d: %w", config.SwingStrength, utils.ErrInvalidParameter)
+	}
+	return Zones(data, config)
+}
+
+// VolumeZones finds the price bins where more volume traded than in the bins around them
+func VolumeZones(data []utils.OHLCV, config ZoneConfig) ([]Zone, error) {
+	config.SwingStrength, config.RoundStep = 0, 0
+	if config.VolumeBins < 1 {
+		return nil, fmt.Errorf("volume bins %d: %w", config.VolumeBins, utils.ErrInvalidParameter)
+	}
+	return Zones(data, config)
+}
+
+// RoundNumberZones finds the multiples of the round number step that price visited
+func RoundNumberZones(data []utils.OHLCV, config ZoneConfig) ([]Zone, error) {
+	config.SwingStrength, config.VolumeBins = 0, 0
+	if !(config.RoundStep > 0) {
+		return nil, fmt.Errorf("round number step %v: %w", config.RoundStep, utils.ErrInvalidParameter)
+	}
+	return Zones(data, config)
+}
+
+// around returns a zone centered on a level with the configured width
+func around(level, width float64, source Source) Zone {
+	low, high := level*(1-width), level*(1+width)
+	return Zone{Low: min(low, high), High: max(low, high), Level: level, Sources: source}
+}
+
+// swingZones clusters the swing points sorted by price. A point joins the
+// cluster while it is within the zone width of the cluster's mean.
+func swingZones(data []utils.OHLCV, config ZoneConfig) []Zone {
+	_, high, low, _, _ := utils.GetOHLCVSlices(data)
+	var prices []float64
+	for _, s := range signals.SwingHighs(utils.NewResult(0, high), config.SwingStrength) {
+		prices = append(prices, s.Value)
+	}
+	for _, s := range signals.SwingLows(utils.NewResult(0, low), config.SwingStrength) {
+		prices = append(prices, s.Value)
+	}
+	sort.Float64s(prices)
+
+	var zones []Zone
+	for start := 0; start < len(prices); {
+		sum, end := prices[start], start+1
+		for end < len(prices) && prices[end] <= sum/float64(end-start)*(1+2*config.Width) {
+			sum += prices[end]
+			end++
+		}
+		z := around(sum/float64(end-start), config.Width, FromSwings)
+		z.Low, z.High = min(z.Low, prices[start]), max(z.High, prices[end-1])
+		zones = append(zones, z)
+		start = end
+	}
+	return zones
+}
+
+// MaxVolumeBins is the most price bins of the volume profile, 8 MB of values
+const MaxVolumeBins = 1 << 20
+
+// volumeZones spreads the volume of each bar evenly over the bins its range
+// covers, and keeps the bins above their neighbours and above the average
+func volumeZones(data []utils.OHLCV, config ZoneConfig) []Zone {
+	lowest, highest := math.Inf(1), math.Inf(-1)
+	for _, d := range data {
+		lowest, highest = min(lowest, d.Low), max(highest, d.High)
+	}
+	if !(highest > lowest) {
+		return nil
+	}
+	bins := config.VolumeBins
+	size := (highest - lowest) / float64(bins)
+	profile := make([]float64, bins)
+	bin := func(price float64) int { return min(int((price-lowest)/size), bins-1) }
+	total := 0.0
+	for _, d := range data {
+		first, last := bin(d.Low), bin(d.High)
+		share := d.Volume / float64(last-first+1)
+		for b := first; b <= last; b++ {
+			profile[b] += share
+		}
+		total += d.Volume
+	}
+
+	var zones []Zone
+	average := total / float64(bins)
+	for b, v := range profile {
+		if v <= average || b > 0 && v <= profile[b-1] || b < bins-1 && v < profile[b+1] {
+			continue
+		}
+		low := lowest + float64(b)*size
+		z := around(low+size/2, config.Width, FromVolume)
+		z.Low, z.High = min(z.Low, low), max(z.High, low+size)
+		zones = append(zones, z)
+	}
+	return zones
+}
+
+// MaxRoundNumbers is the most round numbers the traded range may hold
+const MaxRoundNumbers = 10000
+
+// roundZones places a zone on every multiple of the step within the traded range
+func roundZones(data []utils.OHLCV, config ZoneConfig) ([]Zone, error) {
+	lowest, highest := math.Inf(1), math.Inf(-1)
+	for _, d := range data {
+		lowest, highest = min(lowest, d.Low), max(highest, d.High)
+	}
+	first := math.Ceil(lowest / config.RoundStep)
+	if count := math.Floor(highest/config.RoundStep) - first + 1; !(count <= MaxRoundNumbers) {
+		return nil, fmt.Errorf("round number step %v gives %g zones from %v to %v, more than %d: %w",
+			config.RoundStep, count, lowest, highest, MaxRoundNumbers, utils.ErrInvalidParameter)
+	}
+
+	// Zones stay within a quarter step, so that close round numbers do not merge
+	var zones []Zone
+	for k := first; k*config.RoundStep <= highest; k++ {
+		z := around(k*config.RoundStep, config.Width, FromRoundNumber)
+		z.Low, z.High = max(z.Low, z.Level-config.RoundStep/4), min(z.High, z.Level+config.RoundStep/4)
+		zones = append(zones, z)
+	}
+	return zones, nil
+}
+
+// merge joins the overlapping zones, centering the result on the mean of their levels
+func merge(zones []Zone) []Zone {
+	sort.Slice(zones, func(a, b int) bool { return zones[a].Low < zones[b].Low })
+	var out []Zone
+	count := 0
+	for _, z := range zones {
+		if n := len(out); n > 0 && z.Low <= out[n-1].High {
+			last := &out[n-1]
+			last.High = max(last.High, z.High)
+			last.Level = (last.Level*float64(count) + z.Level) / float64(count+1)
+			last.Sources |= z.Sources
+			count++
+			continue
+		}
+		out = append(out, z)
+		count = 1
+	}
+	return out
+}
+
+// finish measures the touches of each zone, drops the zones touched too
+// rarely and scores the others
+func finish(data []utils.OHLCV, config ZoneConfig, zones []Zone) []Zone {
+	total := 0.0
+	for _, d := range data {
+		total += d.Volume
+	}
+	lastClose := data[len(data)-1].Close
+
+	// The merged zones are sorted and separate, so every bar touches a run
+	// of them found by a binary search, and a single pass over the bars
+	// measures all the touches
+	lastIndex := make([]int, len(zones))
+	for i, d := range data {
+		k := sort.Search(len(zones), func(k int) bool { return zones[k].High >= d.Low })
+		for ; k < len(zones) && zones[k].Low <= d.High; k++ {
+			z := &zones[k]
+			if z.Touches == 0 || lastIndex[k] < i-1 {
+				z.Touches++
+				if z.Touches == 1 {
+					z.FirstTouch = d.Time
+				}
+			}
+			z.LastTouch = d.Time
+			z.Volume += d.Volume
+			lastIndex[k] = i
+		}
+	}
+
+	out := zones[:0]
+	for k, z := range zones {
+		if z.Touches == 0 || z.Touches < config.MinTouches {
+			continue
+		}
+
+		z.Kind = Resistance
+		if z.Level < lastClose {
+			z.Kind = Support
+		}
+		detectors := 0
+		for s := z.Sources; s != 0; s &= s - 1 {
+			detectors++
+		}
+		volumeShare := 0.0
+		if total > 0 {
+			volumeShare = z.Volume / total
+		}
+		recency := 0.5 + 0.5*float64(lastIndex[k])/float64(max(len(data)-1, 1))
+		z.Strength = float64(z.Touches) * float64(detectors) * (1 + volumeShare) * recency
+		out = append(out, z)
+	}
+	return out
+}
@@ -0,0 +1,136 @@
+package levels_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/levels"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// zigzag returns bars going from 100 to 110 and back in steps of 2, ending on a bottom
+func zigzag(cycles int) []utils.OHLCV {
+	data := make([]utils.OHLCV, cycles*10+1)
+	for i := range data {
+		step := i % 10
+		if step > 5 {
+			step = 10 - step
+		}
+		c := 100 + 2*float64(step)
+		data[i] = utils.OHLCV{Time: int64(i) * 60, Open: c, High: c + 0.2, Low: c - 0.2, Close: c, Volume: 1}
+	}
+	return data
+}
+
+// Zones are found where the zigzag turns
+func TestSwingZones(t *testing.T) {
+	synthetic := zigzag(4)
+	config := levels.ZoneConfig{SwingStrength: 2, Width: 0.005}
+	zones, err := levels.SwingZones(synthetic, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		level   float64
+		kind    levels.ZoneKind
+		touches int
+		first   int64
+		last    int64
+	}{
+		{99.8, levels.Support, 5, 0, 40 * 60},
+		{110.2, levels.Resistance, 4, 5 * 60, 35 * 60},
+	}
+	if len(zones) != len(want) {
+		t.Fatalf("%d swing zones on the zigzag, want %d", len(zones), len(want))
+	}
+	for k, w := range want {
+		z := zones[k]
+		if math.Abs(z.Level-w.level) > 1e-9 || z.Kind != w.kind || z.Touches != w.touches || z.FirstTouch != w.first || z.LastTouch != w.last {
+			t.Errorf("zone %+v, want %v %v touched %d times from %d to %d", z, w.kind, w.level, w.touches, w.first, w.last)
+		}
+	}
+
+	// Round numbers merge with the swing zone at the bottom, 105 is never touched
+	config.RoundStep = 5
+	zones, err = levels.Zones(synthetic, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zones) != 2 || zones[0].Sources != levels.FromSwings|levels.FromRoundNumber || zones[0].Strength <= zones[1].Strength {
+		t.Errorf("zones with round numbers %+v", zones)
+	}
+}
+
+// On the sample data the zones are sorted, separate and touched
+func TestZones(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	config := levels.ZoneConfig{SwingStrength: 5, Width: 0.003, VolumeBins: 50, RoundStep: 5000, MinTouches: 2}
+	zones, err := levels.Zones(data, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zones) == 0 {
+		t.Fatalf("no zone in %d bars", len(data))
+	}
+	last := data[len(data)-1].Close
+	for k, z := range zones {
+		switch {
+		case k > 0 && z.Low <= zones[k-1].High:
+			t.Errorf("zones %+v and %+v overlap", zones[k-1], z)
+		case z.Touches < 2 || z.FirstTouch > z.LastTouch || z.Strength <= 0:
+			t.Errorf("zone %+v", z)
+		case (z.Kind == levels.Support) != (z.Level < last):
+			t.Errorf("%v zone at %v with the last close at %v", z.Kind, z.Level, last)
+		}
+	}
+
+	if _, err := levels.Zones(data, levels.ZoneConfig{SwingStrength: 5}); !errors.Is(err, utils.ErrInvalidParameter) {
+		t.Errorf("zero zone width gave error %v", err)
+	}
+}
+
+// Volume bins beyond the cap are rejected before the profile is allocated,
+// the cap itself is allowed
+func TestVolumeZonesBins(t *testing.T) {
+	data := zigzag(4)
+	for _, bins := range []int{levels.MaxVolumeBins + 1, 1 << 62} {
+		if _, err := levels.VolumeZones(data, levels.ZoneConfig{VolumeBins: bins, Width: 0.1}); !errors.Is(err, utils.ErrInvalidParameter) {
+			t.Errorf("%d volume bins gave error %v", bins, err)
+		}
+	}
+	if _, err := levels.VolumeZones(data, levels.ZoneConfig{VolumeBins: levels.MaxVolumeBins, Width: 0.1}); err != nil {
+		t.Errorf("%d volume bins: %v", levels.MaxVolumeBins, err)
+	}
+}
+
+// A step small enough to give more round numbers than allowed is rejected,
+// and the largest count allowed is measured in one pass over the bars
+func TestRoundNumberZones(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	lowest, highest := data[0].Low, data[0].High
+	for _, d := range data {
+		lowest, highest = min(lowest, d.Low), max(highest, d.High)
+	}
+	for _, step := range []float64{1e-6, (highest - lowest) / levels.MaxRoundNumbers / 2} {
+		if _, err := levels.RoundNumberZones(data, levels.ZoneConfig{Width: 0.001, RoundStep: step}); !errors.Is(err, utils.ErrInvalidParameter) {
+			t.Errorf("round number step %v gave error %v", step, err)
+		}
+	}
+	step := (highest - lowest) / (levels.MaxRoundNumbers - 2)
+	zones, err := levels.RoundNumberZones(data, levels.ZoneConfig{Width: 0.001, RoundStep: step})
+	if err != nil {
+		t.Fatal(err)
+	}
+	touches := 0
+	for k, z := range zones {
+		if k > 0 && z.Low <= zones[k-1].High {
+			t.Errorf("round number zones %+v and %+v overlap", zones[k-1], z)
+		}
+		touches += z.Touches
+	}
+	if len(zones) < levels.MaxRoundNumbers/2 || touches < len(data) {
+		t.Errorf("%d round number zones touched %d times with a step of %v", len(zones), touches, step)
+	}
+}
@@ -0,0 +1,257 @@
+package pairs
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// AutoLags selects the number of lagged differences of the augmented
+// Dickey-Fuller regression by the Akaike information criterion, up to
+// 12*(n/100)^(1/4) for n values
+const AutoLags = -1
+
+// Significance selects a critical value of a unit root test
+type Significance int
+
+const (
+	OnePercent Significance = iota
+	FivePercent
+	TenPercent
+)
+
+// String returns the level of the significance
+func (s Significance) String() string {
+	switch s {
+	case OnePercent:
+		return "1%"
+	case FivePercent:
+		return "5%"
+	case TenPercent:
+		return "10%"
+	}
+	return fmt.Sprintf("Significance(%d)", int(s))
+}
+
+// UnitRootTest is the outcome of a unit root test. A statistic below the
+// critical value of a significance rejects the unit root at that level: the
+// series, or the spread for Engle-Granger, is stationary.
+type UnitRootTest struct {
+	Statistic      float64    // t statistic of the coefficient of the lagged level
+	Lags           int        // Lagged differences in the regression
+	Observations   int        // Rows of the regression
+	CriticalValues [3]float64 // At OnePercent, FivePercent and TenPercent
+}
+
+// Rejects reports whether the unit root is rejected at a significance
+func (t UnitRootTest) Rejects(level Significance) bool {
+	if level < OnePercent || level > TenPercent {
+		return false
+	}
+	return t.Statistic < t.CriticalValues[level]
+}
+
+// criticalValue evaluates the response surface of MacKinnon (2010) for n
+// observations
+func criticalValue(coefficients [4]float64, n int) float64 {
+	t := float64(n)
+	return coefficients[0] + coefficients[1]/t + coefficients[2]/(t*t) + coefficients[3]/(t*t*t)
+}
+
+// MacKinnon (2010) critical values with a constant, for one series and for
+// the residuals of a regression on one other series
+var (
+	dickeyFullerCritical = [3][4]float64{
+		{-3.43035, -6.5393, -16.786, -79.433},
+		{-2.86154, -2.8903, -4.234, -40.040},
+		{-2.56677, -1.5384, -2.809, 0},
+	}
+	engleGrangerCritical = [3][4]float64{
+		{-3.89644, -10.9519, -22.527, 0},
+		{-3.33613, -6.1101, -6.823, 0},
+		{-3.04445, -4.2412, -2.720, 0},
+	}
+)
+
+// ADF runs the augmented Dickey-Fuller test with a constant on a series,
+// regressing its changes on its previous value and on lags previous
+// changes, or on the number of changes chosen with AutoLags
+func ADF(series []float64, lags int) (UnitRootTest, error) {
+	test, err := adf(series, lags, true)
+	if err != nil {
+		return UnitRootTest{}, err
+	}
+	for i, c := range dickeyFullerCritical {
+		test.CriticalValues[i] = criticalValue(c, test.Observations)
+	}
+	return test, nil
+}
+
+// adf runs the Dickey-Fuller regression, with or without a constant, and
+// returns the statistic without critical values
+func adf(series []float64, lags int, constant bool) (UnitRootTest, error) {
+	if err := utils.CheckSeries(series); err != nil {
+		return UnitRootTest{}, err
+	}
+	if lags < AutoLags {
+		return UnitRootTest{}, fmt.Errorf("%d lags: %w", lags, utils.ErrInvalidParameter)
+	}
+	if lags == AutoLags {
+		maxLags := int(12 * math.Pow(float64(len(series))/100, 0.25))
+		maxLags = min(maxLags, (len(series)-4)/2)
+		if maxLags < 0 {
+			return UnitRootTest{}, fmt.Errorf("unit root test of %d values: %w", len(series), utils.ErrInvalidParameter)
+		}
+
+		// Every candidate is fitted on the same rows
+		best := math.Inf(1)
+		for l := 0; l <= maxLags; l++ {
+			design, target := dickeyFuller(series, l, maxLags, constant)
+			fit, err := ols(design, target)
+			if err != nil {
+				return UnitRootTest{}, err
+			}
+			n := float64(len(target))
+			if aic := n*math.Log(fit.rss/n) + 2*float64(len(design[0])); aic < best {
+				best, lags = aic, l
+			}
+		}
+	}
+
+	design, target := dickeyFuller(series, lags, lags, constant)
+	if len(design) == 0 || len(design) <= len(design[0]) {
+		return UnitRootTest{}, fmt.Errorf("unit root test of %d values with %d lags: %w", len(series), lags, utils.ErrInvalidParameter)
+	}
+	fit, err := ols(design, target)
+	if err != nil {
+		return UnitRootTest{}, err
+	}
+	return UnitRootTest{Statistic: fit.t, Lags: lags, Observations: len(target)}, nil
+}
+
+// dickeyFuller builds the rows of the regression of the changes of a series
+// from index skip+1, with the constant and lags previous changes first and
+// the previous value last
+func dickeyFuller(series []float64, lags, skip int, constant bool) (design [][]float64, target []float64) {
+	for t := skip + 1; t < len(series); t++ {
+		var row []float64
+		if constant {
+			row = append(row, 1)
+		}
+		for l := 1; l <= lags; l++ {
+			row = append(row, series[t-l]-series[t-l-1])
+		}
+		design = append(design, append(row, series[t-1]))
+		target = append(target, series[t]-series[t-1])
+	}
+	return design, target
+}
+
+// olsFit is the outcome of a least squares regression
+type olsFit struct {
+	coefficients []float64
+	rss          float64 // Residual sum of squares
+	t            float64 // t statistic of the last coefficient
+}
+
+// ols solves a least squares regression with the modified Gram-Schmidt QR
+// decomposition of the design, which keeps its precision when the
+// regressors are nearly collinear
+func ols(design [][]float64, target []float64) (olsFit, error) {
+	n, k := len(design), len(design[0])
+	if n <= k {
+		return olsFit{}, fmt.Errorf("regression of %d rows on %d columns: %w", n, k, utils.ErrInvalidParameter)
+	}
+	q := make([][]float64, k) // Orthonormal columns
+	r := make([][]float64, k)
+	for j := range q {
+		q[j] = make([]float64, n)
+		r[j] = make([]float64, k)
+		for i := range design {
+			q[j][i] = design[i][j]
+		}
+	}
+	residual := append([]float64(nil), target...)
+	qty := make([]float64, k)
+	for j := 0; j < k; j++ {
+		for i := 0; i < j; i++ {
+			r[i][j] = dot(q[i], q[j])
+			for row := range q[j] {
+				q[j][row] -= r[i][j] * q[i][row]
+			}
+		}
+		r[j][j] = math.Sqrt(dot(q[j], q[j]))
+		if r[j][j] == 0 {
+			return olsFit{}, fmt.Errorf("singular regression, column %d depends on the others: %w", j, utils.ErrInvalidParameter)
+		}
+		for row := range q[j] {
+			q[j][row] /= r[j][j]
+		}
+		qty[j] = dot(q[j], residual)
+		for row := range residual {
+			residual[row] -= qty[j] * q[j][row]
+		}
+	}
+
+	// Back substitution of R b = Q'y
+	fit := olsFit{coefficients: make([]float64, k), rss: dot(residual, residual)}
+	for j := k - 1; j >= 0; j-- {
+		b := qty[j]
+		for i := j + 1; i < k; i++ {
+			b -= r[j][i] * fit.coefficients[i]
+		}
+		fit.coefficients[j] = b / r[j][j]
+	}
+	// The variance of the last coefficient is sigma^2 / R[k-1][k-1]^2
+	sigma := math.Sqrt(fit.rss / float64(n-k))
+	fit.t = fit.coefficients[k-1] * r[k-1][k-1] / sigma
+	return fit, nil
+}
+
+func dot(a, b []float64) float64 {
+	var s utils.KahanSum
+	for i := range a {
+		s.Add(a[i] * b[i])
+	}
+	return s.Total()
+}
+
+// Cointegration is the outcome of the Engle-Granger test of a pair
+type Cointegration struct {
+	UnitRootTest           // Dickey-Fuller test of the spread, with the Engle-Granger critical values
+	Intercept    float64   // Of the regression of y on x
+	Ratio        float64   // Hedge ratio of the regression of y on x
+	Spread       []float64 // Residuals y - intercept - ratio*x
+}
+
+// EngleGranger tests whether y and x are cointegrated: it regresses y on x
+// over the whole series and runs the augmented Dickey-Fuller test without
+// constant on the residuals, compared with the critical values of MacKinnon
+// (2010) for two series. Rejects(FivePercent) then reads as cointegrated at
+// 5%.
+func EngleGranger(y, x []float64, lags int) (*Cointegration, error) {
+	if err := utils.CheckSeries(y, x); err != nil {
+		return nil, err
+	}
+	var c utils.RollingCovariance
+	for i := range y {
+		c.Add(x[i], y[i])
+	}
+	if c.X.M2.Total() <= 0 {
+		return nil, fmt.Errorf("hedge leg without variation: %w", utils.ErrInvalidParameter)
+	}
+	out := &Cointegration{Intercept: c.Intercept(), Ratio: c.Slope(), Spread: make([]float64, len(y))}
+	for i := range y {
+		out.Spread[i] = y[i] - out.Intercept - out.Ratio*x[i]
+	}
+	test, err := adf(out.Spread, lags, false)
+	if err != nil {
+		return nil, err
+	}
+	out.UnitRootTest = test
+	for i, cv := range engleGrangerCritical {
+		out.CriticalValues[i] = criticalValue(cv, len(y)-1)
+	}
+	return out, nil
+}
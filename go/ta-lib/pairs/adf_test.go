@@ -0,0 +1,86 @@
+package pairs_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/pairs"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// gaussian returns a generator of standard normal values
+func gaussian(seed uint64) func() float64 {
+	uniform := func() float64 {
+		seed = seed*6364136223846793005 + 1442695040888963407
+		return (float64(seed>>11) + 0.5) / (1 << 53)
+	}
+	return func() float64 {
+		return math.Sqrt(-2*math.Log(uniform())) * math.Cos(2*math.Pi*uniform())
+	}
+}
+
+// syntheticPair returns a random walk x, y = 2 + 1.5x plus an AR(1) spread
+// of coefficient 0.9, whose half-life is ln(0.5)/ln(0.9) = 6.58 bars, and
+// an independent random walk z
+func syntheticPair(n int) (x, y, z []float64) {
+	normal := gaussian(11)
+	x, y, z = make([]float64, n), make([]float64, n), make([]float64, n)
+	spread := 0.0
+	for i := range x {
+		if i > 0 {
+			x[i], z[i] = x[i-1], z[i-1]
+		}
+		x[i] += normal()
+		z[i] += normal()
+		spread = 0.9*spread + normal()
+		y[i] = 2 + 1.5*x[i] + spread
+	}
+	return x, y, z
+}
+
+// The cointegration of the synthetic pair is found, with its hedge ratio
+// and half-life, and not that of independent random walks
+func TestEngleGranger(t *testing.T) {
+	x, y, z := syntheticPair(2000)
+	coint, err := pairs.EngleGranger(y, x, pairs.AutoLags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !coint.Rejects(pairs.OnePercent) {
+		t.Errorf("cointegrated pair not found, statistic %v critical %v", coint.Statistic, coint.CriticalValues)
+	}
+	testutil.Near(t, "Engle-Granger ratio", coint.Ratio, 1.5, 0.01)
+	if halfLife, err := pairs.HalfLife(coint.Spread); err != nil || math.Abs(halfLife-math.Log(0.5)/math.Log(0.9)) > 1.5 {
+		t.Errorf("half-life %v %v", halfLife, err)
+	}
+	if independent, err := pairs.EngleGranger(z, x, pairs.AutoLags); err != nil || independent.Rejects(pairs.TenPercent) {
+		t.Errorf("independent walks cointegrated: %+v %v", independent, err)
+	}
+	if walk, err := pairs.ADF(x, 0); err != nil || walk.Rejects(pairs.TenPercent) {
+		t.Errorf("random walk stationary: %+v %v", walk, err)
+	}
+	if halfLife, err := pairs.HalfLife(x); err != nil || halfLife < 100 {
+		t.Errorf("random walk half-life %v %v", halfLife, err)
+	}
+
+	if _, err := pairs.EngleGranger(y, make([]float64, len(y)), 0); !errors.Is(err, utils.ErrInvalidParameter) {
+		t.Errorf("constant hedge leg: %v", err)
+	}
+}
+
+// Dickey-Fuller statistic with one lag computed exactly by hand
+func TestADF(t *testing.T) {
+	small := []float64{10, 10.5, 10.2, 10.8, 10.4, 10.9, 10.6, 11.3, 10.7, 11.0, 10.9, 11.6}
+	test, err := pairs.ADF(small, 1)
+	if err != nil || test.Observations != 10 || test.Lags != 1 {
+		t.Fatalf("ADF of 12 values: %+v %v", test, err)
+	}
+	testutil.Near(t, "ADF statistic", test.Statistic, -0.38285138237036853, 1e-12)
+	testutil.Near(t, "ADF 5% critical value", test.CriticalValues[pairs.FivePercent], -2.86154-2.8903/10-4.234/100-40.040/1000, 1e-12)
+
+	if _, err := pairs.ADF(small, 20); !errors.Is(err, utils.ErrInvalidParameter) {
+		t.Errorf("ADF with 20 lags of 12 values: %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+// Package pairs provides statistical pair-trading analytics: rolling OLS
+// and Kalman filter hedge ratios, Engle-Granger cointegration with the
+// augmented Dickey-Fuller test, the half-life of mean reversion and spread
+// z-scores.
+//
+// A pair is a dependent leg Y hedged with ratio units of a leg X, and its
+// spread is Y - intercept - ratio*X. Prices are used as given: pass their
+// logs for a spread in returns rather than in price units. Rolling results
+// are utils.Result values.
+package pairs
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Pair holds the closes of the two legs at their common timestamps
+type Pair struct {
+	Time []int64
+	Y    []float64 // Closes of the dependent leg
+	X    []float64 // Closes of the hedge leg
+}
+
+// NewPair aligns the bars of the two legs on the timestamps they have in
+// common. The bars of each leg must be in ascending time.
+func NewPair(y, x []utils.OHLCV) (*Pair, error) {
+	if len(y) == 0 || len(x) == 0 {
+		return nil, utils.ErrEmptyInputData
+	}
+	p := &Pair{}
+	for i, j := 0, 0; i < len(y) && j < len(x); {
+		switch {
+		case y[i].Time < x[j].Time:
+			i++
+		case y[i].Time > x[j].Time:
+			j++
+		default:
+			p.Time = append(p.Time, y[i].Time)
+			p.Y = append(p.Y, y[i].Close)
+			p.X = append(p.X, x[j].Close)
+			i++
+			j++
+		}
+	}
+	if len(p.Time) == 0 {
+		return nil, fmt.Errorf("legs without a common timestamp: %w", utils.ErrEmptyInputData)
+	}
+	return p, nil
+}
+
+// LoadPair fetches the bars of the two legs from their feeds and aligns them
+func LoadPair(y, x utils.DataFeed, startTime, endTime time.Time) (*Pair, error) {
+	yData, err := y.GetData(startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the dependent leg: %w", err)
+	}
+	xData, err := x.GetData(startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the hedge leg: %w", err)
+	}
+	return NewPair(yData, xData)
+}
+
+// HedgeResult holds the hedge ratios of a pair in Values, with the
+// intercepts and the spreads of the same bars
+type HedgeResult struct {
+	utils.Result
+	Intercepts []float64
+	Spreads    []float64 // Y - intercept - ratio*X
+}
+
+// RollingHedgeRatio regresses y on x by ordinary least squares over every
+// window of period bars. The spread of a bar uses the regression of the
+// window ending at it. A window where x does not vary gives a ratio of 0.
+func RollingHedgeRatio(y, x []float64, period int) (*HedgeResult, error) {
+	if err := utils.CheckSeries(y, x); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("period", period, 2); err != nil {
+		return nil, err
+	}
+	if period > len(y) {
+		return &HedgeResult{Result: *utils.NewResult(0, nil)}, nil
+	}
+	n := len(y) - period + 1
+	ratios, intercepts, spreads := make([]float64, 0, n), make([]float64, 0, n), make([]float64, 0, n)
+	var c utils.RollingCovariance
+	for i := range y {
+		if i >= period {
+			c.Replace(x[i-period], y[i-period], x[i], y[i])
+		} else {
+			c.Add(x[i], y[i])
+		}
+		if i < period-1 {
+			continue
+		}
+		ratio, intercept := c.Slope(), c.Intercept()
+		ratios = append(ratios, ratio)
+		intercepts = append(intercepts, intercept)
+		spreads = append(spreads, y[i]-intercept-ratio*x[i])
+	}
+	return &HedgeResult{Result: *utils.NewResult(period-1, ratios), Intercepts: intercepts, Spreads: spreads}, nil
+}
+
+// KalmanResult holds the hedge ratios estimated by a Kalman filter. Its
+// spreads are the forecast errors of y from the estimates of the previous
+// bar, with their standard deviations, so that Spreads[i]/Deviations[i] is a
+// z-score without lookback.
+type KalmanResult struct {
+	HedgeResult
+	Deviations []float64
+}
+
+// KalmanHedgeRatio estimates the intercept and the hedge ratio of y on x as
+// random walks with a Kalman filter. delta in (0, 1) sets how fast they may
+// drift, the state noise being delta/(1-delta) times the identity, such as
+// 1e-4, and observationVariance is the variance of the spread, such as 1e-3
+// for log prices. As in Chan's formulation, the filter starts from a zero
+// state with a zero covariance, full confidence in that prior, which only
+// the drift loosens, so the first values are pulled towards zero.
+func KalmanHedgeRatio(y, x []float64, delta, observationVariance float64) (*KalmanResult, error) {
+	if err := utils.CheckSeries(y, x); err != nil {
+		return nil, err
+	}
+	if !(delta > 0 && delta < 1) || !(observationVariance > 0) {
+		return nil, fmt.Errorf("delta %v and observation variance %v: %w", delta, observationVariance, utils.ErrInvalidParameter)
+	}
+	n := len(y)
+	out := &KalmanResult{
+		HedgeResult: HedgeResult{
+			Result:     *utils.NewResult(0, make([]float64, n)),
+			Intercepts: make([]float64, n),
+			Spreads:    make([]float64, n),
+		},
+		Deviations: make([]float64, n),
+	}
+	drift := delta / (1 - delta)
+
+	// State (ratio, intercept) and its covariance
+	var ratio, intercept float64
+	var p [2][2]float64
+	for i := range y {
+		// Prediction: the state drifts
+		r := p
+		r[0][0] += drift
+		r[1][1] += drift
+
+		// Observation y = ratio*x + intercept
+		f := [2]float64{x[i], 1}
+		rf := [2]float64{r[0][0]*f[0] + r[0][1]*f[1], r[1][0]*f[0] + r[1][1]*f[1]}
+		q := f[0]*rf[0] + f[1]*rf[1] + observationVariance
+		e := y[i] - ratio*x[i] - intercept
+		k := [2]float64{rf[0] / q, rf[1] / q}
+		ratio += k[0] * e
+		intercept += k[1] * e
+		for a := 0; a < 2; a++ {
+			for b := 0; b < 2; b++ {
+				p[a][b] = r[a][b] - k[a]*rf[b]
+			}
+		}
+
+		out.Values[i], out.Intercepts[i] = ratio, intercept
+		out.Spreads[i], out.Deviations[i] = e, math.Sqrt(q)
+	}
+	return out, nil
+}
+
+// ZScore returns how many sample standard deviations each spread is from
+// the mean of the period spreads ending at it, 0 for a window without
+// deviation
+func ZScore(spread []float64, period int) (*utils.Result, error) {
+	if err := utils.CheckSeries(spread); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("period", period, 2); err != nil {
+		return nil, err
+	}
+	if period > len(spread) {
+		return utils.NewResult(0, nil), nil
+	}
+	out := make([]float64, 0, len(spread)-period+1)
+	var m utils.RollingMoments
+	for i, s := range spread {
+		if i >= period {
+			m.Replace(spread[i-period], s)
+		} else {
+			m.Add(s)
+		}
+		if i < period-1 {
+			continue
+		}
+		z := 0.0
+		if dev := m.StdDev(1); dev > 0 {
+			z = (s - m.Mean()) / dev
+		}
+		out = append(out, z)
+	}
+	return utils.NewResult(period-1, out), nil
+}
+
+// HalfLife returns the half-life of mean reversion of a spread in bars,
+// from the regression of its changes on its previous values,
+// s[t] - s[t-1] = a + lambda*s[t-1]: ln(1/2) / ln|1 + lambda|, or +Inf
+// when the spread does not revert
+func HalfLife(spread []float64) (float64, error) {
+	if err := utils.CheckSeries(spread); err != nil {
+		return 0, err
+	}
+	if len(spread) < 3 {
+		return 0, fmt.Errorf("half-life of %d values: %w", len(spread), utils.ErrInvalidParameter)
+	}
+	var c utils.RollingCovariance
+	for t := 1; t < len(spread); t++ {
+		c.Add(spread[t-1], spread[t]-spread[t-1])
+	}
+	decay := math.Abs(1 + c.Slope())
+	switch {
+	case decay >= 1:
+		return math.Inf(1), nil
+	case decay == 0:
+		return 0, nil
+	}
+	return math.Log(0.5) / math.Log(decay), nil
+}
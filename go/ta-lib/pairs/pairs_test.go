@@ -0,0 +1,127 @@
+package pairs_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/petercool/ta-lib/go/ta-lib/pairs"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// sliceFeed serves bars from memory
+type sliceFeed []utils.OHLCV
+
+func (f sliceFeed) GetData(startTime, endTime time.Time) ([]utils.OHLCV, error) {
+	var out []utils.OHLCV
+	for _, b := range f {
+		if b.Time >= startTime.Unix() && b.Time <= endTime.Unix() {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+// covariance returns the sample covariance of x and y
+func covariance(x, y []float64) float64 {
+	var mx, my float64
+	for k := range x {
+		mx += x[k] / float64(len(x))
+		my += y[k] / float64(len(x))
+	}
+	var sxy float64
+	for k := range x {
+		sxy += (x[k] - mx) * (y[k] - my)
+	}
+	return sxy / float64(len(x)-1)
+}
+
+// Rolling regressions match the OLS of every window
+func TestRollingHedgeRatio(t *testing.T) {
+	const n, period = 2000, 50
+	x, y, _ := syntheticPair(n)
+	rolling, err := pairs.RollingHedgeRatio(y, x, period)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rolling.BeginIndex != period-1 || rolling.NBElement != n-period+1 {
+		t.Fatalf("rolling ratios %d from %d", rolling.NBElement, rolling.BeginIndex)
+	}
+	for i, ratio := range rolling.Values {
+		wx, wy := x[i:i+period], y[i:i+period]
+		var mx, my float64
+		for k := range wx {
+			mx, my = mx+wx[k]/period, my+wy[k]/period
+		}
+		want := covariance(wx, wy) / covariance(wx, wx)
+		end := i + period - 1
+		testutil.Near(t, fmt.Sprintf("rolling ratio %d", end), ratio, want, 1e-9)
+		testutil.Near(t, fmt.Sprintf("rolling intercept %d", end), rolling.Intercepts[i], my-want*mx, 1e-9)
+		testutil.Near(t, fmt.Sprintf("rolling spread %d", end), rolling.Spreads[i], y[end]-(my-want*mx)-want*x[end], 1e-9)
+	}
+
+	zscores, err := pairs.ZScore(rolling.Spreads, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, zscore := range zscores.Values {
+		window := rolling.Spreads[i : i+20]
+		mean := 0.0
+		for _, s := range window {
+			mean += s / 20
+		}
+		testutil.Near(t, fmt.Sprintf("z-score %d", i+19), zscore, (window[19]-mean)/math.Sqrt(covariance(window, window)), 1e-9)
+	}
+
+	if _, err := pairs.RollingHedgeRatio(y, x[1:], period); !errors.Is(err, utils.ErrMismatchedInputLengths) {
+		t.Errorf("legs of different lengths: %v", err)
+	}
+}
+
+// The Kalman filter converges to the ratio, with forecast errors of about
+// the deviation of the spread
+func TestKalmanHedgeRatio(t *testing.T) {
+	const n = 2000
+	x, y, _ := syntheticPair(n)
+	kalman, err := pairs.KalmanHedgeRatio(y, x, 1e-5, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.Near(t, "Kalman ratio", kalman.Values[n-1], 1.5, 0.05)
+	var squares float64
+	for i := n / 2; i < n; i++ {
+		zscore := kalman.Spreads[i] / kalman.Deviations[i]
+		squares += zscore * zscore / (n / 2)
+	}
+	testutil.Near(t, "Kalman z-score variance", squares, 1, 0.3)
+
+	if _, err := pairs.KalmanHedgeRatio(y, x, 1, 1); !errors.Is(err, utils.ErrInvalidParameter) {
+		t.Errorf("Kalman delta 1: %v", err)
+	}
+}
+
+// Pairs from feeds keep the common timestamps only
+func TestLoadPair(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	var withGaps sliceFeed
+	for i, d := range data {
+		if i%5 != 0 {
+			withGaps = append(withGaps, d)
+		}
+	}
+	start, end := time.Unix(data[0].Time, 0), time.Unix(data[len(data)-1].Time, 0)
+	pair, err := pairs.LoadPair(sliceFeed(data), withGaps, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pair.Time) != len(withGaps) || pair.Time[0] != withGaps[0].Time || pair.Y[0] != withGaps[0].Close || pair.X[0] != withGaps[0].Close {
+		t.Errorf("pair of %d bars from %d, want %d", len(pair.Time), pair.Time[0], len(withGaps))
+	}
+
+	if _, err := pairs.NewPair(data[:10], data[10:20]); !errors.Is(err, utils.ErrEmptyInputData) {
+		t.Errorf("legs without common bars: %v", err)
+	}
+}
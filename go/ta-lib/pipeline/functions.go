@@ -0,0 +1,800 @@
+package pipeline
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/petercool/ta-lib/go/ta-lib/indicators"
+	"github.com/petercool/ta-lib/go/ta-lib/stream"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// paramKind is the type of an optional input of a TA-Lib function
+type paramKind int
+
+const (
+	intParam paramKind = iota
+	floatParam
+	maTypeParam
+)
+
+// param describes an optional input, named as in the C API without the optIn prefix
+type param struct {
+	name string
+	kind paramKind
+	def  float64
+}
+
+// args holds the resolved optional inputs of a call, in TA-Lib order
+type args []float64
+
+func (a args) int(i int) int             { return int(a[i]) }
+func (a args) maType(i int) utils.MAType { return utils.MAType(a[i]) }
+
+// updateFunc advances the streaming form of a node with the current values
+// of its inputs, writing the outputs when they are ready
+type updateFunc func(in, out []float64) bool
+
+// barUpdateFunc advances the streaming form of a TA-Lib function with its
+// inputs in the fields of a bar. Real inputs are passed in the Close field.
+type barUpdateFunc func(bar utils.OHLCV, out []float64) bool
+
+// function describes a TA-Lib function as in ta_func_api.xml, or an operator
+type function struct {
+	name      string
+	inputs    []string // Real, Open, High, Low, Close or Volume
+	params    []param
+	outputs   []string
+	lookback  func(a args) int
+	batch     func(in [][]float64, a args) (int, [][]float64, error)
+	stream    func(a args) (updateFunc, error)
+	barStream func(a args) (barUpdateFunc, error)  // Set instead of stream by TA-Lib functions
+	format    func(inputs []string, a args) string // Canonical text, call syntax when nil
+}
+
+var (
+	inReal  = []string{"Real"}
+	inHL    = []string{"High", "Low"}
+	inHLC   = []string{"High", "Low", "Close"}
+	inHLCV  = []string{"High", "Low", "Close", "Volume"}
+	inOHLC  = []string{"Open", "High", "Low", "Close"}
+	outReal = []string{"Real"}
+)
+
+func timePeriod(def float64) param {
+	return param{name: "TimePeriod", kind: intParam, def: def}
+}
+
+var maType = param{name: "MAType", kind: maTypeParam}
+
+// result adapts a single output batch function
+func result(r *utils.Result, err error) (int, [][]float64, error) {
+	if err != nil {
+		return 0, nil, err
+	}
+	return r.BeginIndex, [][]float64{r.Values}, nil
+}
+
+// valueUpdater is a streaming indicator on a single input series
+type valueUpdater interface {
+	UpdateValue(v float64) (float64, bool)
+}
+
+// value adapts a streaming indicator on the Real input
+func value(ind valueUpdater, err error) (barUpdateFunc, error) {
+	if err != nil {
+		return nil, err
+	}
+	return func(bar utils.OHLCV, out []float64) bool {
+		v, ok := ind.UpdateValue(bar.Close)
+		out[0] = v
+		return ok
+	}, nil
+}
+
+// single adapts a streaming indicator with a single output
+func single(ind stream.Indicator, err error) (barUpdateFunc, error) {
+	if err != nil {
+		return nil, err
+	}
+	return func(bar utils.OHLCV, out []float64) bool {
+		v, ok := ind.Update(bar)
+		out[0] = v
+		return ok
+	}, nil
+}
+
+// multi adapts a streaming indicator with several outputs
+func multi[T any](update func(utils.OHLCV) (T, bool), outs func(v T, out []float64)) barUpdateFunc {
+	return func(bar utils.OHLCV, out []float64) bool {
+		v, ok := update(bar)
+		if ok {
+			outs(v, out)
+		}
+		return ok
+	}
+}
+
+// price adapts a price transform, which has no lookback
+func price(f func(bar utils.OHLCV) float64) func(a args) (barUpdateFunc, error) {
+	return func(a args) (barUpdateFunc, error) {
+		return func(bar utils.OHLCV, out []float64) bool {
+			out[0] = f(bar)
+			return true
+		}, nil
+	}
+}
+
+func noLookback(a args) int { return 0 }
+
+// movingAverage describes the single period moving averages
+func movingAverage(name string, lookback func(int) int, batch func([]float64, int) (*utils.Result, error), newStream func(int) (valueUpdater, error)) *function {
+	return &function{
+		name:     name,
+		inputs:   inReal,
+		params:   []param{timePeriod(30)},
+		outputs:  outReal,
+		lookback: func(a args) int { return lookback(a.int(0)) },
+		batch: func(in [][]float64, a args) (int, [][]float64, error) {
+			return result(batch(in[0], a.int(0)))
+		},
+		barStream: func(a args) (barUpdateFunc, error) { return value(newStream(a.int(0))) },
+	}
+}
+
+// hilbert describes the single output Hilbert transform functions, which
+// have no optional input
+func hilbert(name string, lookback func() int, batch func([]float64) (*utils.Result, error), newStream func() valueUpdater) *function {
+	return &function{
+		name:     name,
+		inputs:   inReal,
+		outputs:  outReal,
+		lookback: func(a args) int { return lookback() },
+		batch: func(in [][]float64, a args) (int, [][]float64, error) {
+			return result(batch(in[0]))
+		},
+		barStream: func(a args) (barUpdateFunc, error) { return value(newStream(), nil) },
+	}
+}
+
+// functions are the TA-Lib functions available to pipelines, by name
+var functions = map[string]*function{}
+
+func register(fns ...*function) {
+	for _, fn := range fns {
+		fn.stream = barInputs(fn)
+		functions[fn.name] = fn
+	}
+}
+
+// barInputs passes the inputs of a TA-Lib function to its streaming form in
+// the fields of a bar
+func barInputs(fn *function) func(a args) (updateFunc, error) {
+	return func(a args) (updateFunc, error) {
+		update, err := fn.barStream(a)
+		if err != nil {
+			return nil, err
+		}
+		return func(in, out []float64) bool {
+			var bar utils.OHLCV
+			for k, v := range in {
+				setInput(&bar, fn.inputs[k], v)
+			}
+			return update(bar, out)
+		}, nil
+	}
+}
+
+// setInput stores an input of a function in the bar passed to its streaming form
+func setInput(bar *utils.OHLCV, name string, v float64) {
+	switch name {
+	case "Open":
+		bar.Open = v
+	case "High":
+		bar.High = v
+	case "Low":
+		bar.Low = v
+	case "Volume":
+		bar.Volume = v
+	default:
+		bar.Close = v
+	}
+}
+
+func init() {
+	register(
+		movingAverage("SMA", indicators.SMALookback, indicators.SMA, func(p int) (valueUpdater, error) { return stream.NewSMA(p) }),
+		movingAverage("EMA", indicators.EMALookback, indicators.EMA, func(p int) (valueUpdater, error) { return stream.NewEMA(p) }),
+		movingAverage("WMA", indicators.WMALookback, indicators.WMA, func(p int) (valueUpdater, error) { return stream.NewWMA(p) }),
+		movingAverage("DEMA", indicators.DEMALookback, indicators.DEMA, func(p int) (valueUpdater, error) { return stream.NewDEMA(p) }),
+		movingAverage("TEMA", indicators.TEMALookback, indicators.TEMA, func(p int) (valueUpdater, error) { return stream.NewTEMA(p) }),
+		movingAverage("TRIMA", indicators.TRIMALookback, indicators.TRIMA, func(p int) (valueUpdater, error) { return stream.NewTRIMA(p) }),
+		movingAverage("KAMA", indicators.KAMALookback, indicators.KAMA, func(p int) (valueUpdater, error) { return stream.NewKAMA(p) }),
+		&function{
+			name:     "MA",
+			inputs:   inReal,
+			params:   []param{timePeriod(30), maType},
+			outputs:  outReal,
+			lookback: func(a args) int { return indicators.MALookback(a.int(0), a.maType(1)) },
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				r, err := indicators.MA(in[0], a.int(0), a.maType(1))
+				if err != nil {
+					return 0, nil, err
+				}
+				return result(&r.Result, nil)
+			},
+			barStream: func(a args) (barUpdateFunc, error) { return value(stream.NewMA(a.int(0), a.maType(1))) },
+		},
+		&function{
+			name:    "MAMA",
+			inputs:  inReal,
+			params:  []param{{name: "FastLimit", kind: floatParam, def: 0.5}, {name: "SlowLimit", kind: floatParam, def: 0.05}},
+			outputs: []string{"MAMA", "FAMA"},
+			lookback: func(a args) int {
+				return indicators.MAMALookback(a[0], a[1])
+			},
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				r, err := indicators.MAMA(in[0], a[0], a[1])
+				if err != nil {
+					return 0, nil, err
+				}
+				return r.BeginIndex, [][]float64{r.Values, r.FAMA}, nil
+			},
+			barStream: func(a args) (barUpdateFunc, error) {
+				m, err := stream.NewMAMA(a[0], a[1])
+				if err != nil {
+					return nil, err
+				}
+				return multi(m.Update, func(v stream.MAMAValue, out []float64) {
+					out[0], out[1] = v.MAMA, v.FAMA
+				}), nil
+			},
+		},
+		&function{
+			name:     "T3",
+			inputs:   inReal,
+			params:   []param{timePeriod(5), {name: "VFactor", kind: floatParam, def: 0.7}},
+			outputs:  outReal,
+			lookback: func(a args) int { return indicators.T3Lookback(a.int(0), a[1]) },
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.T3(in[0], a.int(0), a[1]))
+			},
+			barStream: func(a args) (barUpdateFunc, error) { return value(stream.NewT3(a.int(0), a[1])) },
+		},
+		movingAverage("HMA", indicators.HMALookback, indicators.HMA, func(p int) (valueUpdater, error) { return stream.NewHMA(p) }),
+		movingAverage("ZLEMA", indicators.ZLEMALookback, indicators.ZLEMA, func(p int) (valueUpdater, error) { return stream.NewZLEMA(p) }),
+		movingAverage("SMMA", indicators.SMMALookback, indicators.SMMA, func(p int) (valueUpdater, error) { return stream.NewSMMA(p) }),
+		movingAverage("MCGINLEY", indicators.MCGINLEYLookback, indicators.MCGINLEY, func(p int) (valueUpdater, error) { return stream.NewMCGINLEY(p) }),
+		&function{
+			name:     "ALMA",
+			inputs:   inReal,
+			params:   []param{timePeriod(9), {name: "Offset", kind: floatParam, def: 0.85}, {name: "Sigma", kind: floatParam, def: 6}},
+			outputs:  outReal,
+			lookback: func(a args) int { return indicators.ALMALookback(a.int(0)) },
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.ALMA(in[0], a.int(0), a[1], a[2]))
+			},
+			barStream: func(a args) (barUpdateFunc, error) { return value(stream.NewALMA(a.int(0), a[1], a[2])) },
+		},
+		&function{
+			name:     "VWMA",
+			inputs:   []string{"Real", "Volume"},
+			params:   []param{timePeriod(20)},
+			outputs:  outReal,
+			lookback: func(a args) int { return indicators.VWMALookback(a.int(0)) },
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.VWMA(in[0], in[1], a.int(0)))
+			},
+			barStream: func(a args) (barUpdateFunc, error) { return single(stream.NewVWMA(a.int(0))) },
+		},
+		&function{
+			name:     "RSI",
+			inputs:   inReal,
+			params:   []param{timePeriod(14)},
+			outputs:  outReal,
+			lookback: func(a args) int { return indicators.RSILookback(a.int(0)) },
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.RSI(in[0], a.int(0)))
+			},
+			barStream: func(a args) (barUpdateFunc, error) { return value(stream.NewRSI(a.int(0))) },
+		},
+		&function{
+			name:   "MACD",
+			inputs: inReal,
+			params: []param{
+				{name: "FastPeriod", kind: intParam, def: 12},
+				{name: "SlowPeriod", kind: intParam, def: 26},
+				{name: "SignalPeriod", kind: intParam, def: 9},
+			},
+			outputs: []string{"MACD", "MACDSignal", "MACDHist"},
+			lookback: func(a args) int {
+				return indicators.MACDLookback(a.int(0), a.int(1), a.int(2))
+			},
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				r, err := indicators.MACD(in[0], a.int(0), a.int(1), a.int(2))
+				if err != nil {
+					return 0, nil, err
+				}
+				return r.BeginIndex, [][]float64{r.Values, r.MACDSignal, r.MACDHist}, nil
+			},
+			barStream: func(a args) (barUpdateFunc, error) {
+				m, err := stream.NewMACD(a.int(0), a.int(1), a.int(2))
+				if err != nil {
+					return nil, err
+				}
+				return multi(m.Update, func(v stream.MACDValue, out []float64) {
+					out[0], out[1], out[2] = v.MACD, v.Signal, v.Hist
+				}), nil
+			},
+		},
+		&function{
+			name:   "MACDEXT",
+			inputs: inReal,
+			params: []param{
+				{name: "FastPeriod", kind: intParam, def: 12},
+				{name: "FastMAType", kind: maTypeParam},
+				{name: "SlowPeriod", kind: intParam, def: 26},
+				{name: "SlowMAType", kind: maTypeParam},
+				{name: "SignalPeriod", kind: intParam, def: 9},
+				{name: "SignalMAType", kind: maTypeParam},
+			},
+			outputs: []string{"MACD", "MACDSignal", "MACDHist"},
+			lookback: func(a args) int {
+				return indicators.MACDEXTLookback(a.int(0), a.maType(1), a.int(2), a.maType(3), a.int(4), a.maType(5))
+			},
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				r, err := indicators.MACDEXT(in[0], a.int(0), a.maType(1), a.int(2), a.maType(3), a.int(4), a.maType(5))
+				if err != nil {
+					return 0, nil, err
+				}
+				return r.BeginIndex, [][]float64{r.Values, r.MACDSignal, r.MACDHist}, nil
+			},
+			barStream: func(a args) (barUpdateFunc, error) {
+				m, err := stream.NewMACDEXT(a.int(0), a.maType(1), a.int(2), a.maType(3), a.int(4), a.maType(5))
+				if err != nil {
+					return nil, err
+				}
+				return multi(m.Update, func(v stream.MACDValue, out []float64) {
+					out[0], out[1], out[2] = v.MACD, v.Signal, v.Hist
+				}), nil
+			},
+		},
+		&function{
+			name:   "STOCH",
+			inputs: inHLC,
+			params: []param{
+				{name: "FastK_Period", kind: intParam, def: 5},
+				{name: "SlowK_Period", kind: intParam, def: 3},
+				{name: "SlowK_MAType", kind: maTypeParam},
+				{name: "SlowD_Period", kind: intParam, def: 3},
+				{name: "SlowD_MAType", kind: maTypeParam},
+			},
+			outputs: []string{"SlowK", "SlowD"},
+			lookback: func(a args) int {
+				return indicators.STOCHLookback(a.int(0), a.int(1), a.maType(2), a.int(3), a.maType(4))
+			},
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				r, err := indicators.STOCH(in[0], in[1], in[2], a.int(0), a.int(1), a.int(3), a.maType(2), a.maType(4))
+				if err != nil {
+					return 0, nil, err
+				}
+				return r.BeginIndex, [][]float64{r.SlowK, r.SlowD}, nil
+			},
+			barStream: func(a args) (barUpdateFunc, error) {
+				s, err := stream.NewSTOCH(a.int(0), a.int(1), a.int(3), a.maType(2), a.maType(4))
+				if err != nil {
+					return nil, err
+				}
+				return multi(s.Update, func(v stream.STOCHValue, out []float64) {
+					out[0], out[1] = v.SlowK, v.SlowD
+				}), nil
+			},
+		},
+		&function{
+			name:   "STOCHF",
+			inputs: inHLC,
+			params: []param{
+				{name: "FastK_Period", kind: intParam, def: 5},
+				{name: "FastD_Period", kind: intParam, def: 3},
+				{name: "FastD_MAType", kind: maTypeParam},
+			},
+			outputs: []string{"FastK", "FastD"},
+			lookback: func(a args) int {
+				return indicators.STOCHFLookback(a.int(0), a.int(1), a.maType(2))
+			},
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				r, err := indicators.STOCHF(in[0], in[1], in[2], a.int(0), a.int(1), a.maType(2))
+				if err != nil {
+					return 0, nil, err
+				}
+				return r.BeginIndex, [][]float64{r.FastK, r.FastD}, nil
+			},
+			barStream: func(a args) (barUpdateFunc, error) {
+				s, err := stream.NewSTOCHF(a.int(0), a.int(1), a.maType(2))
+				if err != nil {
+					return nil, err
+				}
+				return multi(s.Update, func(v stream.STOCHFValue, out []float64) {
+					out[0], out[1] = v.FastK, v.FastD
+				}), nil
+			},
+		},
+		&function{
+			name:   "STOCHRSI",
+			inputs: inReal,
+			params: []param{
+				timePeriod(14),
+				{name: "FastK_Period", kind: intParam, def: 5},
+				{name: "FastD_Period", kind: intParam, def: 3},
+				{name: "FastD_MAType", kind: maTypeParam},
+			},
+			outputs: []string{"FastK", "FastD"},
+			lookback: func(a args) int {
+				return indicators.STOCHRSILookback(a.int(0), a.int(1), a.int(2), a.maType(3))
+			},
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				fastK, fastD, err := indicators.STOCHRSI(in[0], a.int(0), a.int(1), a.int(2), a.maType(3))
+				if err != nil {
+					return 0, nil, err
+				}
+				return fastK.BeginIndex, [][]float64{fastK.Values, fastD.Values}, nil
+			},
+			barStream: func(a args) (barUpdateFunc, error) {
+				s, err := stream.NewSTOCHRSI(a.int(0), a.int(1), a.int(2), a.maType(3))
+				if err != nil {
+					return nil, err
+				}
+				return multi(s.Update, func(v stream.STOCHFValue, out []float64) {
+					out[0], out[1] = v.FastK, v.FastD
+				}), nil
+			},
+		},
+		&function{
+			name:     "ROC",
+			inputs:   inReal,
+			params:   []param{timePeriod(10)},
+			outputs:  outReal,
+			lookback: func(a args) int { return indicators.ROCLookback(a.int(0)) },
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.ROC(in[0], a.int(0)))
+			},
+			barStream: func(a args) (barUpdateFunc, error) { return value(stream.NewROC(a.int(0))) },
+		},
+		&function{
+			name:     "CCI",
+			inputs:   inHLC,
+			params:   []param{timePeriod(14)},
+			outputs:  outReal,
+			lookback: func(a args) int { return indicators.CCILookback(a.int(0)) },
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.CCI(in[0], in[1], in[2], a.int(0)))
+			},
+			barStream: func(a args) (barUpdateFunc, error) { return single(stream.NewCCI(a.int(0))) },
+		},
+		&function{
+			name:     "WILLR",
+			inputs:   inHLC,
+			params:   []param{timePeriod(14)},
+			outputs:  outReal,
+			lookback: func(a args) int { return indicators.WILLRLookback(a.int(0)) },
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.WILLR(in[0], in[1], in[2], a.int(0)))
+			},
+			barStream: func(a args) (barUpdateFunc, error) { return single(stream.NewWILLR(a.int(0))) },
+		},
+		&function{
+			name:     "PLUS_DI",
+			inputs:   inHLC,
+			params:   []param{timePeriod(14)},
+			outputs:  outReal,
+			lookback: func(a args) int { return indicators.PLUSDILookback(a.int(0)) },
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.PLUS_DI(in[0], in[1], in[2], a.int(0)))
+			},
+			barStream: func(a args) (barUpdateFunc, error) { return single(stream.NewPLUS_DI(a.int(0))) },
+		},
+		&function{
+			name:     "MINUS_DI",
+			inputs:   inHLC,
+			params:   []param{timePeriod(14)},
+			outputs:  outReal,
+			lookback: func(a args) int { return indicators.MINUSDILookback(a.int(0)) },
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.MINUS_DI(in[0], in[1], in[2], a.int(0)))
+			},
+			barStream: func(a args) (barUpdateFunc, error) { return single(stream.NewMINUS_DI(a.int(0))) },
+		},
+		&function{
+			name:     "DX",
+			inputs:   inHLC,
+			params:   []param{timePeriod(14)},
+			outputs:  outReal,
+			lookback: func(a args) int { return indicators.DXLookback(a.int(0)) },
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.DX(in[0], in[1], in[2], a.int(0)))
+			},
+			barStream: func(a args) (barUpdateFunc, error) { return single(stream.NewDX(a.int(0))) },
+		},
+		&function{
+			name:     "ADX",
+			inputs:   inHLC,
+			params:   []param{timePeriod(14)},
+			outputs:  outReal,
+			lookback: func(a args) int { return indicators.ADXLookback(a.int(0)) },
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				r, err := indicators.ADX(in[0], in[1], in[2], a.int(0))
+				if err != nil {
+					return 0, nil, err
+				}
+				return result(&r.Result, nil)
+			},
+			barStream: func(a args) (barUpdateFunc, error) {
+				s, err := stream.NewADX(a.int(0))
+				if err != nil {
+					return nil, err
+				}
+				return multi(s.Update, func(v stream.ADXValue, out []float64) { out[0] = v.ADX }), nil
+			},
+		},
+		&function{
+			name:   "APO",
+			inputs: inReal,
+			params: []param{
+				{name: "FastPeriod", kind: intParam, def: 12},
+				{name: "SlowPeriod", kind: intParam, def: 26},
+				maType,
+			},
+			outputs: outReal,
+			lookback: func(a args) int {
+				return indicators.APOLookback(a.int(0), a.int(1), a.maType(2))
+			},
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.APO(in[0], a.int(0), a.int(1), a.maType(2)))
+			},
+			barStream: func(a args) (barUpdateFunc, error) {
+				return single(stream.NewAPO(a.int(0), a.int(1), a.maType(2)))
+			},
+		},
+		&function{
+			name:   "PPO",
+			inputs: inReal,
+			params: []param{
+				{name: "FastPeriod", kind: intParam, def: 12},
+				{name: "SlowPeriod", kind: intParam, def: 26},
+				maType,
+			},
+			outputs: outReal,
+			lookback: func(a args) int {
+				return indicators.PPOLookback(a.int(0), a.int(1), a.maType(2))
+			},
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.PPO(in[0], a.int(0), a.int(1), a.maType(2)))
+			},
+			barStream: func(a args) (barUpdateFunc, error) {
+				return single(stream.NewPPO(a.int(0), a.int(1), a.maType(2)))
+			},
+		},
+		&function{
+			name:     "TRANGE",
+			inputs:   inHLC,
+			outputs:  outReal,
+			lookback: func(a args) int { return indicators.TRANGELookback() },
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.TRANGE(in[0], in[1], in[2]))
+			},
+			barStream: func(a args) (barUpdateFunc, error) { return single(stream.NewTRANGE(), nil) },
+		},
+		&function{
+			name:     "ATR",
+			inputs:   inHLC,
+			params:   []param{timePeriod(14)},
+			outputs:  outReal,
+			lookback: func(a args) int { return indicators.ATRLookback(a.int(0)) },
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.ATR(in[0], in[1], in[2], a.int(0)))
+			},
+			barStream: func(a args) (barUpdateFunc, error) { return single(stream.NewATR(a.int(0))) },
+		},
+		&function{
+			name:     "VAR",
+			inputs:   inReal,
+			params:   []param{timePeriod(5), {name: "NbDev", kind: floatParam, def: 1}},
+			outputs:  outReal,
+			lookback: func(a args) int { return indicators.VARLookback(a.int(0)) },
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.VAR(in[0], a.int(0), a[1]))
+			},
+			barStream: func(a args) (barUpdateFunc, error) { return value(stream.NewVAR(a.int(0), a[1])) },
+		},
+		&function{
+			name:     "STDDEV",
+			inputs:   inReal,
+			params:   []param{timePeriod(5), {name: "NbDev", kind: floatParam, def: 1}},
+			outputs:  outReal,
+			lookback: func(a args) int { return indicators.STDDEVLookback(a.int(0)) },
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.STDDEV(in[0], a.int(0), a[1]))
+			},
+			barStream: func(a args) (barUpdateFunc, error) { return value(stream.NewSTDDEV(a.int(0), a[1])) },
+		},
+		&function{
+			name:   "BBANDS",
+			inputs: inReal,
+			params: []param{
+				timePeriod(5),
+				{name: "NbDevUp", kind: floatParam, def: 2},
+				{name: "NbDevDn", kind: floatParam, def: 2},
+				maType,
+			},
+			outputs: []string{"RealUpperBand", "RealMiddleBand", "RealLowerBand"},
+			lookback: func(a args) int {
+				return indicators.BBANDSLookback(a.int(0), a.maType(3))
+			},
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				r, err := indicators.BBANDS(in[0], a.int(0), a[1], a[2], a.maType(3))
+				if err != nil {
+					return 0, nil, err
+				}
+				return r.BeginIndex, [][]float64{r.UpperBand, r.Values, r.LowerBand}, nil
+			},
+			barStream: func(a args) (barUpdateFunc, error) {
+				b, err := stream.NewBBANDS(a.int(0), a[1], a[2], a.maType(3))
+				if err != nil {
+					return nil, err
+				}
+				return multi(b.Update, func(v stream.BBANDSValue, out []float64) {
+					out[0], out[1], out[2] = v.Upper, v.Middle, v.Lower
+				}), nil
+			},
+		},
+		hilbert("HT_DCPERIOD", indicators.HTDCPERIODLookback, indicators.HT_DCPERIOD, func() valueUpdater { return stream.NewHT_DCPERIOD() }),
+		hilbert("HT_DCPHASE", indicators.HTDCPHASELookback, indicators.HT_DCPHASE, func() valueUpdater { return stream.NewHT_DCPHASE() }),
+		hilbert("HT_TRENDLINE", indicators.HTTRENDLINELookback, indicators.HT_TRENDLINE, func() valueUpdater { return stream.NewHT_TRENDLINE() }),
+		hilbert("HT_TRENDMODE", indicators.HTTRENDMODELookback, indicators.HT_TRENDMODE, func() valueUpdater { return stream.NewHT_TRENDMODE() }),
+		&function{
+			name:     "HT_PHASOR",
+			inputs:   inReal,
+			outputs:  []string{"InPhase", "Quadrature"},
+			lookback: func(a args) int { return indicators.HTPHASORLookback() },
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				r, err := indicators.HT_PHASOR(in[0])
+				if err != nil {
+					return 0, nil, err
+				}
+				return r.BeginIndex, [][]float64{r.Values, r.Quadrature}, nil
+			},
+			barStream: func(a args) (barUpdateFunc, error) {
+				return multi(stream.NewHT_PHASOR().Update, func(v stream.HT_PHASORValue, out []float64) {
+					out[0], out[1] = v.InPhase, v.Quadrature
+				}), nil
+			},
+		},
+		&function{
+			name:     "HT_SINE",
+			inputs:   inReal,
+			outputs:  []string{"Sine", "LeadSine"},
+			lookback: func(a args) int { return indicators.HTSINELookback() },
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				r, err := indicators.HT_SINE(in[0])
+				if err != nil {
+					return 0, nil, err
+				}
+				return r.BeginIndex, [][]float64{r.Values, r.LeadSine}, nil
+			},
+			barStream: func(a args) (barUpdateFunc, error) {
+				return multi(stream.NewHT_SINE().Update, func(v stream.HT_SINEValue, out []float64) {
+					out[0], out[1] = v.Sine, v.LeadSine
+				}), nil
+			},
+		},
+		&function{
+			name:     "OBV",
+			inputs:   []string{"Real", "Volume"},
+			outputs:  outReal,
+			lookback: func(a args) int { return indicators.OBVLookback() },
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.OBV(in[0], in[1]))
+			},
+			barStream: func(a args) (barUpdateFunc, error) { return single(stream.NewOBV(), nil) },
+		},
+		&function{
+			name:     "AD",
+			inputs:   inHLCV,
+			outputs:  outReal,
+			lookback: func(a args) int { return indicators.ADLookback() },
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.AD(in[0], in[1], in[2], in[3]))
+			},
+			barStream: func(a args) (barUpdateFunc, error) { return single(stream.NewAD(), nil) },
+		},
+		&function{
+			name:     "MFI",
+			inputs:   inHLCV,
+			params:   []param{timePeriod(14)},
+			outputs:  outReal,
+			lookback: func(a args) int { return indicators.MFILookback(a.int(0)) },
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.MFI(in[0], in[1], in[2], in[3], a.int(0)))
+			},
+			barStream: func(a args) (barUpdateFunc, error) { return single(stream.NewMFI(a.int(0))) },
+		},
+		&function{
+			name:     "AVGPRICE",
+			inputs:   inOHLC,
+			outputs:  outReal,
+			lookback: noLookback,
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.AVGPRICE(in[0], in[1], in[2], in[3]))
+			},
+			barStream: price(func(b utils.OHLCV) float64 { return (b.High + b.Low + b.Close + b.Open) / 4 }),
+		},
+		&function{
+			name:     "MEDPRICE",
+			inputs:   inHL,
+			outputs:  outReal,
+			lookback: noLookback,
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.MEDPRICE(in[0], in[1]))
+			},
+			barStream: price(func(b utils.OHLCV) float64 { return (b.High + b.Low) / 2.0 }),
+		},
+		&function{
+			name:     "TYPPRICE",
+			inputs:   inHLC,
+			outputs:  outReal,
+			lookback: noLookback,
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.TYPPRICE(in[0], in[1], in[2]))
+			},
+			barStream: price(func(b utils.OHLCV) float64 { return (b.High + b.Low + b.Close) / 3.0 }),
+		},
+		&function{
+			name:     "WCLPRICE",
+			inputs:   inHLC,
+			outputs:  outReal,
+			lookback: noLookback,
+			batch: func(in [][]float64, a args) (int, [][]float64, error) {
+				return result(indicators.WCLPRICE(in[0], in[1], in[2]))
+			},
+			barStream: price(func(b utils.OHLCV) float64 { return (b.High + b.Low + (b.Close * 2.0)) / 4.0 }),
+		},
+	)
+}
+
+// lookupFunction finds a function by its TA-Lib name, ignoring case
+func lookupFunction(name string) (*function, bool) {
+	fn, ok := functions[strings.ToUpper(name)]
+	return fn, ok
+}
+
+// Functions returns the names of the TA-Lib functions available to pipelines
+func Functions() []string {
+	names := make([]string, 0, len(functions))
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FunctionInfo describes the inputs, optional inputs and outputs of a
+// function, in TA-Lib order and with the TA-Lib names
+type FunctionInfo struct {
+	Name    string
+	Inputs  []string
+	Params  []string
+	Outputs []string
+}
+
+// Describe returns the description of a function by its TA-Lib name
+func Describe(name string) (FunctionInfo, bool) {
+	fn, ok := lookupFunction(name)
+	if !ok {
+		return FunctionInfo{}, false
+	}
+	info := FunctionInfo{Name: fn.name, Inputs: fn.inputs, Outputs: fn.outputs}
+	for _, p := range fn.params {
+		info.Params = append(info.Params, p.name)
+	}
+	return info, true
+}
@@ -0,0 +1,212 @@
+package pipeline
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Op is an element-wise operator on two aligned series. Comparisons and
+// logical operators give 1 for true and 0 for false, any non-zero input is
+// true.
+type Op int
+
+const (
+	OpAdd          Op = iota // a + b
+	OpSub                    // a - b
+	OpMul                    // a * b
+	OpDiv                    // a / b
+	OpLess                   // a < b
+	OpLessEqual              // a <= b
+	OpGreater                // a > b
+	OpGreaterEqual           // a >= b
+	OpEqual                  // a == b
+	OpNotEqual               // a != b
+	OpAnd                    // a AND b
+	OpOr                     // a OR b
+)
+
+// boolValue converts a truth value to 1 or 0
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// binaryOps are the functions of the operators, by Op
+var binaryOps = []*function{
+	binary("ADD", "+", func(a, b float64) float64 { return a + b }),
+	binary("SUB", "-", func(a, b float64) float64 { return a - b }),
+	binary("MULT", "*", func(a, b float64) float64 { return a * b }),
+	binary("DIV", "/", func(a, b float64) float64 { return a / b }),
+	binary("LT", "<", func(a, b float64) float64 { return boolValue(a < b) }),
+	binary("LE", "<=", func(a, b float64) float64 { return boolValue(a <= b) }),
+	binary("GT", ">", func(a, b float64) float64 { return boolValue(a > b) }),
+	binary("GE", ">=", func(a, b float64) float64 { return boolValue(a >= b) }),
+	binary("EQ", "==", func(a, b float64) float64 { return boolValue(a == b) }),
+	binary("NE", "!=", func(a, b float64) float64 { return boolValue(a != b) }),
+	binary("AND", "AND", func(a, b float64) float64 { return boolValue(a != 0 && b != 0) }),
+	binary("OR", "OR", func(a, b float64) float64 { return boolValue(a != 0 || b != 0) }),
+}
+
+// elementWise describes an operator computed bar by bar, without lookback
+func elementWise(name string, inputs []string, f func(in []float64) float64, format func(inputs []string, a args) string) *function {
+	return &function{
+		name:     name,
+		inputs:   inputs,
+		outputs:  outReal,
+		lookback: noLookback,
+		batch: func(in [][]float64, a args) (int, [][]float64, error) {
+			out := make([]float64, len(in[0]))
+			values := make([]float64, len(in))
+			for i := range out {
+				for k := range in {
+					values[k] = in[k][i]
+				}
+				out[i] = f(values)
+			}
+			return 0, [][]float64{out}, nil
+		},
+		stream: func(a args) (updateFunc, error) {
+			return func(in, out []float64) bool {
+				out[0] = f(in)
+				return true
+			}, nil
+		},
+		format: format,
+	}
+}
+
+func binary(name, symbol string, f func(a, b float64) float64) *function {
+	return elementWise(name, []string{"Real0", "Real1"},
+		func(in []float64) float64 { return f(in[0], in[1]) },
+		func(inputs []string, a args) string { return "(" + inputs[0] + " " + symbol + " " + inputs[1] + ")" })
+}
+
+var (
+	notOp = elementWise("NOT", inReal,
+		func(in []float64) float64 { return boolValue(in[0] == 0) },
+		func(inputs []string, a args) string { return "NOT " + inputs[0] })
+	negOp = elementWise("NEG", inReal,
+		func(in []float64) float64 { return -in[0] },
+		func(inputs []string, a args) string { return "-" + inputs[0] })
+)
+
+// Binary applies an element-wise operator to two series
+func Binary(op Op, a, b Expr) Expr {
+	if op < OpAdd || op > OpOr {
+		return Expr{call: &call{key: "?", err: fmt.Errorf("unknown operator %d: %w", op, utils.ErrInvalidParameter)}}
+	}
+	return apply(binaryOps[op], nil, []Expr{a, b})
+}
+
+// Not gives 1 where the series is zero and 0 elsewhere
+func Not(e Expr) Expr {
+	return apply(notOp, nil, []Expr{e})
+}
+
+// Neg negates a series
+func Neg(e Expr) Expr {
+	return apply(negOp, nil, []Expr{e})
+}
+
+// Const returns a series with the same value on every bar
+func Const(v float64) Expr {
+	return Expr{call: &call{constant: true, value: v, key: strconv.FormatFloat(v, 'g', -1, 64)}}
+}
+
+// refOp gives the value of a series a number of bars earlier
+var refOp = &function{
+	name:     "REF",
+	inputs:   inReal,
+	params:   []param{{name: "Period", kind: intParam}},
+	outputs:  outReal,
+	lookback: func(a args) int { return a.int(0) },
+	batch: func(in [][]float64, a args) (int, [][]float64, error) {
+		n := a.int(0)
+		if err := utils.CheckPeriod("REF period", n, 0); err != nil {
+			return 0, nil, err
+		}
+		if len(in[0]) <= n {
+			return 0, [][]float64{nil}, nil
+		}
+		out := make([]float64, len(in[0])-n)
+		copy(out, in[0])
+		return n, [][]float64{out}, nil
+	},
+	stream: func(a args) (updateFunc, error) {
+		n := a.int(0)
+		if err := utils.CheckPeriod("REF period", n, 0); err != nil {
+			return nil, err
+		}
+		history := make([]float64, n+1)
+		count, next := 0, 0
+		return func(in, out []float64) bool {
+			history[next] = in[0]
+			next = (next + 1) % len(history)
+			count++
+			if count <= n {
+				return false
+			}
+			// The oldest value is the next one to be overwritten
+			out[0] = history[next]
+			return true
+		}, nil
+	},
+}
+
+// Ref returns the series n bars earlier, REF(x, 1) being the previous bar
+func Ref(e Expr, n int) Expr {
+	return apply(refOp, args{float64(n)}, []Expr{e})
+}
+
+// crossing describes CROSSOVER and CROSSUNDER, true on the bar where a
+// crosses b in the given direction
+func crossing(name string, crossed func(prevA, prevB, a, b float64) bool) *function {
+	return &function{
+		name:     name,
+		inputs:   []string{"Real0", "Real1"},
+		outputs:  outReal,
+		lookback: func(a args) int { return 1 },
+		batch: func(in [][]float64, a args) (int, [][]float64, error) {
+			if len(in[0]) < 2 {
+				return 0, [][]float64{nil}, nil
+			}
+			out := make([]float64, len(in[0])-1)
+			for i := range out {
+				out[i] = boolValue(crossed(in[0][i], in[1][i], in[0][i+1], in[1][i+1]))
+			}
+			return 1, [][]float64{out}, nil
+		},
+		stream: func(a args) (updateFunc, error) {
+			started := false
+			var prevA, prevB float64
+			return func(in, out []float64) bool {
+				ready := started
+				if ready {
+					out[0] = boolValue(crossed(prevA, prevB, in[0], in[1]))
+				}
+				started = true
+				prevA, prevB = in[0], in[1]
+				return ready
+			}, nil
+		},
+	}
+}
+
+var (
+	crossOverOp  = crossing("CROSSOVER", func(prevA, prevB, a, b float64) bool { return prevA <= prevB && a > b })
+	crossUnderOp = crossing("CROSSUNDER", func(prevA, prevB, a, b float64) bool { return prevA >= prevB && a < b })
+)
+
+// CrossOver is 1 on the bars where a moves above b, and 0 elsewhere
+func CrossOver(a, b Expr) Expr {
+	return apply(crossOverOp, nil, []Expr{a, b})
+}
+
+// CrossUnder is 1 on the bars where a moves below b, and 0 elsewhere
+func CrossUnder(a, b Expr) Expr {
+	return apply(crossUnderOp, nil, []Expr{a, b})
+}
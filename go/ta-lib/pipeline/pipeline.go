@@ -0,0 +1,485 @@
+// Package pipeline composes TA-Lib functions into graphs, such as the RSI
+// of OBV or the SMA of ATR.
+//
+// An expression applies a function, looked up by its TA-Lib name, to bar
+// fields or to the outputs of other expressions. A pipeline compiles a set
+// of expressions into a graph where identical sub-expressions are computed
+// once. The outputs of each node are aligned on the input bars, so the
+// combined lookback and the BeginIndex of every output come out of the graph
+// instead of being tracked by hand. A pipeline evaluates all bars at once
+// with Run, or one bar at a time with Stream, with the same values.
+package pipeline
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Params sets the optional inputs of a function by their TA-Lib name, such as
+// "TimePeriod", "NbDevUp" or "SlowK_MAType". Case and underscores are ignored,
+// so "timeperiod" and "slowk_matype" work too. Values are int, float64 or
+// utils.MAType, and unset inputs take the TA-Lib defaults.
+type Params map[string]any
+
+// Expr is a series computed from the bars: a bar field, a constant, or an
+// output of a TA-Lib function or an operator of other expressions. Expressions are immutable and may be
+// used in several places, they are computed once per pipeline.
+type Expr struct {
+	call   *call
+	output int
+}
+
+// call is a function applied to its inputs, shared by all its outputs
+type call struct {
+	fn       *function // nil for a bar field or a constant
+	field    utils.Field
+	constant bool
+	value    float64
+	args     args
+	inputs   []Expr
+	key      string // Canonical text, identical calls have the same key
+	err      error
+}
+
+// The bar fields used as default inputs
+var (
+	Open   = Field(utils.FieldOpen)
+	High   = Field(utils.FieldHigh)
+	Low    = Field(utils.FieldLow)
+	Close  = Field(utils.FieldClose)
+	Volume = Field(utils.FieldVolume)
+)
+
+// fieldNames are the names of the bar fields in expressions
+var fieldNames = map[utils.Field]string{
+	utils.FieldOpen:         "Open",
+	utils.FieldHigh:         "High",
+	utils.FieldLow:          "Low",
+	utils.FieldClose:        "Close",
+	utils.FieldVolume:       "Volume",
+	utils.FieldOpenInterest: "OpenInterest",
+	utils.FieldQuoteVolume:  "QuoteVolume",
+	utils.FieldTradeCount:   "TradeCount",
+	utils.FieldVWAP:         "VWAP",
+}
+
+// defaultInputs maps the TA-Lib input names onto bar fields
+var defaultInputs = map[string]Expr{
+	"Real":   Close,
+	"Open":   Open,
+	"High":   High,
+	"Low":    Low,
+	"Close":  Close,
+	"Volume": Volume,
+}
+
+// Field returns the series of a bar field
+func Field(field utils.Field) Expr {
+	c := &call{field: field, key: fieldNames[field]}
+	if c.key == "" {
+		c.err = fmt.Errorf("unknown bar field %d: %w", field, utils.ErrInvalidParameter)
+	}
+	return Expr{call: c}
+}
+
+// Call applies a TA-Lib function to the inputs. Without inputs the function
+// reads the bar fields, the close for a Real input. The expression is the
+// first output of the function, Out selects the others.
+//
+// Errors, such as an unknown function or an invalid parameter, are reported
+// by Err and Compile.
+func Call(name string, params Params, inputs ...Expr) Expr {
+	fn, ok := lookupFunction(name)
+	if !ok {
+		return Expr{call: &call{key: name, err: fmt.Errorf("unknown function %s: %w", name, utils.ErrInvalidParameter)}}
+	}
+	if len(inputs) == 0 {
+		for _, in := range fn.inputs {
+			inputs = append(inputs, defaultInputs[in])
+		}
+	}
+	a, err := resolveParams(fn, params)
+	if err != nil {
+		return Expr{call: &call{key: callKey(fn, a, inputs), err: fmt.Errorf("%s: %w", callKey(fn, a, inputs), err)}}
+	}
+	return apply(fn, a, inputs)
+}
+
+// apply creates the call of a function with resolved parameters
+func apply(fn *function, a args, inputs []Expr) Expr {
+	c := &call{fn: fn, args: a, inputs: inputs, key: callKey(fn, a, inputs)}
+	if len(inputs) != len(fn.inputs) {
+		c.err = fmt.Errorf("%d inputs, %s takes %d (%s): %w", len(inputs), fn.name, len(fn.inputs), strings.Join(fn.inputs, ", "), utils.ErrInvalidParameter)
+	}
+	for _, in := range inputs {
+		if c.err == nil && in.call == nil {
+			c.err = fmt.Errorf("empty input expression: %w", utils.ErrInvalidParameter)
+		}
+	}
+	if c.err == nil {
+		// The streaming constructors validate the parameters
+		_, c.err = fn.stream(a)
+	}
+	if c.err != nil {
+		c.err = fmt.Errorf("%s: %w", c.key, c.err)
+	}
+	return Expr{call: c}
+}
+
+// normalize reduces a parameter or output name for comparison
+func normalize(name string) string {
+	name = strings.ToLower(strings.ReplaceAll(name, "_", ""))
+	name = strings.TrimPrefix(name, "optin")
+	return strings.TrimPrefix(name, "out")
+}
+
+// resolveParams converts the named parameters to the argument list of a function
+func resolveParams(fn *function, params Params) (args, error) {
+	a := make(args, len(fn.params))
+	for i, p := range fn.params {
+		a[i] = p.def
+	}
+	for name, v := range params {
+		i := -1
+		for k, p := range fn.params {
+			if normalize(p.name) == normalize(name) {
+				i = k
+			}
+		}
+		if i < 0 {
+			return a, fmt.Errorf("unknown parameter %s: %w", name, utils.ErrInvalidParameter)
+		}
+
+		var f float64
+		switch v := v.(type) {
+		case int:
+			f = float64(v)
+		case float64:
+			f = v
+		case utils.MAType:
+			f = float64(v)
+		default:
+			return a, fmt.Errorf("parameter %s has unsupported type %T: %w", name, v, utils.ErrInvalidParameter)
+		}
+		if fn.params[i].kind != floatParam && f != math.Trunc(f) {
+			return a, fmt.Errorf("parameter %s must be an integer, got %v: %w", name, v, utils.ErrInvalidParameter)
+		}
+		a[i] = f
+	}
+	return a, nil
+}
+
+// callKey formats a call as its canonical text, such as "RSI(OBV(Close, Volume), 14)"
+func callKey(fn *function, a args, inputs []Expr) string {
+	parts := make([]string, 0, len(inputs)+len(a))
+	for _, in := range inputs {
+		parts = append(parts, in.String())
+	}
+	if fn.format != nil {
+		return fn.format(parts, a)
+	}
+	for i, v := range a {
+		if fn.params[i].kind == floatParam {
+			parts = append(parts, strconv.FormatFloat(v, 'g', -1, 64))
+		} else {
+			parts = append(parts, strconv.Itoa(int(v)))
+		}
+	}
+	return fn.name + "(" + strings.Join(parts, ", ") + ")"
+}
+
+// Out selects an output of a multi-output function by its TA-Lib name, such
+// as "MACDSignal" or "RealLowerBand". An unambiguous end of the name, such as
+// "Signal" or "LowerBand", selects the output too.
+func (e Expr) Out(name string) Expr {
+	if e.call == nil || e.call.err != nil {
+		return e
+	}
+	if e.call.fn != nil {
+		match := -1
+		for i, out := range e.call.fn.outputs {
+			if normalize(out) == normalize(name) {
+				return Expr{call: e.call, output: i}
+			}
+			if strings.HasSuffix(normalize(out), normalize(name)) {
+				if match >= 0 {
+					match = len(e.call.fn.outputs)
+				} else {
+					match = i
+				}
+			}
+		}
+		if match >= 0 && match < len(e.call.fn.outputs) {
+			return Expr{call: e.call, output: match}
+		}
+	}
+	err := fmt.Errorf("%s has no output %s: %w", e.call.key, name, utils.ErrInvalidParameter)
+	return Expr{call: &call{key: e.call.key + "." + name, err: err}}
+}
+
+// String returns the canonical text of the expression
+func (e Expr) String() string {
+	if e.call == nil {
+		return ""
+	}
+	if e.output > 0 {
+		return e.call.key + "." + e.call.fn.outputs[e.output]
+	}
+	return e.call.key
+}
+
+// Err returns the error of the expression or of one of its inputs
+func (e Expr) Err() error {
+	if e.call == nil {
+		return fmt.Errorf("empty expression: %w", utils.ErrInvalidParameter)
+	}
+	if e.call.err != nil {
+		return e.call.err
+	}
+	for _, in := range e.call.inputs {
+		if err := in.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lookback returns the number of bars consumed before the first value, the
+// lookback of the function added to the largest lookback of its inputs
+func (e Expr) Lookback() int {
+	if e.call == nil || e.call.fn == nil || e.Err() != nil {
+		return 0
+	}
+	inputs := 0
+	for _, in := range e.call.inputs {
+		inputs = max(inputs, in.Lookback())
+	}
+	return inputs + e.call.fn.lookback(e.call.args)
+}
+
+// ref points at an output of a compiled node
+type ref struct {
+	node   int
+	output int
+}
+
+// node is a call in a compiled pipeline
+type node struct {
+	call     *call
+	inputs   []ref
+	lookback int
+}
+
+// Pipeline is a compiled set of expressions
+type Pipeline struct {
+	nodes   []*node // Inputs come before the nodes using them
+	outputs []ref
+	names   []string
+}
+
+// Compile builds the graph of the expressions. Identical sub-expressions,
+// found by their canonical text, become a single node.
+func Compile(outputs ...Expr) (*Pipeline, error) {
+	p := &Pipeline{}
+	index := make(map[string]int)
+	var add func(e Expr) (ref, error)
+	add = func(e Expr) (ref, error) {
+		if err := e.Err(); err != nil {
+			return ref{}, err
+		}
+		if i, ok := index[e.call.key]; ok {
+			return ref{node: i, output: e.output}, nil
+		}
+
+		n := &node{call: e.call}
+		for _, in := range e.call.inputs {
+			r, err := add(in)
+			if err != nil {
+				return ref{}, err
+			}
+			n.inputs = append(n.inputs, r)
+			n.lookback = max(n.lookback, p.nodes[r.node].lookback)
+		}
+		if fn := e.call.fn; fn != nil {
+			n.lookback += fn.lookback(e.call.args)
+		}
+
+		index[e.call.key] = len(p.nodes)
+		p.nodes = append(p.nodes, n)
+		return ref{node: len(p.nodes) - 1, output: e.output}, nil
+	}
+
+	for _, e := range outputs {
+		r, err := add(e)
+		if err != nil {
+			return nil, err
+		}
+		p.outputs = append(p.outputs, r)
+		p.names = append(p.names, e.String())
+	}
+	return p, nil
+}
+
+// Names returns the canonical text of the outputs
+func (p *Pipeline) Names() []string {
+	return p.names
+}
+
+// Nodes returns the number of distinct nodes, bar fields included
+func (p *Pipeline) Nodes() int {
+	return len(p.nodes)
+}
+
+// Lookback returns the number of bars consumed before every output has a value
+func (p *Pipeline) Lookback() int {
+	lookback := 0
+	for _, r := range p.outputs {
+		lookback = max(lookback, p.nodes[r.node].lookback)
+	}
+	return lookback
+}
+
+// nodeValues holds the outputs of a node over all bars
+type nodeValues struct {
+	begIdx int
+	outs   [][]float64
+}
+
+// Run evaluates the pipeline over all bars. Each result has the BeginIndex
+// of the output on the bars, as if it came from a single TA-Lib function.
+func (p *Pipeline) Run(bars *utils.Bars) ([]*utils.Result, error) {
+	if bars == nil || bars.Len() == 0 {
+		return nil, utils.ErrEmptyInputData
+	}
+	values := make([]nodeValues, len(p.nodes))
+	for i, n := range p.nodes {
+		fn := n.call.fn
+		if fn == nil && n.call.constant {
+			out := make([]float64, bars.Len())
+			for k := range out {
+				out[k] = n.call.value
+			}
+			values[i] = nodeValues{outs: [][]float64{out}}
+			continue
+		}
+		if fn == nil {
+			values[i] = nodeValues{outs: [][]float64{bars.Field(n.call.field)}}
+			continue
+		}
+
+		// Align the inputs on the latest first value
+		begIdx := 0
+		for _, r := range n.inputs {
+			begIdx = max(begIdx, values[r.node].begIdx)
+		}
+		in := make([][]float64, len(n.inputs))
+		empty := false
+		for k, r := range n.inputs {
+			v := values[r.node]
+			out := v.outs[r.output]
+			if skip := begIdx - v.begIdx; skip < len(out) {
+				in[k] = out[skip:]
+			}
+			empty = empty || len(in[k]) == 0
+		}
+		if empty {
+			values[i] = nodeValues{outs: make([][]float64, len(fn.outputs))}
+			continue
+		}
+
+		outBegIdx, outs, err := fn.batch(in, n.call.args)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", n.call.key, err)
+		}
+		if len(outs[0]) == 0 {
+			values[i] = nodeValues{outs: make([][]float64, len(fn.outputs))}
+			continue
+		}
+		values[i] = nodeValues{begIdx: begIdx + outBegIdx, outs: outs}
+	}
+
+	results := make([]*utils.Result, len(p.outputs))
+	for i, r := range p.outputs {
+		v := values[r.node]
+		results[i] = utils.NewResult(v.begIdx, v.outs[r.output])
+	}
+	return results, nil
+}
+
+// Stream evaluates the pipeline one bar at a time
+type Stream struct {
+	p       *Pipeline
+	updates []updateFunc // nil for bar fields
+	in      [][]float64  // Input buffers of each node
+	values  [][]float64  // Latest outputs of each node
+	ready   []bool       // Whether the node has outputs for the latest bar
+}
+
+// Stream creates a streaming evaluation of the pipeline with fresh indicators
+func (p *Pipeline) Stream() (*Stream, error) {
+	s := &Stream{
+		p:       p,
+		updates: make([]updateFunc, len(p.nodes)),
+		in:      make([][]float64, len(p.nodes)),
+		values:  make([][]float64, len(p.nodes)),
+		ready:   make([]bool, len(p.nodes)),
+	}
+	for i, n := range p.nodes {
+		if n.call.fn == nil {
+			s.values[i] = make([]float64, 1)
+			continue
+		}
+		update, err := n.call.fn.stream(n.call.args)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", n.call.key, err)
+		}
+		s.updates[i] = update
+		s.in[i] = make([]float64, len(n.inputs))
+		s.values[i] = make([]float64, len(n.call.fn.outputs))
+	}
+	return s, nil
+}
+
+// Update adds a bar and returns one value per output, NaN for the outputs
+// still in their lookback period
+func (s *Stream) Update(bar utils.OHLCV) []float64 {
+	for i, n := range s.p.nodes {
+		fn := n.call.fn
+		if fn == nil {
+			if n.call.constant {
+				s.values[i][0] = n.call.value
+			} else {
+				s.values[i][0] = bar.Value(n.call.field)
+			}
+			s.ready[i] = true
+			continue
+		}
+
+		// A node starts once all of its inputs have values
+		ready := true
+		for k, r := range n.inputs {
+			ready = ready && s.ready[r.node]
+			s.in[i][k] = s.values[r.node][r.output]
+		}
+		s.ready[i] = ready && s.updates[i](s.in[i], s.values[i])
+	}
+
+	out := make([]float64, len(s.p.outputs))
+	for i, r := range s.p.outputs {
+		if s.ready[r.node] {
+			out[i] = s.values[r.node][r.output]
+		} else {
+			out[i] = math.NaN()
+		}
+	}
+	return out
+}
+
+// Ready reports whether output k had a value on the last update
+func (s *Stream) Ready(k int) bool {
+	return s.ready[s.p.outputs[k].node]
+}
@@ -0,0 +1,183 @@
+package pipeline_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/indicators"
+	"github.com/petercool/ta-lib/go/ta-lib/pipeline"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// sameBits reports whether two values are equal bit for bit, NaN included
+func sameBits(a, b float64) bool {
+	return math.Float64bits(a) == math.Float64bits(b) || math.IsNaN(a) && math.IsNaN(b)
+}
+
+// runPipeline runs a pipeline in both modes and compares the streamed values
+// with the batch results padded with NaN
+func runPipeline(t *testing.T, data []utils.OHLCV, p *pipeline.Pipeline) []*utils.Result {
+	t.Helper()
+	results, err := p.Run(utils.BarsFromOHLCV(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := p.Stream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	padded := make([][]float64, len(results))
+	for k, r := range results {
+		padded[k] = r.Padded(len(data))
+	}
+	for i, bar := range data {
+		for k, v := range s.Update(bar) {
+			if want := padded[k][i]; !sameBits(v, want) {
+				t.Fatalf("%s: bar %d streamed %v, batch has %v", p.Names()[k], i, v, want)
+			}
+		}
+	}
+	return results
+}
+
+// Every function runs in both modes and starts at its lookback
+func TestFunctions(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	for _, name := range pipeline.Functions() {
+		e := pipeline.Call(name, nil)
+		outputs := []pipeline.Expr{e}
+		for _, out := range []string{"MACDSignal", "MACDHist", "FAMA", "SlowD", "FastD", "RealMiddleBand", "RealLowerBand", "Quadrature", "LeadSine"} {
+			if o := e.Out(out); o.Err() == nil {
+				outputs = append(outputs, o)
+			}
+		}
+		p, err := pipeline.Compile(outputs...)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		results := runPipeline(t, data, p)
+		if results[0].BeginIndex != e.Lookback() {
+			t.Errorf("%s: first value at bar %d, lookback is %d", e, results[0].BeginIndex, e.Lookback())
+		}
+	}
+}
+
+// Pipelines give the values of the same chains written by hand
+func TestComposition(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	_, high, low, close, volume := utils.GetOHLCVSlices(data)
+
+	obv := pipeline.Call("OBV", nil)
+	rsiOfOBV := pipeline.Call("RSI", pipeline.Params{"TimePeriod": 14}, obv)
+	smaOfATR := pipeline.Call("SMA", pipeline.Params{"timeperiod": 10}, pipeline.Call("ATR", nil))
+	macd := pipeline.Call("MACD", nil, obv)
+	bands := pipeline.Call("BBANDS", pipeline.Params{"TimePeriod": 20, "MAType": utils.EMA}, rsiOfOBV)
+	p, err := pipeline.Compile(rsiOfOBV, smaOfATR, macd, macd.Out("MACDHist"), bands.Out("RealLowerBand"), pipeline.Call("SMA", nil, obv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Close, Volume, High, Low, OBV, RSI, ATR, SMA of ATR, MACD, BBANDS and SMA of OBV
+	if p.Nodes() != 11 {
+		t.Errorf("%d nodes, shared sub-expressions should give 11", p.Nodes())
+	}
+	got := runPipeline(t, data, p)
+
+	wantOBV, _ := indicators.OBV(close, volume)
+	wantRSI, _ := indicators.RSI(wantOBV.Values, 14)
+	wantATR, _ := indicators.ATR(high, low, close, 14)
+	wantSMA, _ := indicators.SMA(wantATR.Values, 10)
+	wantMACD, _ := indicators.MACD(wantOBV.Values, 12, 26, 9)
+	wantBands, _ := indicators.BBANDS(wantRSI.Values, 20, 2, 2, utils.EMA)
+	rsiBegIdx := wantOBV.BeginIndex + wantRSI.BeginIndex
+
+	for i, tc := range []struct {
+		begIdx int
+		values []float64
+	}{
+		{rsiBegIdx, wantRSI.Values},
+		{wantATR.BeginIndex + wantSMA.BeginIndex, wantSMA.Values},
+		{wantOBV.BeginIndex + wantMACD.BeginIndex, wantMACD.Values},
+		{wantOBV.BeginIndex + wantMACD.BeginIndex, wantMACD.MACDHist},
+		{rsiBegIdx + wantBands.BeginIndex, wantBands.LowerBand},
+	} {
+		name := p.Names()[i]
+		if got[i].BeginIndex != tc.begIdx || len(got[i].Values) != len(tc.values) {
+			t.Errorf("%s: %d values from bar %d, want %d from bar %d", name, len(got[i].Values), got[i].BeginIndex, len(tc.values), tc.begIdx)
+			continue
+		}
+		for k, want := range tc.values {
+			if !sameBits(got[i].Values[k], want) {
+				t.Errorf("%s: bar %d is %v, want %v", name, tc.begIdx+k, got[i].Values[k], want)
+				break
+			}
+		}
+	}
+
+	if want := rsiOfOBV.Lookback() + indicators.BBANDSLookback(20, utils.EMA); bands.Lookback() != want {
+		t.Errorf("BBANDS of RSI lookback %d, want %d", bands.Lookback(), want)
+	}
+}
+
+// Bands on the Hilbert transform trendline, a chain of two TA-Lib functions
+func TestBandsOnTrendline(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	close := utils.GetFieldSlice(data, utils.FieldClose)
+	bands := pipeline.Call("BBANDS", pipeline.Params{"TimePeriod": 20}, pipeline.Call("HT_TRENDLINE", nil))
+	p, err := pipeline.Compile(bands, bands.Out("RealLowerBand"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := runPipeline(t, data, p)
+
+	trendline, _ := indicators.HT_TRENDLINE(close)
+	want, _ := indicators.BBANDS(trendline.Values, 20, 2, 2, utils.SMA)
+	begIdx := trendline.BeginIndex + want.BeginIndex
+	if bands.Lookback() != begIdx {
+		t.Errorf("BBANDS of HT_TRENDLINE lookback %d, want %d", bands.Lookback(), begIdx)
+	}
+	for i, values := range [][]float64{want.UpperBand, want.LowerBand} {
+		if got[i].BeginIndex != begIdx || len(got[i].Values) != len(values) {
+			t.Fatalf("%s: %d values from bar %d, want %d from bar %d", p.Names()[i], len(got[i].Values), got[i].BeginIndex, len(values), begIdx)
+		}
+		for k, v := range values {
+			if !sameBits(got[i].Values[k], v) {
+				t.Fatalf("%s: bar %d is %v, want %v", p.Names()[i], begIdx+k, got[i].Values[k], v)
+			}
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, e := range []pipeline.Expr{
+		pipeline.Call("NOPE", nil),
+		pipeline.Call("RSI", pipeline.Params{"Period": 14}),
+		pipeline.Call("RSI", pipeline.Params{"TimePeriod": 1}),
+		pipeline.Call("RSI", pipeline.Params{"TimePeriod": 14.5}),
+		pipeline.Call("RSI", nil, pipeline.Close, pipeline.Volume),
+		pipeline.Call("SMA", nil, pipeline.Call("MACD", nil).Out("Trigger")),
+		pipeline.Call("BBANDS", pipeline.Params{"MAType": utils.MAType(99)}),
+		pipeline.Call("BBANDS", nil).Out("Band"),
+		pipeline.Ref(pipeline.Close, -1),
+		pipeline.Ref(pipeline.Close, 100001),
+		pipeline.Ref(pipeline.Close, 1<<40),
+	} {
+		if _, err := pipeline.Compile(e); !errors.Is(err, utils.ErrInvalidParameter) {
+			t.Errorf("%s compiled with error %v", e, err)
+		}
+	}
+}
+
+// Running on no bars is an error
+func TestRunEmpty(t *testing.T) {
+	p, err := pipeline.Compile(pipeline.Call("RSI", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, bars := range []*utils.Bars{nil, {}} {
+		if _, err := p.Run(bars); !errors.Is(err, utils.ErrEmptyInputData) {
+			t.Errorf("run on %v bars gave error %v", bars, err)
+		}
+	}
+}
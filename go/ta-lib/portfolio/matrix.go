@@ -0,0 +1,318 @@
+// Package portfolio measures how the returns of several symbols move
+// together: rolling covariance and correlation matrices over bars aligned by
+// timestamp, for portfolio construction.
+//
+// A symbol may miss bars. Its return is only defined at a bar following one
+// of its own bars at the previous timestamp, and every pair of symbols uses
+// the returns they have in common in the window (pairwise deletion), so a
+// gap in one symbol does not discard the returns of the others.
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/petercool/ta-lib/go/ta-lib/stats"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Config configures the rolling matrices
+type Config struct {
+	Period     int  // Timestamps in the window
+	MinOverlap int  // Fewest common returns of a pair for its values, at least 2
+	Shrinkage  bool // Ledoit-Wolf shrinkage of the covariance matrix
+}
+
+func (c Config) validate() error {
+	if c.Period < 2 || c.Period > utils.MaxPeriod || c.MinOverlap < 2 || c.MinOverlap > c.Period {
+		return fmt.Errorf("matrix config %+v: %w", c, utils.ErrInvalidParameter)
+	}
+	return nil
+}
+
+// Matrix is the state of the window at a timestamp. The values of a pair
+// come from its common returns only, so the matrices need not be positive
+// semidefinite when symbols miss bars. Values of symbols or pairs with fewer
+// than MinOverlap returns in the window are NaN, and a correlation with a
+// symbol whose returns do not vary is 0.
+type Matrix struct {
+	Time         int64
+	Covariance   [][]float64 // Sample covariances of the returns
+	Correlation  [][]float64 // Pearson correlations of the returns
+	Observations [][]int     // Common returns of each pair, the returns of a symbol on the diagonal
+	Shrinkage    float64     // Ledoit-Wolf intensity, 0 without shrinkage
+}
+
+// Rolling keeps the covariance and correlation of every pair of symbols
+// over the last Period timestamps, updated in O(N^2) per timestamp for N
+// symbols
+type Rolling struct {
+	config  Config
+	symbols int
+	time    int64
+	last    []float64   // Close of every symbol at the last timestamp, NaN if missing
+	returns [][]float64 // Returns of the window, NaN if missing, oldest at head
+	head    int
+	pairs   []utils.RollingCovariance // Pair i < j at pair(i, j), symbol i at pair(i, i)
+}
+
+// NewRolling creates the rolling matrices of a number of symbols
+func NewRolling(symbols int, config Config) (*Rolling, error) {
+	if symbols < 1 {
+		return nil, fmt.Errorf("%d symbols: %w", symbols, utils.ErrInvalidParameter)
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	r := &Rolling{
+		config:  config,
+		symbols: symbols,
+		last:    make([]float64, symbols),
+		returns: make([][]float64, 0, config.Period),
+		pairs:   make([]utils.RollingCovariance, symbols*(symbols+1)/2),
+	}
+	for i := range r.last {
+		r.last[i] = math.NaN()
+	}
+	return r, nil
+}
+
+// pair returns the index of the pair i <= j
+func (r *Rolling) pair(i, j int) int {
+	return i*r.symbols - i*(i-1)/2 + j - i
+}
+
+// Update adds the closes of every symbol at the next timestamp, NaN for a
+// symbol without a bar
+func (r *Rolling) Update(t int64, closes []float64) error {
+	if len(closes) != r.symbols {
+		return utils.ErrMismatchedInputLengths
+	}
+	row := make([]float64, r.symbols)
+	for i, c := range closes {
+		row[i] = c/r.last[i] - 1
+		if math.IsInf(row[i], 0) {
+			row[i] = math.NaN()
+		}
+		r.last[i] = c
+	}
+
+	var oldest []float64
+	if len(r.returns) < r.config.Period {
+		r.returns = append(r.returns, row)
+	} else {
+		oldest = r.returns[r.head]
+		r.returns[r.head] = row
+		r.head = (r.head + 1) % r.config.Period
+	}
+	for i := range row {
+		for j := i; j < r.symbols; j++ {
+			c := &r.pairs[r.pair(i, j)]
+			in := !math.IsNaN(row[i]) && !math.IsNaN(row[j])
+			out := oldest != nil && !math.IsNaN(oldest[i]) && !math.IsNaN(oldest[j])
+			switch {
+			case in && out:
+				c.Replace(oldest[i], oldest[j], row[i], row[j])
+			case in:
+				c.Add(row[i], row[j])
+			case out:
+				c.Remove(oldest[i], oldest[j])
+			}
+		}
+	}
+	r.time = t
+	return nil
+}
+
+// Matrix returns the matrices of the current window
+func (r *Rolling) Matrix() Matrix {
+	n := r.symbols
+	m := Matrix{
+		Time:         r.time,
+		Covariance:   square[float64](n),
+		Correlation:  square[float64](n),
+		Observations: square[int](n),
+	}
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			c := &r.pairs[r.pair(i, j)]
+			cov, corr := math.NaN(), math.NaN()
+			if c.X.N >= r.config.MinOverlap {
+				cov, corr = c.Covariance(1), c.Correlation()
+				if i == j {
+					corr = 1
+				}
+			}
+			m.Covariance[i][j], m.Covariance[j][i] = cov, cov
+			m.Correlation[i][j], m.Correlation[j][i] = corr, corr
+			m.Observations[i][j], m.Observations[j][i] = c.X.N, c.X.N
+		}
+	}
+	if r.config.Shrinkage {
+		r.shrink(&m)
+	}
+	return m
+}
+
+// shrink applies the Ledoit-Wolf shrinkage towards the average variance
+// times the identity. The intensity is estimated on the timestamps of the
+// window where every symbol has a return, and the correlations are those of
+// the shrunk covariances.
+func (r *Rolling) shrink(m *Matrix) {
+	n := r.symbols
+	var rows [][]float64
+	for _, row := range r.returns {
+		complete := true
+		for _, v := range row {
+			complete = complete && !math.IsNaN(v)
+		}
+		if complete {
+			rows = append(rows, row)
+		}
+	}
+	target := 0.0
+	for i := 0; i < n; i++ {
+		target += m.Covariance[i][i] / float64(n)
+	}
+	if math.IsNaN(target) {
+		return
+	}
+	m.Shrinkage = ledoitWolf(rows, n)
+	if m.Shrinkage == 0 {
+		return
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			m.Covariance[i][j] *= 1 - m.Shrinkage
+			if i == j {
+				m.Covariance[i][j] += m.Shrinkage * target
+			}
+		}
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if v := m.Covariance[i][i] * m.Covariance[j][j]; i != j && v > 0 {
+				m.Correlation[i][j] = m.Covariance[i][j] / math.Sqrt(v)
+			}
+		}
+	}
+}
+
+// ledoitWolf returns the optimal shrinkage intensity of the covariance of
+// rows of returns towards a multiple of the identity (Ledoit and Wolf, 2004),
+// 0 with fewer than two rows
+func ledoitWolf(rows [][]float64, n int) float64 {
+	t := len(rows)
+	if t < 2 {
+		return 0
+	}
+	centered := make([][]float64, t)
+	for k := range centered {
+		centered[k] = make([]float64, n)
+	}
+	column := make([]float64, t)
+	for i := 0; i < n; i++ {
+		for k, row := range rows {
+			column[k] = row[i]
+		}
+		mean := stats.Mean(column)
+		for k, row := range rows {
+			centered[k][i] = row[i] - mean
+		}
+	}
+
+	// Population covariance of the rows
+	s := square[float64](n)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			var sum utils.KahanSum
+			for _, x := range centered {
+				sum.Add(x[i] * x[j])
+			}
+			s[i][j], s[j][i] = sum.Total()/float64(t), sum.Total()/float64(t)
+		}
+	}
+	mu := 0.0
+	for i := 0; i < n; i++ {
+		mu += s[i][i] / float64(n)
+	}
+
+	// Distance of the covariance to the target and variance of its estimate
+	var d2, b2 utils.KahanSum
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			d := s[i][j]
+			if i == j {
+				d -= mu
+			}
+			d2.Add(d * d)
+			for _, x := range centered {
+				e := x[i]*x[j] - s[i][j]
+				b2.Add(e * e / float64(t*t))
+			}
+		}
+	}
+	if d2.Total() <= 0 {
+		return 0
+	}
+	return min(b2.Total(), d2.Total()) / d2.Total()
+}
+
+func square[T any](n int) [][]T {
+	m := make([][]T, n)
+	for i := range m {
+		m[i] = make([]T, n)
+	}
+	return m
+}
+
+// Matrices aligns the bars of several symbols on the union of their
+// timestamps and returns the matrices at every timestamp from the one that
+// fills the first window. The bars of a symbol must be in ascending time.
+func Matrices(data [][]utils.OHLCV, config Config) ([]Matrix, error) {
+	if len(data) == 0 {
+		return nil, utils.ErrEmptyInputData
+	}
+	r, err := NewRolling(len(data), config)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int64]bool)
+	var times []int64
+	for s, bars := range data {
+		if len(bars) == 0 {
+			return nil, fmt.Errorf("symbol %d: %w", s, utils.ErrEmptyInputData)
+		}
+		for i, b := range bars {
+			if i > 0 && b.Time <= bars[i-1].Time {
+				return nil, fmt.Errorf("symbol %d: bar %d not after the previous one: %w", s, i, utils.ErrInvalidParameter)
+			}
+			if !seen[b.Time] {
+				seen[b.Time] = true
+				times = append(times, b.Time)
+			}
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	next := make([]int, len(data))
+	closes := make([]float64, len(data))
+	var out []Matrix
+	for k, t := range times {
+		for s, bars := range data {
+			closes[s] = math.NaN()
+			if next[s] < len(bars) && bars[next[s]].Time == t {
+				closes[s] = bars[next[s]].Close
+				next[s]++
+			}
+		}
+		if err := r.Update(t, closes); err != nil {
+			return nil, err
+		}
+		if k >= config.Period {
+			out = append(out, r.Matrix())
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,181 @@
+package portfolio_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/portfolio"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// pairStats returns the sample covariance and the correlation of the pairs
+// of values where both are present, and their number
+func pairStats(x, y []float64) (cov, corr float64, n int) {
+	var xs, ys []float64
+	for k := range x {
+		if !math.IsNaN(x[k]) && !math.IsNaN(y[k]) {
+			xs, ys = append(xs, x[k]), append(ys, y[k])
+		}
+	}
+	if len(xs) < 2 {
+		return math.NaN(), math.NaN(), len(xs)
+	}
+	var mx, my float64
+	for k := range xs {
+		mx += xs[k] / float64(len(xs))
+		my += ys[k] / float64(len(xs))
+	}
+	var sxy, sxx, syy float64
+	for k := range xs {
+		sxy += (xs[k] - mx) * (ys[k] - my)
+		sxx += (xs[k] - mx) * (xs[k] - mx)
+		syy += (ys[k] - my) * (ys[k] - my)
+	}
+	corr = 0
+	if sxx > 0 && syy > 0 {
+		corr = sxy / math.Sqrt(sxx*syy)
+	}
+	return sxy / float64(len(xs)-1), corr, len(xs)
+}
+
+// fourSymbols returns the data, a noisy copy missing every 7th bar, an
+// inverted copy starting late and a noisy one with a gap
+func fourSymbols(data []utils.OHLCV) [][]utils.OHLCV {
+	seed := uint64(7)
+	noise := func() float64 {
+		seed = seed*6364136223846793005 + 1442695040888963407
+		return float64(seed>>11)/(1<<53) - 0.5
+	}
+	series := make([][]utils.OHLCV, 4)
+	for i, d := range data {
+		series[0] = append(series[0], d)
+		if i%7 != 3 {
+			b := d
+			b.Close = d.Close * (1 + 0.01*noise())
+			series[1] = append(series[1], b)
+		}
+		if i >= 30 {
+			b := d
+			b.Close = 1000 / d.Close
+			series[2] = append(series[2], b)
+		}
+		if i < 100 || i >= 140 {
+			b := d
+			b.Close = 50 + 0.1*d.Close + noise()
+			series[3] = append(series[3], b)
+		}
+	}
+	return series
+}
+
+// The rolling matrices match the pairwise statistics of every window
+// recomputed from scratch, with bars missing in some symbols
+func TestMatrices(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	series := fourSymbols(data)
+	symbols := len(series)
+	config := portfolio.Config{Period: 60, MinOverlap: 20}
+	matrices, err := portfolio.Matrices(series, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matrices) != len(data)-config.Period {
+		t.Fatalf("%d matrices for %d bars", len(matrices), len(data))
+	}
+
+	// Returns from scratch, NaN where a symbol or its previous bar is missing
+	returns := make([][]float64, symbols)
+	for s, bars := range series {
+		returns[s] = make([]float64, len(data))
+		closes := make(map[int64]float64)
+		for _, b := range bars {
+			closes[b.Time] = b.Close
+		}
+		for k := range data {
+			returns[s][k] = math.NaN()
+			c, ok := closes[data[k].Time]
+			if prev, okPrev := closes[data[max(k-1, 0)].Time]; k > 0 && ok && okPrev {
+				returns[s][k] = c/prev - 1
+			}
+		}
+	}
+	for m, matrix := range matrices {
+		end := m + config.Period + 1
+		if matrix.Time != data[end-1].Time {
+			t.Fatalf("matrix %d at %d, want %d", m, matrix.Time, data[end-1].Time)
+		}
+		for i := 0; i < symbols; i++ {
+			for j := 0; j < symbols; j++ {
+				cov, corr, n := pairStats(returns[i][end-config.Period:end], returns[j][end-config.Period:end])
+				if n < config.MinOverlap {
+					cov, corr = math.NaN(), math.NaN()
+				}
+				if i == j && !math.IsNaN(corr) {
+					corr = 1
+				}
+				name := fmt.Sprintf("matrix %d (%d, %d)", m, i, j)
+				if matrix.Observations[i][j] != n {
+					t.Errorf("%s has %d returns, want %d", name, matrix.Observations[i][j], n)
+				}
+				if math.IsNaN(cov) != math.IsNaN(matrix.Covariance[i][j]) || math.IsNaN(corr) != math.IsNaN(matrix.Correlation[i][j]) {
+					t.Errorf("%s is %v %v, want %v %v", name, matrix.Covariance[i][j], matrix.Correlation[i][j], cov, corr)
+					continue
+				}
+				if !math.IsNaN(cov) {
+					testutil.Near(t, name+" covariance", matrix.Covariance[i][j], cov, 1e-13)
+					testutil.Near(t, name+" correlation", matrix.Correlation[i][j], corr, 1e-9)
+				}
+			}
+		}
+		if t.Failed() {
+			return
+		}
+	}
+
+	// The gap of the fourth symbol leaves too few returns in the window
+	// ending at its first bar after the gap
+	if gap := matrices[140-config.Period]; !math.IsNaN(gap.Covariance[0][3]) || gap.Observations[3][3] != 19 {
+		t.Errorf("covariance %v with %d returns inside the gap", gap.Covariance[0][3], gap.Observations[3][3])
+	}
+}
+
+// Ledoit-Wolf intensity checked by hand for six returns of two symbols
+func TestShrinkage(t *testing.T) {
+	rows := [][2]float64{{0.01, 0.02}, {-0.02, -0.03}, {0.03, 0.05}, {0, 0.01}, {0.02, 0.02}, {-0.01, -0.04}}
+	r, err := portfolio.NewRolling(2, portfolio.Config{Period: 6, MinOverlap: 2, Shrinkage: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	closes := []float64{100, 100}
+	r.Update(0, closes)
+	for k, row := range rows {
+		closes = []float64{closes[0] * (1 + row[0]), closes[1] * (1 + row[1])}
+		r.Update(int64(k+1), closes)
+	}
+	shrunk := r.Matrix()
+	const intensity = 0.2697631699796629
+	target := (0.00035 + 0.00115) / 2
+	testutil.Near(t, "Ledoit-Wolf intensity", shrunk.Shrinkage, intensity, 1e-9)
+	testutil.Near(t, "shrunk variance 0", shrunk.Covariance[0][0], (1-intensity)*0.00035+intensity*target, 1e-13)
+	testutil.Near(t, "shrunk variance 1", shrunk.Covariance[1][1], (1-intensity)*0.00115+intensity*target, 1e-13)
+	testutil.Near(t, "shrunk covariance", shrunk.Covariance[0][1], (1-intensity)*0.00059, 1e-13)
+	testutil.Near(t, "shrunk correlation", shrunk.Correlation[1][0], 0.6236979614622099, 1e-9)
+
+	if err := r.Update(7, []float64{1}); !errors.Is(err, utils.ErrMismatchedInputLengths) {
+		t.Errorf("update with one close: %v", err)
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	for _, c := range []portfolio.Config{{Period: 1, MinOverlap: 2}, {Period: 10, MinOverlap: 1}, {Period: 10, MinOverlap: 11}} {
+		if _, err := portfolio.NewRolling(2, c); !errors.Is(err, utils.ErrInvalidParameter) {
+			t.Errorf("config %+v: %v", c, err)
+		}
+	}
+	if _, err := portfolio.Matrices([][]utils.OHLCV{testutil.MustOHLCV(t), nil}, portfolio.Config{Period: 60, MinOverlap: 20}); !errors.Is(err, utils.ErrEmptyInputData) {
+		t.Errorf("symbol without bars: %v", err)
+	}
+}
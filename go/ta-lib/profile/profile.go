@@ -0,0 +1,228 @@
+// Package profile builds volume profiles and TPO market profiles: the volume
+// or time traded at each price, with the point of control, the value area and
+// the high and low volume nodes.
+//
+// Prices are grouped in bins of TickSize. Bin k holds the prices from
+// Low + k*TickSize up to the next bin, and levels are reported by the lowest
+// price of their bin.
+package profile
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Config configures the profiles
+type Config struct {
+	TickSize   float64 // Price step of the bins
+	ValueArea  float64 // Share of the total in the value area, such as 0.7
+	NodeWindow int     // Bins on each side compared to find the volume nodes
+}
+
+func (c Config) validate(data []utils.OHLCV) error {
+	if len(data) == 0 {
+		return utils.ErrEmptyInputData
+	}
+	if !(c.TickSize > 0) || !(c.ValueArea > 0 && c.ValueArea <= 1) || c.NodeWindow < 1 {
+		return fmt.Errorf("profile config %+v: %w", c, utils.ErrInvalidParameter)
+	}
+	return nil
+}
+
+// histogram holds a value per price bin
+type histogram struct {
+	low    float64 // Lowest price of the first bin
+	tick   float64
+	values []float64
+}
+
+// MaxBins is the largest number of bins of a profile, 8 MB of values
+const MaxBins = 1 << 20
+
+// newHistogram creates the bins covering the range of the bars. The range
+// must fit in MaxBins bins of the tick size.
+func newHistogram(data []utils.OHLCV, tick float64) (*histogram, error) {
+	if !(tick > 0) || math.IsInf(tick, 1) {
+		return nil, fmt.Errorf("tick size %v: %w", tick, utils.ErrInvalidParameter)
+	}
+	lowest, highest := math.Inf(1), math.Inf(-1)
+	for i, d := range data {
+		if math.IsNaN(d.Low) || math.IsInf(d.Low, 0) || math.IsNaN(d.High) || math.IsInf(d.High, 0) || d.Low > d.High {
+			return nil, fmt.Errorf("bar %d has a low %v and a high %v: %w", i, d.Low, d.High, utils.ErrInvalidParameter)
+		}
+		lowest, highest = min(lowest, d.Low), max(highest, d.High)
+	}
+	h := &histogram{low: math.Floor(lowest/tick) * tick, tick: tick}
+	if bins := math.Floor((highest-h.low)/tick+1e-9) + 1; !(bins <= MaxBins) {
+		return nil, fmt.Errorf("range %v to %v needs %g bins of %v, more than %d: %w", lowest, highest, bins, tick, MaxBins, utils.ErrInvalidParameter)
+	}
+	h.values = make([]float64, h.bin(highest)+1)
+	return h, nil
+}
+
+// bin returns the bin of a price, tolerating the rounding of tick multiples
+func (h *histogram) bin(price float64) int {
+	return int(math.Floor((price-h.low)/h.tick + 1e-9))
+}
+
+// price returns the lowest price of bin k
+func (h *histogram) price(k int) float64 {
+	return h.low + float64(k)*h.tick
+}
+
+// spread adds a value evenly over the bins from low to high
+func (h *histogram) spread(low, high, value float64) {
+	first, last := h.bin(low), h.bin(high)
+	share := value / float64(last-first+1)
+	for k := first; k <= last; k++ {
+		h.values[k] += share
+	}
+}
+
+// poc returns the bin with the largest value, the one closest to the middle
+// of the profile on a tie
+func (h *histogram) poc() int {
+	best := 0
+	middle := float64(len(h.values)-1) / 2
+	for k, v := range h.values {
+		if v > h.values[best] || v == h.values[best] && math.Abs(float64(k)-middle) < math.Abs(float64(best)-middle) {
+			best = k
+		}
+	}
+	return best
+}
+
+// valueArea grows a range from the point of control, adding the larger
+// neighbouring bin, the upper one on a tie, until it holds the share of the
+// total
+func (h *histogram) valueArea(poc int, share float64) (int, int) {
+	total := 0.0
+	for _, v := range h.values {
+		total += v
+	}
+	lo, hi := poc, poc
+	inside := h.values[poc]
+	for inside < share*total && (lo > 0 || hi < len(h.values)-1) {
+		below, above := math.Inf(-1), math.Inf(-1)
+		if lo > 0 {
+			below = h.values[lo-1]
+		}
+		if hi < len(h.values)-1 {
+			above = h.values[hi+1]
+		}
+		if above >= below {
+			hi++
+			inside += above
+		} else {
+			lo--
+			inside += below
+		}
+	}
+	return lo, hi
+}
+
+// nodes returns the prices of the bins that are the largest, or the
+// smallest, within window bins on each side. The bins at the edges of the
+// profile are not low volume nodes.
+func (h *histogram) nodes(window int, high bool) []float64 {
+	var out []float64
+	for k, v := range h.values {
+		if !high && (k == 0 || k == len(h.values)-1) {
+			continue
+		}
+		node := true
+		for j := max(k-window, 0); j <= min(k+window, len(h.values)-1) && node; j++ {
+			switch {
+			case j == k:
+			case high:
+				node = v > h.values[j] || v == h.values[j] && j > k
+			default:
+				node = v < h.values[j] || v == h.values[j] && j > k
+			}
+		}
+		if node {
+			out = append(out, h.price(k))
+		}
+	}
+	return out
+}
+
+// VolumeProfile is the volume traded at each price over a range of bars
+type VolumeProfile struct {
+	Start, End      int64     // Times of the first and last bars
+	TickSize        float64   // Price step of the bins
+	Low             float64   // Lowest price of the first bin
+	Volumes         []float64 // Volume of each bin
+	POC             float64   // Point of control, the price with the most volume
+	ValueAreaLow    float64   // Lowest price of the value area
+	ValueAreaHigh   float64   // Highest price of the value area
+	HighVolumeNodes []float64 // Prices with more volume than the bins around them
+	LowVolumeNodes  []float64 // Prices with less volume than the bins around them
+}
+
+// Price returns the lowest price of bin k
+func (p *VolumeProfile) Price(k int) float64 {
+	return p.Low + float64(k)*p.TickSize
+}
+
+// NewVolumeProfile builds the volume profile of a fixed range of bars. The
+// volume of each bar is spread evenly over the bins from its low to its high.
+func NewVolumeProfile(data []utils.OHLCV, config Config) (*VolumeProfile, error) {
+	if err := config.validate(data); err != nil {
+		return nil, err
+	}
+	h, err := newHistogram(data, config.TickSize)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range data {
+		h.spread(d.Low, d.High, d.Volume)
+	}
+	poc := h.poc()
+	lo, hi := h.valueArea(poc, config.ValueArea)
+	return &VolumeProfile{
+		Start:           data[0].Time,
+		End:             data[len(data)-1].Time,
+		TickSize:        config.TickSize,
+		Low:             h.low,
+		Volumes:         h.values,
+		POC:             h.price(poc),
+		ValueAreaLow:    h.price(lo),
+		ValueAreaHigh:   h.price(hi),
+		HighVolumeNodes: h.nodes(config.NodeWindow, true),
+		LowVolumeNodes:  h.nodes(config.NodeWindow, false),
+	}, nil
+}
+
+// SessionVolumeProfiles builds a volume profile for every calendar day in
+// loc, or in UTC when loc is nil
+func SessionVolumeProfiles(data []utils.OHLCV, config Config, loc *time.Location) ([]*VolumeProfile, error) {
+	if err := config.validate(data); err != nil {
+		return nil, err
+	}
+	var out []*VolumeProfile
+	for _, session := range sessions(data, loc) {
+		p, err := NewVolumeProfile(session, config)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// sessions splits the bars by calendar day
+func sessions(data []utils.OHLCV, loc *time.Location) [][]utils.OHLCV {
+	var out [][]utils.OHLCV
+	first := 0
+	for i := 1; i <= len(data); i++ {
+		if i == len(data) || utils.Daily.PeriodStart(data[i].Time, loc) != utils.Daily.PeriodStart(data[first].Time, loc) {
+			out = append(out, data[first:i])
+			first = i
+		}
+	}
+	return out
+}
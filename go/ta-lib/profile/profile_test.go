@@ -0,0 +1,123 @@
+package profile_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/petercool/ta-lib/go/ta-lib/profile"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// hourly turns the sample bars into consecutive hourly bars
+func hourly(data []utils.OHLCV) []utils.OHLCV {
+	out := append([]utils.OHLCV(nil), data...)
+	for i := range out {
+		out[i].Time = data[0].Time + int64(i)*3600
+	}
+	return out
+}
+
+// Ticks, prices and ranges that would give no bins or too many are rejected
+func TestLimits(t *testing.T) {
+	config := profile.Config{TickSize: 1, ValueArea: 0.7, NodeWindow: 1}
+	bar := utils.OHLCV{Low: 10, High: 12, Volume: 30}
+	for _, tc := range []struct {
+		name string
+		tick float64
+		bars []utils.OHLCV
+	}{
+		{"tick size 0", 0, []utils.OHLCV{bar}},
+		{"negative tick size", -1, []utils.OHLCV{bar}},
+		{"infinite tick size", math.Inf(1), []utils.OHLCV{bar}},
+		{"NaN tick size", math.NaN(), []utils.OHLCV{bar}},
+		{"NaN low", 1, []utils.OHLCV{bar, {Low: math.NaN(), High: 12}}},
+		{"infinite high", 1, []utils.OHLCV{bar, {Low: 10, High: math.Inf(1)}}},
+		{"low above the high", 1, []utils.OHLCV{{Low: 12, High: 10}}},
+		{"tiny tick", 1e-9, []utils.OHLCV{bar}},
+		{"huge range", 1, []utils.OHLCV{bar, {Low: 10, High: 10 + profile.MaxBins}}},
+	} {
+		config.TickSize = tc.tick
+		if _, err := profile.NewVolumeProfile(tc.bars, config); !errors.Is(err, utils.ErrInvalidParameter) {
+			t.Errorf("volume profile with a %s: got %v, want ErrInvalidParameter", tc.name, err)
+		}
+		if _, err := profile.SessionMarketProfiles(tc.bars, config, time.Hour, nil); !errors.Is(err, utils.ErrInvalidParameter) {
+			t.Errorf("market profile with a %s: got %v, want ErrInvalidParameter", tc.name, err)
+		}
+	}
+
+	// The largest range allowed still builds
+	config.TickSize = 1
+	p, err := profile.NewVolumeProfile([]utils.OHLCV{bar, {Low: 10, High: 10 + profile.MaxBins - 1}}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Volumes) != profile.MaxBins {
+		t.Errorf("%d bins for a range of %d ticks", len(p.Volumes), profile.MaxBins)
+	}
+
+	if _, err := profile.NewVolumeProfile([]utils.OHLCV{bar}, profile.Config{TickSize: 1, ValueArea: 1.5, NodeWindow: 1}); !errors.Is(err, utils.ErrInvalidParameter) {
+		t.Errorf("value area 1.5: %v", err)
+	}
+}
+
+// A profile matches a hand-computed histogram
+func TestVolumeProfile(t *testing.T) {
+	// 10: 10, 11: 10 + 20, 12: 10 + 3, 13: 3, 14: 3
+	bars := []utils.OHLCV{
+		{Time: 0, Low: 10, High: 12, Volume: 30},
+		{Time: 60, Low: 11, High: 11, Volume: 20},
+		{Time: 120, Low: 12, High: 14, Volume: 9},
+	}
+	p, err := profile.NewVolumeProfile(bars, profile.Config{TickSize: 1, ValueArea: 0.7, NodeWindow: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{10, 30, 13, 3, 3}
+	if fmt.Sprint(p.Volumes) != fmt.Sprint(want) || p.Low != 10 {
+		t.Errorf("volumes %v from %v, want %v from 10", p.Volumes, p.Low, want)
+	}
+	// The value area adds 12 to the POC, with 43 of the 59 traded
+	if p.POC != 11 || p.ValueAreaLow != 11 || p.ValueAreaHigh != 12 {
+		t.Errorf("POC %v, value area %v-%v", p.POC, p.ValueAreaLow, p.ValueAreaHigh)
+	}
+	if fmt.Sprint(p.HighVolumeNodes) != "[11]" || fmt.Sprint(p.LowVolumeNodes) != "[13]" {
+		t.Errorf("high volume nodes %v, low volume nodes %v", p.HighVolumeNodes, p.LowVolumeNodes)
+	}
+}
+
+// Every session keeps its volume and holds 70% of it in the value area
+func TestSessionVolumeProfiles(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	config := profile.Config{TickSize: 250, ValueArea: 0.7, NodeWindow: 3}
+	bars := hourly(data)
+	sessions, err := profile.SessionVolumeProfiles(bars, config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := 0
+	for _, s := range sessions {
+		total, traded, inside := 0.0, 0.0, 0.0
+		for ; first < len(data) && bars[first].Time <= s.End; first++ {
+			traded += data[first].Volume
+		}
+		for k, v := range s.Volumes {
+			total += v
+			if price := s.Price(k); price >= s.ValueAreaLow && price <= s.ValueAreaHigh {
+				inside += v
+			}
+			if v > s.Volumes[int((s.POC-s.Low)/config.TickSize+0.5)] {
+				t.Fatalf("session at %d: %v traded at %v, more than the POC", s.Start, v, s.Price(k))
+			}
+		}
+		if math.Abs(total-traded) > 1e-6*traded || inside < 0.7*total || s.POC < s.ValueAreaLow || s.POC > s.ValueAreaHigh {
+			t.Fatalf("session at %d: %v traded, %v in the profile, %v in the value area", s.Start, traded, total, inside)
+		}
+	}
+	if first != len(data) || len(sessions) < len(data)/24 {
+		t.Errorf("%d sessions cover %d of %d bars", len(sessions), first, len(data))
+	}
+}
@@ -0,0 +1,96 @@
+package profile
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// tpoLetters name the periods of a session, starting over after the last one
+const tpoLetters = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// MarketProfile is the TPO profile of a session: each period of the session
+// gets a letter, marked on every price it traded at
+type MarketProfile struct {
+	Start              int64     // Start of the session
+	Period             int64     // Length of the periods in seconds
+	TickSize           float64   // Price step of the bins
+	Low                float64   // Lowest price of the first bin
+	Letters            []string  // Letters of the periods that traded in each bin
+	Counts             []float64 // Number of periods that traded in each bin
+	POC                float64   // Price traded in the most periods
+	ValueAreaLow       float64   // Lowest price of the value area
+	ValueAreaHigh      float64   // Highest price of the value area
+	InitialBalanceLow  float64   // Low of periods A and B
+	InitialBalanceHigh float64   // High of periods A and B
+}
+
+// Price returns the lowest price of bin k
+func (p *MarketProfile) Price(k int) float64 {
+	return p.Low + float64(k)*p.TickSize
+}
+
+// SessionMarketProfiles builds a TPO profile for every calendar day in loc,
+// or in UTC when loc is nil, with periods of the given length aligned on
+// midnight, usually 30 minutes. The periods are lettered from the first one
+// with bars, A to Z then a to z, and the initial balance is the range of the
+// first two.
+func SessionMarketProfiles(data []utils.OHLCV, config Config, period time.Duration, loc *time.Location) ([]*MarketProfile, error) {
+	if err := config.validate(data); err != nil {
+		return nil, err
+	}
+	seconds := int64(period / time.Second)
+	if seconds < 1 {
+		return nil, fmt.Errorf("TPO period %v: %w", period, utils.ErrInvalidParameter)
+	}
+
+	var out []*MarketProfile
+	for _, session := range sessions(data, loc) {
+		start := utils.Daily.PeriodStart(session[0].Time, loc)
+		h, err := newHistogram(session, config.TickSize)
+		if err != nil {
+			return nil, err
+		}
+		letters := make([][]byte, len(h.values))
+		p := &MarketProfile{Start: start, Period: seconds, TickSize: config.TickSize, Low: h.low}
+
+		// Range of every period, then one mark per period on each of its bins.
+		// The first period with bars is A.
+		base := (session[0].Time - start) / seconds
+		first := 0
+		for i := 1; i <= len(session); i++ {
+			index := (session[first].Time-start)/seconds - base
+			if i < len(session) && (session[i].Time-start)/seconds-base == index {
+				continue
+			}
+			low, high := session[first].Low, session[first].High
+			for _, d := range session[first:i] {
+				low, high = min(low, d.Low), max(high, d.High)
+			}
+			switch index {
+			case 0:
+				p.InitialBalanceLow, p.InitialBalanceHigh = low, high
+			case 1:
+				p.InitialBalanceLow, p.InitialBalanceHigh = min(p.InitialBalanceLow, low), max(p.InitialBalanceHigh, high)
+			}
+			letter := tpoLetters[index%int64(len(tpoLetters))]
+			for k := h.bin(low); k <= h.bin(high); k++ {
+				h.values[k]++
+				letters[k] = append(letters[k], letter)
+			}
+			first = i
+		}
+
+		poc := h.poc()
+		lo, hi := h.valueArea(poc, config.ValueArea)
+		p.Counts = h.values
+		p.POC, p.ValueAreaLow, p.ValueAreaHigh = h.price(poc), h.price(lo), h.price(hi)
+		p.Letters = make([]string, len(letters))
+		for k, l := range letters {
+			p.Letters[k] = string(l)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
@@ -0,0 +1,62 @@
+package profile_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/petercool/ta-lib/go/ta-lib/profile"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+func TestSessionMarketProfiles(t *testing.T) {
+	bars := hourly(testutil.MustOHLCV(t))
+	config := profile.Config{TickSize: 250, ValueArea: 0.7, NodeWindow: 3}
+	profiles, err := profile.SessionMarketProfiles(bars, config, 2*time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := 0
+	for _, p := range profiles {
+		// Each two hour period is a letter on every bin of its range, from A
+		// for the first period with bars
+		periods := map[byte][2]float64{}
+		base := (bars[i].Time - p.Start) / 7200
+		for ; i < len(bars) && utils.Daily.PeriodStart(bars[i].Time, nil) == p.Start; i++ {
+			letter := byte('A' + (bars[i].Time-p.Start)/7200 - base)
+			r, ok := periods[letter]
+			if !ok {
+				r = [2]float64{bars[i].Low, bars[i].High}
+			}
+			periods[letter] = [2]float64{min(r[0], bars[i].Low), max(r[1], bars[i].High)}
+		}
+		a, b := periods['A'], periods['B']
+		if _, ok := periods['B']; !ok {
+			b = a
+		}
+		if p.InitialBalanceLow != min(a[0], b[0]) || p.InitialBalanceHigh != max(a[1], b[1]) {
+			t.Fatalf("session at %d: initial balance %v-%v, periods A and B traded %v and %v", p.Start, p.InitialBalanceLow, p.InitialBalanceHigh, a, b)
+		}
+		for k, letters := range p.Letters {
+			price := p.Price(k)
+			for letter, r := range periods {
+				traded := price+config.TickSize > r[0]+1e-9 && price <= r[1]+1e-9
+				if traded != (strings.IndexByte(letters, letter) >= 0) {
+					t.Fatalf("session at %d: letters %q at %v, period %c traded %v-%v", p.Start, letters, price, letter, r[0], r[1])
+				}
+			}
+			if float64(len(letters)) != p.Counts[k] {
+				t.Fatalf("session at %d: %d letters and %v TPOs at %v", p.Start, len(letters), p.Counts[k], price)
+			}
+		}
+	}
+	if i != len(bars) {
+		t.Errorf("market profiles cover %d of %d bars", i, len(bars))
+	}
+
+	if _, err := profile.SessionMarketProfiles(bars, config, 0, nil); !errors.Is(err, utils.ErrInvalidParameter) {
+		t.Errorf("TPO period 0: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package regime_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/regime"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// gaussian returns a generator of standard normal values
+func gaussian(seed uint64) func() float64 {
+	uniform := func() float64 {
+		seed = seed*6364136223846793005 + 1442695040888963407
+		return (float64(seed>>11) + 0.5) / (1 << 53)
+	}
+	return func() float64 {
+		return math.Sqrt(-2*math.Log(uniform())) * math.Cos(2*math.Pi*uniform())
+	}
+}
+
+// The Hurst exponents and the classifier tell random walks from persistent
+// and anti-persistent ones
+func TestHurstClassify(t *testing.T) {
+	normal := gaussian(5)
+	for _, walk := range []struct {
+		phi        float64
+		low, high  float64
+		regime     regime.Regime
+		classified bool
+	}{
+		{0, 0.42, 0.58, regime.Random, false},
+		{0.6, 0.6, 1.2, regime.Trending, true},
+		{-0.6, 0, 0.4, regime.MeanReverting, true},
+	} {
+		x := make([]float64, 3000)
+		increment := 0.0
+		for i := 1; i < len(x); i++ {
+			increment = walk.phi*increment + normal()
+			x[i] = x[i-1] + increment
+		}
+		for _, method := range []regime.HurstMethod{regime.RescaledRange, regime.DFA} {
+			name := fmt.Sprintf("%v Hurst of increments with autocorrelation %v", method, walk.phi)
+			config := regime.ClassifierConfig{Period: 128, Method: method, Band: 0.05}
+			classes, err := regime.Classify(x, config)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if classes.BeginIndex != regime.ClassifyLookback(128) || classes.NBElement != len(x)-127 || len(classes.Regimes) != classes.NBElement {
+				t.Fatalf("%s: %d regimes from %d", name, len(classes.Regimes), classes.BeginIndex)
+			}
+			mean := 0.0
+			counts := make(map[regime.Regime]int)
+			for i, h := range classes.Values {
+				mean += h / float64(classes.NBElement)
+				counts[classes.Regimes[i]]++
+			}
+			if mean < walk.low || mean > walk.high {
+				t.Errorf("%s averages %v, want it in [%v, %v]", name, mean, walk.low, walk.high)
+			}
+			if walk.classified && counts[walk.regime] < classes.NBElement*3/4 {
+				t.Errorf("%s: %v regimes %v", name, walk.regime, counts)
+			}
+		}
+	}
+}
+
+func TestHurst(t *testing.T) {
+	// A constant series has no memory
+	constant := make([]float64, 40)
+	if h, err := regime.Hurst(constant, 20, regime.DFA); err != nil || h.Values[0] != 0.5 {
+		t.Errorf("Hurst of a constant series %v %v", h, err)
+	}
+
+	x := make([]float64, 100)
+	for i := range x {
+		x[i] = float64(i % 7)
+	}
+	if _, err := regime.Hurst(x, 16, regime.RescaledRange); !errors.Is(err, utils.ErrInvalidParameter) {
+		t.Errorf("Hurst over 16 values: %v", err)
+	}
+	if _, err := regime.Hurst(x, 64, regime.HurstMethod(2)); !errors.Is(err, utils.ErrInvalidParameter) {
+		t.Errorf("unknown Hurst method: %v", err)
+	}
+}
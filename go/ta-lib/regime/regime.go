@@ -0,0 +1,220 @@
+// Package regime measures whether a market trends, reverts to its mean or
+// walks randomly: the rolling Hurst exponent by rescaled range or detrended
+// fluctuation analysis, the fractal dimension index, the choppiness index
+// and the efficiency ratio, and a classifier labelling every bar with its
+// regime to switch between trend following and mean reversion. The rolling
+// measures are utils.Result values.
+package regime
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// EfficiencyRatioLookback returns the number of values consumed before the
+// first efficiency ratio
+func EfficiencyRatioLookback(period int) int {
+	return period
+}
+
+// EfficiencyRatio returns the efficiency ratio of Kaufman that drives KAMA:
+// the net change over period values divided by the sum of the absolute
+// changes, from 0 for noise to 1 for a straight move. A window without any
+// change gives 0.
+func EfficiencyRatio(inReal []float64, period int) (*utils.Result, error) {
+	if err := utils.CheckSeries(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", period, 1); err != nil {
+		return nil, err
+	}
+	lookback := EfficiencyRatioLookback(period)
+	if lookback >= len(inReal) {
+		return utils.NewResult(0, nil), nil
+	}
+	// The path length slides with the window in a compensated sum
+	var sum utils.KahanSum
+	for i := 1; i < lookback; i++ {
+		sum.Add(math.Abs(inReal[i] - inReal[i-1]))
+	}
+	out := make([]float64, 0, len(inReal)-lookback)
+	for today := lookback; today < len(inReal); today++ {
+		sum.Add(math.Abs(inReal[today] - inReal[today-1]))
+		if trailing := today - period; trailing > 0 {
+			sum.Add(-math.Abs(inReal[trailing] - inReal[trailing-1]))
+		}
+		path := sum.Total()
+		ratio := 0.0
+		if path > 0 {
+			ratio = min(math.Abs(inReal[today]-inReal[today-period])/path, 1)
+		}
+		out = append(out, ratio)
+	}
+	return utils.NewResult(lookback, out), nil
+}
+
+// FractalDimensionLookback returns the number of values consumed before the
+// first fractal dimension
+func FractalDimensionLookback(period int) int {
+	return period - 1
+}
+
+// FractalDimension returns the fractal dimension index of every window of
+// period values by the method of Sevcik: with the window scaled to a unit
+// square, 1 + ln(L) / ln(2(period-1)) for a curve of length L. It is close
+// to 1 for a straight move, 1.5 for a random walk and towards 2 for a
+// choppy market. A flat window gives 1.
+func FractalDimension(inReal []float64, period int) (*utils.Result, error) {
+	if err := utils.CheckSeries(inReal); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", period, 2); err != nil {
+		return nil, err
+	}
+	lookback := FractalDimensionLookback(period)
+	if lookback >= len(inReal) {
+		return utils.NewResult(0, nil), nil
+	}
+	step := 1 / float64(period-1)
+	out := make([]float64, 0, len(inReal)-lookback)
+	for today := lookback; today < len(inReal); today++ {
+		window := inReal[today-period+1 : today+1]
+		lowest, highest := utils.MinInSlice(window), utils.MaxInSlice(window)
+		length := 0.0
+		for i := 1; i < len(window); i++ {
+			dy := 0.0
+			if highest > lowest {
+				dy = (window[i] - window[i-1]) / (highest - lowest)
+			}
+			length += math.Hypot(dy, step)
+		}
+		out = append(out, 1+math.Log(length)/math.Log(2*float64(period-1)))
+	}
+	return utils.NewResult(lookback, out), nil
+}
+
+// ChoppinessLookback returns the number of bars consumed before the first
+// choppiness index
+func ChoppinessLookback(period int) int {
+	return period
+}
+
+// Choppiness returns the choppiness index of every window of period bars:
+// 100 log10(sum of the true ranges / (highest high - lowest low)) /
+// log10(period). It goes from 0 for a straight move to 100 for a market
+// going back and forth over its range, readings above 61.8 being commonly
+// taken as choppy and below 38.2 as trending. A window without range gives
+// 0.
+func Choppiness(inHigh, inLow, inClose []float64, period int) (*utils.Result, error) {
+	if err := utils.CheckSeries(inHigh, inLow, inClose); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("time period", period, 2); err != nil {
+		return nil, err
+	}
+	lookback := ChoppinessLookback(period)
+	if lookback >= len(inClose) {
+		return utils.NewResult(0, nil), nil
+	}
+	out := make([]float64, 0, len(inClose)-lookback)
+	for today := lookback; today < len(inClose); today++ {
+		start := today - period + 1
+		highest, lowest := utils.MaxInSlice(inHigh[start:today+1]), utils.MinInSlice(inLow[start:today+1])
+		ranges := 0.0
+		for i := start; i <= today; i++ {
+			ranges += max(inHigh[i], inClose[i-1]) - min(inLow[i], inClose[i-1])
+		}
+		chop := 0.0
+		if highest > lowest {
+			chop = 100 * math.Log10(ranges/(highest-lowest)) / math.Log10(float64(period))
+		}
+		out = append(out, chop)
+	}
+	return utils.NewResult(lookback, out), nil
+}
+
+// Regime labels the behaviour of a market
+type Regime int
+
+const (
+	Random        Regime = iota // No exploitable memory
+	Trending                    // Persistent moves
+	MeanReverting               // Moves that tend to reverse
+)
+
+// String returns the name of the regime
+func (r Regime) String() string {
+	switch r {
+	case Random:
+		return "random"
+	case Trending:
+		return "trending"
+	case MeanReverting:
+		return "mean-reverting"
+	}
+	return fmt.Sprintf("Regime(%d)", int(r))
+}
+
+// ClassifierConfig configures the regime classifier
+type ClassifierConfig struct {
+	Period        int         // Values in the window of the Hurst exponent and the efficiency ratio
+	Method        HurstMethod // Estimator of the Hurst exponent
+	Band          float64     // Distance from 0.5 of the Hurst exponent still considered random, such as 0.05
+	MinEfficiency float64     // Efficiency ratio needed to confirm a trend, 0 to only use the Hurst exponent
+}
+
+func (c ClassifierConfig) validate() error {
+	if !(c.Band >= 0 && c.Band < 0.5) || !(c.MinEfficiency >= 0 && c.MinEfficiency <= 1) {
+		return fmt.Errorf("classifier config %+v: %w", c, utils.ErrInvalidParameter)
+	}
+	return nil
+}
+
+// RegimeResult holds the regime of every bar with the Hurst exponents in
+// Values and the efficiency ratios they were classified with
+type RegimeResult struct {
+	utils.Result
+	Efficiency []float64
+	Regimes    []Regime
+}
+
+// ClassifyLookback returns the number of values consumed before the first
+// regime
+func ClassifyLookback(period int) int {
+	return HurstLookback(period)
+}
+
+// Classify labels every bar with the regime of the period values ending at
+// it: trending when the Hurst exponent is above 0.5 + Band and the
+// efficiency ratio over the window reaches MinEfficiency, mean-reverting
+// when it is below 0.5 - Band, and random otherwise.
+func Classify(inReal []float64, config ClassifierConfig) (*RegimeResult, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	hurst, err := Hurst(inReal, config.Period, config.Method)
+	if err != nil {
+		return nil, err
+	}
+	out := &RegimeResult{Result: *hurst, Efficiency: []float64{}, Regimes: []Regime{}}
+	if hurst.NBElement == 0 {
+		return out, nil
+	}
+	efficiency, err := EfficiencyRatio(inReal, config.Period-1)
+	if err != nil {
+		return nil, err
+	}
+	out.Efficiency = efficiency.Values
+	out.Regimes = make([]Regime, len(hurst.Values))
+	for i, h := range hurst.Values {
+		switch {
+		case h > 0.5+config.Band && out.Efficiency[i] >= config.MinEfficiency:
+			out.Regimes[i] = Trending
+		case h < 0.5-config.Band:
+			out.Regimes[i] = MeanReverting
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,77 @@
+package regime_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/regime"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Efficiency ratio: net change 2 over a path of 4, and a straight line
+func TestEfficiencyRatio(t *testing.T) {
+	er, err := regime.EfficiencyRatio([]float64{1, 2, 1, 2, 3, 4, 5, 6}, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if er.BeginIndex != 4 || er.NBElement != 4 {
+		t.Fatalf("efficiency ratio %d values from %d", er.NBElement, er.BeginIndex)
+	}
+	testutil.Near(t, "efficiency ratio 4", er.Values[0], 0.5, 1e-15)
+	testutil.Near(t, "efficiency ratio 7", er.Values[3], 1, 1e-15)
+}
+
+// Fractal dimension: a peak, a straight line and a flat window
+func TestFractalDimension(t *testing.T) {
+	fdi, err := regime.FractalDimension([]float64{0, 1, 0, 1, 2, 2, 2}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.Near(t, "fractal dimension of a peak", fdi.Values[0], 1+math.Log(2*math.Hypot(1, 0.5))/math.Log(4), 1e-15)
+	testutil.Near(t, "fractal dimension of a line", fdi.Values[2], 1+math.Log(math.Sqrt2)/math.Log(4), 1e-15)
+	testutil.Near(t, "fractal dimension of a flat window", fdi.Values[4], 1, 1e-15)
+}
+
+// Choppiness: true ranges of 2 over a range of 3
+func TestChoppiness(t *testing.T) {
+	high := []float64{10, 11, 12, 11}
+	low := []float64{9, 9, 10, 9}
+	close := []float64{10, 10, 11, 10}
+	chop, err := regime.Choppiness(high, low, close, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chop.BeginIndex != 3 || chop.NBElement != 1 {
+		t.Fatalf("choppiness %d values from %d", chop.NBElement, chop.BeginIndex)
+	}
+	testutil.Near(t, "choppiness", chop.Values[0], 100*math.Log10(6.0/3)/math.Log10(3), 1e-12)
+
+	if _, err := regime.Choppiness(high, low[1:], close, 3); !errors.Is(err, utils.ErrMismatchedInputLengths) {
+		t.Errorf("choppiness of mismatched bars: %v", err)
+	}
+}
+
+// The efficiency confirms trends
+func TestClassifyEfficiency(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	close := utils.GetFieldSlice(data, utils.FieldClose)
+	loose, err := regime.Classify(close, regime.ClassifierConfig{Period: 64, Band: 0.05})
+	if err != nil {
+		t.Fatal(err)
+	}
+	strict, err := regime.Classify(close, regime.ClassifierConfig{Period: 64, Band: 0.05, MinEfficiency: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range strict.Regimes {
+		if strict.Regimes[i] == regime.Trending && (loose.Regimes[i] != regime.Trending || strict.Efficiency[i] < 0.5) {
+			t.Fatalf("trend at %d with efficiency %v", i, strict.Efficiency[i])
+		}
+	}
+
+	if _, err := regime.Classify(close, regime.ClassifierConfig{Period: 64, Band: 0.5}); !errors.Is(err, utils.ErrInvalidParameter) {
+		t.Errorf("band 0.5: %v", err)
+	}
+}
@@ -0,0 +1,228 @@
+package signals
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Swing is a confirmed swing high or low
+type Swing struct {
+	Index int     // Index of the bar on the input bars
+	Value float64 // Value at the swing
+}
+
+// SwingHighs finds the bars whose value is above the strength bars before
+// and not below the strength bars after. A swing is only known strength bars
+// after it happens, so the last strength bars never hold one.
+func SwingHighs(r *utils.Result, strength int) []Swing {
+	return swings(r, strength, func(v, other float64) bool { return v > other })
+}
+
+// SwingLows finds the bars whose value is below the strength bars before
+// and not above the strength bars after
+func SwingLows(r *utils.Result, strength int) []Swing {
+	return swings(r, strength, func(v, other float64) bool { return v < other })
+}
+
+// swings keeps the bars beyond their neighbours. The comparison is strict on
+// the left only, so that a flat top gives a single swing on its first bar.
+func swings(r *utils.Result, strength int, beyond func(v, other float64) bool) []Swing {
+	var out []Swing
+	strength = max(strength, 1)
+	for j := strength; j+strength < len(r.Values); j++ {
+		v := r.Values[j]
+		swing := true
+		for k := 1; k <= strength && swing; k++ {
+			swing = beyond(v, r.Values[j-k]) && !beyond(r.Values[j+k], v)
+		}
+		if swing {
+			out = append(out, Swing{Index: r.BeginIndex + j, Value: v})
+		}
+	}
+	return out
+}
+
+// DivergenceKind classifies divergences
+type DivergenceKind int
+
+const (
+	RegularBullish DivergenceKind = iota // Price makes a lower low, the indicator a higher low
+	RegularBearish                       // Price makes a higher high, the indicator a lower high
+	HiddenBullish                        // Price makes a higher low, the indicator a lower low
+	HiddenBearish                        // Price makes a lower high, the indicator a higher high
+)
+
+// String returns the name of the divergence kind
+func (k DivergenceKind) String() string {
+	switch k {
+	case RegularBullish:
+		return "regular bullish"
+	case RegularBearish:
+		return "regular bearish"
+	case HiddenBullish:
+		return "hidden bullish"
+	case HiddenBearish:
+		return "hidden bearish"
+	}
+	return fmt.Sprintf("DivergenceKind(%d)", int(k))
+}
+
+// Bullish reports whether the divergence points to a rise
+func (k DivergenceKind) Bullish() bool {
+	return k == RegularBullish || k == HiddenBullish
+}
+
+// DivergenceConfig configures the divergence detection
+type DivergenceConfig struct {
+	Strength  int // Bars on each side of a swing, at least 1
+	MaxBars   int // Most bars between the two swings, 0 for no limit
+	Tolerance int // Most bars between a price swing and the matching indicator swing
+}
+
+// Divergence is a pair of swings where price and indicator disagree
+type Divergence struct {
+	Kind        DivergenceKind
+	Start       int        // Bar of the first price swing
+	End         int        // Bar of the second price swing
+	Price       [2]float64 // Price at the two swings
+	Indicator   [2]float64 // Indicator at the two matching swings
+	IndicatorAt [2]int     // Bars of the indicator swings
+	Strength    float64    // Between 0 and 1, see Divergences
+}
+
+// Divergences pairs consecutive price swings, highs on high and lows on low,
+// with the indicator swings found within Tolerance bars of them, and reports
+// where price and indicator move in opposite directions. Pass the closes as
+// both high and low to use closing prices.
+//
+// The indicator may be any single-output result on the same bars, such as
+// RSI, a MACD histogram, OBV or MFI. Strength is the average of the price and
+// indicator moves between the swings, each divided by its range over the
+// divergence, so 1 means both moves span their whole range. Divergences are
+// sorted by their end bar.
+func Divergences(high, low []float64, indicator *utils.Result, config DivergenceConfig) ([]Divergence, error) {
+	if len(high) != len(low) {
+		return nil, utils.ErrMismatchedInputLengths
+	}
+	if indicator == nil {
+		return nil, utils.ErrEmptyInputData
+	}
+	if indicator.BeginIndex+len(indicator.Values) > len(high) {
+		return nil, fmt.Errorf("indicator has %d bars, price has %d: %w", indicator.BeginIndex+len(indicator.Values), len(high), utils.ErrMismatchedInputLengths)
+	}
+	if config.Strength < 1 || config.MaxBars < 0 || config.Tolerance < 0 {
+		return nil, fmt.Errorf("divergence strength %d, max bars %d, tolerance %d: %w", config.Strength, config.MaxBars, config.Tolerance, utils.ErrInvalidParameter)
+	}
+
+	highs := utils.NewResult(0, high)
+	lows := utils.NewResult(0, low)
+	d := &detector{config: config, indicator: indicator, high: high, low: low}
+	d.pair(SwingLows(lows, config.Strength), SwingLows(indicator, config.Strength), RegularBullish, HiddenBullish)
+	d.pair(SwingHighs(highs, config.Strength), SwingHighs(indicator, config.Strength), RegularBearish, HiddenBearish)
+
+	sort.SliceStable(d.found, func(a, b int) bool { return d.found[a].End < d.found[b].End })
+	return d.found, nil
+}
+
+// detector collects the divergences of both directions
+type detector struct {
+	config    DivergenceConfig
+	indicator *utils.Result
+	high, low []float64
+	found     []Divergence
+}
+
+// pair compares consecutive price swings with the matching indicator swings
+func (d *detector) pair(price, ind []Swing, regular, hidden DivergenceKind) {
+	lows := regular == RegularBullish
+	for k := 1; k < len(price); k++ {
+		p1, p2 := price[k-1], price[k]
+		if d.config.MaxBars > 0 && p2.Index-p1.Index > d.config.MaxBars {
+			continue
+		}
+		i1, ok1 := nearest(ind, p1.Index, d.config.Tolerance)
+		i2, ok2 := nearest(ind, p2.Index, d.config.Tolerance)
+		if !ok1 || !ok2 || i1.Index >= i2.Index {
+			continue
+		}
+
+		// Lower lows and higher highs are regular, the reverse is hidden
+		var kind DivergenceKind
+		switch {
+		case p2.Value < p1.Value && i2.Value > i1.Value:
+			kind = regular
+			if !lows {
+				kind = hidden
+			}
+		case p2.Value > p1.Value && i2.Value < i1.Value:
+			kind = hidden
+			if !lows {
+				kind = regular
+			}
+		default:
+			continue
+		}
+		d.found = append(d.found, Divergence{
+			Kind:        kind,
+			Start:       p1.Index,
+			End:         p2.Index,
+			Price:       [2]float64{p1.Value, p2.Value},
+			Indicator:   [2]float64{i1.Value, i2.Value},
+			IndicatorAt: [2]int{i1.Index, i2.Index},
+			Strength:    d.strength(p1, p2, i1, i2),
+		})
+	}
+}
+
+// strength averages the price and indicator moves relative to their ranges
+func (d *detector) strength(p1, p2, i1, i2 Swing) float64 {
+	priceLow, priceHigh := d.low[p1.Index], d.high[p1.Index]
+	for i := p1.Index; i <= p2.Index; i++ {
+		priceLow, priceHigh = min(priceLow, d.low[i]), max(priceHigh, d.high[i])
+	}
+	indLow, indHigh := i1.Value, i1.Value
+	for i := i1.Index; i <= i2.Index; i++ {
+		v := value(d.indicator, i)
+		indLow, indHigh = min(indLow, v), max(indHigh, v)
+	}
+	return (ratio(p2.Value-p1.Value, priceHigh-priceLow) + ratio(i2.Value-i1.Value, indHigh-indLow)) / 2
+}
+
+// ratio returns |move| / span, 0 when the span is 0
+func ratio(move, span float64) float64 {
+	if span <= 0 {
+		return 0
+	}
+	if move < 0 {
+		move = -move
+	}
+	return min(move/span, 1)
+}
+
+// nearest finds the swing closest to bar i within tolerance bars, the
+// earlier one on a tie
+func nearest(swings []Swing, i, tolerance int) (Swing, bool) {
+	best, found := Swing{}, false
+	for _, s := range swings {
+		dist := s.Index - i
+		if dist < -tolerance {
+			continue
+		}
+		if dist > tolerance {
+			break
+		}
+		if !found || abs(dist) < abs(best.Index-i) {
+			best, found = s, true
+		}
+	}
+	return best, found
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,69 @@
+package signals_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/indicators"
+	"github.com/petercool/ta-lib/go/ta-lib/signals"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// A lower low in price with a higher low in the indicator
+func TestDivergenceSynthetic(t *testing.T) {
+	low := []float64{11, 10, 8, 6, 8, 10, 9, 5, 9, 10}
+	high := make([]float64, len(low))
+	for i, v := range low {
+		high[i] = v + 1
+	}
+	osc := &utils.Result{BeginIndex: 1, NBElement: 9, Values: []float64{50, 40, 30, 40, 50, 45, 35, 45, 50}}
+	found, err := signals.Divergences(high, low, osc, signals.DivergenceConfig{Strength: 2, Tolerance: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Kind != signals.RegularBullish || found[0].Start != 3 || found[0].End != 7 {
+		t.Errorf("divergences %+v, want one regular bullish from bar 3 to 7", found)
+	}
+}
+
+// On the sample data every divergence is consistent with its swings
+func TestDivergences(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	_, h, l, close, _ := utils.GetOHLCVSlices(data)
+	rsi, _ := indicators.RSI(close, 14)
+	found, err := signals.Divergences(h, l, rsi, signals.DivergenceConfig{Strength: 3, MaxBars: 60, Tolerance: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) == 0 {
+		t.Fatalf("no divergence between price and RSI in %d bars", len(data))
+	}
+	for k, d := range found {
+		priceUp, indUp := d.Price[1] > d.Price[0], d.Indicator[1] > d.Indicator[0]
+		prices := l
+		if !d.Kind.Bullish() {
+			prices = h
+		}
+		regular := d.Kind == signals.RegularBullish || d.Kind == signals.RegularBearish
+		switch {
+		case priceUp == indUp:
+			t.Errorf("%v divergence at bars %d-%d moves with price", d.Kind, d.Start, d.End)
+		case regular != (priceUp != d.Kind.Bullish()):
+			t.Errorf("%v divergence at bars %d-%d has price moving %v", d.Kind, d.Start, d.End, priceUp)
+		case prices[d.Start] != d.Price[0] || prices[d.End] != d.Price[1] || d.End-d.Start > 60:
+			t.Errorf("%v divergence at bars %d-%d does not match the prices", d.Kind, d.Start, d.End)
+		case d.Strength < 0 || d.Strength > 1:
+			t.Errorf("%v divergence at bars %d-%d has strength %v", d.Kind, d.Start, d.End, d.Strength)
+		case k > 0 && found[k-1].End > d.End:
+			t.Errorf("divergences are not sorted at bar %d", d.End)
+		}
+	}
+
+	if _, err := signals.Divergences(h, l, rsi, signals.DivergenceConfig{}); !errors.Is(err, utils.ErrInvalidParameter) {
+		t.Errorf("zero swing strength gave error %v", err)
+	}
+	if _, err := signals.Divergences(h, l, nil, signals.DivergenceConfig{Strength: 3}); !errors.Is(err, utils.ErrEmptyInputData) {
+		t.Errorf("nil indicator gave error %v", err)
+	}
+}
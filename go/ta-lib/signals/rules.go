@@ -0,0 +1,168 @@
+package signals
+
+import (
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// constant returns a result with the same level on every bar
+func constant(level float64, like *utils.Result) *utils.Result {
+	values := make([]float64, len(like.Values))
+	for i := range values {
+		values[i] = level
+	}
+	return utils.NewResult(like.BeginIndex, values)
+}
+
+// CrossAbove is true on the bars where a moves above b: a is above b on the
+// bar and was at or below it on the previous one
+func CrossAbove(a, b *utils.Result) *Signal {
+	return build(overlap(1, a, b), func(i int) bool {
+		return value(a, i) > value(b, i) && value(a, i-1) <= value(b, i-1)
+	})
+}
+
+// CrossBelow is true on the bars where a moves below b
+func CrossBelow(a, b *utils.Result) *Signal {
+	return build(overlap(1, a, b), func(i int) bool {
+		return value(a, i) < value(b, i) && value(a, i-1) >= value(b, i-1)
+	})
+}
+
+// CrossAboveLevel is true on the bars where a moves above a fixed level
+func CrossAboveLevel(a *utils.Result, level float64) *Signal {
+	return CrossAbove(a, constant(level, a))
+}
+
+// CrossBelowLevel is true on the bars where a moves below a fixed level
+func CrossBelowLevel(a *utils.Result, level float64) *Signal {
+	return CrossBelow(a, constant(level, a))
+}
+
+// Above is true where a is greater than b
+func Above(a, b *utils.Result) *Signal {
+	return build(overlap(0, a, b), func(i int) bool { return value(a, i) > value(b, i) })
+}
+
+// Below is true where a is less than b
+func Below(a, b *utils.Result) *Signal {
+	return build(overlap(0, a, b), func(i int) bool { return value(a, i) < value(b, i) })
+}
+
+// AboveLevel is true where a is greater than a fixed level
+func AboveLevel(a *utils.Result, level float64) *Signal {
+	return Above(a, constant(level, a))
+}
+
+// BelowLevel is true where a is less than a fixed level
+func BelowLevel(a *utils.Result, level float64) *Signal {
+	return Below(a, constant(level, a))
+}
+
+// Threshold is a state with hysteresis: it turns true when a goes beyond
+// the entry level and stays true until a goes back beyond the exit level.
+// With entry above exit, such as 70 and 50 on an RSI, the state is entered
+// when a > entry and left when a < exit. With entry below exit, such as 30
+// and 50, it is entered when a < entry and left when a > exit. Between the
+// levels the previous state holds, which avoids flickering around a single
+// level.
+func Threshold(a *utils.Result, entry, exit float64) *Signal {
+	enter := func(v float64) bool { return v > entry }
+	leave := func(v float64) bool { return v < exit }
+	if entry < exit {
+		enter = func(v float64) bool { return v < entry }
+		leave = func(v float64) bool { return v > exit }
+	}
+
+	active := false
+	return build(overlap(0, a), func(i int) bool {
+		v := value(a, i)
+		if !active && enter(v) {
+			active = true
+		} else if active && leave(v) {
+			active = false
+		}
+		return active
+	})
+}
+
+// Rising is true where a increased on each of the last n bars
+func Rising(a *utils.Result, n int) *Signal {
+	return run(a, n, func(prev, cur float64) bool { return cur > prev })
+}
+
+// Falling is true where a decreased on each of the last n bars
+func Falling(a *utils.Result, n int) *Signal {
+	return run(a, n, func(prev, cur float64) bool { return cur < prev })
+}
+
+// run is true where step holds between each of the last n pairs of bars
+func run(a *utils.Result, n int, step func(prev, cur float64) bool) *Signal {
+	n = max(n, 1)
+	return build(overlap(n, a), func(i int) bool {
+		for k := i - n + 1; k <= i; k++ {
+			if !step(value(a, k-1), value(a, k)) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// BarsSince counts the bars since the signal was last true, 0 on the bar of
+// the event. The result starts on the first event.
+func BarsSince(s *Signal) *utils.Result {
+	var out []float64
+	outBegIdx, last := -1, 0
+	for j, event := range s.Values {
+		i := s.BeginIndex + j
+		if event {
+			last = i
+			if outBegIdx < 0 {
+				outBegIdx = i
+			}
+		}
+		if outBegIdx >= 0 {
+			out = append(out, float64(i-last))
+		}
+	}
+	return utils.NewResult(outBegIdx, out)
+}
+
+// HighestSince is the highest value of a since the signal was last true,
+// the bar of the event included. The result starts on the first event where
+// a has a value.
+func HighestSince(a *utils.Result, s *Signal) *utils.Result {
+	return extremeSince(a, s, func(prev, cur float64) bool { return cur > prev })
+}
+
+// LowestSince is the lowest value of a since the signal was last true,
+// the bar of the event included
+func LowestSince(a *utils.Result, s *Signal) *utils.Result {
+	return extremeSince(a, s, func(prev, cur float64) bool { return cur < prev })
+}
+
+// extremeSince keeps the best value of a since the last event
+func extremeSince(a *utils.Result, s *Signal, better func(prev, cur float64) bool) *utils.Result {
+	sp := overlap(0, a)
+	begIdx := max(sp.begIdx, s.BeginIndex)
+	var out []float64
+	outBegIdx := -1
+	extreme := 0.0
+	for i := begIdx; i < sp.endIdx; i++ {
+		event, _ := s.At(i)
+		v := value(a, i)
+		switch {
+		case event:
+			extreme = v
+			if outBegIdx < 0 {
+				outBegIdx = i
+			}
+		case outBegIdx < 0:
+			continue
+		case better(extreme, v):
+			extreme = v
+		}
+		out = append(out, extreme)
+	}
+	return utils.NewResult(outBegIdx, out)
+}
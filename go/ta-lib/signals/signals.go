@@ -0,0 +1,199 @@
+// Package signals derives trading conditions from indicator outputs:
+// crossovers, thresholds with hysteresis, rising and falling runs, and the
+// extremes since an event.
+//
+// Inputs are indicator results whose BeginIndex counts the input bars, so
+// results with different lookbacks are compared on the same bars. Outputs
+// keep that convention: a Signal starts on the first bar where all of its
+// inputs have a value, and Events attaches the bar timestamps.
+package signals
+
+import (
+	"math"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Signal is a boolean series on the input bars
+type Signal struct {
+	BeginIndex int    // Index of the first value on the input bars
+	Values     []bool // One value per bar from BeginIndex
+}
+
+// Event is a bar where a signal is true
+type Event struct {
+	Index int   // Index of the bar
+	Time  int64 // Timestamp of the bar
+}
+
+// newSignal creates a signal, with BeginIndex 0 when it has no values
+func newSignal(begIdx int, values []bool) *Signal {
+	if len(values) == 0 {
+		return &Signal{Values: []bool{}}
+	}
+	return &Signal{BeginIndex: begIdx, Values: values}
+}
+
+// Len returns the number of values
+func (s *Signal) Len() int {
+	return len(s.Values)
+}
+
+// At returns the value on bar i, false when the signal has no value there
+func (s *Signal) At(i int) (value, ok bool) {
+	if j := i - s.BeginIndex; j >= 0 && j < len(s.Values) {
+		return s.Values[j], true
+	}
+	return false, false
+}
+
+// Indexes returns the bars where the signal is true
+func (s *Signal) Indexes() []int {
+	var out []int
+	for j, v := range s.Values {
+		if v {
+			out = append(out, s.BeginIndex+j)
+		}
+	}
+	return out
+}
+
+// Events returns the bars where the signal is true with their timestamps
+func (s *Signal) Events(times []int64) []Event {
+	indexes := s.Indexes()
+	out := make([]Event, len(indexes))
+	for k, i := range indexes {
+		out[k] = Event{Index: i, Time: times[i]}
+	}
+	return out
+}
+
+// Series maps the signal onto the bar timestamps as 1 and 0, NaN before BeginIndex
+func (s *Signal) Series(times []int64) *utils.Series {
+	values := make([]float64, len(times))
+	for i := range values {
+		values[i] = math.NaN()
+		if v, ok := s.At(i); ok {
+			values[i] = boolValue(v)
+		}
+	}
+	return &utils.Series{Time: times, Values: values}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// FromSeries converts a NaN padded series, one value per bar, into a result
+// starting at its first value
+func FromSeries(s *utils.Series) *utils.Result {
+	begIdx := 0
+	for begIdx < len(s.Values) && math.IsNaN(s.Values[begIdx]) {
+		begIdx++
+	}
+	return utils.NewResult(begIdx, s.Values[begIdx:])
+}
+
+// span is a range of bars covered by every input
+type span struct {
+	begIdx, endIdx int // endIdx is exclusive
+}
+
+// overlap returns the bars where all results have a value, skipping the
+// first extra bars so that the previous bars are available too
+func overlap(extra int, results ...*utils.Result) span {
+	if len(results) == 0 {
+		return span{}
+	}
+	sp := span{begIdx: 0, endIdx: math.MaxInt}
+	for _, r := range results {
+		sp.begIdx = max(sp.begIdx, r.BeginIndex)
+		sp.endIdx = min(sp.endIdx, r.BeginIndex+len(r.Values))
+	}
+	sp.begIdx += extra
+	if sp.endIdx < sp.begIdx {
+		sp.endIdx = sp.begIdx
+	}
+	return sp
+}
+
+// value returns the value of a result on bar i
+func value(r *utils.Result, i int) float64 {
+	return r.Values[i-r.BeginIndex]
+}
+
+// build evaluates a condition on every bar of a span
+func build(sp span, cond func(i int) bool) *Signal {
+	values := make([]bool, sp.endIdx-sp.begIdx)
+	for j := range values {
+		values[j] = cond(sp.begIdx + j)
+	}
+	return newSignal(sp.begIdx, values)
+}
+
+// signalSpan returns the bars where all signals have a value
+func signalSpan(signals ...*Signal) span {
+	if len(signals) == 0 {
+		return span{}
+	}
+	sp := span{begIdx: 0, endIdx: math.MaxInt}
+	for _, s := range signals {
+		sp.begIdx = max(sp.begIdx, s.BeginIndex)
+		sp.endIdx = min(sp.endIdx, s.BeginIndex+len(s.Values))
+	}
+	if sp.endIdx < sp.begIdx {
+		sp.endIdx = sp.begIdx
+	}
+	return sp
+}
+
+// And is true where all signals are true
+func And(signals ...*Signal) *Signal {
+	return build(signalSpan(signals...), func(i int) bool {
+		for _, s := range signals {
+			if v, _ := s.At(i); !v {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Or is true where any signal is true
+func Or(signals ...*Signal) *Signal {
+	return build(signalSpan(signals...), func(i int) bool {
+		for _, s := range signals {
+			if v, _ := s.At(i); v {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Not inverts a signal
+func Not(s *Signal) *Signal {
+	return build(signalSpan(s), func(i int) bool {
+		v, _ := s.At(i)
+		return !v
+	})
+}
+
+// Starts is true on the bars where a signal turns true
+func Starts(s *Signal) *Signal {
+	sp := signalSpan(s)
+	sp.begIdx = min(sp.begIdx+1, sp.endIdx)
+	return build(sp, func(i int) bool {
+		prev, _ := s.At(i - 1)
+		cur, _ := s.At(i)
+		return cur && !prev
+	})
+}
+
+// Ends is true on the bars where a signal turns false
+func Ends(s *Signal) *Signal {
+	return Starts(Not(s))
+}
@@ -0,0 +1,141 @@
+package signals_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/formula"
+	"github.com/petercool/ta-lib/go/ta-lib/indicators"
+	"github.com/petercool/ta-lib/go/ta-lib/signals"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+func at(r *utils.Result, i int) float64 { return r.Values[i-r.BeginIndex] }
+
+// sameSignal compares a signal with a rule evaluated from a bar
+func sameSignal(t *testing.T, name string, s *signals.Signal, begIdx, endIdx int, want func(i int) bool) {
+	t.Helper()
+	if s.BeginIndex != begIdx || s.BeginIndex+s.Len() != endIdx {
+		t.Errorf("%s: bars [%d, %d), want [%d, %d)", name, s.BeginIndex, s.BeginIndex+s.Len(), begIdx, endIdx)
+		return
+	}
+	for i := begIdx; i < endIdx; i++ {
+		if got, _ := s.At(i); got != want(i) {
+			t.Errorf("%s: bar %d is %v", name, i, got)
+			return
+		}
+	}
+}
+
+// Crossovers match the formulas, with results of different lookbacks
+func TestCrossFormula(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	_, _, _, close, _ := utils.GetOHLCVSlices(data)
+	ema12, _ := indicators.EMA(close, 12)
+	ema26, _ := indicators.EMA(close, 26)
+	rsi, _ := indicators.RSI(close, 14)
+	for _, tc := range []struct {
+		name, source string
+		signal       *signals.Signal
+	}{
+		{"CrossAbove", "CROSSOVER(EMA(close,12), EMA(close,26))", signals.CrossAbove(ema12, ema26)},
+		{"CrossBelowLevel", "CROSSUNDER(RSI(close,14), 50)", signals.CrossBelowLevel(rsi, 50)},
+	} {
+		p, err := formula.Compile(tc.source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := p.Run(utils.BarsFromOHLCV(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameSignal(t, tc.name, tc.signal, f.BeginIndex, len(data), func(i int) bool { return at(f, i) == 1 })
+	}
+}
+
+// Thresholds hold with hysteresis, in both directions
+func TestThreshold(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	_, _, _, close, _ := utils.GetOHLCVSlices(data)
+	rsi, _ := indicators.RSI(close, 14)
+	for _, levels := range [][2]float64{{60, 50}, {40, 50}} {
+		entry, exit := levels[0], levels[1]
+		active := false
+		sameSignal(t, fmt.Sprintf("Threshold %v/%v", entry, exit), signals.Threshold(rsi, entry, exit), rsi.BeginIndex, len(data), func(i int) bool {
+			v := at(rsi, i)
+			switch {
+			case entry > exit && !active:
+				active = v > entry
+			case entry > exit:
+				active = v >= exit
+			case !active:
+				active = v < entry
+			default:
+				active = v <= exit
+			}
+			return active
+		})
+	}
+}
+
+// Rising runs of three bars and their combinations
+func TestCombinations(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	_, _, _, close, _ := utils.GetOHLCVSlices(data)
+	ema12, _ := indicators.EMA(close, 12)
+	ema26, _ := indicators.EMA(close, 26)
+	n := len(data)
+
+	rising := signals.Rising(ema12, 3)
+	sameSignal(t, "Rising", rising, ema12.BeginIndex+3, n, func(i int) bool {
+		return at(ema12, i) > at(ema12, i-1) && at(ema12, i-1) > at(ema12, i-2) && at(ema12, i-2) > at(ema12, i-3)
+	})
+	starts := signals.Starts(signals.And(rising, signals.Above(ema12, ema26)))
+	sameSignal(t, "Starts(And)", starts, ema26.BeginIndex+1, n, func(i int) bool {
+		cur, _ := rising.At(i)
+		prev, _ := rising.At(i - 1)
+		return cur && at(ema12, i) > at(ema26, i) && !(prev && at(ema12, i-1) > at(ema26, i-1))
+	})
+}
+
+// Bars and highest close since each crossover
+func TestSince(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	_, _, _, close, _ := utils.GetOHLCVSlices(data)
+	ema12, _ := indicators.EMA(close, 12)
+	ema26, _ := indicators.EMA(close, 26)
+	n := len(data)
+	cross := signals.CrossAbove(ema12, ema26)
+
+	events := cross.Events(utils.BarsFromOHLCV(data).Time)
+	if len(events) == 0 {
+		t.Fatalf("no crossover in %d bars", n)
+	}
+	for _, e := range events {
+		if e.Time != data[e.Index].Time {
+			t.Errorf("event at bar %d has time %d", e.Index, e.Time)
+		}
+	}
+	closes := &utils.Result{NBElement: n, Values: close}
+	barsSince := signals.BarsSince(cross)
+	highest := signals.HighestSince(closes, cross)
+	for _, r := range []*utils.Result{barsSince, highest} {
+		if r.BeginIndex != events[0].Index || r.BeginIndex+len(r.Values) != n {
+			t.Fatalf("since results start at bar %d, first crossover is at %d", r.BeginIndex, events[0].Index)
+		}
+	}
+	last := 0
+	for i := events[0].Index; i < n; i++ {
+		if v, _ := cross.At(i); v {
+			last = i
+		}
+		want := close[last]
+		for k := last; k <= i; k++ {
+			want = max(want, close[k])
+		}
+		if at(barsSince, i) != float64(i-last) || at(highest, i) != want {
+			t.Fatalf("bar %d: %v bars and highest %v since the crossover, want %d and %v", i, at(barsSince, i), at(highest, i), i-last, want)
+		}
+	}
+}
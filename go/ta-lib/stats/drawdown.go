@@ -0,0 +1,122 @@
+package stats
+
+import (
+	"math"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Drawdown is a fall of an equity curve from a peak and its recovery
+type Drawdown struct {
+	Peak      int     // Index of the peak
+	Trough    int     // Index of the lowest value before the recovery
+	Recovery  int     // Index of the first value back at the peak, -1 if it never recovers
+	Depth     float64 // Fall from the peak to the trough, as a fraction of the peak
+	Duration  int     // Values from the peak to the trough
+	Length    int     // Values from the peak to the recovery, or to the last value
+	Recovered bool    // Whether the curve got back to the peak
+}
+
+// Drawdowns returns every drawdown of an equity curve in order. A drawdown
+// starts when the curve falls below its running maximum and ends when it is
+// back at it.
+func Drawdowns(equity []float64) ([]Drawdown, error) {
+	if err := checkPrices(equity); err != nil {
+		return nil, err
+	}
+	var out []Drawdown
+	peak := 0
+	var current *Drawdown
+	for i, v := range equity {
+		switch {
+		case v >= equity[peak]:
+			if current != nil {
+				current.Recovery, current.Recovered = i, true
+				current.Length = i - current.Peak
+				out = append(out, *current)
+				current = nil
+			}
+			peak = i
+		case current == nil:
+			current = &Drawdown{Peak: peak, Trough: i, Recovery: -1}
+			fallthrough
+		default:
+			if v < equity[current.Trough] {
+				current.Trough = i
+			}
+		}
+	}
+	if current != nil {
+		current.Length = len(equity) - 1 - current.Peak
+		out = append(out, *current)
+	}
+	for i := range out {
+		d := &out[i]
+		d.Depth = (equity[d.Peak] - equity[d.Trough]) / equity[d.Peak]
+		d.Duration = d.Trough - d.Peak
+	}
+	return out, nil
+}
+
+// MaxDrawdown returns the deepest drawdown of an equity curve, the earliest
+// one on a tie, and false when the curve never falls
+func MaxDrawdown(equity []float64) (Drawdown, bool, error) {
+	drawdowns, err := Drawdowns(equity)
+	if err != nil || len(drawdowns) == 0 {
+		return Drawdown{}, false, err
+	}
+	deepest := drawdowns[0]
+	for _, d := range drawdowns[1:] {
+		if d.Depth > deepest.Depth {
+			deepest = d
+		}
+	}
+	return deepest, true, nil
+}
+
+// maxDepth is the depth of the deepest drawdown of a curve
+func maxDepth(equity []float64) float64 {
+	peak, depth := math.Inf(-1), 0.0
+	for _, v := range equity {
+		peak = max(peak, v)
+		depth = max(depth, (peak-v)/peak)
+	}
+	return depth
+}
+
+// UlcerIndexLookback returns the number of values consumed before the first
+// Ulcer index
+func UlcerIndexLookback(period int) int {
+	return 2 * (period - 1)
+}
+
+// UlcerIndex returns the Ulcer index of prices: the root mean square over
+// period values of the percentage drawdowns from the highest price of the
+// period values before each
+func UlcerIndex(prices []float64, period int) (*utils.Result, error) {
+	if err := checkPrices(prices); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("period", period, 2); err != nil {
+		return nil, err
+	}
+	if period > len(prices) {
+		return utils.NewResult(0, nil), nil
+	}
+	drawdowns := make([]float64, 0, len(prices)-period+1)
+	for end := period; end <= len(prices); end++ {
+		highest := utils.MaxInSlice(prices[end-period : end])
+		drawdowns = append(drawdowns, 100*(prices[end-1]-highest)/highest)
+	}
+	r := rolling(drawdowns, period, func(window []float64) float64 {
+		squares := make([]float64, len(window))
+		for i, d := range window {
+			squares[i] = d * d
+		}
+		return math.Sqrt(Mean(squares))
+	})
+	if r.NBElement > 0 {
+		r.BeginIndex = UlcerIndexLookback(period)
+	}
+	return r, nil
+}
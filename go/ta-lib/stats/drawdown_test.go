@@ -0,0 +1,50 @@
+package stats_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/stats"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Two drawdowns, the first one recovered
+func TestDrawdowns(t *testing.T) {
+	equity := []float64{100, 120, 90, 110, 130, 100, 105}
+	drawdowns, err := stats.Drawdowns(equity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []stats.Drawdown{
+		{Peak: 1, Trough: 2, Recovery: 4, Depth: 0.25, Duration: 1, Length: 3, Recovered: true},
+		{Peak: 4, Trough: 5, Recovery: -1, Depth: 30.0 / 130, Duration: 1, Length: 2},
+	}
+	if fmt.Sprint(drawdowns) != fmt.Sprint(want) {
+		t.Errorf("drawdowns %+v, want %+v", drawdowns, want)
+	}
+	if deepest, ok, err := stats.MaxDrawdown(equity); err != nil || !ok || deepest != want[0] {
+		t.Errorf("max drawdown %+v %v %v", deepest, ok, err)
+	}
+}
+
+// Ulcer index against the drawdowns from each 14 bar high
+func TestUlcerIndex(t *testing.T) {
+	close := utils.GetFieldSlice(testutil.MustOHLCV(t), utils.FieldClose)
+	ulcer, err := stats.UlcerIndex(close, 14)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ulcer.BeginIndex != 26 {
+		t.Fatalf("Ulcer index starts at %d", ulcer.BeginIndex)
+	}
+	for k, v := range ulcer.Values {
+		squares := 0.0
+		for i := k + 13; i <= k+26; i++ {
+			highest := utils.MaxInSlice(close[i-13 : i+1])
+			squares += math.Pow(100*(close[i]-highest)/highest, 2)
+		}
+		testutil.Near(t, fmt.Sprintf("Ulcer index at %d", k+26), v, math.Sqrt(squares/14), 1e-9)
+	}
+}
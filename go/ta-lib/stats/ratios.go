@@ -0,0 +1,110 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+func checkRatio(returns []float64, period int, periodsPerYear float64) error {
+	if err := utils.CheckSeries(returns); err != nil {
+		return err
+	}
+	if err := utils.CheckPeriod("period", period, 2); err != nil {
+		return err
+	}
+	if !(periodsPerYear > 0) {
+		return fmt.Errorf("periods per year %v: %w", periodsPerYear, utils.ErrInvalidParameter)
+	}
+	return nil
+}
+
+// RollingSharpe returns the Sharpe ratio of every window of period returns:
+// the mean return in excess of riskFree, a return per period, over the
+// sample deviation of the returns, scaled by the square root of
+// periodsPerYear, such as 252 for daily returns or 1 to leave the ratio per
+// period. A window without deviation gives 0.
+func RollingSharpe(returns []float64, period int, riskFree, periodsPerYear float64) (*utils.Result, error) {
+	if err := checkRatio(returns, period, periodsPerYear); err != nil {
+		return nil, err
+	}
+	if period > len(returns) {
+		return utils.NewResult(0, nil), nil
+	}
+	scale := math.Sqrt(periodsPerYear)
+	var moments utils.RollingMoments
+	out := make([]float64, 0, len(returns)-period+1)
+	for i, r := range returns {
+		if i >= period {
+			moments.Replace(returns[i-period]-riskFree, r-riskFree)
+		} else {
+			moments.Add(r - riskFree)
+		}
+		if i < period-1 {
+			continue
+		}
+		sharpe := 0.0
+		if dev := moments.StdDev(1); dev > 0 {
+			sharpe = moments.Mean() / dev * scale
+		}
+		out = append(out, sharpe)
+	}
+	return utils.NewResult(period-1, out), nil
+}
+
+// RollingSortino returns the Sortino ratio of every window of period
+// returns: the mean return in excess of target over the downside deviation,
+// the root mean square of the shortfalls below target, scaled by the square
+// root of periodsPerYear. A window without shortfall gives 0.
+func RollingSortino(returns []float64, period int, target, periodsPerYear float64) (*utils.Result, error) {
+	if err := checkRatio(returns, period, periodsPerYear); err != nil {
+		return nil, err
+	}
+	scale := math.Sqrt(periodsPerYear)
+	return rolling(returns, period, func(window []float64) float64 {
+		excess := make([]float64, len(window))
+		shortfalls := make([]float64, len(window))
+		for i, r := range window {
+			excess[i] = r - target
+			shortfall := min(excess[i], 0)
+			shortfalls[i] = shortfall * shortfall
+		}
+		downside := math.Sqrt(Mean(shortfalls))
+		if downside == 0 {
+			return 0
+		}
+		return Mean(excess) / downside * scale
+	}), nil
+}
+
+// RollingCalmar returns the Calmar ratio of every window of period simple
+// returns: the compounded annual return over the maximum drawdown of the
+// window. A window without drawdown gives 0.
+func RollingCalmar(returns []float64, period int, periodsPerYear float64) (*utils.Result, error) {
+	if err := checkRatio(returns, period, periodsPerYear); err != nil {
+		return nil, err
+	}
+	for i, r := range returns {
+		if !(r > -1) {
+			return nil, fmt.Errorf("return %v at %d is not above -100%%: %w", r, i, utils.ErrInvalidParameter)
+		}
+	}
+	return rolling(returns, period, func(window []float64) float64 {
+		growth, err := CumulativeReturns(window)
+		if err != nil {
+			return 0
+		}
+		equity := make([]float64, len(window)+1)
+		equity[0] = 1
+		for i, g := range growth {
+			equity[i+1] = 1 + g
+		}
+		depth := maxDepth(equity)
+		if depth == 0 {
+			return 0
+		}
+		logGrowth := math.Log1p(growth[len(growth)-1])
+		return math.Expm1(logGrowth*periodsPerYear/float64(len(window))) / depth
+	}), nil
+}
@@ -0,0 +1,54 @@
+package stats_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/stats"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Rolling ratios against their definitions
+func TestRollingRatios(t *testing.T) {
+	simple, err := stats.SimpleReturns(utils.GetFieldSlice(testutil.MustOHLCV(t), utils.FieldClose))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const period = 30
+	sharpe, err := stats.RollingSharpe(simple.Values, period, 0.0001, 365)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sortino, err := stats.RollingSortino(simple.Values, period, 0, 365)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calmar, err := stats.RollingCalmar(simple.Values, period, 365)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []*utils.Result{sharpe, sortino, calmar} {
+		if r.BeginIndex != period-1 || r.NBElement != len(simple.Values)-period+1 {
+			t.Fatalf("rolling ratio has %d values from %d", r.NBElement, r.BeginIndex)
+		}
+	}
+	for k := range sharpe.Values {
+		window := simple.Values[k : k+period]
+		var sum, sum2, down float64
+		equity, peak, depth := 1.0, 1.0, 0.0
+		for _, r := range window {
+			sum += r - 0.0001
+			sum2 += (r - 0.0001) * (r - 0.0001)
+			down += math.Pow(min(r, 0), 2)
+			equity *= 1 + r
+			peak = max(peak, equity)
+			depth = max(depth, 1-equity/peak)
+		}
+		m := sum / period
+		testutil.Near(t, fmt.Sprintf("Sharpe at %d", k), sharpe.Values[k], m/math.Sqrt((sum2-sum*m)/(period-1))*math.Sqrt(365), 1e-9)
+		testutil.Near(t, fmt.Sprintf("Sortino at %d", k), sortino.Values[k], (m+0.0001)/math.Sqrt(down/period)*math.Sqrt(365), 1e-9)
+		testutil.Near(t, fmt.Sprintf("Calmar at %d", k), calmar.Values[k], (math.Pow(equity, 365.0/period)-1)/depth, 1e-9)
+	}
+}
@@ -0,0 +1,75 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+func checkPrices(prices []float64) error {
+	if err := utils.CheckSeries(prices); err != nil {
+		return err
+	}
+	for i, p := range prices {
+		if !(p > 0) {
+			return fmt.Errorf("price %v at %d is not positive: %w", p, i, utils.ErrInvalidParameter)
+		}
+	}
+	return nil
+}
+
+// SimpleReturns returns p[i]/p[i-1] - 1 for every price after the first,
+// starting at index 1
+func SimpleReturns(prices []float64) (*utils.Result, error) {
+	if err := checkPrices(prices); err != nil {
+		return nil, err
+	}
+	out := make([]float64, len(prices)-1)
+	for i := range out {
+		out[i] = (prices[i+1] - prices[i]) / prices[i]
+	}
+	return utils.NewResult(1, out), nil
+}
+
+// LogReturns returns ln(p[i]/p[i-1]) for every price after the first,
+// starting at index 1
+func LogReturns(prices []float64) (*utils.Result, error) {
+	if err := checkPrices(prices); err != nil {
+		return nil, err
+	}
+	out := make([]float64, len(prices)-1)
+	for i := range out {
+		out[i] = math.Log1p((prices[i+1] - prices[i]) / prices[i])
+	}
+	return utils.NewResult(1, out), nil
+}
+
+// CumulativeReturns compounds simple returns: value i is the return of an
+// investment from before the first return to the end of return i. The
+// growth is accumulated in logs so that long series keep their precision.
+func CumulativeReturns(returns []float64) ([]float64, error) {
+	if err := utils.CheckSeries(returns); err != nil {
+		return nil, err
+	}
+	out := make([]float64, len(returns))
+	logGrowth, c := 0.0, 0.0
+	for i, r := range returns {
+		if !(r >= -1) {
+			return nil, fmt.Errorf("return %v at %d is below -100%%: %w", r, i, utils.ErrInvalidParameter)
+		}
+		if r == -1 || math.IsInf(logGrowth, -1) {
+			// Nothing left to compound
+			logGrowth = math.Inf(-1)
+			out[i] = -1
+			continue
+		}
+		// Kahan summation of the log growth
+		y := math.Log1p(r) - c
+		t := logGrowth + y
+		c = (t - logGrowth) - y
+		logGrowth = t
+		out[i] = math.Expm1(logGrowth)
+	}
+	return out, nil
+}
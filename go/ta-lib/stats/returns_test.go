@@ -0,0 +1,40 @@
+package stats_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/stats"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+func TestReturns(t *testing.T) {
+	close := utils.GetFieldSlice(testutil.MustOHLCV(t), utils.FieldClose)
+	simple, err := stats.SimpleReturns(close)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs, err := stats.LogReturns(close)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cumulative, err := stats.CumulativeReturns(simple.Values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if simple.BeginIndex != 1 || simple.NBElement != len(close)-1 {
+		t.Fatalf("%d returns from %d", simple.NBElement, simple.BeginIndex)
+	}
+	for i, r := range simple.Values {
+		testutil.Near(t, fmt.Sprintf("return %d", i+1), r, close[i+1]/close[i]-1, 1e-12)
+		testutil.Near(t, fmt.Sprintf("log return %d", i+1), logs.Values[i], math.Log(close[i+1]/close[i]), 1e-12)
+		testutil.Near(t, fmt.Sprintf("cumulative return %d", i+1), cumulative[i], close[i+1]/close[0]-1, 1e-12)
+	}
+
+	if _, err := stats.LogReturns([]float64{1, 0, 2}); !errors.Is(err, utils.ErrInvalidParameter) {
+		t.Errorf("log return of a zero price: %v", err)
+	}
+}
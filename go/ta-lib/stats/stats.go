@@ -0,0 +1,260 @@
+// Package stats computes return series and performance statistics: simple,
+// log and cumulative returns, rolling Sharpe, Sortino and Calmar ratios,
+// drawdowns, the Ulcer index, skewness, kurtosis, value at risk and rolling
+// beta.
+//
+// Rolling statistics return a utils.Result, each value being the statistic
+// of the window ending at its bar. Sums are
+// compensated, variances of whole series use the corrected two-pass
+// algorithm and rolling ones the compensated Welford updates of
+// utils.RollingMoments, so that long series and small deviations keep their
+// precision.
+package stats
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// rolling applies a statistic to every window of period values
+func rolling(values []float64, period int, stat func(window []float64) float64) *utils.Result {
+	if period > len(values) {
+		return utils.NewResult(0, nil)
+	}
+	out := make([]float64, 0, len(values)-period+1)
+	for end := period; end <= len(values); end++ {
+		out = append(out, stat(values[end-period:end]))
+	}
+	return utils.NewResult(period-1, out)
+}
+
+// sum adds values with compensation of the rounding errors
+func sum(values []float64) float64 {
+	var s utils.KahanSum
+	for _, v := range values {
+		s.Add(v)
+	}
+	return s.Total()
+}
+
+// Mean returns the compensated mean of the values, NaN without values
+func Mean(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	return sum(values) / float64(len(values))
+}
+
+// centralMoment returns the mean of (x - mean)^k, with the mean corrected
+// by the residual of the first pass
+func centralMoment(values []float64, mean float64, k int) float64 {
+	n := float64(len(values))
+	residual := 0.0
+	for _, v := range values {
+		residual += v - mean
+	}
+	mean += residual / n
+	powers := make([]float64, len(values))
+	for i, v := range values {
+		powers[i] = math.Pow(v-mean, float64(k))
+	}
+	return sum(powers) / n
+}
+
+// variance is the corrected two-pass variance with n - ddof in the divider
+func variance(values []float64, ddof int) float64 {
+	n := len(values)
+	if n <= ddof {
+		return math.NaN()
+	}
+	mean := Mean(values)
+	var squares, residual float64
+	for _, v := range values {
+		d := v - mean
+		squares += d * d
+		residual += d
+	}
+	return (squares - residual*residual/float64(n)) / float64(n-ddof)
+}
+
+// Variance returns the sample variance of the values, with n-1 degrees of
+// freedom, or NaN with less than two values
+func Variance(values []float64) float64 {
+	return variance(values, 1)
+}
+
+// StdDev returns the sample standard deviation of the values
+func StdDev(values []float64) float64 {
+	return math.Sqrt(max(Variance(values), 0))
+}
+
+// Skewness returns the skewness of the values, the third central moment over
+// the cube of the population deviation, 0 without deviation
+func Skewness(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	mean := Mean(values)
+	m2 := centralMoment(values, mean, 2)
+	if m2 == 0 {
+		return 0
+	}
+	return centralMoment(values, mean, 3) / math.Pow(m2, 1.5)
+}
+
+// Kurtosis returns the excess kurtosis of the values, the fourth central
+// moment over the squared population variance minus 3, 0 without deviation
+func Kurtosis(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	mean := Mean(values)
+	m2 := centralMoment(values, mean, 2)
+	if m2 == 0 {
+		return 0
+	}
+	return centralMoment(values, mean, 4)/(m2*m2) - 3
+}
+
+// RollingSkewness returns the skewness of every window of period values
+func RollingSkewness(values []float64, period int) (*utils.Result, error) {
+	if err := utils.CheckSeries(values); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("period", period, 3); err != nil {
+		return nil, err
+	}
+	return rolling(values, period, Skewness), nil
+}
+
+// RollingKurtosis returns the excess kurtosis of every window of period values
+func RollingKurtosis(values []float64, period int) (*utils.Result, error) {
+	if err := utils.CheckSeries(values); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("period", period, 4); err != nil {
+		return nil, err
+	}
+	return rolling(values, period, Kurtosis), nil
+}
+
+// VaRMethod selects how the value at risk is estimated
+type VaRMethod int
+
+const (
+	Historical VaRMethod = iota // Empirical quantile of the returns
+	Parametric                  // Normal distribution with the mean and deviation of the returns
+)
+
+// String returns the name of the method
+func (m VaRMethod) String() string {
+	switch m {
+	case Historical:
+		return "historical"
+	case Parametric:
+		return "parametric"
+	}
+	return fmt.Sprintf("VaRMethod(%d)", int(m))
+}
+
+func checkVaR(returns []float64, confidence float64, method VaRMethod) error {
+	if err := utils.CheckSeries(returns); err != nil {
+		return err
+	}
+	if !(confidence > 0 && confidence < 1) {
+		return fmt.Errorf("confidence %v out of range (0, 1): %w", confidence, utils.ErrInvalidParameter)
+	}
+	if method != Historical && method != Parametric {
+		return fmt.Errorf("unknown VaR method %d: %w", method, utils.ErrInvalidParameter)
+	}
+	if method == Parametric && len(returns) < 2 {
+		return fmt.Errorf("parametric VaR of %d return: %w", len(returns), utils.ErrInvalidParameter)
+	}
+	return nil
+}
+
+// quantile returns the p quantile of sorted values, interpolating linearly
+// between the closest ranks
+func quantile(sorted []float64, p float64) float64 {
+	h := p * float64(len(sorted)-1)
+	lo := int(math.Floor(h))
+	if lo+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lo] + (h-float64(lo))*(sorted[lo+1]-sorted[lo])
+}
+
+// normalQuantile is the inverse of the standard normal distribution
+func normalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
+
+// VaR returns the value at risk of the returns at a confidence level such
+// as 0.95: the loss, as a positive return, exceeded with probability
+// 1 - confidence
+func VaR(returns []float64, confidence float64, method VaRMethod) (float64, error) {
+	if err := checkVaR(returns, confidence, method); err != nil {
+		return 0, err
+	}
+	if method == Parametric {
+		return -(Mean(returns) + StdDev(returns)*normalQuantile(1-confidence)), nil
+	}
+	sorted := slices.Clone(returns)
+	slices.Sort(sorted)
+	return -quantile(sorted, 1-confidence), nil
+}
+
+// CVaR returns the conditional value at risk, or expected shortfall: the
+// average loss beyond the value at risk
+func CVaR(returns []float64, confidence float64, method VaRMethod) (float64, error) {
+	if err := checkVaR(returns, confidence, method); err != nil {
+		return 0, err
+	}
+	if method == Parametric {
+		z := normalQuantile(1 - confidence)
+		density := math.Exp(-z*z/2) / math.Sqrt(2*math.Pi)
+		return -(Mean(returns) - StdDev(returns)*density/(1-confidence)), nil
+	}
+	sorted := slices.Clone(returns)
+	slices.Sort(sorted)
+	q := quantile(sorted, 1-confidence)
+	tail := sorted[:1]
+	for len(tail) < len(sorted) && sorted[len(tail)] <= q {
+		tail = sorted[:len(tail)+1]
+	}
+	return -Mean(tail), nil
+}
+
+// RollingBeta returns the beta of the asset returns against the benchmark
+// returns over every window of period returns: their covariance over the
+// variance of the benchmark, 0 when the benchmark does not move
+func RollingBeta(asset, benchmark []float64, period int) (*utils.Result, error) {
+	if err := utils.CheckSeries(asset, benchmark); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("period", period, 2); err != nil {
+		return nil, err
+	}
+	if period > len(asset) {
+		return utils.NewResult(0, nil), nil
+	}
+
+	// Regression of the asset on the benchmark
+	var c utils.RollingCovariance
+	for i := 0; i < period-1; i++ {
+		c.Add(benchmark[i], asset[i])
+	}
+	out := make([]float64, 0, len(asset)-period+1)
+	for i := period - 1; i < len(asset); i++ {
+		if i >= period {
+			c.Replace(benchmark[i-period], asset[i-period], benchmark[i], asset[i])
+		} else {
+			c.Add(benchmark[i], asset[i])
+		}
+		out = append(out, c.Slope())
+	}
+	return utils.NewResult(period-1, out), nil
+}
@@ -0,0 +1,79 @@
+package stats_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/stats"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Large values with a small spread keep their variance and moments
+func TestMoments(t *testing.T) {
+	large := make([]float64, 10)
+	for i := range large {
+		large[i] = 1e9 + float64(i)
+	}
+	testutil.Near(t, "variance of 1e9 + 0..9", stats.Variance(large), 55.0/6, 1e-12)
+	testutil.Near(t, "skewness of 1e9 + 0..9", stats.Skewness(large), 0, 1e-12)
+	testutil.Near(t, "kurtosis of 1e9 + 0..9", stats.Kurtosis(large), -6.0*101/(5*99), 1e-12)
+}
+
+// Value at risk of -5% to 4%
+func TestVaR(t *testing.T) {
+	returns := make([]float64, 10)
+	for i := range returns {
+		returns[i] = float64(i-5) / 100
+	}
+	mean, dev := -0.005, stats.StdDev(returns)
+	const z = 1.6448536269514722
+	for _, tc := range []struct {
+		name       string
+		risk       func([]float64, float64, stats.VaRMethod) (float64, error)
+		confidence float64
+		method     stats.VaRMethod
+		want       float64
+		tolerance  float64
+	}{
+		{"historical VaR", stats.VaR, 0.9, stats.Historical, 0.041, 1e-12},
+		{"historical CVaR", stats.CVaR, 0.9, stats.Historical, 0.05, 1e-12},
+		{"parametric VaR", stats.VaR, 0.95, stats.Parametric, -(mean - z*dev), 1e-9},
+		{"parametric CVaR", stats.CVaR, 0.95, stats.Parametric, -(mean - dev*math.Exp(-z*z/2)/math.Sqrt(2*math.Pi)/0.05), 1e-9},
+	} {
+		got, err := tc.risk(returns, tc.confidence, tc.method)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		testutil.Near(t, tc.name, got, tc.want, tc.tolerance)
+	}
+
+	if _, err := stats.VaR(returns, 1, stats.Historical); !errors.Is(err, utils.ErrInvalidParameter) {
+		t.Errorf("VaR at 100%%: %v", err)
+	}
+}
+
+// An asset moving twice as much as its benchmark has a beta of 2
+func TestRollingBeta(t *testing.T) {
+	simple, err := stats.SimpleReturns(utils.GetFieldSlice(testutil.MustOHLCV(t), utils.FieldClose))
+	if err != nil {
+		t.Fatal(err)
+	}
+	asset := make([]float64, len(simple.Values))
+	for i, r := range simple.Values {
+		asset[i] = 2*r + 0.001
+	}
+	beta, err := stats.RollingBeta(asset, simple.Values, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range beta.Values {
+		testutil.Near(t, fmt.Sprintf("beta at %d", k+19), v, 2, 1e-9)
+	}
+
+	if _, err := stats.RollingBeta(asset, simple.Values[1:], 20); !errors.Is(err, utils.ErrMismatchedInputLengths) {
+		t.Errorf("beta of mismatched series: %v", err)
+	}
+}
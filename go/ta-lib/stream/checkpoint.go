@@ -0,0 +1,520 @@
+package stream
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// stateVersion is written in every saved state and bumped whenever the
+// layout of an indicator state changes
+const stateVersion = 6
+
+// stateMagic starts every binary state
+const stateMagic = "TAST"
+
+// indicatorName returns the type name of an indicator, such as "RSI"
+func indicatorName(ind Stateful) string {
+	t := reflect.TypeOf(ind)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// SaveState encodes the parameters and the state of an indicator in a
+// compact binary form. The values are stored exactly, so an indicator
+// restored with LoadState continues with the same outputs.
+func SaveState(ind Stateful) ([]byte, error) {
+	name := indicatorName(ind)
+	e := &binaryEncoder{buf: make([]byte, 0, 256)}
+	e.buf = append(e.buf, stateMagic...)
+	e.buf = binary.LittleEndian.AppendUint16(e.buf, stateVersion)
+	e.buf = append(e.buf, byte(len(name)))
+	e.buf = append(e.buf, name...)
+	ind.visitState(e)
+	return e.buf, nil
+}
+
+// LoadState restores a state saved by SaveState into an indicator created
+// with the same parameters. The indicator is left unchanged on error.
+func LoadState(ind Stateful, data []byte) error {
+	d := &binaryDecoder{data: data}
+	if string(d.read(len(stateMagic))) != stateMagic {
+		return fmt.Errorf("not a binary indicator state: %w", utils.ErrInvalidState)
+	}
+	if version := binary.LittleEndian.Uint16(d.read(2)); d.err == nil && version != stateVersion {
+		return fmt.Errorf("state version %d, expected %d: %w", version, stateVersion, utils.ErrInvalidState)
+	}
+	name := string(d.read(int(d.readByte())))
+	if d.err != nil {
+		return d.err
+	}
+	if err := checkName(ind, name); err != nil {
+		return err
+	}
+
+	return restore(ind, d, func() error {
+		if d.err == nil && len(d.data) > 0 {
+			d.err = fmt.Errorf("%d unexpected bytes after the state: %w", len(d.data), utils.ErrInvalidState)
+		}
+		return d.err
+	})
+}
+
+// jsonState is the JSON form of a saved state
+type jsonState struct {
+	Version   int            `json:"version"`
+	Indicator string         `json:"indicator"`
+	State     map[string]any `json:"state"`
+}
+
+// SaveStateJSON encodes the parameters and the state of an indicator as
+// JSON, with one object per component. JSON has no number for NaN and the
+// infinities, they are written as the strings "NaN", "+Inf" and "-Inf".
+func SaveStateJSON(ind Stateful) ([]byte, error) {
+	e := &jsonEncoder{obj: make(map[string]any)}
+	ind.visitState(e)
+	return json.Marshal(jsonState{Version: stateVersion, Indicator: indicatorName(ind), State: e.obj})
+}
+
+// LoadStateJSON restores a state saved by SaveStateJSON into an indicator
+// created with the same parameters. The indicator is left unchanged on error.
+func LoadStateJSON(ind Stateful, data []byte) error {
+	var saved jsonState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("%v: %w", err, utils.ErrInvalidState)
+	}
+	if saved.Version != stateVersion {
+		return fmt.Errorf("state version %d, expected %d: %w", saved.Version, stateVersion, utils.ErrInvalidState)
+	}
+	if err := checkName(ind, saved.Indicator); err != nil {
+		return err
+	}
+	d := &jsonDecoder{obj: saved.State}
+	return restore(ind, d, func() error { return d.err })
+}
+
+// checkName verifies that a saved state belongs to the type of the indicator
+func checkName(ind Stateful, name string) error {
+	if want := indicatorName(ind); name != want {
+		return fmt.Errorf("state of %s cannot be loaded into %s: %w", name, want, utils.ErrInvalidState)
+	}
+	return nil
+}
+
+// restore visits the indicator with a decoder, rolling the variables back
+// when decoding fails
+func restore(ind Stateful, v stateVisitor, done func() error) error {
+	s := snapshot{all: true}
+	s.save(ind)
+	ind.visitState(v)
+	if err := done(); err != nil {
+		s.rollback(ind)
+		return err
+	}
+	return nil
+}
+
+// statePath tracks the component being decoded for error messages
+type statePath []string
+
+func (p statePath) at(name string) string {
+	return strings.Join(append(p[:len(p):len(p)], name), ".")
+}
+
+// paramMismatch reports a parameter that differs from the saved one
+func paramMismatch(name string, saved, current any) error {
+	return fmt.Errorf("parameter %s is %v in the state, %v in the indicator: %w", name, saved, current, utils.ErrInvalidParameter)
+}
+
+// outOfRange reports a counter or an index outside of [min, max], which
+// would make the next update misbehave or panic
+func outOfRange(name string, v, min, max int) error {
+	if v < min || v > max {
+		return fmt.Errorf("%s is %d in the state, outside of [%d, %d]: %w", name, v, min, max, utils.ErrInvalidState)
+	}
+	return nil
+}
+
+// binaryEncoder writes little-endian values in visiting order
+type binaryEncoder struct {
+	buf []byte
+}
+
+func (e *binaryEncoder) intParam(name string, p *int)       { e.intVar(name, p) }
+func (e *binaryEncoder) int64Param(name string, p *int64)   { e.int64Var(name, p) }
+func (e *binaryEncoder) floatParam(name string, p *float64) { e.floatVar(name, p) }
+func (e *binaryEncoder) boolParam(name string, p *bool)     { e.boolVar(name, p) }
+
+func (e *binaryEncoder) intVar(name string, p *int) {
+	e.buf = binary.LittleEndian.AppendUint64(e.buf, uint64(int64(*p)))
+}
+
+func (e *binaryEncoder) int64Var(name string, p *int64) {
+	e.buf = binary.LittleEndian.AppendUint64(e.buf, uint64(*p))
+}
+
+func (e *binaryEncoder) rangeVar(name string, p *int, min, max int) {
+	e.intVar(name, p)
+}
+
+func (e *binaryEncoder) floatVar(name string, p *float64) {
+	e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(*p))
+}
+
+func (e *binaryEncoder) boolVar(name string, p *bool) {
+	if *p {
+		e.buf = append(e.buf, 1)
+	} else {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+func (e *binaryEncoder) floatsVar(name string, p []float64) {
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, uint32(len(p)))
+	for i := range p {
+		e.floatVar(name, &p[i])
+	}
+}
+
+func (e *binaryEncoder) ringVar(name string, p []float64, next int) {
+	e.floatsVar(name, p)
+}
+
+func (e *binaryEncoder) child(name string, c Stateful) {
+	c.visitState(e)
+}
+
+// binaryDecoder reads the values written by binaryEncoder, stopping at the first error
+type binaryDecoder struct {
+	data []byte
+	path statePath
+	err  error
+}
+
+// read consumes n bytes, or returns zeros once the data is exhausted
+func (d *binaryDecoder) read(n int) []byte {
+	if d.err == nil && len(d.data) < n {
+		d.err = fmt.Errorf("truncated state: %w", utils.ErrInvalidState)
+	}
+	if d.err != nil {
+		return make([]byte, n)
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *binaryDecoder) readByte() byte {
+	return d.read(1)[0]
+}
+
+func (d *binaryDecoder) readInt() int {
+	return int(d.readInt64())
+}
+
+func (d *binaryDecoder) readInt64() int64 {
+	return int64(binary.LittleEndian.Uint64(d.read(8)))
+}
+
+func (d *binaryDecoder) readFloat() float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(d.read(8)))
+}
+
+func (d *binaryDecoder) intParam(name string, p *int) {
+	if v := d.readInt(); d.err == nil && v != *p {
+		d.err = paramMismatch(d.path.at(name), v, *p)
+	}
+}
+
+func (d *binaryDecoder) int64Param(name string, p *int64) {
+	if v := d.readInt64(); d.err == nil && v != *p {
+		d.err = paramMismatch(d.path.at(name), v, *p)
+	}
+}
+
+func (d *binaryDecoder) floatParam(name string, p *float64) {
+	if v := d.readFloat(); d.err == nil && v != *p {
+		d.err = paramMismatch(d.path.at(name), v, *p)
+	}
+}
+
+func (d *binaryDecoder) boolParam(name string, p *bool) {
+	if v := d.readByte(); d.err == nil && (v != 0) != *p {
+		d.err = paramMismatch(d.path.at(name), v != 0, *p)
+	}
+}
+
+func (d *binaryDecoder) intVar(name string, p *int) {
+	if v := d.readInt(); d.err == nil {
+		*p = v
+	}
+}
+
+func (d *binaryDecoder) int64Var(name string, p *int64) {
+	if v := d.readInt64(); d.err == nil {
+		*p = v
+	}
+}
+
+func (d *binaryDecoder) rangeVar(name string, p *int, min, max int) {
+	if v := d.readInt(); d.err == nil {
+		if d.err = outOfRange(d.path.at(name), v, min, max); d.err == nil {
+			*p = v
+		}
+	}
+}
+
+func (d *binaryDecoder) floatVar(name string, p *float64) {
+	if v := d.readFloat(); d.err == nil {
+		*p = v
+	}
+}
+
+func (d *binaryDecoder) boolVar(name string, p *bool) {
+	if v := d.readByte(); d.err == nil {
+		*p = v != 0
+	}
+}
+
+func (d *binaryDecoder) floatsVar(name string, p []float64) {
+	n := int(binary.LittleEndian.Uint32(d.read(4)))
+	if d.err == nil && n != len(p) {
+		d.err = fmt.Errorf("%s has %d values in the state, %d in the indicator: %w", d.path.at(name), n, len(p), utils.ErrInvalidState)
+	}
+	for i := range p {
+		d.floatVar(name, &p[i])
+	}
+}
+
+func (d *binaryDecoder) ringVar(name string, p []float64, next int) {
+	d.floatsVar(name, p)
+}
+
+func (d *binaryDecoder) child(name string, c Stateful) {
+	d.path = append(d.path, name)
+	c.visitState(d)
+	d.path = d.path[:len(d.path)-1]
+}
+
+// jsonFloat returns a float as a JSON number, or as a string for the
+// values JSON cannot hold
+func jsonFloat(v float64) any {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return v
+}
+
+// parseJSONFloat reverses jsonFloat
+func parseJSONFloat(raw any) (float64, bool) {
+	switch v := raw.(type) {
+	case float64:
+		return v, true
+	case string:
+		switch v {
+		case "NaN":
+			return math.NaN(), true
+		case "+Inf":
+			return math.Inf(1), true
+		case "-Inf":
+			return math.Inf(-1), true
+		}
+	}
+	return 0, false
+}
+
+// jsonEncoder collects the values of a component into a JSON object
+type jsonEncoder struct {
+	obj map[string]any
+}
+
+func (e *jsonEncoder) intParam(name string, p *int)           { e.obj[name] = *p }
+func (e *jsonEncoder) int64Param(name string, p *int64)       { e.obj[name] = *p }
+func (e *jsonEncoder) floatParam(name string, p *float64)     { e.obj[name] = jsonFloat(*p) }
+func (e *jsonEncoder) boolParam(name string, p *bool)         { e.obj[name] = *p }
+func (e *jsonEncoder) intVar(name string, p *int)             { e.obj[name] = *p }
+func (e *jsonEncoder) int64Var(name string, p *int64)         { e.obj[name] = *p }
+func (e *jsonEncoder) rangeVar(name string, p *int, _, _ int) { e.obj[name] = *p }
+func (e *jsonEncoder) floatVar(name string, p *float64)       { e.obj[name] = jsonFloat(*p) }
+func (e *jsonEncoder) boolVar(name string, p *bool)           { e.obj[name] = *p }
+
+func (e *jsonEncoder) floatsVar(name string, p []float64) {
+	values := make([]any, len(p))
+	for i, v := range p {
+		values[i] = jsonFloat(v)
+	}
+	e.obj[name] = values
+}
+
+func (e *jsonEncoder) ringVar(name string, p []float64, next int) {
+	e.floatsVar(name, p)
+}
+
+func (e *jsonEncoder) child(name string, c Stateful) {
+	sub := &jsonEncoder{obj: make(map[string]any)}
+	c.visitState(sub)
+	e.obj[name] = sub.obj
+}
+
+// jsonDecoder reads the values of a component from a decoded JSON object,
+// stopping at the first error
+type jsonDecoder struct {
+	obj  map[string]any
+	path statePath
+	err  error
+}
+
+// get returns a value of the expected JSON type
+func get[T any](d *jsonDecoder, name string) (T, bool) {
+	var zero T
+	if d.err != nil {
+		return zero, false
+	}
+	raw, found := d.obj[name]
+	if !found {
+		d.err = fmt.Errorf("%s missing from the state: %w", d.path.at(name), utils.ErrInvalidState)
+		return zero, false
+	}
+	v, ok := raw.(T)
+	if !ok {
+		d.err = fmt.Errorf("%s has an invalid value %v: %w", d.path.at(name), raw, utils.ErrInvalidState)
+		return zero, false
+	}
+	return v, true
+}
+
+// getFloat returns a number, or NaN or an infinity written by jsonFloat
+func getFloat(d *jsonDecoder, name string) (float64, bool) {
+	raw, ok := get[any](d, name)
+	if !ok {
+		return 0, false
+	}
+	v, ok := parseJSONFloat(raw)
+	if !ok {
+		d.err = fmt.Errorf("%s has an invalid value %v: %w", d.path.at(name), raw, utils.ErrInvalidState)
+	}
+	return v, ok
+}
+
+// getInt64 returns a whole number that fits in an int64
+func getInt64(d *jsonDecoder, name string) (int64, bool) {
+	v, ok := get[float64](d, name)
+	if ok && (v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64) {
+		d.err = fmt.Errorf("%s has an invalid value %v: %w", d.path.at(name), v, utils.ErrInvalidState)
+		return 0, false
+	}
+	return int64(v), ok
+}
+
+// getInt returns a whole number that fits in an int
+func getInt(d *jsonDecoder, name string) (int, bool) {
+	v, ok := getInt64(d, name)
+	if ok && int64(int(v)) != v {
+		d.err = fmt.Errorf("%s has an invalid value %v: %w", d.path.at(name), v, utils.ErrInvalidState)
+		return 0, false
+	}
+	return int(v), ok
+}
+
+func (d *jsonDecoder) intParam(name string, p *int) {
+	if v, ok := getInt(d, name); ok && v != *p {
+		d.err = paramMismatch(d.path.at(name), v, *p)
+	}
+}
+
+func (d *jsonDecoder) int64Param(name string, p *int64) {
+	if v, ok := getInt64(d, name); ok && v != *p {
+		d.err = paramMismatch(d.path.at(name), v, *p)
+	}
+}
+
+func (d *jsonDecoder) floatParam(name string, p *float64) {
+	if v, ok := getFloat(d, name); ok && v != *p {
+		d.err = paramMismatch(d.path.at(name), v, *p)
+	}
+}
+
+func (d *jsonDecoder) boolParam(name string, p *bool) {
+	if v, ok := get[bool](d, name); ok && v != *p {
+		d.err = paramMismatch(d.path.at(name), v, *p)
+	}
+}
+
+func (d *jsonDecoder) intVar(name string, p *int) {
+	if v, ok := getInt(d, name); ok {
+		*p = v
+	}
+}
+
+func (d *jsonDecoder) int64Var(name string, p *int64) {
+	if v, ok := getInt64(d, name); ok {
+		*p = v
+	}
+}
+
+func (d *jsonDecoder) rangeVar(name string, p *int, min, max int) {
+	if v, ok := getInt(d, name); ok {
+		if d.err = outOfRange(d.path.at(name), v, min, max); d.err == nil {
+			*p = v
+		}
+	}
+}
+
+func (d *jsonDecoder) floatVar(name string, p *float64) {
+	if v, ok := getFloat(d, name); ok {
+		*p = v
+	}
+}
+
+func (d *jsonDecoder) boolVar(name string, p *bool) {
+	if v, ok := get[bool](d, name); ok {
+		*p = v
+	}
+}
+
+func (d *jsonDecoder) floatsVar(name string, p []float64) {
+	values, ok := get[[]any](d, name)
+	if !ok {
+		return
+	}
+	if len(values) != len(p) {
+		d.err = fmt.Errorf("%s has %d values in the state, %d in the indicator: %w", d.path.at(name), len(values), len(p), utils.ErrInvalidState)
+		return
+	}
+	for i, raw := range values {
+		v, ok := parseJSONFloat(raw)
+		if !ok {
+			d.err = fmt.Errorf("%s has an invalid value %v: %w", d.path.at(name), raw, utils.ErrInvalidState)
+			return
+		}
+		p[i] = v
+	}
+}
+
+func (d *jsonDecoder) ringVar(name string, p []float64, next int) {
+	d.floatsVar(name, p)
+}
+
+func (d *jsonDecoder) child(name string, c Stateful) {
+	obj, ok := get[map[string]any](d, name)
+	if !ok {
+		return
+	}
+	sub := &jsonDecoder{obj: obj, path: append(d.path[:len(d.path):len(d.path)], name)}
+	c.visitState(sub)
+	if d.err == nil {
+		d.err = sub.err
+	}
+}
@@ -0,0 +1,197 @@
+package stream_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/stream"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// A restored indicator continues exactly like the one it was saved from,
+// whether saved during the warm up or after it
+func TestCheckpoint(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	for _, tc := range statefulCases() {
+		for _, split := range []int{3, len(data) / 2} {
+			orig, err := tc.new()
+			if err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+			for _, bar := range data[:split] {
+				orig.Update(bar)
+			}
+			bin, err := stream.SaveState(orig.unwrap())
+			if err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+			js, err := stream.SaveStateJSON(orig.unwrap())
+			if err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+
+			fromBin, _ := tc.new()
+			fromJSON, _ := tc.new()
+			if err := stream.LoadState(fromBin.unwrap(), bin); err != nil {
+				t.Fatalf("%s: binary state: %v", tc.name, err)
+			}
+			if err := stream.LoadStateJSON(fromJSON.unwrap(), js); err != nil {
+				t.Fatalf("%s: JSON state: %v", tc.name, err)
+			}
+			if again, _ := stream.SaveState(fromJSON.unwrap()); !bytes.Equal(again, bin) {
+				t.Fatalf("%s: state restored from JSON differs from the binary one", tc.name)
+			}
+
+			// A damaged state is rejected without touching the indicator
+			if err := stream.LoadState(fromBin.unwrap(), bin[:len(bin)-1]); !errors.Is(err, utils.ErrInvalidState) {
+				t.Fatalf("%s: truncated state gave %v", tc.name, err)
+			}
+			if again, _ := stream.SaveState(fromBin.unwrap()); !bytes.Equal(again, bin) {
+				t.Fatalf("%s: failed restore changed the indicator", tc.name)
+			}
+
+			for i, bar := range data[split:] {
+				want, wantOK := orig.Update(bar)
+				if v, ok := fromBin.Update(bar); v != want || ok != wantOK {
+					t.Fatalf("%s: bar %d restored from binary is %v, original has %v", tc.name, split+i, v, want)
+				}
+				if v, ok := fromJSON.Update(bar); v != want || ok != wantOK {
+					t.Fatalf("%s: bar %d restored from JSON is %v, original has %v", tc.name, split+i, v, want)
+				}
+			}
+		}
+	}
+}
+
+func TestCheckpointMismatch(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	rsi, _ := stream.NewRSI(14)
+	for _, bar := range data[:50] {
+		rsi.Update(bar)
+	}
+	bin, _ := stream.SaveState(rsi)
+	js, _ := stream.SaveStateJSON(rsi)
+	ema, _ := stream.NewMA(14, utils.EMA)
+	emaState, _ := stream.SaveState(ema)
+	future := bytes.Clone(bin)
+	future[4]++
+
+	rsi10, _ := stream.NewRSI(10)
+	kama, _ := stream.NewKAMA(14)
+	sma, _ := stream.NewMA(14, utils.SMA)
+	for _, tc := range []struct {
+		name string
+		load func() error
+		want error
+	}{
+		{"binary RSI(14) state into RSI(10)", func() error { return stream.LoadState(rsi10, bin) }, utils.ErrInvalidParameter},
+		{"JSON RSI(14) state into RSI(10)", func() error { return stream.LoadStateJSON(rsi10, js) }, utils.ErrInvalidParameter},
+		{"RSI state into KAMA", func() error { return stream.LoadState(kama, bin) }, utils.ErrInvalidState},
+		{"EMA state into SMA", func() error { return stream.LoadState(sma, emaState) }, utils.ErrInvalidParameter},
+		{"unknown state version", func() error { return stream.LoadState(rsi, future) }, utils.ErrInvalidState},
+	} {
+		if err := tc.load(); !errors.Is(err, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+// NaN and the infinities, which JSON numbers cannot hold, survive a JSON
+// round trip
+func TestCheckpointNonFinite(t *testing.T) {
+	sma, _ := stream.NewSMA(5)
+	for _, v := range []float64{1, math.Inf(1), math.Inf(-1), math.NaN()} {
+		sma.UpdateValue(v)
+	}
+	js, err := stream.SaveStateJSON(sma)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`"NaN"`, `"+Inf"`, `"-Inf"`} {
+		if !strings.Contains(string(js), s) {
+			t.Errorf("%s missing from the SMA state %s", s, js)
+		}
+	}
+	restored, _ := stream.NewSMA(5)
+	if err := stream.LoadStateJSON(restored, js); err != nil {
+		t.Fatal(err)
+	}
+	// The NaN payloads are not kept, the JSON forms are the same
+	if again, _ := stream.SaveStateJSON(restored); !bytes.Equal(again, js) {
+		t.Errorf("state restored from JSON is %s, saved %s", again, js)
+	}
+	for _, v := range []float64{2, 3} {
+		got, _ := restored.UpdateValue(v)
+		want, _ := sma.UpdateValue(v)
+		if got != want && !(math.IsNaN(got) && math.IsNaN(want)) {
+			t.Errorf("restored SMA gave %v, want %v", got, want)
+		}
+	}
+
+	corrupt := strings.Replace(string(js), `"NaN"`, `"nan"`, 1)
+	if err := stream.LoadStateJSON(restored, []byte(corrupt)); !errors.Is(err, utils.ErrInvalidState) {
+		t.Errorf("SMA state with \"nan\" gave %v", err)
+	}
+}
+
+// States whose counters and buffer positions were edited are rejected
+// rather than panic on the next update
+func TestCheckpointCorrupt(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	sma, _ := stream.NewMA(3, utils.SMA)
+	for _, bar := range data[:5] {
+		sma.Update(bar)
+	}
+	saved, _ := stream.SaveStateJSON(sma)
+	want, _ := sma.Update(data[5])
+	for _, edit := range []struct{ from, to string }{
+		{`"next":2`, `"next":99`},
+		{`"next":2`, `"next":3`},
+		{`"next":2`, `"next":-1`},
+		{`"next":2`, `"next":1.5`},
+		{`"count":3`, `"count":4`},
+		{`"size":3`, `"size":4`},
+		{`"values":[`, `"values":[1,`},
+		{`"next":2`, `"next":1e300`},
+	} {
+		corrupt := strings.Replace(string(saved), edit.from, edit.to, 1)
+		if corrupt == string(saved) {
+			t.Fatalf("%s not found in the SMA state %s", edit.from, saved)
+		}
+		restored, _ := stream.NewMA(3, utils.SMA)
+		if err := stream.LoadStateJSON(restored, []byte(corrupt)); err == nil {
+			t.Errorf("SMA state with %s loaded", edit.to)
+		}
+		// The indicator is left unchanged on error
+		if _, ok := restored.Update(data[5]); ok {
+			t.Errorf("SMA state with %s was partly loaded", edit.to)
+		}
+	}
+	restored, _ := stream.NewMA(3, utils.SMA)
+	if err := stream.LoadStateJSON(restored, saved); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := restored.Update(data[5]); got != want {
+		t.Errorf("restored SMA gave %v, want %v", got, want)
+	}
+
+	// The same checks apply to the binary form, here the MFI buffer position
+	mfi, _ := stream.NewMFI(3)
+	for _, bar := range data[:5] {
+		mfi.Update(bar)
+	}
+	bin, _ := stream.SaveState(mfi)
+	for _, idx := range []int64{-1, 3, 1 << 40} {
+		corrupt := bytes.Clone(bin)
+		// mflowIdx precedes the two flow sums ending the state
+		binary.LittleEndian.PutUint64(corrupt[len(corrupt)-24:], uint64(idx))
+		if err := stream.LoadState(mfi, corrupt); !errors.Is(err, utils.ErrInvalidState) {
+			t.Errorf("MFI state with mflowIdx %d gave %v", idx, err)
+		}
+	}
+}
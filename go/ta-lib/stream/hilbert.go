@@ -0,0 +1,423 @@
+package stream
+
+import (
+	"math"
+
+	"github.com/petercool/ta-lib/go/ta-lib/indicators"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// smoothPriceSize is the number of smoothed prices kept for the dominant
+// cycle phase, the longest period measured being 50 bars
+const smoothPriceSize = 50
+
+// dominantCycle holds the state shared by the Hilbert transform indicators:
+// the in-phase and quadrature components of the smoothed price and the
+// period of its dominant cycle, measured with the homodyne discriminator
+type dominantCycle struct {
+	warmup int // Bars smoothed before the first transform
+
+	today                         int
+	wma                           *priceWMA
+	detrender, q1, jI, jQ         hilbertTransform
+	hilbertIdx                    int
+	period, smoothPeriod          float64
+	prevI2, prevQ2                float64
+	re, im                        float64
+	i1ForOddPrev3, i1ForEvenPrev3 float64
+	i1ForOddPrev2, i1ForEvenPrev2 float64
+	inPhase                       float64 // In-phase component of the last bar
+	smoothPrice                   *window
+}
+
+func newDominantCycle(warmup int) *dominantCycle {
+	return &dominantCycle{warmup: warmup, wma: newPriceWMA(), smoothPrice: newWindow(smoothPriceSize)}
+}
+
+func (c *dominantCycle) visitState(v stateVisitor) {
+	v.intParam("warmup", &c.warmup)
+	v.rangeVar("today", &c.today, 0, unbounded)
+	v.child("wma", c.wma)
+	v.child("detrender", &c.detrender)
+	v.child("q1", &c.q1)
+	v.child("jI", &c.jI)
+	v.child("jQ", &c.jQ)
+	v.rangeVar("hilbertIdx", &c.hilbertIdx, 0, len(c.detrender.odd)-1)
+	v.floatVar("period", &c.period)
+	v.floatVar("smoothPeriod", &c.smoothPeriod)
+	v.floatVar("prevI2", &c.prevI2)
+	v.floatVar("prevQ2", &c.prevQ2)
+	v.floatVar("re", &c.re)
+	v.floatVar("im", &c.im)
+	v.floatVar("i1ForOddPrev3", &c.i1ForOddPrev3)
+	v.floatVar("i1ForEvenPrev3", &c.i1ForEvenPrev3)
+	v.floatVar("i1ForOddPrev2", &c.i1ForOddPrev2)
+	v.floatVar("i1ForEvenPrev2", &c.i1ForEvenPrev2)
+	v.floatVar("inPhase", &c.inPhase)
+	v.child("smoothPrice", c.smoothPrice)
+}
+
+// next adds a price and reports whether the cycle was updated, the first
+// 3 + warmup prices only warming up the price smoother. It returns the index
+// of the bar, counted from 0.
+func (c *dominantCycle) next(price float64) (int, bool) {
+	today := c.today
+	c.today++
+	smoothedValue, ok := c.wma.next(price)
+	if !ok || today < 3+c.warmup {
+		return today, false
+	}
+
+	adjustedPrevPeriod := (0.075 * c.period) + 0.54
+	c.smoothPrice.push(smoothedValue)
+	var q2, i2 float64
+	if today%2 == 0 {
+		c.detrender.do(smoothedValue, true, c.hilbertIdx, adjustedPrevPeriod)
+		c.q1.do(c.detrender.value, true, c.hilbertIdx, adjustedPrevPeriod)
+		c.inPhase = c.i1ForEvenPrev3
+		c.jI.do(c.i1ForEvenPrev3, true, c.hilbertIdx, adjustedPrevPeriod)
+		c.jQ.do(c.q1.value, true, c.hilbertIdx, adjustedPrevPeriod)
+		c.hilbertIdx++
+		if c.hilbertIdx == 3 {
+			c.hilbertIdx = 0
+		}
+		q2 = (0.2 * (c.q1.value + c.jI.value)) + (0.8 * c.prevQ2)
+		i2 = (0.2 * (c.i1ForEvenPrev3 - c.jQ.value)) + (0.8 * c.prevI2)
+		c.i1ForOddPrev3 = c.i1ForOddPrev2
+		c.i1ForOddPrev2 = c.detrender.value
+	} else {
+		c.detrender.do(smoothedValue, false, c.hilbertIdx, adjustedPrevPeriod)
+		c.q1.do(c.detrender.value, false, c.hilbertIdx, adjustedPrevPeriod)
+		c.inPhase = c.i1ForOddPrev3
+		c.jI.do(c.i1ForOddPrev3, false, c.hilbertIdx, adjustedPrevPeriod)
+		c.jQ.do(c.q1.value, false, c.hilbertIdx, adjustedPrevPeriod)
+		q2 = (0.2 * (c.q1.value + c.jI.value)) + (0.8 * c.prevQ2)
+		i2 = (0.2 * (c.i1ForOddPrev3 - c.jQ.value)) + (0.8 * c.prevI2)
+		c.i1ForEvenPrev3 = c.i1ForEvenPrev2
+		c.i1ForEvenPrev2 = c.detrender.value
+	}
+
+	// Homodyne discriminator
+	c.re = (0.2 * ((i2 * c.prevI2) + (q2 * c.prevQ2))) + (0.8 * c.re)
+	c.im = (0.2 * ((i2 * c.prevQ2) - (q2 * c.prevI2))) + (0.8 * c.im)
+	c.prevQ2 = q2
+	c.prevI2 = i2
+	tempReal := c.period
+	if c.im != 0.0 && c.re != 0.0 {
+		c.period = 360.0 / (math.Atan(c.im/c.re) * rad2Deg)
+	}
+	tempReal2 := 1.5 * tempReal
+	if c.period > tempReal2 {
+		c.period = tempReal2
+	}
+	tempReal2 = 0.67 * tempReal
+	if c.period < tempReal2 {
+		c.period = tempReal2
+	}
+	if c.period < 6 {
+		c.period = 6
+	} else if c.period > 50 {
+		c.period = 50
+	}
+	c.period = (0.2 * c.period) + (0.8 * tempReal)
+	c.smoothPeriod = (0.33 * c.period) + (0.67 * c.smoothPeriod)
+	return today, true
+}
+
+// phase returns the phase of the dominant cycle in degrees, from the
+// smoothed prices of the last period and the previous phase
+func (c *dominantCycle) phase(prevPhase float64) float64 {
+	constDeg2RadBy360 := math.Atan(1) * 8.0
+	dcPeriodInt := int(c.smoothPeriod + 0.5)
+	realPart, imagPart := 0.0, 0.0
+	for i := 0; i < dcPeriodInt; i++ {
+		tempReal := (float64(i) * constDeg2RadBy360) / float64(dcPeriodInt)
+		tempReal2 := c.smoothPrice.ago(i)
+		realPart += math.Sin(tempReal) * tempReal2
+		imagPart += math.Cos(tempReal) * tempReal2
+	}
+
+	phase := prevPhase
+	tempReal := math.Abs(imagPart)
+	if tempReal > 0.0 {
+		phase = math.Atan(realPart/imagPart) * rad2Deg
+	} else if tempReal <= 0.01 {
+		if realPart < 0.0 {
+			phase -= 90.0
+		} else if realPart > 0.0 {
+			phase += 90.0
+		}
+	}
+	phase += 90.0
+
+	// Compensate for the one bar lag of the weighted moving average
+	phase += 360.0 / c.smoothPeriod
+	if imagPart < 0.0 {
+		phase += 180.0
+	}
+	if phase > 315.0 {
+		phase -= 360.0
+	}
+	return phase
+}
+
+// average returns the average of the prices of the last dominant cycle
+// period, 0 for a period under one bar
+func (c *dominantCycle) average(prices *window) float64 {
+	dcPeriodInt := int(c.smoothPeriod + 0.5)
+	tempReal := 0.0
+	for i := 0; i < dcPeriodInt; i++ {
+		tempReal += prices.ago(i)
+	}
+	if dcPeriodInt > 0 {
+		tempReal /= float64(dcPeriodInt)
+	}
+	return tempReal
+}
+
+// HT_DCPERIOD is a streaming Hilbert Transform - Dominant Cycle Period
+type HT_DCPERIOD struct {
+	cycle *dominantCycle
+}
+
+// NewHT_DCPERIOD creates a streaming HT_DCPERIOD
+func NewHT_DCPERIOD() *HT_DCPERIOD {
+	return &HT_DCPERIOD{cycle: newDominantCycle(9)}
+}
+
+func (h *HT_DCPERIOD) visitState(v stateVisitor) {
+	v.child("cycle", h.cycle)
+}
+
+// Update adds a bar and returns the dominant cycle period of the closes
+func (h *HT_DCPERIOD) Update(bar utils.OHLCV) (float64, bool) {
+	return h.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the dominant cycle period
+func (h *HT_DCPERIOD) UpdateValue(v float64) (float64, bool) {
+	today, ok := h.cycle.next(v)
+	if !ok {
+		return 0, false
+	}
+	return h.cycle.smoothPeriod, today >= indicators.HTDCPERIODLookback()
+}
+
+// HT_PHASORValue holds the outputs of HT_PHASOR
+type HT_PHASORValue struct {
+	InPhase    float64 // In-phase component
+	Quadrature float64 // Quadrature component
+}
+
+// HT_PHASOR is a streaming Hilbert Transform - Phasor Components
+type HT_PHASOR struct {
+	cycle *dominantCycle
+}
+
+// NewHT_PHASOR creates a streaming HT_PHASOR
+func NewHT_PHASOR() *HT_PHASOR {
+	return &HT_PHASOR{cycle: newDominantCycle(9)}
+}
+
+func (h *HT_PHASOR) visitState(v stateVisitor) {
+	v.child("cycle", h.cycle)
+}
+
+// Update adds a bar and returns the phasor components of the closes
+func (h *HT_PHASOR) Update(bar utils.OHLCV) (HT_PHASORValue, bool) {
+	return h.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the phasor components
+func (h *HT_PHASOR) UpdateValue(v float64) (HT_PHASORValue, bool) {
+	today, ok := h.cycle.next(v)
+	if !ok {
+		return HT_PHASORValue{}, false
+	}
+	return HT_PHASORValue{InPhase: h.cycle.inPhase, Quadrature: h.cycle.q1.value}, today >= indicators.HTPHASORLookback()
+}
+
+// HT_DCPHASE is a streaming Hilbert Transform - Dominant Cycle Phase
+type HT_DCPHASE struct {
+	cycle   *dominantCycle
+	dcPhase float64
+}
+
+// NewHT_DCPHASE creates a streaming HT_DCPHASE
+func NewHT_DCPHASE() *HT_DCPHASE {
+	return &HT_DCPHASE{cycle: newDominantCycle(34)}
+}
+
+func (h *HT_DCPHASE) visitState(v stateVisitor) {
+	v.child("cycle", h.cycle)
+	v.floatVar("dcPhase", &h.dcPhase)
+}
+
+// Update adds a bar and returns the dominant cycle phase of the closes
+func (h *HT_DCPHASE) Update(bar utils.OHLCV) (float64, bool) {
+	return h.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the dominant cycle phase, in degrees
+func (h *HT_DCPHASE) UpdateValue(v float64) (float64, bool) {
+	today, ok := h.cycle.next(v)
+	if !ok {
+		return 0, false
+	}
+	h.dcPhase = h.cycle.phase(h.dcPhase)
+	return h.dcPhase, today >= indicators.HTDCPHASELookback()
+}
+
+// HT_SINEValue holds the outputs of HT_SINE
+type HT_SINEValue struct {
+	Sine     float64 // Sine of the dominant cycle phase
+	LeadSine float64 // Sine of the phase advanced by 45 degrees
+}
+
+// HT_SINE is a streaming Hilbert Transform - SineWave
+type HT_SINE struct {
+	cycle   *dominantCycle
+	dcPhase float64
+}
+
+// NewHT_SINE creates a streaming HT_SINE
+func NewHT_SINE() *HT_SINE {
+	return &HT_SINE{cycle: newDominantCycle(34)}
+}
+
+func (h *HT_SINE) visitState(v stateVisitor) {
+	v.child("cycle", h.cycle)
+	v.floatVar("dcPhase", &h.dcPhase)
+}
+
+// Update adds a bar and returns the sine wave of the closes
+func (h *HT_SINE) Update(bar utils.OHLCV) (HT_SINEValue, bool) {
+	return h.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the sine wave
+func (h *HT_SINE) UpdateValue(v float64) (HT_SINEValue, bool) {
+	today, ok := h.cycle.next(v)
+	if !ok {
+		return HT_SINEValue{}, false
+	}
+	h.dcPhase = h.cycle.phase(h.dcPhase)
+	deg2Rad := 1.0 / rad2Deg
+	out := HT_SINEValue{Sine: math.Sin(h.dcPhase * deg2Rad), LeadSine: math.Sin((h.dcPhase + 45) * deg2Rad)}
+	return out, today >= indicators.HTSINELookback()
+}
+
+// HT_TRENDLINE is a streaming Hilbert Transform - Instantaneous Trendline
+type HT_TRENDLINE struct {
+	cycle                     *dominantCycle
+	prices                    *window
+	iTrend1, iTrend2, iTrend3 float64
+}
+
+// NewHT_TRENDLINE creates a streaming HT_TRENDLINE
+func NewHT_TRENDLINE() *HT_TRENDLINE {
+	return &HT_TRENDLINE{cycle: newDominantCycle(34), prices: newWindow(smoothPriceSize)}
+}
+
+func (h *HT_TRENDLINE) visitState(v stateVisitor) {
+	v.child("cycle", h.cycle)
+	v.child("prices", h.prices)
+	v.floatVar("iTrend1", &h.iTrend1)
+	v.floatVar("iTrend2", &h.iTrend2)
+	v.floatVar("iTrend3", &h.iTrend3)
+}
+
+// Update adds a bar and returns the trendline of the closes
+func (h *HT_TRENDLINE) Update(bar utils.OHLCV) (float64, bool) {
+	return h.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the trendline
+func (h *HT_TRENDLINE) UpdateValue(v float64) (float64, bool) {
+	h.prices.push(v)
+	today, ok := h.cycle.next(v)
+	if !ok {
+		return 0, false
+	}
+	tempReal := h.cycle.average(h.prices)
+	trendline := (4.0*tempReal + 3.0*h.iTrend1 + 2.0*h.iTrend2 + h.iTrend3) / 10.0
+	h.iTrend3, h.iTrend2, h.iTrend1 = h.iTrend2, h.iTrend1, tempReal
+	return trendline, today >= indicators.HTTRENDLINELookback()
+}
+
+// HT_TRENDMODE is a streaming Hilbert Transform - Trend vs Cycle Mode
+type HT_TRENDMODE struct {
+	cycle                     *dominantCycle
+	prices                    *window
+	iTrend1, iTrend2, iTrend3 float64
+	daysInTrend               int
+	dcPhase                   float64
+	sine, leadSine            float64
+}
+
+// NewHT_TRENDMODE creates a streaming HT_TRENDMODE
+func NewHT_TRENDMODE() *HT_TRENDMODE {
+	return &HT_TRENDMODE{cycle: newDominantCycle(34), prices: newWindow(smoothPriceSize)}
+}
+
+func (h *HT_TRENDMODE) visitState(v stateVisitor) {
+	v.child("cycle", h.cycle)
+	v.child("prices", h.prices)
+	v.floatVar("iTrend1", &h.iTrend1)
+	v.floatVar("iTrend2", &h.iTrend2)
+	v.floatVar("iTrend3", &h.iTrend3)
+	v.rangeVar("daysInTrend", &h.daysInTrend, 0, unbounded)
+	v.floatVar("dcPhase", &h.dcPhase)
+	v.floatVar("sine", &h.sine)
+	v.floatVar("leadSine", &h.leadSine)
+}
+
+// Update adds a bar and returns the trend mode of the closes
+func (h *HT_TRENDMODE) Update(bar utils.OHLCV) (float64, bool) {
+	return h.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns 1 when the prices trend and 0 when
+// they follow a cycle
+func (h *HT_TRENDMODE) UpdateValue(v float64) (float64, bool) {
+	h.prices.push(v)
+	today, ok := h.cycle.next(v)
+	if !ok {
+		return 0, false
+	}
+	c := h.cycle
+	prevDCPhase := h.dcPhase
+	h.dcPhase = c.phase(h.dcPhase)
+	prevSine, prevLeadSine := h.sine, h.leadSine
+	deg2Rad := 1.0 / rad2Deg
+	h.sine = math.Sin(h.dcPhase * deg2Rad)
+	h.leadSine = math.Sin((h.dcPhase + 45) * deg2Rad)
+
+	average := c.average(h.prices)
+	trendline := (4.0*average + 3.0*h.iTrend1 + 2.0*h.iTrend2 + h.iTrend3) / 10.0
+	h.iTrend3, h.iTrend2, h.iTrend1 = h.iTrend2, h.iTrend1, average
+
+	// A crossing of the sine and the lead sine starts a cycle
+	trend := 1.0
+	if (h.sine > h.leadSine && prevSine <= prevLeadSine) || (h.sine < h.leadSine && prevSine >= prevLeadSine) {
+		h.daysInTrend = 0
+		trend = 0
+	}
+	h.daysInTrend++
+	if float64(h.daysInTrend) < 0.5*c.smoothPeriod {
+		trend = 0
+	}
+
+	// A phase moving at the cycle rate is a cycle
+	tempReal := h.dcPhase - prevDCPhase
+	if c.smoothPeriod != 0.0 && tempReal > 0.67*360.0/c.smoothPeriod && tempReal < 1.5*360.0/c.smoothPeriod {
+		trend = 0
+	}
+
+	// Prices 1.5% away from the trendline are a trend
+	tempReal = c.smoothPrice.ago(0)
+	if trendline != 0.0 && math.Abs((tempReal-trendline)/trendline) >= 0.015 {
+		trend = 1
+	}
+	return trend, today >= indicators.HTTRENDMODELookback()
+}
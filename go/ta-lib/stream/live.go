@@ -0,0 +1,63 @@
+package stream
+
+import (
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Updater is a streaming indicator of this package with an output of type T,
+// such as *RSI for float64 or *MACD for MACDValue
+type Updater[T any] interface {
+	Update(bar utils.OHLCV) (T, bool)
+	Stateful
+}
+
+// Live feeds an indicator from a source that revises the forming bar until
+// it closes, such as the Binance kline stream. Forming bars give provisional
+// values without changing the indicator, closed bars are committed.
+type Live[T any] struct {
+	ind        Updater[T]
+	snapshot   snapshot
+	pending    utils.OHLCV
+	hasPending bool
+}
+
+// NewLive wraps a streaming indicator, for example NewLive(rsi)
+func NewLive[T any](ind Updater[T]) *Live[T] {
+	return &Live[T]{ind: ind}
+}
+
+// Peek returns the value the indicator would have if the bar closed now,
+// leaving the indicator unchanged
+func (l *Live[T]) Peek(bar utils.OHLCV) (T, bool) {
+	l.pending = bar
+	l.hasPending = true
+	l.snapshot.save(l.ind)
+	value, ok := l.ind.Update(bar)
+	l.snapshot.rollback(l.ind)
+	return value, ok
+}
+
+// Commit adds a closed bar to the indicator and returns its value
+func (l *Live[T]) Commit(bar utils.OHLCV) (T, bool) {
+	l.hasPending = false
+	return l.ind.Update(bar)
+}
+
+// Update peeks at a forming bar or commits a closed one, so the closed flag
+// of a kline stream maps directly onto it:
+//
+//	bar, closed, err := utils.ParseBinanceKline(msg)
+//	value, ready := live.Update(bar, closed)
+//
+// When a bar starts while the previous one was never seen closed, for
+// example after a missed message, the last revision of the previous bar is
+// committed first.
+func (l *Live[T]) Update(bar utils.OHLCV, closed bool) (T, bool) {
+	if l.hasPending && bar.Time > l.pending.Time {
+		l.Commit(l.pending)
+	}
+	if closed {
+		return l.Commit(bar)
+	}
+	return l.Peek(bar)
+}
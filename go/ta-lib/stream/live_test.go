@@ -0,0 +1,212 @@
+package stream_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/stream"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// indicator is a streaming indicator whose outputs are compared as any, so
+// that the indicators of every output type fit in one table
+type indicator interface {
+	stream.Updater[any]
+	unwrap() stream.Stateful
+}
+
+// anyUpdater adapts a streaming indicator to indicator
+type anyUpdater[T any] struct {
+	stream.Updater[T]
+}
+
+func (a anyUpdater[T]) Update(bar utils.OHLCV) (any, bool) {
+	return a.Updater.Update(bar)
+}
+
+func (a anyUpdater[T]) unwrap() stream.Stateful {
+	return a.Updater
+}
+
+// wrap adapts the result of a streaming indicator constructor
+func wrap[T any, I stream.Updater[T]](ind I, err error) (indicator, error) {
+	if err != nil {
+		return nil, err
+	}
+	return anyUpdater[T]{ind}, nil
+}
+
+// statefulCase creates a streaming indicator for the tests of its state
+type statefulCase struct {
+	name string
+	new  func() (indicator, error)
+}
+
+// statefulCases are streaming indicators of every kind of state
+func statefulCases() []statefulCase {
+	var cases []statefulCase
+	for _, maType := range allMATypes {
+		cases = append(cases,
+			statefulCase{fmt.Sprintf("MA(10, %v)", maType), func() (indicator, error) {
+				return wrap[float64](stream.NewMA(10, maType))
+			}},
+			statefulCase{fmt.Sprintf("BBANDS(20, %v)", maType), func() (indicator, error) {
+				return wrap[stream.BBANDSValue](stream.NewBBANDS(20, 2, 2, maType))
+			}},
+			statefulCase{fmt.Sprintf("STOCH(14, 3, 3, %v)", maType), func() (indicator, error) {
+				return wrap[stream.STOCHValue](stream.NewSTOCH(14, 3, 3, maType, maType))
+			}},
+		)
+	}
+	return append(cases, []statefulCase{
+		{"MAMA", func() (indicator, error) { return wrap[stream.MAMAValue](stream.NewMAMA(0.5, 0.05)) }},
+		{"T3", func() (indicator, error) { return wrap[float64](stream.NewT3(5, 0.7)) }},
+		{"HT_DCPERIOD", func() (indicator, error) { return wrap[float64](stream.NewHT_DCPERIOD(), nil) }},
+		{"HT_PHASOR", func() (indicator, error) { return wrap[stream.HT_PHASORValue](stream.NewHT_PHASOR(), nil) }},
+		{"HT_DCPHASE", func() (indicator, error) { return wrap[float64](stream.NewHT_DCPHASE(), nil) }},
+		{"HT_SINE", func() (indicator, error) { return wrap[stream.HT_SINEValue](stream.NewHT_SINE(), nil) }},
+		{"HT_TRENDLINE", func() (indicator, error) { return wrap[float64](stream.NewHT_TRENDLINE(), nil) }},
+		{"HT_TRENDMODE", func() (indicator, error) { return wrap[float64](stream.NewHT_TRENDMODE(), nil) }},
+		{"RSI", func() (indicator, error) { return wrap[float64](stream.NewRSI(14)) }},
+		{"MACD", func() (indicator, error) { return wrap[stream.MACDValue](stream.NewMACD(12, 26, 9)) }},
+		{"STOCHF", func() (indicator, error) { return wrap[stream.STOCHFValue](stream.NewSTOCHF(5, 3)) }},
+		{"STOCHRSI", func() (indicator, error) { return wrap[stream.STOCHFValue](stream.NewSTOCHRSI(14, 5, 3, utils.EMA)) }},
+		{"ROC", func() (indicator, error) { return wrap[float64](stream.NewROC(10)) }},
+		{"CCI", func() (indicator, error) { return wrap[float64](stream.NewCCI(14)) }},
+		{"WILLR", func() (indicator, error) { return wrap[float64](stream.NewWILLR(14)) }},
+		{"PLUS_DI", func() (indicator, error) { return wrap[float64](stream.NewPLUS_DI(14)) }},
+		{"MINUS_DI", func() (indicator, error) { return wrap[float64](stream.NewMINUS_DI(1)) }},
+		{"DX", func() (indicator, error) { return wrap[float64](stream.NewDX(14)) }},
+		{"ADX", func() (indicator, error) { return wrap[stream.ADXValue](stream.NewADX(14)) }},
+		{"APO", func() (indicator, error) { return wrap[float64](stream.NewAPO(12, 26, utils.KAMA)) }},
+		{"PPO", func() (indicator, error) { return wrap[float64](stream.NewPPO(12, 26)) }},
+		{"TRANGE", func() (indicator, error) { return wrap[float64](stream.NewTRANGE(), nil) }},
+		{"ATR", func() (indicator, error) { return wrap[float64](stream.NewATR(14)) }},
+		{"VAR", func() (indicator, error) { return wrap[float64](stream.NewVAR(10, 1)) }},
+		{"STDDEV", func() (indicator, error) { return wrap[float64](stream.NewSTDDEV(10, 2)) }},
+		{"OBV", func() (indicator, error) { return wrap[float64](stream.NewOBV(), nil) }},
+		{"VWMA", func() (indicator, error) { return wrap[float64](stream.NewVWMA(20)) }},
+		{"AD", func() (indicator, error) { return wrap[float64](stream.NewAD(), nil) }},
+		{"MFI", func() (indicator, error) { return wrap[float64](stream.NewMFI(14)) }},
+		{"VWAP", func() (indicator, error) { return wrap[stream.VWAPValue](stream.NewSessionVWAP(nil, 2)) }},
+		{"anchored VWAP", func() (indicator, error) { return wrap[stream.VWAPValue](stream.NewAnchoredVWAP(1720000000, 1)) }},
+	}...)
+}
+
+// revisions returns intermediate versions of a bar, as a kline stream sends
+// them before the bar closes
+func revisions(bar utils.OHLCV) []utils.OHLCV {
+	out := make([]utils.OHLCV, 0, 3)
+	for _, f := range []float64{0.97, 1.05, 1.01} {
+		rev := bar
+		rev.High *= f + 0.02
+		rev.Low *= f - 0.02
+		rev.Close *= f
+		rev.Volume *= f / 2
+		out = append(out, rev)
+	}
+	return out
+}
+
+// Provisional values of forming bars never leak into the indicator state:
+// peeks followed by commits, closed flags and bars that never close give the
+// values of a plain stream
+func TestLive(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	for _, tc := range statefulCases() {
+		var inds [4]indicator
+		for i := range inds {
+			ind, err := tc.new()
+			if err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+			inds[i] = ind
+		}
+		plain := inds[0]
+		peeked := stream.NewLive[any](inds[1])
+		flagged := stream.NewLive[any](inds[2])
+		unclosed := stream.NewLive[any](inds[3])
+
+		for i, bar := range data {
+			want, wantOK := plain.Update(bar)
+			for _, rev := range revisions(bar) {
+				peeked.Peek(rev)
+				flagged.Update(rev, false)
+				unclosed.Update(rev, false)
+			}
+
+			if v, ok := peeked.Peek(bar); v != want || ok != wantOK {
+				t.Fatalf("%s: peek at bar %d is %v, stream has %v", tc.name, i, v, want)
+			}
+			if v, ok := peeked.Commit(bar); v != want || ok != wantOK {
+				t.Fatalf("%s: commit at bar %d is %v, stream has %v", tc.name, i, v, want)
+			}
+			if v, ok := flagged.Update(bar, true); v != want || ok != wantOK {
+				t.Fatalf("%s: closed update at bar %d is %v, stream has %v", tc.name, i, v, want)
+			}
+			// The previous bar is committed when this one starts
+			if v, ok := unclosed.Update(bar, false); v != want || ok != wantOK {
+				t.Fatalf("%s: unclosed update at bar %d is %v, stream has %v", tc.name, i, v, want)
+			}
+		}
+	}
+}
+
+// Peeking leaves the state as it was and, once the first peek sized the
+// snapshot, costs no allocation whatever the period
+func TestLivePeek(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	alma, err := stream.NewALMA(100, 0.85, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mfi, err := stream.NewMFI(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name string
+		ind  stream.Stateful
+		live interface {
+			Peek(utils.OHLCV) (float64, bool)
+			Commit(utils.OHLCV) (float64, bool)
+		}
+	}{
+		{"ALMA", alma, stream.NewLive[float64](alma)},
+		{"MFI", mfi, stream.NewLive[float64](mfi)},
+	} {
+		last := len(data) - 1
+		for _, bar := range data[:last] {
+			tc.live.Commit(bar)
+		}
+		before, err := stream.SaveState(tc.ind)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bar := data[last]
+		want, ok := tc.live.Peek(bar)
+		if !ok {
+			t.Fatalf("%s: not ready after %d bars", tc.name, last)
+		}
+		allocs := testing.AllocsPerRun(100, func() {
+			bar.Close *= 1.001
+			bar.High = max(bar.High, bar.Close)
+			tc.live.Peek(bar)
+		})
+		if allocs != 0 {
+			t.Errorf("%s: %v allocations per peek", tc.name, allocs)
+		}
+		after, err := stream.SaveState(tc.ind)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(before, after) {
+			t.Errorf("%s: peeking changed the state", tc.name)
+		}
+		if got, _ := tc.live.Commit(data[last]); got != want {
+			t.Errorf("%s: committed %v after peeking %v", tc.name, got, want)
+		}
+	}
+}
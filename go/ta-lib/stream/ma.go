@@ -0,0 +1,1099 @@
+package stream
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// SMA is a streaming Simple Moving Average
+type SMA struct {
+	period      int
+	window      *window
+	periodTotal float64
+}
+
+// NewSMA creates a streaming SMA
+func NewSMA(optInTimePeriod int) (*SMA, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	return newSMA(optInTimePeriod), nil
+}
+
+func newSMA(period int) *SMA {
+	return &SMA{period: period, window: newWindow(period)}
+}
+
+func (s *SMA) visitState(v stateVisitor) {
+	v.intParam("period", &s.period)
+	v.child("window", s.window)
+	v.floatVar("periodTotal", &s.periodTotal)
+}
+
+// Update adds a bar and returns the SMA of the closes
+func (s *SMA) Update(bar utils.OHLCV) (float64, bool) {
+	return s.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the SMA
+func (s *SMA) UpdateValue(v float64) (float64, bool) {
+	s.periodTotal += v
+	s.window.push(v)
+	if !s.window.full() {
+		return 0, false
+	}
+	tempReal := s.periodTotal
+	s.periodTotal -= s.window.ago(s.period - 1)
+	return tempReal / float64(s.period), true
+}
+
+// EMA is a streaming Exponential Moving Average
+type EMA struct {
+	period int
+	k      float64
+	count  int
+	prevMA float64
+}
+
+// NewEMA creates a streaming EMA
+func NewEMA(optInTimePeriod int) (*EMA, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	return newEMA(optInTimePeriod), nil
+}
+
+func newEMA(period int) *EMA {
+	return &EMA{period: period, k: utils.PerToK(period)}
+}
+
+func (e *EMA) visitState(v stateVisitor) {
+	v.intParam("period", &e.period)
+	v.rangeVar("count", &e.count, 0, unbounded)
+	v.floatVar("prevMA", &e.prevMA)
+}
+
+// Update adds a bar and returns the EMA of the closes
+func (e *EMA) Update(bar utils.OHLCV) (float64, bool) {
+	return e.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the EMA, seeded with the simple average of the first period
+func (e *EMA) UpdateValue(v float64) (float64, bool) {
+	if e.count < e.period {
+		e.prevMA += v
+		e.count++
+		if e.count < e.period {
+			return 0, false
+		}
+		e.prevMA /= float64(e.period)
+		return e.prevMA, true
+	}
+	e.prevMA = ((v - e.prevMA) * e.k) + e.prevMA
+	return e.prevMA, true
+}
+
+// WMA is a streaming Weighted Moving Average
+type WMA struct {
+	period        int
+	divider       float64
+	window        *window
+	periodSum     float64
+	periodSub     float64
+	trailingValue float64
+}
+
+// NewWMA creates a streaming WMA
+func NewWMA(optInTimePeriod int) (*WMA, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	return newWMA(optInTimePeriod), nil
+}
+
+func newWMA(period int) *WMA {
+	return &WMA{
+		period:  period,
+		divider: float64((period * (period + 1)) >> 1),
+		window:  newWindow(period),
+	}
+}
+
+func (w *WMA) visitState(v stateVisitor) {
+	v.intParam("period", &w.period)
+	v.child("window", w.window)
+	v.floatVar("periodSum", &w.periodSum)
+	v.floatVar("periodSub", &w.periodSub)
+	v.floatVar("trailingValue", &w.trailingValue)
+}
+
+// Update adds a bar and returns the WMA of the closes
+func (w *WMA) Update(bar utils.OHLCV) (float64, bool) {
+	return w.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the WMA
+func (w *WMA) UpdateValue(v float64) (float64, bool) {
+	if w.window.count < w.period-1 {
+		w.window.push(v)
+		w.periodSub += v
+		w.periodSum += v * float64(w.window.count)
+		return 0, false
+	}
+	w.periodSub += v
+	w.periodSub -= w.trailingValue
+	w.periodSum += v * float64(w.period)
+	w.window.push(v)
+	w.trailingValue = w.window.ago(w.period - 1)
+	out := w.periodSum / w.divider
+	w.periodSum -= w.periodSub
+	return out, true
+}
+
+// DEMA is a streaming Double Exponential Moving Average
+type DEMA struct {
+	first, second *EMA
+}
+
+// NewDEMA creates a streaming DEMA
+func NewDEMA(optInTimePeriod int) (*DEMA, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	return newDEMA(optInTimePeriod), nil
+}
+
+func newDEMA(period int) *DEMA {
+	return &DEMA{first: newEMA(period), second: newEMA(period)}
+}
+
+func (d *DEMA) visitState(v stateVisitor) {
+	v.child("first", d.first)
+	v.child("second", d.second)
+}
+
+// Update adds a bar and returns the DEMA of the closes
+func (d *DEMA) Update(bar utils.OHLCV) (float64, bool) {
+	return d.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the DEMA
+func (d *DEMA) UpdateValue(v float64) (float64, bool) {
+	firstEMA, ok := d.first.UpdateValue(v)
+	if !ok {
+		return 0, false
+	}
+	secondEMA, ok := d.second.UpdateValue(firstEMA)
+	if !ok {
+		return 0, false
+	}
+	return (2.0 * firstEMA) - secondEMA, true
+}
+
+// TEMA is a streaming Triple Exponential Moving Average
+type TEMA struct {
+	first, second, third *EMA
+}
+
+// NewTEMA creates a streaming TEMA
+func NewTEMA(optInTimePeriod int) (*TEMA, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	return newTEMA(optInTimePeriod), nil
+}
+
+func newTEMA(period int) *TEMA {
+	return &TEMA{first: newEMA(period), second: newEMA(period), third: newEMA(period)}
+}
+
+func (t *TEMA) visitState(v stateVisitor) {
+	v.child("first", t.first)
+	v.child("second", t.second)
+	v.child("third", t.third)
+}
+
+// Update adds a bar and returns the TEMA of the closes
+func (t *TEMA) Update(bar utils.OHLCV) (float64, bool) {
+	return t.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the TEMA
+func (t *TEMA) UpdateValue(v float64) (float64, bool) {
+	firstEMA, ok := t.first.UpdateValue(v)
+	if !ok {
+		return 0, false
+	}
+	secondEMA, ok := t.second.UpdateValue(firstEMA)
+	if !ok {
+		return 0, false
+	}
+	thirdEMA, ok := t.third.UpdateValue(secondEMA)
+	if !ok {
+		return 0, false
+	}
+	return thirdEMA + ((3.0 * firstEMA) - (3.0 * secondEMA)), true
+}
+
+// TRIMA is a streaming Triangular Moving Average
+type TRIMA struct {
+	period       int
+	half         int
+	odd          bool
+	factor       float64
+	window       *window
+	ready        bool
+	numerator    float64
+	numeratorSub float64
+	numeratorAdd float64
+}
+
+// NewTRIMA creates a streaming TRIMA
+func NewTRIMA(optInTimePeriod int) (*TRIMA, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	return newTRIMA(optInTimePeriod), nil
+}
+
+func newTRIMA(period int) *TRIMA {
+	t := &TRIMA{
+		period: period,
+		half:   period >> 1,
+		odd:    period%2 == 1,
+		window: newWindow(period + 1),
+	}
+	if t.odd {
+		t.factor = 1.0 / float64((t.half+1)*(t.half+1))
+	} else {
+		t.factor = 1.0 / float64(t.half*(t.half+1))
+	}
+	return t
+}
+
+func (t *TRIMA) visitState(v stateVisitor) {
+	v.intParam("period", &t.period)
+	v.child("window", t.window)
+	v.boolVar("ready", &t.ready)
+	v.floatVar("numerator", &t.numerator)
+	v.floatVar("numeratorSub", &t.numeratorSub)
+	v.floatVar("numeratorAdd", &t.numeratorAdd)
+}
+
+// Update adds a bar and returns the TRIMA of the closes
+func (t *TRIMA) Update(bar utils.OHLCV) (float64, bool) {
+	return t.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the TRIMA
+func (t *TRIMA) UpdateValue(v float64) (float64, bool) {
+	t.window.push(v)
+	if !t.ready {
+		if t.window.count < t.period {
+			return 0, false
+		}
+
+		// Sum the first window from the middle outwards
+		middleIdx := t.half - 1
+		if t.odd {
+			middleIdx = t.half
+		}
+		last := t.period - 1
+		for i := middleIdx; i >= 0; i-- {
+			t.numeratorSub += t.window.ago(last - i)
+			t.numerator += t.numeratorSub
+		}
+		for i := middleIdx + 1; i <= last; i++ {
+			t.numeratorAdd += t.window.ago(last - i)
+			t.numerator += t.numeratorAdd
+		}
+		t.ready = true
+		return t.numerator * t.factor, true
+	}
+
+	t.numerator -= t.numeratorSub
+	t.numeratorSub -= t.window.ago(t.period)
+	tempReal := t.window.ago(t.half)
+	t.numeratorSub += tempReal
+	if t.odd {
+		t.numerator += t.numeratorAdd
+		t.numeratorAdd -= tempReal
+	} else {
+		t.numeratorAdd -= tempReal
+		t.numerator += t.numeratorAdd
+	}
+	t.numeratorAdd += v
+	t.numerator += v
+	return t.numerator * t.factor, true
+}
+
+// KAMA smoothing constants for the fastest (2) and slowest (30) periods,
+// rounded to float64 before the subtraction as in the C implementation
+var (
+	kamaConstMax  = 2.0 / (30.0 + 1.0)
+	kamaConstDiff = 2.0/(2.0+1.0) - kamaConstMax
+)
+
+// kamaSmoothing converts an efficiency ratio input into the KAMA smoothing factor
+func kamaSmoothing(periodROC, sumROC1 float64) float64 {
+	var tempReal float64
+	if sumROC1 <= periodROC || utils.TAIsZero(sumROC1) {
+		tempReal = 1.0
+	} else {
+		tempReal = math.Abs(periodROC / sumROC1)
+	}
+	tempReal = (tempReal * kamaConstDiff) + kamaConstMax
+	return tempReal * tempReal
+}
+
+// KAMA is a streaming Kaufman Adaptive Moving Average
+type KAMA struct {
+	period        int
+	window        *window
+	ready         bool
+	sumROC1       float64
+	prevKAMA      float64
+	trailingValue float64
+}
+
+// NewKAMA creates a streaming KAMA
+func NewKAMA(optInTimePeriod int) (*KAMA, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	return newKAMA(optInTimePeriod), nil
+}
+
+func newKAMA(period int) *KAMA {
+	return &KAMA{period: period, window: newWindow(period + 1)}
+}
+
+func (k *KAMA) visitState(v stateVisitor) {
+	v.intParam("period", &k.period)
+	v.child("window", k.window)
+	v.boolVar("ready", &k.ready)
+	v.floatVar("sumROC1", &k.sumROC1)
+	v.floatVar("prevKAMA", &k.prevKAMA)
+	v.floatVar("trailingValue", &k.trailingValue)
+}
+
+// Update adds a bar and returns the KAMA of the closes
+func (k *KAMA) Update(bar utils.OHLCV) (float64, bool) {
+	return k.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the KAMA
+func (k *KAMA) UpdateValue(v float64) (float64, bool) {
+	k.window.push(v)
+	if !k.ready {
+		if k.window.count > 1 {
+			k.sumROC1 += math.Abs(k.window.ago(1) - v)
+		}
+		if !k.window.full() {
+			return 0, false
+		}
+
+		// First KAMA value is seeded with the previous price
+		k.prevKAMA = k.window.ago(1)
+		periodROC := v - k.window.ago(k.period)
+		k.trailingValue = k.window.ago(k.period)
+		k.prevKAMA = ((v - k.prevKAMA) * kamaSmoothing(periodROC, k.sumROC1)) + k.prevKAMA
+		k.ready = true
+		return k.prevKAMA, true
+	}
+
+	tempReal2 := k.window.ago(k.period)
+	periodROC := v - tempReal2
+	k.sumROC1 -= math.Abs(k.trailingValue - tempReal2)
+	k.sumROC1 += math.Abs(v - k.window.ago(1))
+	k.trailingValue = tempReal2
+	k.prevKAMA = ((v - k.prevKAMA) * kamaSmoothing(periodROC, k.sumROC1)) + k.prevKAMA
+	return k.prevKAMA, true
+}
+
+// MAMAValue holds the outputs of MAMA
+type MAMAValue struct {
+	MAMA float64 // MESA Adaptive Moving Average
+	FAMA float64 // Following Adaptive Moving Average
+}
+
+// Hilbert transform coefficients used by the MESA functions
+const (
+	hilbertA = 0.0962
+	hilbertB = 0.5769
+)
+
+var rad2Deg = 180.0 / (4.0 * math.Atan(1))
+
+// hilbertTransform holds the state of one Hilbert transform variable,
+// mirroring the HILBERT_VARIABLES macros of the C implementation
+type hilbertTransform struct {
+	odd, even                   [3]float64
+	value                       float64
+	prevOdd, prevEven           float64
+	prevInputOdd, prevInputEven float64
+}
+
+// do runs one transform step on the odd or even half of the state
+func (h *hilbertTransform) do(input float64, isEven bool, hilbertIdx int, adjustedPrevPeriod float64) {
+	hilbertTempReal := hilbertA * input
+	if isEven {
+		h.value = -h.even[hilbertIdx]
+		h.even[hilbertIdx] = hilbertTempReal
+		h.value += hilbertTempReal
+		h.value -= h.prevEven
+		h.prevEven = hilbertB * h.prevInputEven
+		h.value += h.prevEven
+		h.prevInputEven = input
+	} else {
+		h.value = -h.odd[hilbertIdx]
+		h.odd[hilbertIdx] = hilbertTempReal
+		h.value += hilbertTempReal
+		h.value -= h.prevOdd
+		h.prevOdd = hilbertB * h.prevInputOdd
+		h.value += h.prevOdd
+		h.prevInputOdd = input
+	}
+	h.value *= adjustedPrevPeriod
+}
+
+func (h *hilbertTransform) visitState(v stateVisitor) {
+	v.floatsVar("odd", h.odd[:])
+	v.floatsVar("even", h.even[:])
+	v.floatVar("value", &h.value)
+	v.floatVar("prevOdd", &h.prevOdd)
+	v.floatVar("prevEven", &h.prevEven)
+	v.floatVar("prevInputOdd", &h.prevInputOdd)
+	v.floatVar("prevInputEven", &h.prevInputEven)
+}
+
+// priceWMA is the 4 bar weighted moving average used to smooth prices
+// before the Hilbert transform
+type priceWMA struct {
+	prices        *window
+	sub, sum      float64
+	trailingValue float64
+}
+
+func newPriceWMA() *priceWMA {
+	return &priceWMA{prices: newWindow(4)}
+}
+
+func (w *priceWMA) visitState(v stateVisitor) {
+	v.child("prices", w.prices)
+	v.floatVar("sub", &w.sub)
+	v.floatVar("sum", &w.sum)
+	v.floatVar("trailingValue", &w.trailingValue)
+}
+
+// next adds a price and returns the smoothed value once three prices were seen
+func (w *priceWMA) next(price float64) (float64, bool) {
+	w.prices.push(price)
+	if w.prices.count < 4 {
+		w.sub += price
+		w.sum += price * float64(w.prices.count)
+		return 0, false
+	}
+	w.sub += price
+	w.sub -= w.trailingValue
+	w.sum += price * 4.0
+	w.trailingValue = w.prices.ago(3)
+	smoothed := w.sum * 0.1
+	w.sum -= w.sub
+	return smoothed, true
+}
+
+// MAMA is a streaming MESA Adaptive Moving Average
+type MAMA struct {
+	fastLimit, slowLimit float64
+
+	today                         int
+	wma                           *priceWMA
+	detrender, q1, jI, jQ         hilbertTransform
+	hilbertIdx                    int
+	period                        float64
+	prevI2, prevQ2                float64
+	re, im                        float64
+	mama, fama                    float64
+	i1ForOddPrev3, i1ForEvenPrev3 float64
+	i1ForOddPrev2, i1ForEvenPrev2 float64
+	prevPhase                     float64
+}
+
+// NewMAMA creates a streaming MAMA
+func NewMAMA(optInFastLimit, optInSlowLimit float64) (*MAMA, error) {
+	if optInFastLimit < 0.01 || optInFastLimit > 0.99 || optInSlowLimit < 0.01 || optInSlowLimit > 0.99 {
+		return nil, utils.ErrInvalidParameter
+	}
+	return newMAMA(optInFastLimit, optInSlowLimit), nil
+}
+
+func newMAMA(fastLimit, slowLimit float64) *MAMA {
+	return &MAMA{fastLimit: fastLimit, slowLimit: slowLimit, wma: newPriceWMA()}
+}
+
+func (m *MAMA) visitState(v stateVisitor) {
+	v.floatParam("fastLimit", &m.fastLimit)
+	v.floatParam("slowLimit", &m.slowLimit)
+	v.rangeVar("today", &m.today, 0, unbounded)
+	v.child("wma", m.wma)
+	v.child("detrender", &m.detrender)
+	v.child("q1", &m.q1)
+	v.child("jI", &m.jI)
+	v.child("jQ", &m.jQ)
+	v.rangeVar("hilbertIdx", &m.hilbertIdx, 0, len(m.detrender.odd)-1)
+	v.floatVar("period", &m.period)
+	v.floatVar("prevI2", &m.prevI2)
+	v.floatVar("prevQ2", &m.prevQ2)
+	v.floatVar("re", &m.re)
+	v.floatVar("im", &m.im)
+	v.floatVar("mama", &m.mama)
+	v.floatVar("fama", &m.fama)
+	v.floatVar("i1ForOddPrev3", &m.i1ForOddPrev3)
+	v.floatVar("i1ForEvenPrev3", &m.i1ForEvenPrev3)
+	v.floatVar("i1ForOddPrev2", &m.i1ForOddPrev2)
+	v.floatVar("i1ForEvenPrev2", &m.i1ForEvenPrev2)
+	v.floatVar("prevPhase", &m.prevPhase)
+}
+
+// Update adds a bar and returns MAMA and FAMA of the closes
+func (m *MAMA) Update(bar utils.OHLCV) (MAMAValue, bool) {
+	return m.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns MAMA and FAMA
+func (m *MAMA) UpdateValue(v float64) (MAMAValue, bool) {
+	today := m.today
+	m.today++
+	smoothedValue, ok := m.wma.next(v)
+	if !ok || today < 12 {
+		return MAMAValue{}, false
+	}
+
+	adjustedPrevPeriod := (0.075 * m.period) + 0.54
+	var q2, i2, tempReal2 float64
+	if today%2 == 0 {
+		m.detrender.do(smoothedValue, true, m.hilbertIdx, adjustedPrevPeriod)
+		m.q1.do(m.detrender.value, true, m.hilbertIdx, adjustedPrevPeriod)
+		m.jI.do(m.i1ForEvenPrev3, true, m.hilbertIdx, adjustedPrevPeriod)
+		m.jQ.do(m.q1.value, true, m.hilbertIdx, adjustedPrevPeriod)
+		m.hilbertIdx++
+		if m.hilbertIdx == 3 {
+			m.hilbertIdx = 0
+		}
+		q2 = (0.2 * (m.q1.value + m.jI.value)) + (0.8 * m.prevQ2)
+		i2 = (0.2 * (m.i1ForEvenPrev3 - m.jQ.value)) + (0.8 * m.prevI2)
+		m.i1ForOddPrev3 = m.i1ForOddPrev2
+		m.i1ForOddPrev2 = m.detrender.value
+		if m.i1ForEvenPrev3 != 0.0 {
+			tempReal2 = math.Atan(m.q1.value/m.i1ForEvenPrev3) * rad2Deg
+		}
+	} else {
+		m.detrender.do(smoothedValue, false, m.hilbertIdx, adjustedPrevPeriod)
+		m.q1.do(m.detrender.value, false, m.hilbertIdx, adjustedPrevPeriod)
+		m.jI.do(m.i1ForOddPrev3, false, m.hilbertIdx, adjustedPrevPeriod)
+		m.jQ.do(m.q1.value, false, m.hilbertIdx, adjustedPrevPeriod)
+		q2 = (0.2 * (m.q1.value + m.jI.value)) + (0.8 * m.prevQ2)
+		i2 = (0.2 * (m.i1ForOddPrev3 - m.jQ.value)) + (0.8 * m.prevI2)
+		m.i1ForEvenPrev3 = m.i1ForEvenPrev2
+		m.i1ForEvenPrev2 = m.detrender.value
+		if m.i1ForOddPrev3 != 0.0 {
+			tempReal2 = math.Atan(m.q1.value/m.i1ForOddPrev3) * rad2Deg
+		}
+	}
+
+	// Adaptive alpha from the phase rate of change
+	tempReal := m.prevPhase - tempReal2
+	m.prevPhase = tempReal2
+	if tempReal < 1.0 {
+		tempReal = 1.0
+	}
+	if tempReal > 1.0 {
+		tempReal = m.fastLimit / tempReal
+		if tempReal < m.slowLimit {
+			tempReal = m.slowLimit
+		}
+	} else {
+		tempReal = m.fastLimit
+	}
+
+	m.mama = (tempReal * v) + ((1 - tempReal) * m.mama)
+	tempReal *= 0.5
+	m.fama = (tempReal * m.mama) + ((1 - tempReal) * m.fama)
+	out := MAMAValue{MAMA: m.mama, FAMA: m.fama}
+
+	// Homodyne discriminator
+	m.re = (0.2 * ((i2 * m.prevI2) + (q2 * m.prevQ2))) + (0.8 * m.re)
+	m.im = (0.2 * ((i2 * m.prevQ2) - (q2 * m.prevI2))) + (0.8 * m.im)
+	m.prevQ2 = q2
+	m.prevI2 = i2
+	tempReal = m.period
+	if m.im != 0.0 && m.re != 0.0 {
+		m.period = 360.0 / (math.Atan(m.im/m.re) * rad2Deg)
+	}
+	tempReal2 = 1.5 * tempReal
+	if m.period > tempReal2 {
+		m.period = tempReal2
+	}
+	tempReal2 = 0.67 * tempReal
+	if m.period < tempReal2 {
+		m.period = tempReal2
+	}
+	if m.period < 6 {
+		m.period = 6
+	} else if m.period > 50 {
+		m.period = 50
+	}
+	m.period = (0.2 * m.period) + (0.8 * tempReal)
+
+	return out, today >= 32
+}
+
+// mamaLine exposes the MAMA line of a MAMA as a single output
+type mamaLine struct {
+	*MAMA
+}
+
+func (m mamaLine) UpdateValue(v float64) (float64, bool) {
+	out, ok := m.MAMA.UpdateValue(v)
+	return out.MAMA, ok
+}
+
+// T3 is a streaming Triple Exponential Moving Average of Tillson
+type T3 struct {
+	period    int
+	vFactor   float64
+	k         float64
+	c         [4]float64 // Weights of the last four EMAs
+	count     int
+	periodSum float64 // Sum seeding the EMA being initialized
+	e         [6]float64
+	lookback  int
+}
+
+// NewT3 creates a streaming T3 with the volume factor optInVFactor in [0, 1], usually 0.7
+func NewT3(optInTimePeriod int, optInVFactor float64) (*T3, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	if !(optInVFactor >= 0 && optInVFactor <= 1) {
+		return nil, fmt.Errorf("volume factor %v out of range [0, 1]: %w", optInVFactor, utils.ErrInvalidParameter)
+	}
+	return newT3(optInTimePeriod, optInVFactor), nil
+}
+
+func newT3(period int, vFactor float64) *T3 {
+	t := &T3{period: period, vFactor: vFactor, k: 2.0 / (float64(period) + 1.0), lookback: 6 * (period - 1)}
+	tempReal := vFactor * vFactor
+	t.c[0] = -(tempReal * vFactor)
+	t.c[1] = 3.0 * (tempReal - t.c[0])
+	t.c[2] = -6.0*tempReal - 3.0*(vFactor-t.c[0])
+	t.c[3] = 1.0 + 3.0*vFactor - t.c[0] + 3.0*tempReal
+	return t
+}
+
+func (t *T3) visitState(v stateVisitor) {
+	v.intParam("period", &t.period)
+	v.floatParam("vFactor", &t.vFactor)
+	v.rangeVar("count", &t.count, 0, t.lookback+1)
+	v.floatVar("periodSum", &t.periodSum)
+	v.floatsVar("e", t.e[:])
+}
+
+// Update adds a bar and returns the T3 of the closes
+func (t *T3) Update(bar utils.OHLCV) (float64, bool) {
+	return t.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the T3. Each of the six chained EMAs
+// is seeded with the average of the first period of its input.
+func (t *T3) UpdateValue(v float64) (float64, bool) {
+	n := t.count
+	if t.count <= t.lookback {
+		t.count++
+	}
+	if n < t.period {
+		t.periodSum += v
+		if n == t.period-1 {
+			t.e[0] = t.periodSum / float64(t.period)
+			t.periodSum = t.e[0]
+		}
+		return 0, false
+	}
+
+	oneMinusK := 1.0 - t.k
+	level := len(t.e) // Number of EMAs already seeded
+	if n <= t.lookback {
+		level = 1 + (n-t.period)/(t.period-1)
+	}
+	t.e[0] = (t.k * v) + (oneMinusK * t.e[0])
+	for j := 1; j < level; j++ {
+		t.e[j] = (t.k * t.e[j-1]) + (oneMinusK * t.e[j])
+	}
+	if n <= t.lookback {
+		t.periodSum += t.e[level-1]
+		if (n-t.period+1)%(t.period-1) != 0 {
+			return 0, false
+		}
+		t.e[level] = t.periodSum / float64(t.period)
+		t.periodSum = t.e[level]
+		if n < t.lookback {
+			return 0, false
+		}
+	}
+	return t.c[0]*t.e[5] + t.c[1]*t.e[4] + t.c[2]*t.e[3] + t.c[3]*t.e[2], true
+}
+
+// HMA is a streaming Hull Moving Average
+type HMA struct {
+	fast, slow, smooth valueIndicator
+}
+
+// NewHMA creates a streaming HMA
+func NewHMA(optInTimePeriod int) (*HMA, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	return newHMA(optInTimePeriod), nil
+}
+
+func newHMA(period int) *HMA {
+	return &HMA{
+		fast:   newMA(period/2, utils.WMA),
+		slow:   newWMA(period),
+		smooth: newMA(int(math.Sqrt(float64(period))), utils.WMA),
+	}
+}
+
+func (h *HMA) visitState(v stateVisitor) {
+	v.child("fast", h.fast)
+	v.child("slow", h.slow)
+	v.child("smooth", h.smooth)
+}
+
+// Update adds a bar and returns the HMA of the closes
+func (h *HMA) Update(bar utils.OHLCV) (float64, bool) {
+	return h.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the HMA
+func (h *HMA) UpdateValue(v float64) (float64, bool) {
+	fast, _ := h.fast.UpdateValue(v)
+	slow, ok := h.slow.UpdateValue(v)
+	if !ok {
+		return 0, false
+	}
+	return h.smooth.UpdateValue(2*fast - slow)
+}
+
+// ALMA is a streaming Arnaud Legoux Moving Average
+type ALMA struct {
+	period  int
+	offset  float64
+	sigma   float64
+	weights []float64
+	norm    float64
+	window  *window
+}
+
+// NewALMA creates a streaming ALMA with the Gaussian centred at optInOffset
+// of the window, usually 0.85, and of width period/optInSigma, usually 6
+func NewALMA(optInTimePeriod int, optInOffset, optInSigma float64) (*ALMA, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	if optInOffset < 0 || optInOffset > 1 || !(optInSigma > 0) {
+		return nil, fmt.Errorf("ALMA offset %v and sigma %v: %w", optInOffset, optInSigma, utils.ErrInvalidParameter)
+	}
+	return newALMA(optInTimePeriod, optInOffset, optInSigma), nil
+}
+
+func newALMA(period int, offset, sigma float64) *ALMA {
+	a := &ALMA{period: period, offset: offset, sigma: sigma, weights: make([]float64, period), window: newWindow(period)}
+	m := offset * float64(period-1)
+	s := float64(period) / sigma
+	for i := range a.weights {
+		d := float64(i) - m
+		a.weights[i] = math.Exp(-(d * d) / (2 * s * s))
+		a.norm += a.weights[i]
+	}
+	return a
+}
+
+func (a *ALMA) visitState(v stateVisitor) {
+	v.intParam("period", &a.period)
+	v.floatParam("offset", &a.offset)
+	v.floatParam("sigma", &a.sigma)
+	v.child("window", a.window)
+}
+
+// Update adds a bar and returns the ALMA of the closes
+func (a *ALMA) Update(bar utils.OHLCV) (float64, bool) {
+	return a.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the ALMA
+func (a *ALMA) UpdateValue(v float64) (float64, bool) {
+	a.window.push(v)
+	if !a.window.full() {
+		return 0, false
+	}
+	sum := 0.0
+	for i, w := range a.weights {
+		sum += w * a.window.ago(a.period-1-i)
+	}
+	return sum / a.norm, true
+}
+
+// ZLEMA is a streaming Zero Lag Exponential Moving Average
+type ZLEMA struct {
+	window *window // Values back to the lag
+	ema    *EMA
+}
+
+// NewZLEMA creates a streaming ZLEMA
+func NewZLEMA(optInTimePeriod int) (*ZLEMA, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	return newZLEMA(optInTimePeriod), nil
+}
+
+func newZLEMA(period int) *ZLEMA {
+	return &ZLEMA{window: newWindow((period-1)/2 + 1), ema: newEMA(period)}
+}
+
+func (z *ZLEMA) visitState(v stateVisitor) {
+	v.child("window", z.window)
+	v.child("ema", z.ema)
+}
+
+// Update adds a bar and returns the ZLEMA of the closes
+func (z *ZLEMA) Update(bar utils.OHLCV) (float64, bool) {
+	return z.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the ZLEMA
+func (z *ZLEMA) UpdateValue(v float64) (float64, bool) {
+	z.window.push(v)
+	if !z.window.full() {
+		return 0, false
+	}
+	return z.ema.UpdateValue(2*v - z.window.ago(len(z.window.values)-1))
+}
+
+// VWMA is a streaming Volume Weighted Moving Average of the closes
+type VWMA struct {
+	period  int
+	prices  *window
+	volumes *window
+	sumPV   utils.KahanSum
+	sumV    utils.KahanSum
+	sum     utils.KahanSum
+}
+
+// NewVWMA creates a streaming VWMA
+func NewVWMA(optInTimePeriod int) (*VWMA, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	return &VWMA{period: optInTimePeriod, prices: newWindow(optInTimePeriod), volumes: newWindow(optInTimePeriod)}, nil
+}
+
+func (w *VWMA) visitState(v stateVisitor) {
+	v.intParam("period", &w.period)
+	v.child("prices", w.prices)
+	v.child("volumes", w.volumes)
+	v.child("sumPV", (*kahanSum)(&w.sumPV))
+	v.child("sumV", (*kahanSum)(&w.sumV))
+	v.child("sum", (*kahanSum)(&w.sum))
+}
+
+// Update adds a bar and returns the VWMA of the closes, the simple average
+// over a period without volume
+func (w *VWMA) Update(bar utils.OHLCV) (float64, bool) {
+	w.prices.push(bar.Close)
+	w.volumes.push(bar.Volume)
+	w.sumPV.Add(bar.Close * bar.Volume)
+	w.sumV.Add(bar.Volume)
+	w.sum.Add(bar.Close)
+	if !w.prices.full() {
+		return 0, false
+	}
+	out := w.sum.Total() / float64(w.period)
+	if volume := w.sumV.Total(); !utils.TAIsZeroOrNeg(volume) {
+		out = w.sumPV.Total() / volume
+	}
+	trailingPrice, trailingVolume := w.prices.ago(w.period-1), w.volumes.ago(w.period-1)
+	w.sumPV.Add(-trailingPrice * trailingVolume)
+	w.sumV.Add(-trailingVolume)
+	w.sum.Add(-trailingPrice)
+	return out, true
+}
+
+// kahanSum saves the state of utils.KahanSum
+type kahanSum utils.KahanSum
+
+func (s *kahanSum) visitState(v stateVisitor) {
+	v.floatVar("value", &s.Value)
+	v.floatVar("compensation", &s.Compensation)
+}
+
+// SMMA is a streaming Smoothed Moving Average
+type SMMA struct {
+	ema *EMA
+}
+
+// NewSMMA creates a streaming SMMA
+func NewSMMA(optInTimePeriod int) (*SMMA, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	return newSMMA(optInTimePeriod), nil
+}
+
+func newSMMA(period int) *SMMA {
+	return &SMMA{ema: &EMA{period: period, k: 1.0 / float64(period)}}
+}
+
+func (s *SMMA) visitState(v stateVisitor) {
+	v.child("ema", s.ema)
+}
+
+// Update adds a bar and returns the SMMA of the closes
+func (s *SMMA) Update(bar utils.OHLCV) (float64, bool) {
+	return s.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the SMMA
+func (s *SMMA) UpdateValue(v float64) (float64, bool) {
+	return s.ema.UpdateValue(v)
+}
+
+// MCGINLEY is a streaming McGinley Dynamic
+type MCGINLEY struct {
+	period int
+	count  int
+	prevMD float64
+}
+
+// NewMCGINLEY creates a streaming McGinley Dynamic
+func NewMCGINLEY(optInTimePeriod int) (*MCGINLEY, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	return &MCGINLEY{period: optInTimePeriod}, nil
+}
+
+func (m *MCGINLEY) visitState(v stateVisitor) {
+	v.intParam("period", &m.period)
+	v.rangeVar("count", &m.count, 0, unbounded)
+	v.floatVar("prevMD", &m.prevMD)
+}
+
+// Update adds a bar and returns the McGinley Dynamic of the closes
+func (m *MCGINLEY) Update(bar utils.OHLCV) (float64, bool) {
+	return m.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the McGinley Dynamic, seeded with the simple average of the first period
+func (m *MCGINLEY) UpdateValue(v float64) (float64, bool) {
+	if m.count < m.period {
+		m.prevMD += v
+		m.count++
+		if m.count < m.period {
+			return 0, false
+		}
+		m.prevMD /= float64(m.period)
+		return m.prevMD, true
+	}
+	m.prevMD = mcginleyStep(m.prevMD, v, m.period)
+	return m.prevMD, true
+}
+
+// mcginleyStep moves the McGinley Dynamic towards a price, or to the price
+// when the ratio is undefined
+func mcginleyStep(prevMD, price float64, period int) float64 {
+	ratio := price / prevMD
+	divider := float64(period) * ratio * ratio * ratio * ratio
+	if prevMD == 0 || divider == 0 || math.IsInf(divider, 0) {
+		return price
+	}
+	return prevMD + (price-prevMD)/divider
+}
+
+// MA is a streaming moving average of any type
+type MA struct {
+	maType utils.MAType
+	ma     valueIndicator
+}
+
+// NewMA creates a streaming moving average of the given type
+func NewMA(optInTimePeriod int, optInMAType utils.MAType) (*MA, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 1); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckMAType(optInMAType); err != nil {
+		return nil, err
+	}
+	return &MA{maType: optInMAType, ma: newMA(optInTimePeriod, optInMAType)}, nil
+}
+
+// newMA creates the moving average of the given type, the type must be valid
+func newMA(period int, maType utils.MAType) valueIndicator {
+	if period == 1 {
+		return identity{}
+	}
+	switch maType {
+	case utils.EMA:
+		return newEMA(period)
+	case utils.WMA:
+		return newWMA(period)
+	case utils.DEMA:
+		return newDEMA(period)
+	case utils.TEMA:
+		return newTEMA(period)
+	case utils.TRIMA:
+		return newTRIMA(period)
+	case utils.KAMA:
+		return newKAMA(period)
+	case utils.MAMA:
+		return mamaLine{newMAMA(0.5, 0.05)}
+	case utils.T3:
+		return newT3(period, 0.7)
+	case utils.HMA:
+		return newHMA(period)
+	case utils.ALMA:
+		return newALMA(period, 0.85, 6)
+	case utils.ZLEMA:
+		return newZLEMA(period)
+	case utils.SMMA:
+		return newSMMA(period)
+	case utils.MCGINLEY:
+		return &MCGINLEY{period: period}
+	default:
+		return newSMA(period)
+	}
+}
+
+// MAType returns the type of moving average
+func (m *MA) MAType() utils.MAType {
+	return m.maType
+}
+
+func (m *MA) visitState(v stateVisitor) {
+	v.intParam("maType", (*int)(&m.maType))
+	v.child("ma", m.ma)
+}
+
+// Update adds a bar and returns the moving average of the closes
+func (m *MA) Update(bar utils.OHLCV) (float64, bool) {
+	return m.ma.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the moving average
+func (m *MA) UpdateValue(v float64) (float64, bool) {
+	return m.ma.UpdateValue(v)
+}
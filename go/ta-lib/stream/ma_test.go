@@ -0,0 +1,156 @@
+package stream_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/indicators"
+	"github.com/petercool/ta-lib/go/ta-lib/stream"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testdata"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+func TestMovingAverages(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	close := utils.GetFieldSlice(data, utils.FieldClose)
+	for _, period := range []int{2, 5, 9, 10, 30} {
+		name := func(s string) string { return fmt.Sprintf("%s(%d)", s, period) }
+		s1, e1 := stream.NewSMA(period)
+		b1, f1 := indicators.SMA(close, period)
+		single(t, data, name("SMA"), s1, e1, b1, f1)
+		s2, e2 := stream.NewEMA(period)
+		b2, f2 := indicators.EMA(close, period)
+		single(t, data, name("EMA"), s2, e2, b2, f2)
+		s3, e3 := stream.NewWMA(period)
+		b3, f3 := indicators.WMA(close, period)
+		single(t, data, name("WMA"), s3, e3, b3, f3)
+		s4, e4 := stream.NewDEMA(period)
+		b4, f4 := indicators.DEMA(close, period)
+		single(t, data, name("DEMA"), s4, e4, b4, f4)
+		s5, e5 := stream.NewTEMA(period)
+		b5, f5 := indicators.TEMA(close, period)
+		single(t, data, name("TEMA"), s5, e5, b5, f5)
+		s6, e6 := stream.NewTRIMA(period)
+		b6, f6 := indicators.TRIMA(close, period)
+		single(t, data, name("TRIMA"), s6, e6, b6, f6)
+		s7, e7 := stream.NewKAMA(period)
+		b7, f7 := indicators.KAMA(close, period)
+		single(t, data, name("KAMA"), s7, e7, b7, f7)
+		for _, vFactor := range []float64{0, 0.7, 1} {
+			s8, e8 := stream.NewT3(period, vFactor)
+			b8, f8 := indicators.T3(close, period, vFactor)
+			single(t, data, fmt.Sprintf("T3(%d, %v)", period, vFactor), s8, e8, b8, f8)
+		}
+	}
+}
+
+func TestExtendedAverages(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	close := utils.GetFieldSlice(data, utils.FieldClose)
+	volume := utils.GetFieldSlice(data, utils.FieldVolume)
+	for _, period := range []int{2, 3, 9, 16, 30} {
+		name := func(s string) string { return fmt.Sprintf("%s(%d)", s, period) }
+		s1, e1 := stream.NewHMA(period)
+		b1, f1 := indicators.HMA(close, period)
+		single(t, data, name("HMA"), s1, e1, b1, f1)
+		s2, e2 := stream.NewALMA(period, 0.85, 6)
+		b2, f2 := indicators.ALMA(close, period, 0.85, 6)
+		single(t, data, name("ALMA"), s2, e2, b2, f2)
+		s3, e3 := stream.NewZLEMA(period)
+		b3, f3 := indicators.ZLEMA(close, period)
+		single(t, data, name("ZLEMA"), s3, e3, b3, f3)
+		s4, e4 := stream.NewVWMA(period)
+		b4, f4 := indicators.VWMA(close, volume, period)
+		single(t, data, name("VWMA"), s4, e4, b4, f4)
+		s5, e5 := stream.NewSMMA(period)
+		b5, f5 := indicators.SMMA(close, period)
+		single(t, data, name("SMMA"), s5, e5, b5, f5)
+		s6, e6 := stream.NewMCGINLEY(period)
+		b6, f6 := indicators.MCGINLEY(close, period)
+		single(t, data, name("MCGINLEY"), s6, e6, b6, f6)
+	}
+
+	// The volume factor of T3 is in [0, 1]
+	for _, vFactor := range []float64{-0.1, 1.1, math.NaN()} {
+		if _, err := stream.NewT3(5, vFactor); !errors.Is(err, utils.ErrInvalidParameter) {
+			t.Errorf("T3 volume factor %v: %v", vFactor, err)
+		}
+	}
+}
+
+func TestMA(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	close := utils.GetFieldSlice(data, utils.FieldClose)
+	for _, period := range []int{1, 3, 10} {
+		for _, maType := range allMATypes {
+			s, err := stream.NewMA(period, maType)
+			b, batchErr := indicators.MA(close, period, maType)
+			var want *utils.Result
+			if b != nil {
+				want = &b.Result
+			}
+			single(t, data, fmt.Sprintf("MA(%d, %v)", period, maType), s, err, want, batchErr)
+		}
+	}
+}
+
+func TestMAMA(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	close := utils.GetFieldSlice(data, utils.FieldClose)
+	mama, err := stream.NewMAMA(0.5, 0.05)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := runStream(t, data, mama.Update)
+	want, err := indicators.MAMA(close, 0.5, 0.05)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameValues(t, "MAMA", field(got, func(v stream.MAMAValue) float64 { return v.MAMA }), want.BeginIndex, want.Values)
+	sameValues(t, "FAMA", field(got, func(v stream.MAMAValue) float64 { return v.FAMA }), want.BeginIndex, want.FAMA)
+}
+
+func TestHilbertTransform(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	close := utils.GetFieldSlice(data, utils.FieldClose)
+	b1, f1 := indicators.HT_DCPERIOD(close)
+	single(t, data, "HT_DCPERIOD", stream.NewHT_DCPERIOD(), nil, b1, f1)
+	b2, f2 := indicators.HT_DCPHASE(close)
+	single(t, data, "HT_DCPHASE", stream.NewHT_DCPHASE(), nil, b2, f2)
+	b3, f3 := indicators.HT_TRENDLINE(close)
+	single(t, data, "HT_TRENDLINE", stream.NewHT_TRENDLINE(), nil, b3, f3)
+	b4, f4 := indicators.HT_TRENDMODE(close)
+	single(t, data, "HT_TRENDMODE", stream.NewHT_TRENDMODE(), nil, b4, f4)
+
+	phasor := runStream(t, data, stream.NewHT_PHASOR().Update)
+	wantPhasor, err := indicators.HT_PHASOR(close)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameValues(t, "HT_PHASOR InPhase", field(phasor, func(v stream.HT_PHASORValue) float64 { return v.InPhase }), wantPhasor.BeginIndex, wantPhasor.Values)
+	sameValues(t, "HT_PHASOR Quadrature", field(phasor, func(v stream.HT_PHASORValue) float64 { return v.Quadrature }), wantPhasor.BeginIndex, wantPhasor.Quadrature)
+
+	sine := runStream(t, data, stream.NewHT_SINE().Update)
+	wantSine, err := indicators.HT_SINE(close)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameValues(t, "HT_SINE Sine", field(sine, func(v stream.HT_SINEValue) float64 { return v.Sine }), wantSine.BeginIndex, wantSine.Values)
+	sameValues(t, "HT_SINE LeadSine", field(sine, func(v stream.HT_SINEValue) float64 { return v.LeadSine }), wantSine.BeginIndex, wantSine.LeadSine)
+}
+
+// The streaming VWMA compensates its sums like the batch one, on volumes
+// spiking now and then
+func TestVWMAPrecision(t *testing.T) {
+	data := testdata.TightOHLCV(100000)
+	for i := 0; i < len(data); i += 50 {
+		data[i].Volume *= 1e8
+	}
+	b := utils.BarsFromOHLCV(data)
+	s, err := stream.NewVWMA(20)
+	want, batchErr := indicators.VWMA(b.Close, b.Volume, 20)
+	single(t, data, "VWMA(20) of 70,000", s, err, want, batchErr)
+}
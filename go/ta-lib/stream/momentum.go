@@ -0,0 +1,922 @@
+package stream
+
+import (
+	"math"
+
+	"github.com/petercool/ta-lib/go/ta-lib/indicators"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// RSI is a streaming Relative Strength Index
+type RSI struct {
+	period    int
+	count     int
+	prevValue float64
+	prevGain  float64
+	prevLoss  float64
+}
+
+// NewRSI creates a streaming RSI
+func NewRSI(optInTimePeriod int) (*RSI, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	return newRSI(optInTimePeriod), nil
+}
+
+func newRSI(period int) *RSI {
+	return &RSI{period: period}
+}
+
+func (r *RSI) visitState(v stateVisitor) {
+	v.intParam("period", &r.period)
+	v.rangeVar("count", &r.count, 0, unbounded)
+	v.floatVar("prevValue", &r.prevValue)
+	v.floatVar("prevGain", &r.prevGain)
+	v.floatVar("prevLoss", &r.prevLoss)
+}
+
+// Update adds a bar and returns the RSI of the closes
+func (r *RSI) Update(bar utils.OHLCV) (float64, bool) {
+	return r.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the RSI
+func (r *RSI) UpdateValue(v float64) (float64, bool) {
+	period := float64(r.period)
+	r.count++
+	if r.count == 1 {
+		r.prevValue = v
+		return 0, false
+	}
+
+	tempValue2 := v - r.prevValue
+	r.prevValue = v
+	if r.count <= r.period+1 {
+		// Accumulate the initial gains and losses
+		if tempValue2 < 0 {
+			r.prevLoss -= tempValue2
+		} else {
+			r.prevGain += tempValue2
+		}
+		if r.count <= r.period {
+			return 0, false
+		}
+		r.prevLoss /= period
+		r.prevGain /= period
+		return rsiValue(r.prevGain, r.prevLoss), true
+	}
+
+	r.prevLoss *= period - 1
+	r.prevGain *= period - 1
+	if tempValue2 < 0 {
+		r.prevLoss -= tempValue2
+	} else {
+		r.prevGain += tempValue2
+	}
+	r.prevLoss /= period
+	r.prevGain /= period
+	return rsiValue(r.prevGain, r.prevLoss), true
+}
+
+// rsiValue converts average gain and loss into an RSI value
+func rsiValue(avgGain, avgLoss float64) float64 {
+	total := avgGain + avgLoss
+	if utils.TAIsZero(total) {
+		return 0.0
+	}
+	return 100.0 * (avgGain / total)
+}
+
+// MACDValue holds the outputs of MACD
+type MACDValue struct {
+	MACD   float64 // MACD line
+	Signal float64 // Signal line
+	Hist   float64 // Histogram
+}
+
+// MACD is a streaming Moving Average Convergence/Divergence
+type MACD struct {
+	fast   valueIndicator
+	slow   *EMA
+	signal *EMA
+}
+
+// NewMACD creates a streaming MACD
+func NewMACD(optInFastPeriod, optInSlowPeriod, optInSignalPeriod int) (*MACD, error) {
+	if err := utils.CheckPeriod("fast period", optInFastPeriod, 2); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("slow period", optInSlowPeriod, 2); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("signal period", optInSignalPeriod, 1); err != nil {
+		return nil, err
+	}
+	if optInSlowPeriod < optInFastPeriod {
+		optInSlowPeriod, optInFastPeriod = optInFastPeriod, optInSlowPeriod
+	}
+
+	// The fast EMA is seeded on the same bar as the slow one
+	return &MACD{
+		fast:   &skip{n: optInSlowPeriod - optInFastPeriod, in: newEMA(optInFastPeriod)},
+		slow:   newEMA(optInSlowPeriod),
+		signal: newEMA(optInSignalPeriod),
+	}, nil
+}
+
+func (m *MACD) visitState(v stateVisitor) {
+	v.child("fast", m.fast)
+	v.child("slow", m.slow)
+	v.child("signal", m.signal)
+}
+
+// Update adds a bar and returns the MACD of the closes
+func (m *MACD) Update(bar utils.OHLCV) (MACDValue, bool) {
+	return m.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the MACD
+func (m *MACD) UpdateValue(v float64) (MACDValue, bool) {
+	fastEMA, _ := m.fast.UpdateValue(v)
+	slowEMA, ok := m.slow.UpdateValue(v)
+	if !ok {
+		return MACDValue{}, false
+	}
+	line := fastEMA - slowEMA
+	signal, ok := m.signal.UpdateValue(line)
+	if !ok {
+		return MACDValue{}, false
+	}
+	return MACDValue{MACD: line, Signal: signal, Hist: line - signal}, true
+}
+
+// MACDEXT is a streaming MACD with a moving average type for each line
+type MACDEXT struct {
+	fast, slow valueIndicator
+	signal     valueIndicator
+}
+
+// NewMACDEXT creates a streaming MACDEXT
+func NewMACDEXT(optInFastPeriod int, optInFastMAType utils.MAType, optInSlowPeriod int, optInSlowMAType utils.MAType, optInSignalPeriod int, optInSignalMAType utils.MAType) (*MACDEXT, error) {
+	if err := utils.CheckPeriod("fast period", optInFastPeriod, 2); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("slow period", optInSlowPeriod, 2); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("signal period", optInSignalPeriod, 1); err != nil {
+		return nil, err
+	}
+	for _, maType := range []utils.MAType{optInFastMAType, optInSlowMAType, optInSignalMAType} {
+		if err := utils.CheckMAType(maType); err != nil {
+			return nil, err
+		}
+	}
+	if optInSlowPeriod < optInFastPeriod {
+		optInSlowPeriod, optInFastPeriod = optInFastPeriod, optInSlowPeriod
+		optInSlowMAType, optInFastMAType = optInFastMAType, optInSlowMAType
+	}
+
+	// Both MAs start on the bar of the one with the longest lookback
+	fastLookback := indicators.MALookback(optInFastPeriod, optInFastMAType)
+	slowLookback := indicators.MALookback(optInSlowPeriod, optInSlowMAType)
+	largest := max(fastLookback, slowLookback)
+	return &MACDEXT{
+		fast:   &skip{n: largest - fastLookback, in: newMA(optInFastPeriod, optInFastMAType)},
+		slow:   &skip{n: largest - slowLookback, in: newMA(optInSlowPeriod, optInSlowMAType)},
+		signal: newMA(optInSignalPeriod, optInSignalMAType),
+	}, nil
+}
+
+func (m *MACDEXT) visitState(v stateVisitor) {
+	v.child("fast", m.fast)
+	v.child("slow", m.slow)
+	v.child("signal", m.signal)
+}
+
+// Update adds a bar and returns the MACDEXT of the closes
+func (m *MACDEXT) Update(bar utils.OHLCV) (MACDValue, bool) {
+	return m.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the MACDEXT
+func (m *MACDEXT) UpdateValue(v float64) (MACDValue, bool) {
+	fastMA, fastOK := m.fast.UpdateValue(v)
+	slowMA, slowOK := m.slow.UpdateValue(v)
+	if !fastOK || !slowOK {
+		return MACDValue{}, false
+	}
+	line := fastMA - slowMA
+	signal, ok := m.signal.UpdateValue(line)
+	if !ok {
+		return MACDValue{}, false
+	}
+	return MACDValue{MACD: line, Signal: signal, Hist: line - signal}, true
+}
+
+// extremes tracks the highest high and lowest low over the last bars.
+// A full rescan only happens when the previous extreme falls out of the window.
+type extremes struct {
+	highs, lows *window
+	today       int
+	highestIdx  int
+	lowestIdx   int
+	highest     float64
+	lowest      float64
+}
+
+func newExtremes(period int) *extremes {
+	return &extremes{
+		highs:      newWindow(period),
+		lows:       newWindow(period),
+		highestIdx: -1,
+		lowestIdx:  -1,
+	}
+}
+
+func (e *extremes) visitState(v stateVisitor) {
+	v.child("highs", e.highs)
+	v.child("lows", e.lows)
+	v.rangeVar("today", &e.today, 0, unbounded)
+	v.rangeVar("highestIdx", &e.highestIdx, -1, e.today-1)
+	v.rangeVar("lowestIdx", &e.lowestIdx, -1, e.today-1)
+	v.floatVar("highest", &e.highest)
+	v.floatVar("lowest", &e.lowest)
+}
+
+// update adds a bar and returns the highest high and lowest low once the window is full
+func (e *extremes) update(high, low float64) (float64, float64, bool) {
+	e.highs.push(high)
+	e.lows.push(low)
+	today := e.today
+	e.today++
+	if !e.highs.full() {
+		return 0, 0, false
+	}
+
+	period := len(e.highs.values)
+	trailingIdx := today - (period - 1)
+
+	// Set the lowest low
+	if e.lowestIdx < trailingIdx {
+		e.lowestIdx = trailingIdx
+		e.lowest = e.lows.ago(period - 1)
+		for i := e.lowestIdx + 1; i <= today; i++ {
+			if tmp := e.lows.ago(today - i); tmp < e.lowest {
+				e.lowestIdx = i
+				e.lowest = tmp
+			}
+		}
+	} else if low <= e.lowest {
+		e.lowestIdx = today
+		e.lowest = low
+	}
+
+	// Set the highest high
+	if e.highestIdx < trailingIdx {
+		e.highestIdx = trailingIdx
+		e.highest = e.highs.ago(period - 1)
+		for i := e.highestIdx + 1; i <= today; i++ {
+			if tmp := e.highs.ago(today - i); tmp > e.highest {
+				e.highestIdx = i
+				e.highest = tmp
+			}
+		}
+	} else if high >= e.highest {
+		e.highestIdx = today
+		e.highest = high
+	}
+
+	return e.highest, e.lowest, true
+}
+
+// fastK calculates the raw stochastic %K
+type fastK struct {
+	extremes *extremes
+}
+
+func (f *fastK) visitState(v stateVisitor) {
+	v.child("extremes", f.extremes)
+}
+
+func (f *fastK) update(high, low, close float64) (float64, bool) {
+	highest, lowest, ok := f.extremes.update(high, low)
+	if !ok {
+		return 0, false
+	}
+	diff := (highest - lowest) / 100.0
+	if diff != 0.0 {
+		return (close - lowest) / diff, true
+	}
+	return 0.0, true
+}
+
+// STOCHValue holds the outputs of STOCH
+type STOCHValue struct {
+	SlowK float64 // Slow %K line
+	SlowD float64 // Slow %D line
+}
+
+// STOCH is a streaming Stochastic oscillator
+type STOCH struct {
+	fastK fastK
+	slowK valueIndicator
+	slowD valueIndicator
+}
+
+// NewSTOCH creates a streaming STOCH, the optional MA types apply to
+// slow %K and slow %D and default to SMA
+func NewSTOCH(optInFastKPeriod, optInSlowKPeriod, optInSlowDPeriod int, optInMATypes ...utils.MAType) (*STOCH, error) {
+	if err := utils.CheckPeriod("fast-K period", optInFastKPeriod, 1); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("slow-K period", optInSlowKPeriod, 1); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("slow-D period", optInSlowDPeriod, 1); err != nil {
+		return nil, err
+	}
+	slowKMAType := utils.OptionalMAType(optInMATypes, 0)
+	slowDMAType := utils.OptionalMAType(optInMATypes, 1)
+	if err := utils.CheckMAType(slowKMAType); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckMAType(slowDMAType); err != nil {
+		return nil, err
+	}
+	return &STOCH{
+		fastK: fastK{newExtremes(optInFastKPeriod)},
+		slowK: newMA(optInSlowKPeriod, slowKMAType),
+		slowD: newMA(optInSlowDPeriod, slowDMAType),
+	}, nil
+}
+
+func (s *STOCH) visitState(v stateVisitor) {
+	v.child("fastK", &s.fastK)
+	v.child("slowK", s.slowK)
+	v.child("slowD", s.slowD)
+}
+
+// Update adds a bar and returns slow %K and %D
+func (s *STOCH) Update(bar utils.OHLCV) (STOCHValue, bool) {
+	k, ok := s.fastK.update(bar.High, bar.Low, bar.Close)
+	if !ok {
+		return STOCHValue{}, false
+	}
+	slowK, ok := s.slowK.UpdateValue(k)
+	if !ok {
+		return STOCHValue{}, false
+	}
+	slowD, ok := s.slowD.UpdateValue(slowK)
+	if !ok {
+		return STOCHValue{}, false
+	}
+	return STOCHValue{SlowK: slowK, SlowD: slowD}, true
+}
+
+// STOCHFValue holds the outputs of STOCHF and STOCHRSI
+type STOCHFValue struct {
+	FastK float64 // Fast %K line
+	FastD float64 // Fast %D line
+}
+
+// STOCHF is a streaming Fast Stochastic oscillator
+type STOCHF struct {
+	fastK fastK
+	fastD valueIndicator
+}
+
+// NewSTOCHF creates a streaming STOCHF, the optional MA type applies to
+// fast %D and defaults to SMA
+func NewSTOCHF(optInFastKPeriod, optInFastDPeriod int, optInFastDMAType ...utils.MAType) (*STOCHF, error) {
+	if err := utils.CheckPeriod("fast-K period", optInFastKPeriod, 1); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("fast-D period", optInFastDPeriod, 1); err != nil {
+		return nil, err
+	}
+	fastDMAType := utils.OptionalMAType(optInFastDMAType, 0)
+	if err := utils.CheckMAType(fastDMAType); err != nil {
+		return nil, err
+	}
+	return newSTOCHF(optInFastKPeriod, optInFastDPeriod, fastDMAType), nil
+}
+
+func newSTOCHF(fastKPeriod, fastDPeriod int, fastDMAType utils.MAType) *STOCHF {
+	return &STOCHF{
+		fastK: fastK{newExtremes(fastKPeriod)},
+		fastD: newMA(fastDPeriod, fastDMAType),
+	}
+}
+
+func (s *STOCHF) visitState(v stateVisitor) {
+	v.child("fastK", &s.fastK)
+	v.child("fastD", s.fastD)
+}
+
+// Update adds a bar and returns fast %K and %D
+func (s *STOCHF) Update(bar utils.OHLCV) (STOCHFValue, bool) {
+	return s.update(bar.High, bar.Low, bar.Close)
+}
+
+func (s *STOCHF) update(high, low, close float64) (STOCHFValue, bool) {
+	k, ok := s.fastK.update(high, low, close)
+	if !ok {
+		return STOCHFValue{}, false
+	}
+	d, ok := s.fastD.UpdateValue(k)
+	if !ok {
+		return STOCHFValue{}, false
+	}
+	return STOCHFValue{FastK: k, FastD: d}, true
+}
+
+// STOCHRSI is a streaming Stochastic RSI
+type STOCHRSI struct {
+	rsi    *RSI
+	stochf *STOCHF
+}
+
+// NewSTOCHRSI creates a streaming STOCHRSI
+func NewSTOCHRSI(optInTimePeriod, optInFastKPeriod, optInFastDPeriod int, optInFastDMAType utils.MAType) (*STOCHRSI, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("fast-K period", optInFastKPeriod, 1); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("fast-D period", optInFastDPeriod, 1); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckMAType(optInFastDMAType); err != nil {
+		return nil, err
+	}
+	return &STOCHRSI{
+		rsi:    newRSI(optInTimePeriod),
+		stochf: newSTOCHF(optInFastKPeriod, optInFastDPeriod, optInFastDMAType),
+	}, nil
+}
+
+func (s *STOCHRSI) visitState(v stateVisitor) {
+	v.child("rsi", s.rsi)
+	v.child("stochf", s.stochf)
+}
+
+// Update adds a bar and returns fast %K and %D of the RSI of the closes
+func (s *STOCHRSI) Update(bar utils.OHLCV) (STOCHFValue, bool) {
+	return s.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns fast %K and %D of its RSI
+func (s *STOCHRSI) UpdateValue(v float64) (STOCHFValue, bool) {
+	rsi, ok := s.rsi.UpdateValue(v)
+	if !ok {
+		return STOCHFValue{}, false
+	}
+	return s.stochf.update(rsi, rsi, rsi)
+}
+
+// ROC is a streaming Rate of Change: ((price/prevPrice)-1)*100
+type ROC struct {
+	period int
+	window *window
+}
+
+// NewROC creates a streaming ROC
+func NewROC(optInTimePeriod int) (*ROC, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 1); err != nil {
+		return nil, err
+	}
+	return &ROC{period: optInTimePeriod, window: newWindow(optInTimePeriod + 1)}, nil
+}
+
+func (r *ROC) visitState(v stateVisitor) {
+	v.intParam("period", &r.period)
+	v.child("window", r.window)
+}
+
+// Update adds a bar and returns the ROC of the closes
+func (r *ROC) Update(bar utils.OHLCV) (float64, bool) {
+	return r.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the ROC
+func (r *ROC) UpdateValue(v float64) (float64, bool) {
+	r.window.push(v)
+	if !r.window.full() {
+		return 0, false
+	}
+	tempReal := r.window.ago(r.period)
+	if tempReal != 0.0 {
+		return ((v / tempReal) - 1.0) * 100.0, true
+	}
+	return 0.0, true
+}
+
+// CCI is a streaming Commodity Channel Index
+type CCI struct {
+	period     int
+	circBuffer *window
+}
+
+// NewCCI creates a streaming CCI
+func NewCCI(optInTimePeriod int) (*CCI, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	return &CCI{period: optInTimePeriod, circBuffer: newWindow(optInTimePeriod)}, nil
+}
+
+func (c *CCI) visitState(v stateVisitor) {
+	v.intParam("period", &c.period)
+	v.child("circBuffer", c.circBuffer)
+}
+
+// Update adds a bar and returns the CCI. Each update sums the window,
+// in the same order as the batch function.
+func (c *CCI) Update(bar utils.OHLCV) (float64, bool) {
+	lastValue := (bar.High + bar.Low + bar.Close) / 3
+	c.circBuffer.push(lastValue)
+	if !c.circBuffer.full() {
+		return 0, false
+	}
+
+	period := float64(c.period)
+	theAverage := 0.0
+	for _, v := range c.circBuffer.values {
+		theAverage += v
+	}
+	theAverage /= period
+
+	tempReal2 := 0.0
+	for _, v := range c.circBuffer.values {
+		tempReal2 += math.Abs(v - theAverage)
+	}
+
+	tempReal := lastValue - theAverage
+	if tempReal != 0.0 && tempReal2 != 0.0 {
+		return tempReal / (0.015 * (tempReal2 / period)), true
+	}
+	return 0.0, true
+}
+
+// WILLR is a streaming Williams' %R
+type WILLR struct {
+	extremes *extremes
+}
+
+// NewWILLR creates a streaming WILLR
+func NewWILLR(optInTimePeriod int) (*WILLR, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	return &WILLR{extremes: newExtremes(optInTimePeriod)}, nil
+}
+
+func (w *WILLR) visitState(v stateVisitor) {
+	v.child("extremes", w.extremes)
+}
+
+// Update adds a bar and returns the WILLR
+func (w *WILLR) Update(bar utils.OHLCV) (float64, bool) {
+	highest, lowest, ok := w.extremes.update(bar.High, bar.Low)
+	if !ok {
+		return 0, false
+	}
+	diff := (highest - lowest) / (-100.0)
+	if diff != 0.0 {
+		return (highest - bar.Close) / diff, true
+	}
+	return 0.0, true
+}
+
+// directionalMovement tracks Wilder-smoothed +DM, -DM and true range,
+// shared by ADX, DX, PLUS_DI and MINUS_DI
+type directionalMovement struct {
+	period                       int
+	count                        int
+	prevHigh, prevLow, prevClose float64
+	plusDM, minusDM, tr          float64
+}
+
+func (d *directionalMovement) visitState(v stateVisitor) {
+	v.intParam("period", &d.period)
+	v.rangeVar("count", &d.count, 0, unbounded)
+	v.floatVar("prevHigh", &d.prevHigh)
+	v.floatVar("prevLow", &d.prevLow)
+	v.floatVar("prevClose", &d.prevClose)
+	v.floatVar("plusDM", &d.plusDM)
+	v.floatVar("minusDM", &d.minusDM)
+	v.floatVar("tr", &d.tr)
+}
+
+// deltas returns the plus and minus directional movements of a bar
+func (d *directionalMovement) deltas(bar utils.OHLCV) (float64, float64) {
+	diffP := bar.High - d.prevHigh
+	d.prevHigh = bar.High
+	diffM := d.prevLow - bar.Low
+	d.prevLow = bar.Low
+	return diffP, diffM
+}
+
+// update accumulates the first period-1 bars, then applies Wilder's smoothing.
+// It reports whether the smoothed values are available.
+func (d *directionalMovement) update(bar utils.OHLCV) bool {
+	d.count++
+	if d.count == 1 {
+		d.prevHigh = bar.High
+		d.prevLow = bar.Low
+		d.prevClose = bar.Close
+		return false
+	}
+
+	diffP, diffM := d.deltas(bar)
+	if d.count <= d.period {
+		if diffM > 0 && diffP < diffM {
+			d.minusDM += diffM
+		} else if diffP > 0 && diffP > diffM {
+			d.plusDM += diffP
+		}
+		d.tr += trueRange(d.prevHigh, d.prevLow, d.prevClose)
+		d.prevClose = bar.Close
+		return false
+	}
+
+	period := float64(d.period)
+	d.minusDM -= d.minusDM / period
+	d.plusDM -= d.plusDM / period
+	if diffM > 0 && diffP < diffM {
+		d.minusDM += diffM
+	} else if diffP > 0 && diffP > diffM {
+		d.plusDM += diffP
+	}
+	d.tr = d.tr - (d.tr / period) + trueRange(d.prevHigh, d.prevLow, d.prevClose)
+	d.prevClose = bar.Close
+	return true
+}
+
+// plusDI returns the current +DI, or 0 when the true range is zero
+func (d *directionalMovement) plusDI() float64 {
+	if utils.TAIsZero(d.tr) {
+		return 0.0
+	}
+	return 100.0 * (d.plusDM / d.tr)
+}
+
+// minusDI returns the current -DI, or 0 when the true range is zero
+func (d *directionalMovement) minusDI() float64 {
+	if utils.TAIsZero(d.tr) {
+		return 0.0
+	}
+	return 100.0 * (d.minusDM / d.tr)
+}
+
+// dx returns the current directional movement index and whether it is defined
+func (d *directionalMovement) dx() (float64, bool) {
+	if utils.TAIsZero(d.tr) {
+		return 0.0, false
+	}
+	minusDI := 100.0 * (d.minusDM / d.tr)
+	plusDI := 100.0 * (d.plusDM / d.tr)
+	tempReal := minusDI + plusDI
+	if utils.TAIsZero(tempReal) {
+		return 0.0, false
+	}
+	return 100.0 * (math.Abs(minusDI-plusDI) / tempReal), true
+}
+
+// directionalIndicator implements PLUS_DI and MINUS_DI
+type directionalIndicator struct {
+	dm   directionalMovement
+	plus bool
+}
+
+func newDirectionalIndicator(optInTimePeriod int, plus bool) (*directionalIndicator, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 1); err != nil {
+		return nil, err
+	}
+	return &directionalIndicator{dm: directionalMovement{period: optInTimePeriod}, plus: plus}, nil
+}
+
+func (d *directionalIndicator) visitState(v stateVisitor) {
+	v.child("dm", &d.dm)
+}
+
+func (d *directionalIndicator) Update(bar utils.OHLCV) (float64, bool) {
+	if d.dm.period <= 1 {
+		// Without smoothing the indicator is the raw movement over the true range
+		d.dm.count++
+		if d.dm.count == 1 {
+			d.dm.prevHigh, d.dm.prevLow, d.dm.prevClose = bar.High, bar.Low, bar.Close
+			return 0, false
+		}
+		diffP, diffM := d.dm.deltas(bar)
+		tr := trueRange(d.dm.prevHigh, d.dm.prevLow, d.dm.prevClose)
+		d.dm.prevClose = bar.Close
+		if d.plus && diffP > 0 && diffP > diffM && !utils.TAIsZero(tr) {
+			return diffP / tr, true
+		} else if !d.plus && diffM > 0 && diffP < diffM && !utils.TAIsZero(tr) {
+			return diffM / tr, true
+		}
+		return 0.0, true
+	}
+
+	if !d.dm.update(bar) {
+		return 0, false
+	}
+	if d.plus {
+		return d.dm.plusDI(), true
+	}
+	return d.dm.minusDI(), true
+}
+
+// PLUS_DI is a streaming Plus Directional Indicator
+type PLUS_DI struct {
+	directionalIndicator
+}
+
+// NewPLUS_DI creates a streaming PLUS_DI
+func NewPLUS_DI(optInTimePeriod int) (*PLUS_DI, error) {
+	d, err := newDirectionalIndicator(optInTimePeriod, true)
+	if err != nil {
+		return nil, err
+	}
+	return &PLUS_DI{*d}, nil
+}
+
+// MINUS_DI is a streaming Minus Directional Indicator
+type MINUS_DI struct {
+	directionalIndicator
+}
+
+// NewMINUS_DI creates a streaming MINUS_DI
+func NewMINUS_DI(optInTimePeriod int) (*MINUS_DI, error) {
+	d, err := newDirectionalIndicator(optInTimePeriod, false)
+	if err != nil {
+		return nil, err
+	}
+	return &MINUS_DI{*d}, nil
+}
+
+// DX is a streaming Directional Movement Index
+type DX struct {
+	dm     directionalMovement
+	prevDX float64
+}
+
+// NewDX creates a streaming DX
+func NewDX(optInTimePeriod int) (*DX, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	return &DX{dm: directionalMovement{period: optInTimePeriod}}, nil
+}
+
+func (d *DX) visitState(v stateVisitor) {
+	v.child("dm", &d.dm)
+	v.floatVar("prevDX", &d.prevDX)
+}
+
+// Update adds a bar and returns the DX, undefined values repeat the previous one
+func (d *DX) Update(bar utils.OHLCV) (float64, bool) {
+	if !d.dm.update(bar) {
+		return 0, false
+	}
+	if value, ok := d.dm.dx(); ok {
+		d.prevDX = value
+	}
+	return d.prevDX, true
+}
+
+// ADXValue holds the outputs of ADX
+type ADXValue struct {
+	ADX     float64 // ADX line
+	PlusDI  float64 // +DI on the same bar
+	MinusDI float64 // -DI on the same bar
+}
+
+// ADX is a streaming Average Directional Movement Index
+type ADX struct {
+	dm      directionalMovement
+	nbDX    int
+	sumDX   float64
+	prevADX float64
+}
+
+// NewADX creates a streaming ADX
+func NewADX(optInTimePeriod int) (*ADX, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	return &ADX{dm: directionalMovement{period: optInTimePeriod}}, nil
+}
+
+func (a *ADX) visitState(v stateVisitor) {
+	v.child("dm", &a.dm)
+	v.rangeVar("nbDX", &a.nbDX, 0, a.dm.period)
+	v.floatVar("sumDX", &a.sumDX)
+	v.floatVar("prevADX", &a.prevADX)
+}
+
+// Update adds a bar and returns the ADX with the +DI and -DI lines
+func (a *ADX) Update(bar utils.OHLCV) (ADXValue, bool) {
+	if !a.dm.update(bar) {
+		return ADXValue{}, false
+	}
+
+	period := float64(a.dm.period)
+	value, ok := a.dm.dx()
+	if a.nbDX < a.dm.period {
+		// The first ADX is the average of the first period of DX values
+		a.nbDX++
+		if ok {
+			a.sumDX += value
+		}
+		if a.nbDX < a.dm.period {
+			return ADXValue{}, false
+		}
+		a.prevADX = a.sumDX / period
+	} else if ok {
+		a.prevADX = ((a.prevADX * (period - 1)) + value) / period
+	}
+	return ADXValue{ADX: a.prevADX, PlusDI: a.dm.plusDI(), MinusDI: a.dm.minusDI()}, true
+}
+
+// priceOscillator implements APO and PPO as the difference between a fast and a slow MA
+type priceOscillator struct {
+	fast, slow         valueIndicator
+	doPercentageOutput bool
+}
+
+func newPriceOscillator(optInFastPeriod, optInSlowPeriod int, optInMAType utils.MAType, doPercentageOutput bool) (*priceOscillator, error) {
+	if err := utils.CheckPeriod("fast period", optInFastPeriod, 2); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPeriod("slow period", optInSlowPeriod, 2); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckMAType(optInMAType); err != nil {
+		return nil, err
+	}
+	if optInSlowPeriod < optInFastPeriod {
+		optInSlowPeriod, optInFastPeriod = optInFastPeriod, optInSlowPeriod
+	}
+	return &priceOscillator{
+		fast:               newMA(optInFastPeriod, optInMAType),
+		slow:               newMA(optInSlowPeriod, optInMAType),
+		doPercentageOutput: doPercentageOutput,
+	}, nil
+}
+
+func (p *priceOscillator) visitState(v stateVisitor) {
+	v.child("fast", p.fast)
+	v.child("slow", p.slow)
+}
+
+func (p *priceOscillator) Update(bar utils.OHLCV) (float64, bool) {
+	return p.UpdateValue(bar.Close)
+}
+
+func (p *priceOscillator) UpdateValue(v float64) (float64, bool) {
+	fastMA, fastOK := p.fast.UpdateValue(v)
+	slowMA, slowOK := p.slow.UpdateValue(v)
+	if !fastOK || !slowOK {
+		return 0, false
+	}
+	if !p.doPercentageOutput {
+		return fastMA - slowMA, true
+	}
+	if !utils.TAIsZero(slowMA) {
+		return ((fastMA - slowMA) / slowMA) * 100.0, true
+	}
+	return 0.0, true
+}
+
+// APO is a streaming Absolute Price Oscillator
+type APO struct {
+	priceOscillator
+}
+
+// NewAPO creates a streaming APO, the MA type defaults to SMA
+func NewAPO(optInFastPeriod, optInSlowPeriod int, optInMAType ...utils.MAType) (*APO, error) {
+	p, err := newPriceOscillator(optInFastPeriod, optInSlowPeriod, utils.OptionalMAType(optInMAType, 0), false)
+	if err != nil {
+		return nil, err
+	}
+	return &APO{*p}, nil
+}
+
+// PPO is a streaming Percentage Price Oscillator
+type PPO struct {
+	priceOscillator
+}
+
+// NewPPO creates a streaming PPO, the MA type defaults to SMA
+func NewPPO(optInFastPeriod, optInSlowPeriod int, optInMAType ...utils.MAType) (*PPO, error) {
+	p, err := newPriceOscillator(optInFastPeriod, optInSlowPeriod, utils.OptionalMAType(optInMAType, 0), true)
+	if err != nil {
+		return nil, err
+	}
+	return &PPO{*p}, nil
+}
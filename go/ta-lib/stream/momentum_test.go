@@ -0,0 +1,197 @@
+package stream_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/indicators"
+	"github.com/petercool/ta-lib/go/ta-lib/stream"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+func TestMomentum(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	_, high, low, close, _ := utils.GetOHLCVSlices(data)
+	for _, period := range []int{2, 14, 30} {
+		s1, e1 := stream.NewRSI(period)
+		b1, f1 := indicators.RSI(close, period)
+		single(t, data, fmt.Sprintf("RSI(%d)", period), s1, e1, b1, f1)
+		s2, e2 := stream.NewCCI(period)
+		b2, f2 := indicators.CCI(high, low, close, period)
+		single(t, data, fmt.Sprintf("CCI(%d)", period), s2, e2, b2, f2)
+		s3, e3 := stream.NewWILLR(period)
+		b3, f3 := indicators.WILLR(high, low, close, period)
+		single(t, data, fmt.Sprintf("WILLR(%d)", period), s3, e3, b3, f3)
+		s4, e4 := stream.NewROC(period)
+		b4, f4 := indicators.ROC(close, period)
+		single(t, data, fmt.Sprintf("ROC(%d)", period), s4, e4, b4, f4)
+	}
+}
+
+func TestMACD(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	close := utils.GetFieldSlice(data, utils.FieldClose)
+	for _, p := range [][3]int{{12, 26, 9}, {26, 12, 9}, {5, 35, 5}, {3, 10, 1}} {
+		name := fmt.Sprintf("MACD%v", p)
+		s, err := stream.NewMACD(p[0], p[1], p[2])
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := runStream(t, data, s.Update)
+		want, err := indicators.MACD(close, p[0], p[1], p[2])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameValues(t, name, field(got, func(v stream.MACDValue) float64 { return v.MACD }), want.BeginIndex, want.Values)
+		sameValues(t, name+" signal", field(got, func(v stream.MACDValue) float64 { return v.Signal }), want.BeginIndex, want.MACDSignal)
+		sameValues(t, name+" hist", field(got, func(v stream.MACDValue) float64 { return v.Hist }), want.BeginIndex, want.MACDHist)
+	}
+}
+
+func TestMACDEXT(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	close := utils.GetFieldSlice(data, utils.FieldClose)
+	for i, maType := range allMATypes {
+		// Each type in turn on every line, against the next one on the slow line
+		other := allMATypes[(i+1)%len(allMATypes)]
+		for _, p := range [][3]int{{12, 26, 9}, {26, 12, 9}, {3, 10, 1}} {
+			name := fmt.Sprintf("MACDEXT(%d %v, %d %v, %d %v)", p[0], maType, p[1], other, p[2], maType)
+			s, err := stream.NewMACDEXT(p[0], maType, p[1], other, p[2], maType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := runStream(t, data, s.Update)
+			want, err := indicators.MACDEXT(close, p[0], maType, p[1], other, p[2], maType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sameValues(t, name, field(got, func(v stream.MACDValue) float64 { return v.MACD }), want.BeginIndex, want.Values)
+			sameValues(t, name+" signal", field(got, func(v stream.MACDValue) float64 { return v.Signal }), want.BeginIndex, want.MACDSignal)
+			sameValues(t, name+" hist", field(got, func(v stream.MACDValue) float64 { return v.Hist }), want.BeginIndex, want.MACDHist)
+		}
+	}
+
+	// With EMA on every line it is the classic MACD
+	macd, err := indicators.MACD(close, 12, 26, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ext, err := indicators.MACDEXT(close, 12, utils.EMA, 26, utils.EMA, 9, utils.EMA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ext.BeginIndex != macd.BeginIndex || len(ext.Values) != len(macd.Values) {
+		t.Fatalf("MACDEXT of EMAs has %d values from bar %d, MACD %d from bar %d", len(ext.Values), ext.BeginIndex, len(macd.Values), macd.BeginIndex)
+	}
+	for i := range macd.Values {
+		if math.Abs(ext.Values[i]-macd.Values[i]) > 1e-9 || math.Abs(ext.MACDSignal[i]-macd.MACDSignal[i]) > 1e-9 {
+			t.Fatalf("MACDEXT of EMAs at bar %d is %v, %v, MACD has %v, %v", macd.BeginIndex+i, ext.Values[i], ext.MACDSignal[i], macd.Values[i], macd.MACDSignal[i])
+		}
+	}
+
+	for _, maType := range []utils.MAType{-1, utils.MCGINLEY + 1} {
+		if _, err := stream.NewMACDEXT(12, utils.EMA, 26, utils.EMA, 9, maType); !errors.Is(err, utils.ErrInvalidParameter) {
+			t.Errorf("MACDEXT with a %v signal: got %v, want ErrInvalidParameter", maType, err)
+		}
+		if _, err := indicators.MACDEXT(close, 12, maType, 26, utils.EMA, 9, utils.EMA); !errors.Is(err, utils.ErrInvalidParameter) {
+			t.Errorf("batch MACDEXT with a %v fast line: got %v, want ErrInvalidParameter", maType, err)
+		}
+	}
+}
+
+func TestStochastics(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	_, high, low, close, _ := utils.GetOHLCVSlices(data)
+	for _, maType := range allMATypes {
+		for _, p := range [][3]int{{5, 3, 3}, {14, 3, 3}, {14, 1, 1}} {
+			name := fmt.Sprintf("STOCH(%v, %v)", p, maType)
+			s, err := stream.NewSTOCH(p[0], p[1], p[2], maType, utils.SMA)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := runStream(t, data, s.Update)
+			want, err := indicators.STOCH(high, low, close, p[0], p[1], p[2], maType, utils.SMA)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sameValues(t, name+" slow-K", field(got, func(v stream.STOCHValue) float64 { return v.SlowK }), want.BeginIndex, want.SlowK)
+			sameValues(t, name+" slow-D", field(got, func(v stream.STOCHValue) float64 { return v.SlowD }), want.BeginIndex, want.SlowD)
+		}
+
+		name := fmt.Sprintf("STOCHF(5, 3, %v)", maType)
+		sf, err := stream.NewSTOCHF(5, 3, maType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotF := runStream(t, data, sf.Update)
+		wantF, err := indicators.STOCHF(high, low, close, 5, 3, maType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameValues(t, name+" fast-K", field(gotF, func(v stream.STOCHFValue) float64 { return v.FastK }), wantF.BeginIndex, wantF.FastK)
+		sameValues(t, name+" fast-D", field(gotF, func(v stream.STOCHFValue) float64 { return v.FastD }), wantF.BeginIndex, wantF.FastD)
+
+		name = fmt.Sprintf("STOCHRSI(14, 14, 3, %v)", maType)
+		sr, err := stream.NewSTOCHRSI(14, 14, 3, maType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotR := runStream(t, data, sr.Update)
+		wantK, wantD, err := indicators.STOCHRSI(close, 14, 14, 3, maType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameValues(t, name+" fast-K", field(gotR, func(v stream.STOCHFValue) float64 { return v.FastK }), wantK.BeginIndex, wantK.Values)
+		sameValues(t, name+" fast-D", field(gotR, func(v stream.STOCHFValue) float64 { return v.FastD }), wantD.BeginIndex, wantD.Values)
+	}
+}
+
+func TestPriceOscillators(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	close := utils.GetFieldSlice(data, utils.FieldClose)
+	for _, maType := range allMATypes {
+		s1, e1 := stream.NewAPO(12, 26, maType)
+		b1, f1 := indicators.APO(close, 12, 26, maType)
+		single(t, data, fmt.Sprintf("APO(12, 26, %v)", maType), s1, e1, b1, f1)
+		s2, e2 := stream.NewPPO(26, 12, maType)
+		b2, f2 := indicators.PPO(close, 26, 12, maType)
+		single(t, data, fmt.Sprintf("PPO(26, 12, %v)", maType), s2, e2, b2, f2)
+	}
+}
+
+func TestDirectionalMovement(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	_, high, low, close, _ := utils.GetOHLCVSlices(data)
+	for _, period := range []int{1, 2, 14, 30} {
+		s1, e1 := stream.NewPLUS_DI(period)
+		b1, f1 := indicators.PLUS_DI(high, low, close, period)
+		single(t, data, fmt.Sprintf("PLUS_DI(%d)", period), s1, e1, b1, f1)
+		s2, e2 := stream.NewMINUS_DI(period)
+		b2, f2 := indicators.MINUS_DI(high, low, close, period)
+		single(t, data, fmt.Sprintf("MINUS_DI(%d)", period), s2, e2, b2, f2)
+		if period < 2 {
+			continue
+		}
+
+		s3, e3 := stream.NewDX(period)
+		b3, f3 := indicators.DX(high, low, close, period)
+		single(t, data, fmt.Sprintf("DX(%d)", period), s3, e3, b3, f3)
+
+		name := fmt.Sprintf("ADX(%d)", period)
+		s, err := stream.NewADX(period)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := runStream(t, data, s.Update)
+		want, err := indicators.ADX(high, low, close, period)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameValues(t, name, field(got, func(v stream.ADXValue) float64 { return v.ADX }), want.BeginIndex, want.Values)
+		sameValues(t, name+" +DI", field(got, func(v stream.ADXValue) float64 { return v.PlusDI }), want.BeginIndex, want.PlusDI)
+		sameValues(t, name+" -DI", field(got, func(v stream.ADXValue) float64 { return v.MinusDI }), want.BeginIndex, want.MinusDI)
+	}
+}
@@ -0,0 +1,123 @@
+package stream
+
+import "math"
+
+// unbounded is the upper limit of counters that grow with every update
+const unbounded = math.MaxInt
+
+// stateVisitor walks the parameters and the mutable state of an indicator,
+// always in the same order. Parameters are fixed at construction while
+// variables change with every update.
+type stateVisitor interface {
+	intParam(name string, p *int)
+	int64Param(name string, p *int64)
+	floatParam(name string, p *float64)
+	boolParam(name string, p *bool)
+	intVar(name string, p *int)
+	int64Var(name string, p *int64)
+	rangeVar(name string, p *int, min, max int)
+	floatVar(name string, p *float64)
+	boolVar(name string, p *bool)
+	floatsVar(name string, p []float64)
+	// ringVar visits a circular buffer of which an update only writes the
+	// value at next
+	ringVar(name string, p []float64, next int)
+	child(name string, s Stateful)
+}
+
+// Stateful is implemented by every streaming indicator of this package and
+// its components. The state can be saved with SaveState and restored with
+// LoadState.
+type Stateful interface {
+	visitState(v stateVisitor)
+}
+
+// snapshot saves the variables of an indicator so that they can be rolled
+// back. Of a circular buffer, only the value the next update writes is
+// saved unless all is set, so that saving costs the same whatever the
+// period.
+type snapshot struct {
+	all      bool
+	ints     []int64
+	floats   []float64
+	intPos   int
+	floatPos int
+	restore  bool
+}
+
+// save records the current variables of the indicator
+func (s *snapshot) save(ind Stateful) {
+	s.ints, s.floats = s.ints[:0], s.floats[:0]
+	s.restore = false
+	ind.visitState(s)
+}
+
+// rollback puts back the variables recorded by the last save. Without all,
+// only a single update since the save can be rolled back.
+func (s *snapshot) rollback(ind Stateful) {
+	s.intPos, s.floatPos = 0, 0
+	s.restore = true
+	ind.visitState(s)
+}
+
+func (s *snapshot) intParam(name string, p *int)       {}
+func (s *snapshot) int64Param(name string, p *int64)   {}
+func (s *snapshot) floatParam(name string, p *float64) {}
+func (s *snapshot) boolParam(name string, p *bool)     {}
+
+func (s *snapshot) intVar(name string, p *int) {
+	v := int64(*p)
+	s.int64Var(name, &v)
+	*p = int(v)
+}
+
+func (s *snapshot) int64Var(name string, p *int64) {
+	if s.restore {
+		*p = s.ints[s.intPos]
+		s.intPos++
+		return
+	}
+	s.ints = append(s.ints, *p)
+}
+
+func (s *snapshot) rangeVar(name string, p *int, min, max int) {
+	s.intVar(name, p)
+}
+
+func (s *snapshot) floatVar(name string, p *float64) {
+	if s.restore {
+		*p = s.floats[s.floatPos]
+		s.floatPos++
+		return
+	}
+	s.floats = append(s.floats, *p)
+}
+
+func (s *snapshot) boolVar(name string, p *bool) {
+	b := 0
+	if *p {
+		b = 1
+	}
+	s.intVar(name, &b)
+	*p = b != 0
+}
+
+func (s *snapshot) floatsVar(name string, p []float64) {
+	for i := range p {
+		s.floatVar(name, &p[i])
+	}
+}
+
+func (s *snapshot) ringVar(name string, p []float64, next int) {
+	if s.all || len(p) == 0 {
+		s.floatsVar(name, p)
+		return
+	}
+	// The position is the one at the save, the buffer may have moved since
+	s.intVar(name, &next)
+	s.floatVar(name, &p[next])
+}
+
+func (s *snapshot) child(name string, c Stateful) {
+	c.visitState(s)
+}
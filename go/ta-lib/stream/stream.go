@@ -0,0 +1,100 @@
+// Package stream implements incremental versions of the TA-Lib functions.
+//
+// Each indicator keeps its own state and is updated one bar at a time in
+// constant time for most functions. Once warmed up, the values are exactly
+// the ones the batch functions of the indicators package return for the same
+// bars. Update returns false as long as the lookback period is not over.
+//
+// The state of every indicator can be saved with SaveState and restored with
+// LoadState, including the slow warm-ups of KAMA, T3 and the Hilbert
+// transform indicators (HT_*).
+package stream
+
+import (
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Indicator is a streaming indicator with a single output
+type Indicator interface {
+	Update(bar utils.OHLCV) (float64, bool)
+}
+
+// valueIndicator is a streaming indicator on a single input series
+type valueIndicator interface {
+	UpdateValue(v float64) (float64, bool)
+	Stateful
+}
+
+// window holds the last values of a series in a circular buffer
+type window struct {
+	values []float64
+	size   int // Length of values, kept for the state visitors
+	next   int // Position of the next write
+	count  int // Number of values held
+}
+
+func newWindow(size int) *window {
+	return &window{values: make([]float64, size), size: size}
+}
+
+// push adds a value, overwriting the oldest one when the window is full
+func (w *window) push(v float64) {
+	w.values[w.next] = v
+	w.next++
+	if w.next == len(w.values) {
+		w.next = 0
+	}
+	if w.count < len(w.values) {
+		w.count++
+	}
+}
+
+// full reports whether the window holds as many values as its size
+func (w *window) full() bool {
+	return w.count == len(w.values)
+}
+
+func (w *window) visitState(v stateVisitor) {
+	v.intParam("size", &w.size)
+	v.ringVar("values", w.values, w.next)
+	v.rangeVar("next", &w.next, 0, w.size-1)
+	v.rangeVar("count", &w.count, 0, w.size)
+}
+
+// ago returns the value pushed n updates ago, 0 being the latest
+func (w *window) ago(n int) float64 {
+	i := w.next - 1 - n
+	if i < 0 {
+		i += len(w.values)
+	}
+	return w.values[i]
+}
+
+// skip drops the first n values of a series before passing the rest to an
+// indicator, so that it starts on the same bar as in the batch functions
+type skip struct {
+	n  int
+	in valueIndicator
+}
+
+func (s *skip) UpdateValue(v float64) (float64, bool) {
+	if s.n > 0 {
+		s.n--
+		return 0, false
+	}
+	return s.in.UpdateValue(v)
+}
+
+func (s *skip) visitState(v stateVisitor) {
+	v.rangeVar("skip", &s.n, 0, unbounded)
+	v.child("in", s.in)
+}
+
+// identity passes values through, used for moving averages of period 1
+type identity struct{}
+
+func (identity) UpdateValue(v float64) (float64, bool) {
+	return v, true
+}
+
+func (identity) visitState(v stateVisitor) {}
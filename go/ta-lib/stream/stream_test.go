@@ -0,0 +1,87 @@
+package stream_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/stream"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Streaming indicators must produce exactly the batch values once warmed up
+
+// allMATypes are the moving averages of a series without volume
+var allMATypes = []utils.MAType{
+	utils.SMA, utils.EMA, utils.WMA, utils.DEMA, utils.TEMA, utils.TRIMA, utils.KAMA, utils.MAMA, utils.T3,
+	utils.HMA, utils.ALMA, utils.ZLEMA, utils.SMMA, utils.MCGINLEY,
+}
+
+// streamed holds the outputs of a streaming indicator over the sample bars
+type streamed[T any] struct {
+	begIdx int
+	values []T
+}
+
+// runStream updates an indicator with every bar, keeping the ready outputs
+func runStream[T any](t *testing.T, data []utils.OHLCV, update func(utils.OHLCV) (T, bool)) streamed[T] {
+	t.Helper()
+	out := streamed[T]{begIdx: -1}
+	for i, bar := range data {
+		v, ok := update(bar)
+		if !ok {
+			if out.begIdx >= 0 {
+				t.Fatalf("not ready at bar %d after being ready at bar %d", i, out.begIdx)
+			}
+			continue
+		}
+		if out.begIdx < 0 {
+			out.begIdx = i
+		}
+		out.values = append(out.values, v)
+	}
+	return out
+}
+
+// field extracts one output of a multi-output streaming indicator
+func field[T any](s streamed[T], get func(T) float64) streamed[float64] {
+	out := streamed[float64]{begIdx: s.begIdx, values: make([]float64, len(s.values))}
+	for i, v := range s.values {
+		out.values[i] = get(v)
+	}
+	return out
+}
+
+// sameValues compares streamed values with a batch output bit for bit
+func sameValues(t *testing.T, name string, got streamed[float64], begIdx int, want []float64) {
+	t.Helper()
+	if len(want) == 0 {
+		if len(got.values) != 0 {
+			t.Errorf("%s: %d streamed values, batch has none", name, len(got.values))
+		}
+		return
+	}
+	if got.begIdx != begIdx {
+		t.Errorf("%s: first value at bar %d, batch at %d", name, got.begIdx, begIdx)
+		return
+	}
+	if len(got.values) != len(want) {
+		t.Errorf("%s: %d values, batch has %d", name, len(got.values), len(want))
+		return
+	}
+	for i := range want {
+		if math.Float64bits(got.values[i]) != math.Float64bits(want[i]) {
+			t.Errorf("%s: bar %d is %v, batch has %v", name, begIdx+i, got.values[i], want[i])
+			return
+		}
+	}
+}
+
+// single checks a single output streaming indicator against its batch result
+func single(t *testing.T, data []utils.OHLCV, name string, ind stream.Indicator, err error, want *utils.Result, batchErr error) {
+	t.Helper()
+	if err != nil || batchErr != nil {
+		t.Errorf("%s: %v %v", name, err, batchErr)
+		return
+	}
+	sameValues(t, name, runStream(t, data, ind.Update), want.BeginIndex, want.Values)
+}
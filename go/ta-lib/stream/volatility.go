@@ -0,0 +1,256 @@
+package stream
+
+import (
+	"math"
+
+	"github.com/petercool/ta-lib/go/ta-lib/indicators"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// trueRange returns the greatest of the bar range and the distances to the previous close
+func trueRange(high, low, prevClose float64) float64 {
+	greatest := high - low
+	if val2 := math.Abs(prevClose - high); val2 > greatest {
+		greatest = val2
+	}
+	if val3 := math.Abs(prevClose - low); val3 > greatest {
+		greatest = val3
+	}
+	return greatest
+}
+
+// TRANGE is a streaming True Range
+type TRANGE struct {
+	count     int
+	prevClose float64
+}
+
+// NewTRANGE creates a streaming TRANGE
+func NewTRANGE() *TRANGE {
+	return &TRANGE{}
+}
+
+func (t *TRANGE) visitState(v stateVisitor) {
+	v.rangeVar("count", &t.count, 0, unbounded)
+	v.floatVar("prevClose", &t.prevClose)
+}
+
+// Update adds a bar and returns its true range
+func (t *TRANGE) Update(bar utils.OHLCV) (float64, bool) {
+	t.count++
+	prevClose := t.prevClose
+	t.prevClose = bar.Close
+	if t.count == 1 {
+		return 0, false
+	}
+	return trueRange(bar.High, bar.Low, prevClose), true
+}
+
+// ATR is a streaming Average True Range
+type ATR struct {
+	period  int
+	trange  TRANGE
+	count   int
+	prevATR float64
+}
+
+// NewATR creates a streaming ATR
+func NewATR(optInTimePeriod int) (*ATR, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 1); err != nil {
+		return nil, err
+	}
+	return &ATR{period: optInTimePeriod}, nil
+}
+
+func (a *ATR) visitState(v stateVisitor) {
+	v.intParam("period", &a.period)
+	v.child("trange", &a.trange)
+	v.rangeVar("count", &a.count, 0, unbounded)
+	v.floatVar("prevATR", &a.prevATR)
+}
+
+// Update adds a bar and returns the ATR
+func (a *ATR) Update(bar utils.OHLCV) (float64, bool) {
+	tr, ok := a.trange.Update(bar)
+	if !ok || a.period <= 1 {
+		return tr, ok
+	}
+
+	// The first ATR is the simple average of the true ranges,
+	// then Wilder's smoothing is applied
+	if a.count < a.period {
+		a.prevATR += tr
+		a.count++
+		if a.count < a.period {
+			return 0, false
+		}
+		a.prevATR /= float64(a.period)
+		return a.prevATR, true
+	}
+	a.prevATR *= float64(a.period - 1)
+	a.prevATR += tr
+	a.prevATR /= float64(a.period)
+	return a.prevATR, true
+}
+
+// VAR is a streaming population Variance
+type VAR struct {
+	period  int
+	window  *window
+	moments utils.RollingMoments
+}
+
+// NewVAR creates a streaming VAR
+func NewVAR(optInTimePeriod int, optInNbDev float64) (*VAR, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 1); err != nil {
+		return nil, err
+	}
+	return newVAR(optInTimePeriod), nil
+}
+
+func newVAR(period int) *VAR {
+	return &VAR{period: period, window: newWindow(period)}
+}
+
+func (x *VAR) visitState(v stateVisitor) {
+	v.intParam("period", &x.period)
+	v.child("window", x.window)
+	v.child("moments", (*rollingMoments)(&x.moments))
+}
+
+// Update adds a bar and returns the variance of the closes
+func (v *VAR) Update(bar utils.OHLCV) (float64, bool) {
+	return v.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the variance
+func (v *VAR) UpdateValue(x float64) (float64, bool) {
+	if !v.window.full() {
+		v.window.push(x)
+		v.moments.Add(x)
+		if !v.window.full() {
+			return 0, false
+		}
+		return v.moments.Variance(0), true
+	}
+	oldest := v.window.ago(v.period - 1)
+	v.window.push(x)
+	v.moments.Replace(oldest, x)
+	return v.moments.Variance(0), true
+}
+
+// rollingMoments saves the state of utils.RollingMoments
+type rollingMoments utils.RollingMoments
+
+func (m *rollingMoments) visitState(v stateVisitor) {
+	v.rangeVar("n", &m.N, 0, unbounded)
+	v.floatVar("shift", &m.Shift)
+	v.floatVar("mean", &m.ShiftedMean.Value)
+	v.floatVar("meanCompensation", &m.ShiftedMean.Compensation)
+	v.floatVar("m2", &m.M2.Value)
+	v.floatVar("m2Compensation", &m.M2.Compensation)
+}
+
+// STDDEV is a streaming population Standard Deviation
+type STDDEV struct {
+	variance *VAR
+	nbDev    float64
+}
+
+// NewSTDDEV creates a streaming STDDEV
+func NewSTDDEV(optInTimePeriod int, optInNbDev float64) (*STDDEV, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	return newSTDDEV(optInTimePeriod, optInNbDev), nil
+}
+
+func newSTDDEV(period int, nbDev float64) *STDDEV {
+	return &STDDEV{variance: newVAR(period), nbDev: nbDev}
+}
+
+func (s *STDDEV) visitState(v stateVisitor) {
+	v.floatParam("nbDev", &s.nbDev)
+	v.child("variance", s.variance)
+}
+
+// Update adds a bar and returns the standard deviation of the closes
+func (s *STDDEV) Update(bar utils.OHLCV) (float64, bool) {
+	return s.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the standard deviation
+func (s *STDDEV) UpdateValue(v float64) (float64, bool) {
+	tempReal, ok := s.variance.UpdateValue(v)
+	if !ok {
+		return 0, false
+	}
+	return math.Sqrt(tempReal) * s.nbDev, true
+}
+
+// BBANDSValue holds the outputs of BBANDS
+type BBANDSValue struct {
+	Upper  float64 // Upper band
+	Middle float64 // Middle band
+	Lower  float64 // Lower band
+}
+
+// BBANDS is a streaming Bollinger Bands
+type BBANDS struct {
+	period  int
+	maType  utils.MAType
+	nbDevUp float64
+	nbDevDn float64
+	middle  valueIndicator
+	stddev  valueIndicator
+}
+
+// NewBBANDS creates a streaming BBANDS, the middle band uses an SMA unless an MA type is given
+func NewBBANDS(optInTimePeriod int, optInNbDevUp, optInNbDevDn float64, optInMAType ...utils.MAType) (*BBANDS, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	maType := utils.OptionalMAType(optInMAType, 0)
+	if err := utils.CheckMAType(maType); err != nil {
+		return nil, err
+	}
+
+	// The deviation window ends on the first bar of the middle band
+	n := indicators.MALookback(optInTimePeriod, maType) - (optInTimePeriod - 1)
+	return &BBANDS{
+		period:  optInTimePeriod,
+		maType:  maType,
+		nbDevUp: optInNbDevUp,
+		nbDevDn: optInNbDevDn,
+		middle:  newMA(optInTimePeriod, maType),
+		stddev:  &skip{n: max(n, 0), in: newSTDDEV(optInTimePeriod, 1.0)},
+	}, nil
+}
+
+func (b *BBANDS) visitState(v stateVisitor) {
+	v.intParam("period", &b.period)
+	v.intParam("maType", (*int)(&b.maType))
+	v.floatParam("nbDevUp", &b.nbDevUp)
+	v.floatParam("nbDevDn", &b.nbDevDn)
+	v.child("middle", b.middle)
+	v.child("stddev", b.stddev)
+}
+
+// Update adds a bar and returns the bands around the closes
+func (b *BBANDS) Update(bar utils.OHLCV) (BBANDSValue, bool) {
+	return b.UpdateValue(bar.Close)
+}
+
+// UpdateValue adds a value and returns the bands
+func (b *BBANDS) UpdateValue(v float64) (BBANDSValue, bool) {
+	middle, ok := b.middle.UpdateValue(v)
+	dev, devOK := b.stddev.UpdateValue(v)
+	if !ok || !devOK {
+		return BBANDSValue{}, false
+	}
+	return BBANDSValue{
+		Upper:  middle + dev*b.nbDevUp,
+		Middle: middle,
+		Lower:  middle - dev*b.nbDevDn,
+	}, true
+}
@@ -0,0 +1,67 @@
+package stream_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/indicators"
+	"github.com/petercool/ta-lib/go/ta-lib/stream"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testdata"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+func TestVolatility(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	_, high, low, close, _ := utils.GetOHLCVSlices(data)
+
+	b1, f1 := indicators.TRANGE(high, low, close)
+	single(t, data, "TRANGE", stream.NewTRANGE(), nil, b1, f1)
+	for _, period := range []int{1, 2, 14} {
+		s, err := stream.NewATR(period)
+		b, batchErr := indicators.ATR(high, low, close, period)
+		single(t, data, fmt.Sprintf("ATR(%d)", period), s, err, b, batchErr)
+	}
+	for _, period := range []int{2, 10, 30} {
+		s1, e1 := stream.NewVAR(period, 1)
+		b1, f1 := indicators.VAR(close, period, 1)
+		single(t, data, fmt.Sprintf("VAR(%d)", period), s1, e1, b1, f1)
+		s2, e2 := stream.NewSTDDEV(period, 1.5)
+		b2, f2 := indicators.STDDEV(close, period, 1.5)
+		single(t, data, fmt.Sprintf("STDDEV(%d)", period), s2, e2, b2, f2)
+	}
+}
+
+func TestBBANDS(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	close := utils.GetFieldSlice(data, utils.FieldClose)
+	for _, maType := range allMATypes {
+		for _, period := range []int{5, 20, 40} {
+			name := fmt.Sprintf("BBANDS(%d, %v)", period, maType)
+			s, err := stream.NewBBANDS(period, 2, 1.5, maType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := runStream(t, data, s.Update)
+			want, err := indicators.BBANDS(close, period, 2, 1.5, maType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sameValues(t, name+" upper", field(got, func(v stream.BBANDSValue) float64 { return v.Upper }), want.BeginIndex, want.UpperBand)
+			sameValues(t, name+" middle", field(got, func(v stream.BBANDSValue) float64 { return v.Middle }), want.BeginIndex, want.Values)
+			sameValues(t, name+" lower", field(got, func(v stream.BBANDSValue) float64 { return v.Lower }), want.BeginIndex, want.LowerBand)
+		}
+	}
+}
+
+// The streaming VAR keeps the precision of the batch one on large values
+// with a tiny spread over long series
+func TestVARPrecision(t *testing.T) {
+	data := testdata.TightOHLCV(100000)
+	close := utils.GetFieldSlice(data, utils.FieldClose)
+	for _, period := range []int{20, 500} {
+		s, err := stream.NewVAR(period, 1)
+		b, batchErr := indicators.VAR(close, period, 1)
+		single(t, data, fmt.Sprintf("VAR(%d) of 70,000", period), s, err, b, batchErr)
+	}
+}
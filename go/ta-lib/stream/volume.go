@@ -0,0 +1,144 @@
+package stream
+
+import (
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// OBV is a streaming On Balance Volume
+type OBV struct {
+	started  bool
+	prevOBV  float64
+	prevReal float64
+}
+
+// NewOBV creates a streaming OBV
+func NewOBV() *OBV {
+	return &OBV{}
+}
+
+func (o *OBV) visitState(v stateVisitor) {
+	v.boolVar("started", &o.started)
+	v.floatVar("prevOBV", &o.prevOBV)
+	v.floatVar("prevReal", &o.prevReal)
+}
+
+// Update adds a bar and returns the OBV of its close and volume
+func (o *OBV) Update(bar utils.OHLCV) (float64, bool) {
+	if !o.started {
+		o.started = true
+		o.prevOBV = bar.Volume
+		o.prevReal = bar.Close
+		return o.prevOBV, true
+	}
+	if bar.Close > o.prevReal {
+		o.prevOBV += bar.Volume
+	} else if bar.Close < o.prevReal {
+		o.prevOBV -= bar.Volume
+	}
+	o.prevReal = bar.Close
+	return o.prevOBV, true
+}
+
+// AD is a streaming Chaikin Accumulation/Distribution Line
+type AD struct {
+	ad float64
+}
+
+// NewAD creates a streaming AD
+func NewAD() *AD {
+	return &AD{}
+}
+
+func (a *AD) visitState(v stateVisitor) {
+	v.floatVar("ad", &a.ad)
+}
+
+// Update adds a bar and returns the AD line
+func (a *AD) Update(bar utils.OHLCV) (float64, bool) {
+	if tmp := bar.High - bar.Low; tmp > 0.0 {
+		a.ad += (((bar.Close - bar.Low) - (bar.High - bar.Close)) / tmp) * bar.Volume
+	}
+	return a.ad, true
+}
+
+// MFI is a streaming Money Flow Index
+type MFI struct {
+	period        int
+	count         int
+	prevValue     float64
+	positiveFlows []float64 // Circular buffer of the positive money flows
+	negativeFlows []float64 // Circular buffer of the negative money flows
+	mflowIdx      int
+	posSumMF      float64
+	negSumMF      float64
+}
+
+// NewMFI creates a streaming MFI
+func NewMFI(optInTimePeriod int) (*MFI, error) {
+	if err := utils.CheckPeriod("time period", optInTimePeriod, 2); err != nil {
+		return nil, err
+	}
+	return &MFI{
+		period:        optInTimePeriod,
+		positiveFlows: make([]float64, optInTimePeriod),
+		negativeFlows: make([]float64, optInTimePeriod),
+	}, nil
+}
+
+func (m *MFI) visitState(v stateVisitor) {
+	v.intParam("period", &m.period)
+	v.rangeVar("count", &m.count, 0, unbounded)
+	v.floatVar("prevValue", &m.prevValue)
+	v.ringVar("positiveFlows", m.positiveFlows, m.mflowIdx)
+	v.ringVar("negativeFlows", m.negativeFlows, m.mflowIdx)
+	v.rangeVar("mflowIdx", &m.mflowIdx, 0, m.period-1)
+	v.floatVar("posSumMF", &m.posSumMF)
+	v.floatVar("negSumMF", &m.negSumMF)
+}
+
+// Update adds a bar and returns the MFI
+func (m *MFI) Update(bar utils.OHLCV) (float64, bool) {
+	m.count++
+	tempValue1 := (bar.High + bar.Low + bar.Close) / 3.0
+	if m.count == 1 {
+		m.prevValue = tempValue1
+		return 0, false
+	}
+
+	// Drop the flow leaving the window
+	if m.count > m.period+1 {
+		m.posSumMF -= m.positiveFlows[m.mflowIdx]
+		m.negSumMF -= m.negativeFlows[m.mflowIdx]
+	}
+
+	tempValue2 := tempValue1 - m.prevValue
+	m.prevValue = tempValue1
+	tempValue1 *= bar.Volume
+	m.positiveFlows[m.mflowIdx] = 0.0
+	m.negativeFlows[m.mflowIdx] = 0.0
+	if tempValue2 < 0 {
+		m.negativeFlows[m.mflowIdx] = tempValue1
+		m.negSumMF += tempValue1
+	} else if tempValue2 > 0 {
+		m.positiveFlows[m.mflowIdx] = tempValue1
+		m.posSumMF += tempValue1
+	}
+	m.mflowIdx++
+	if m.mflowIdx == m.period {
+		m.mflowIdx = 0
+	}
+
+	if m.count <= m.period {
+		return 0, false
+	}
+	return mfiValue(m.posSumMF, m.negSumMF), true
+}
+
+// mfiValue converts positive and negative money flow sums into an MFI value
+func mfiValue(posSumMF, negSumMF float64) float64 {
+	tempValue1 := posSumMF + negSumMF
+	if tempValue1 < 1.0 {
+		return 0.0
+	}
+	return 100.0 * (posSumMF / tempValue1)
+}
@@ -0,0 +1,25 @@
+package stream_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/indicators"
+	"github.com/petercool/ta-lib/go/ta-lib/stream"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+func TestVolume(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	_, high, low, close, volume := utils.GetOHLCVSlices(data)
+	b1, f1 := indicators.OBV(close, volume)
+	single(t, data, "OBV", stream.NewOBV(), nil, b1, f1)
+	b2, f2 := indicators.AD(high, low, close, volume)
+	single(t, data, "AD", stream.NewAD(), nil, b2, f2)
+	for _, period := range []int{2, 14} {
+		s, err := stream.NewMFI(period)
+		b, batchErr := indicators.MFI(high, low, close, volume, period)
+		single(t, data, fmt.Sprintf("MFI(%d)", period), s, err, b, batchErr)
+	}
+}
@@ -0,0 +1,103 @@
+package stream
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// VWAPValue holds the outputs of VWAP
+type VWAPValue struct {
+	Upper  float64 // VWAP plus NbDev standard deviations
+	VWAP   float64 // Volume-weighted average of the typical price
+	Lower  float64 // VWAP minus NbDev standard deviations
+	StdDev float64 // Volume-weighted standard deviation of the typical price
+}
+
+// VWAP is a streaming session or anchored VWAP. The time zone of the
+// sessions is not part of the saved state.
+type VWAP struct {
+	nbDev    float64
+	anchored bool  // Whether the VWAP starts at anchor instead of restarting every day
+	anchor   int64 // Anchor time in Unix seconds
+	loc      *time.Location
+	session  int64 // Start of the current session
+	started  bool
+	moments  utils.WeightedMoments // Typical prices weighted by volume
+}
+
+// NewSessionVWAP creates a streaming VWAP restarting on every calendar day
+// in loc, or in UTC when loc is nil
+func NewSessionVWAP(loc *time.Location, optInNbDev float64) (*VWAP, error) {
+	if err := checkNbDev(optInNbDev); err != nil {
+		return nil, err
+	}
+	return &VWAP{nbDev: optInNbDev, loc: loc}, nil
+}
+
+// NewAnchoredVWAP creates a streaming VWAP starting on the first bar at or
+// after the anchor time, in Unix seconds
+func NewAnchoredVWAP(anchor int64, optInNbDev float64) (*VWAP, error) {
+	if err := checkNbDev(optInNbDev); err != nil {
+		return nil, err
+	}
+	return &VWAP{nbDev: optInNbDev, anchored: true, anchor: anchor}, nil
+}
+
+// checkNbDev rejects a NaN or negative number of deviations of the bands
+func checkNbDev(nbDev float64) error {
+	if math.IsNaN(nbDev) || nbDev < 0 {
+		return fmt.Errorf("deviations %v: %w", nbDev, utils.ErrInvalidParameter)
+	}
+	return nil
+}
+
+func (w *VWAP) visitState(v stateVisitor) {
+	v.floatParam("nbDev", &w.nbDev)
+	v.boolParam("anchored", &w.anchored)
+	v.int64Param("anchor", &w.anchor)
+	v.int64Var("session", &w.session)
+	v.boolVar("started", &w.started)
+	v.child("moments", (*weightedMoments)(&w.moments))
+}
+
+// weightedMoments saves the state of utils.WeightedMoments
+type weightedMoments utils.WeightedMoments
+
+func (m *weightedMoments) visitState(v stateVisitor) {
+	v.rangeVar("n", &m.N, 0, unbounded)
+	v.floatVar("weight", &m.Weight.Value)
+	v.floatVar("weightCompensation", &m.Weight.Compensation)
+	v.floatVar("shift", &m.Shift)
+	v.floatVar("mean", &m.ShiftedMean.Value)
+	v.floatVar("meanCompensation", &m.ShiftedMean.Compensation)
+	v.floatVar("m2", &m.M2.Value)
+	v.floatVar("m2Compensation", &m.M2.Compensation)
+}
+
+// Update adds a bar and returns the VWAP of its typical price, false before the anchor
+func (w *VWAP) Update(bar utils.OHLCV) (VWAPValue, bool) {
+	if !w.anchored {
+		if session := utils.Daily.PeriodStart(bar.Time, w.loc); !w.started || session != w.session {
+			w.session = session
+			w.moments = utils.WeightedMoments{}
+		}
+	} else if !w.started && bar.Time < w.anchor {
+		return VWAPValue{}, false
+	}
+	w.started = true
+
+	price := (bar.High + bar.Low + bar.Close) / 3.0
+	w.moments.Add(price, bar.Volume)
+
+	vwap, dev := price, 0.0
+	if !utils.TAIsZeroOrNeg(w.moments.Weight.Total()) {
+		vwap = w.moments.Mean()
+		if variance := w.moments.Variance(); !utils.TAIsZeroOrNeg(variance) {
+			dev = math.Sqrt(variance)
+		}
+	}
+	return VWAPValue{Upper: vwap + w.nbDev*dev, VWAP: vwap, Lower: vwap - w.nbDev*dev, StdDev: dev}, true
+}
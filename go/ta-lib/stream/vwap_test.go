@@ -0,0 +1,112 @@
+package stream_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/petercool/ta-lib/go/ta-lib/indicators"
+	"github.com/petercool/ta-lib/go/ta-lib/stream"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testdata"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// hourly turns the sample bars into consecutive hourly bars
+func hourly(data []utils.OHLCV) []utils.OHLCV {
+	out := append([]utils.OHLCV(nil), data...)
+	for i := range out {
+		out[i].Time = data[0].Time + int64(i)*3600
+	}
+	return out
+}
+
+// sameVWAP compares the streamed VWAP and bands with the batch ones
+func sameVWAP(t *testing.T, data []utils.OHLCV, name string, s *stream.VWAP, err error, want *indicators.VWAPResult) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	got := runStream(t, data, s.Update)
+	sameValues(t, name, field(got, func(v stream.VWAPValue) float64 { return v.VWAP }), want.BeginIndex, want.Values)
+	sameValues(t, name+" upper band", field(got, func(v stream.VWAPValue) float64 { return v.Upper }), want.BeginIndex, want.UpperBand)
+	sameValues(t, name+" lower band", field(got, func(v stream.VWAPValue) float64 { return v.Lower }), want.BeginIndex, want.LowerBand)
+	sameValues(t, name+" deviation", field(got, func(v stream.VWAPValue) float64 { return v.StdDev }), want.BeginIndex, want.StdDev)
+}
+
+func TestVWAP(t *testing.T) {
+	bars := hourly(testutil.MustOHLCV(t))
+	b := utils.BarsFromOHLCV(bars)
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		newYork = time.FixedZone("EST", -5*3600)
+	}
+	for _, loc := range []*time.Location{nil, newYork} {
+		batch, err := indicators.SessionVWAPBars(b, loc, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := stream.NewSessionVWAP(loc, 2)
+		sameVWAP(t, bars, "session VWAP in "+loc.String(), s, err, batch)
+	}
+
+	anchor := b.Time[100] - 1800
+	batch, err := indicators.AnchoredVWAPBars(b, anchor, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := stream.NewAnchoredVWAP(anchor, 1)
+	sameVWAP(t, bars, "anchored VWAP", s, err, batch)
+}
+
+// The bands are a positive number of deviations away, and the states of
+// session and anchored VWAPs, whose anchors may be past 2^31, do not mix
+func TestVWAPParameters(t *testing.T) {
+	for _, nbDev := range []float64{-1, math.NaN()} {
+		if _, err := stream.NewSessionVWAP(nil, nbDev); !errors.Is(err, utils.ErrInvalidParameter) {
+			t.Errorf("session VWAP with %v deviations: %v", nbDev, err)
+		}
+		if _, err := stream.NewAnchoredVWAP(0, nbDev); !errors.Is(err, utils.ErrInvalidParameter) {
+			t.Errorf("anchored VWAP with %v deviations: %v", nbDev, err)
+		}
+	}
+
+	const anchor = 1 << 40
+	anchored, _ := stream.NewAnchoredVWAP(anchor, 2)
+	sessions, _ := stream.NewSessionVWAP(nil, 2)
+	earlier, _ := stream.NewAnchoredVWAP(anchor-1<<32, 2)
+	for _, save := range []struct {
+		name string
+		save func(stream.Stateful) ([]byte, error)
+		load func(stream.Stateful, []byte) error
+	}{
+		{"binary", stream.SaveState, stream.LoadState},
+		{"JSON", stream.SaveStateJSON, stream.LoadStateJSON},
+	} {
+		data, err := save.save(anchored)
+		if err != nil {
+			t.Fatal(err)
+		}
+		restored, _ := stream.NewAnchoredVWAP(anchor, 2)
+		if err := save.load(restored, data); err != nil {
+			t.Errorf("%s state of an anchored VWAP: %v", save.name, err)
+		}
+		if err := save.load(sessions, data); !errors.Is(err, utils.ErrInvalidParameter) {
+			t.Errorf("%s state of an anchored VWAP loaded into a session one: %v", save.name, err)
+		}
+		if err := save.load(earlier, data); !errors.Is(err, utils.ErrInvalidParameter) {
+			t.Errorf("%s state with an anchor 2^32 seconds later loaded: %v", save.name, err)
+		}
+	}
+}
+
+func TestVWAPPrecision(t *testing.T) {
+	bars := testdata.TightOHLCV(20000)
+	batch, err := indicators.AnchoredVWAPBars(utils.BarsFromOHLCV(bars), 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := stream.NewAnchoredVWAP(0, 2)
+	sameVWAP(t, bars, "anchored VWAP of 70,000", s, err, batch)
+}
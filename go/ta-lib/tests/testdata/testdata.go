@@ -0,0 +1,81 @@
+// Package testdata provides the sample market data used by the examples and tests.
+//
+// Besides data_0.csv, it holds the data of ta_regtest, the regression tests
+// of the C library in src/tools/ta_regtest: the 252 reference bars of
+// test_data.c in ta_regtest_ref.csv, the 10000 profiling bars of
+// ta_gDataOpen/High/Low/Close.c in ta_regtest_profile.csv, and the expected
+// values of its test_*.c files in ta_regtest_cases.csv. The C data has no
+// dates, so bar i is dated i days after the Unix epoch, and the profiling
+// bars have no volumes.
+//
+// The feed_*.csv and binance_*.json files are the fixtures of the feed tests
+// of utils: CSV files with and without optional columns or with malformed
+// rows, and Binance kline payloads of the REST and websocket APIs.
+package testdata
+
+import (
+	"math"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Path returns the absolute path of a file in the testdata directory
+func Path(name string) string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), name)
+}
+
+// GetOHLCV loads the sample daily bars from data_0.csv
+func GetOHLCV() ([]utils.OHLCV, error) {
+	return utils.NewCSVFeed(Path("data_0.csv")).GetData(time.Time{}, time.Time{})
+}
+
+// GetBars loads the sample daily bars from data_0.csv in columnar form
+func GetBars() (*utils.Bars, error) {
+	data, err := GetOHLCV()
+	if err != nil {
+		return nil, err
+	}
+	return utils.BarsFromOHLCV(data), nil
+}
+
+// GetOHLCVSlices loads the sample daily bars as separate open, high, low, close and volume slices
+func GetOHLCVSlices() (open, high, low, close, volume []float64, err error) {
+	data, err := GetOHLCV()
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+	open, high, low, close, volume = utils.GetOHLCVSlices(data)
+	return open, high, low, close, volume, nil
+}
+
+// GetReferenceOHLCV loads the reference daily bars of ta_regtest from ta_regtest_ref.csv
+func GetReferenceOHLCV() ([]utils.OHLCV, error) {
+	return utils.NewCSVFeed(Path("ta_regtest_ref.csv")).GetData(time.Time{}, time.Time{})
+}
+
+// GetProfileOHLCV loads the profiling bars of ta_regtest from ta_regtest_profile.csv
+func GetProfileOHLCV() ([]utils.OHLCV, error) {
+	return utils.NewCSVFeed(Path("ta_regtest_profile.csv")).GetData(time.Time{}, time.Time{})
+}
+
+// TightOHLCV generates n one-minute bars of a price near 70,000 moving by a
+// hundredth, with a thousandth of noise and volumes between 50 and 250. On
+// these large values with a tiny spread the running sums of x and x*x lose
+// the variance, which the precision tests of the rolling statistics check.
+func TightOHLCV(n int) []utils.OHLCV {
+	seed := uint64(1)
+	noise := func() float64 {
+		seed = seed*6364136223846793005 + 1442695040888963407
+		return float64(seed>>11)/float64(1<<53)*2 - 1
+	}
+	bars := make([]utils.OHLCV, n)
+	for i := range bars {
+		x := 70000 + 0.01*math.Sin(float64(i)/50) + 0.001*noise()
+		bars[i] = utils.OHLCV{Time: int64(i) * 60, Open: x, High: x + 0.002, Low: x - 0.002, Close: x, Volume: 100 * (1.5 + noise())}
+	}
+	return bars
+}
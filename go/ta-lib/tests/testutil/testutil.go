@@ -0,0 +1,31 @@
+// Package testutil provides the helpers shared by the tests. It imports
+// testing, so only _test.go files may import it.
+package testutil
+
+import (
+	"math"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testdata"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// MustOHLCV loads the sample daily bars from data_0.csv, failing the test
+// when they cannot be read
+func MustOHLCV(t testing.TB) []utils.OHLCV {
+	t.Helper()
+	data, err := testdata.GetOHLCV()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// Near fails the test when got is not within tolerance of want, relative to
+// want or absolute when want is under 1
+func Near(t testing.TB, name string, got, want, tolerance float64) {
+	t.Helper()
+	if math.Abs(got-want) > tolerance*max(math.Abs(want), 1) {
+		t.Errorf("%s is %v, want %v", name, got, want)
+	}
+}
@@ -0,0 +1,223 @@
+package utils
+
+import (
+	"sort"
+)
+
+// Bars holds candlestick data in columnar form, one slice per field.
+//
+// All slices have the same length and Time is in Unix seconds, sorted in
+// ascending order. Slicing a Bars shares the underlying arrays with the
+// original, so no data is copied.
+type Bars struct {
+	Time   []int64
+	Open   []float64
+	High   []float64
+	Low    []float64
+	Close  []float64
+	Volume []float64
+
+	// Optional fields of OHLCV, zero when the data source does not provide
+	// them. A nil or short column reads as zeros for the missing bars.
+	OpenInterest []float64
+	QuoteVolume  []float64
+	TradeCount   []int64
+	VWAP         []float64
+}
+
+// NewBars creates an empty Bars with room for capacity bars
+func NewBars(capacity int) *Bars {
+	return &Bars{
+		Time:   make([]int64, 0, capacity),
+		Open:   make([]float64, 0, capacity),
+		High:   make([]float64, 0, capacity),
+		Low:    make([]float64, 0, capacity),
+		Close:  make([]float64, 0, capacity),
+		Volume: make([]float64, 0, capacity),
+
+		OpenInterest: make([]float64, 0, capacity),
+		QuoteVolume:  make([]float64, 0, capacity),
+		TradeCount:   make([]int64, 0, capacity),
+		VWAP:         make([]float64, 0, capacity),
+	}
+}
+
+// BarsFromOHLCV converts OHLCV data to columnar Bars
+func BarsFromOHLCV(data []OHLCV) *Bars {
+	b := NewBars(len(data))
+	b.Append(data...)
+	return b
+}
+
+// Len returns the number of bars
+func (b *Bars) Len() int {
+	return len(b.Time)
+}
+
+// At returns the bar at index i
+func (b *Bars) At(i int) OHLCV {
+	return OHLCV{
+		Time:   b.Time[i],
+		Open:   b.Open[i],
+		High:   b.High[i],
+		Low:    b.Low[i],
+		Close:  b.Close[i],
+		Volume: b.Volume[i],
+
+		OpenInterest: optionalAt(b.OpenInterest, i),
+		QuoteVolume:  optionalAt(b.QuoteVolume, i),
+		TradeCount:   optionalAt(b.TradeCount, i),
+		VWAP:         optionalAt(b.VWAP, i),
+	}
+}
+
+// Append adds bars at the end. Nil or short optional columns are first
+// filled with zeros, so they stay aligned with Time.
+func (b *Bars) Append(data ...OHLCV) {
+	if len(data) == 0 {
+		return
+	}
+	n := b.Len()
+	b.OpenInterest = padOptional(b.OpenInterest, n)
+	b.QuoteVolume = padOptional(b.QuoteVolume, n)
+	b.TradeCount = padOptional(b.TradeCount, n)
+	b.VWAP = padOptional(b.VWAP, n)
+	for _, d := range data {
+		b.Time = append(b.Time, d.Time)
+		b.Open = append(b.Open, d.Open)
+		b.High = append(b.High, d.High)
+		b.Low = append(b.Low, d.Low)
+		b.Close = append(b.Close, d.Close)
+		b.Volume = append(b.Volume, d.Volume)
+		b.OpenInterest = append(b.OpenInterest, d.OpenInterest)
+		b.QuoteVolume = append(b.QuoteVolume, d.QuoteVolume)
+		b.TradeCount = append(b.TradeCount, d.TradeCount)
+		b.VWAP = append(b.VWAP, d.VWAP)
+	}
+}
+
+// ToOHLCV converts the bars back to row form
+func (b *Bars) ToOHLCV() []OHLCV {
+	data := make([]OHLCV, b.Len())
+	for i := range data {
+		data[i] = b.At(i)
+	}
+	return data
+}
+
+// Slice returns the bars in [i, j) without copying.
+// Appending to the result never overwrites bars of the original.
+func (b *Bars) Slice(i, j int) *Bars {
+	return &Bars{
+		Time:   b.Time[i:j:j],
+		Open:   b.Open[i:j:j],
+		High:   b.High[i:j:j],
+		Low:    b.Low[i:j:j],
+		Close:  b.Close[i:j:j],
+		Volume: b.Volume[i:j:j],
+
+		OpenInterest: sliceOptional(b.OpenInterest, i, j),
+		QuoteVolume:  sliceOptional(b.QuoteVolume, i, j),
+		TradeCount:   sliceOptional(b.TradeCount, i, j),
+		VWAP:         sliceOptional(b.VWAP, i, j),
+	}
+}
+
+// SliceTime returns the bars with start <= Time <= end without copying.
+// A zero start or end leaves that side of the range open.
+func (b *Bars) SliceTime(start, end int64) *Bars {
+	i, j := 0, b.Len()
+	if start != 0 {
+		i = sort.Search(j, func(k int) bool { return b.Time[k] >= start })
+	}
+	if end != 0 {
+		j = sort.Search(j, func(k int) bool { return b.Time[k] > end })
+	}
+	if j < i {
+		j = i
+	}
+	return b.Slice(i, j)
+}
+
+// Index returns the index of the bar with the given time, or -1
+func (b *Bars) Index(t int64) int {
+	i := sort.Search(b.Len(), func(k int) bool { return b.Time[k] >= t })
+	if i < b.Len() && b.Time[i] == t {
+		return i
+	}
+	return -1
+}
+
+// Field returns the column for a field, sharing the underlying array. The
+// integer columns, Time and TradeCount, are converted to a new slice, a nil or
+// short optional column is returned zero-filled to Len and an unknown field
+// gives NaN values.
+func (b *Bars) Field(field Field) []float64 {
+	switch field {
+	case FieldOpen:
+		return b.Open
+	case FieldHigh:
+		return b.High
+	case FieldLow:
+		return b.Low
+	case FieldClose:
+		return b.Close
+	case FieldVolume:
+		return b.Volume
+	case FieldOpenInterest:
+		return fullOptional(b.OpenInterest, b.Len())
+	case FieldQuoteVolume:
+		return fullOptional(b.QuoteVolume, b.Len())
+	case FieldVWAP:
+		return fullOptional(b.VWAP, b.Len())
+	}
+	values := make([]float64, b.Len())
+	for i := range values {
+		values[i] = b.At(i).Value(field)
+	}
+	return values
+}
+
+// optionalAt returns the value of an optional column at index i, or zero when
+// the column does not reach it
+func optionalAt[T int64 | float64](col []T, i int) T {
+	if i < len(col) {
+		return col[i]
+	}
+	return 0
+}
+
+// sliceOptional returns the optional column in [i, j), nil when the column is
+// nil and a zero-padded copy when it is too short
+func sliceOptional[T int64 | float64](col []T, i, j int) []T {
+	switch {
+	case col == nil:
+		return nil
+	case j <= len(col):
+		return col[i:j:j]
+	}
+	out := make([]T, j-i)
+	if i < len(col) {
+		copy(out, col[i:])
+	}
+	return out
+}
+
+// padOptional extends an optional column with zeros up to n values
+func padOptional[T int64 | float64](col []T, n int) []T {
+	for len(col) < n {
+		col = append(col, 0)
+	}
+	return col
+}
+
+// fullOptional returns an optional column of n values, sharing the column
+// when it is complete and copying it zero-padded otherwise
+func fullOptional(col []float64, n int) []float64 {
+	if len(col) >= n {
+		return col[:n]
+	}
+	out := make([]float64, n)
+	copy(out, col)
+	return out
+}
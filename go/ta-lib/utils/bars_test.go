@@ -0,0 +1,191 @@
+package utils_test
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// sampleBars returns bars at times 100, 200, ..., 500 closing at 1 to 5
+func sampleBars() *utils.Bars {
+	b := utils.NewBars(5)
+	for i := 1; i <= 5; i++ {
+		x := float64(i)
+		b.Append(utils.OHLCV{
+			Time: int64(i) * 100, Open: x - 0.5, High: x + 1, Low: x - 1, Close: x, Volume: 10 * x,
+			OpenInterest: 100 * x, QuoteVolume: 10 * x * x, TradeCount: int64(i) * 7, VWAP: x + 0.25,
+		})
+	}
+	return b
+}
+
+func TestBarsConversion(t *testing.T) {
+	b := sampleBars()
+	data := b.ToOHLCV()
+	third := utils.OHLCV{
+		Time: 300, Open: 2.5, High: 4, Low: 2, Close: 3, Volume: 30,
+		OpenInterest: 300, QuoteVolume: 90, TradeCount: 21, VWAP: 3.25,
+	}
+	if len(data) != 5 || data[2] != third {
+		t.Fatalf("bars converted to %+v", data)
+	}
+	if back := utils.BarsFromOHLCV(data); !slices.Equal(back.ToOHLCV(), data) {
+		t.Errorf("round trip gives %+v", back.ToOHLCV())
+	}
+	if got := b.Field(utils.FieldVolume); !slices.Equal(got, []float64{10, 20, 30, 40, 50}) {
+		t.Errorf("volume column %v", got)
+	}
+	if got := b.Field(utils.FieldTime); !slices.Equal(got, []float64{100, 200, 300, 400, 500}) {
+		t.Errorf("time field %v", got)
+	}
+
+	// The optional fields are kept, as columns or converted for the trade count
+	for _, tc := range []struct {
+		field utils.Field
+		want  []float64
+	}{
+		{utils.FieldOpenInterest, []float64{100, 200, 300, 400, 500}},
+		{utils.FieldQuoteVolume, []float64{10, 40, 90, 160, 250}},
+		{utils.FieldTradeCount, []float64{7, 14, 21, 28, 35}},
+		{utils.FieldVWAP, []float64{1.25, 2.25, 3.25, 4.25, 5.25}},
+	} {
+		if got := b.Field(tc.field); !slices.Equal(got, tc.want) {
+			t.Errorf("field %d is %v, want %v", tc.field, got, tc.want)
+		}
+	}
+	if got := b.Field(utils.Field(-1)); !math.IsNaN(got[0]) {
+		t.Errorf("unknown field gives %v", got)
+	}
+}
+
+func TestBarsSlice(t *testing.T) {
+	b := sampleBars()
+	s := b.Slice(1, 3)
+	if !slices.Equal(s.Time, []int64{200, 300}) || !slices.Equal(s.Close, []float64{2, 3}) {
+		t.Fatalf("Slice(1, 3) has times %v and closes %v", s.Time, s.Close)
+	}
+	if !slices.Equal(s.TradeCount, []int64{14, 21}) || !slices.Equal(s.VWAP, []float64{2.25, 3.25}) {
+		t.Fatalf("Slice(1, 3) has trade counts %v and VWAPs %v", s.TradeCount, s.VWAP)
+	}
+
+	// The slice shares the arrays, but appending to it leaves the original alone
+	s.Close[0] = 20
+	if b.Close[1] != 20 {
+		t.Error("Slice copied the closes")
+	}
+	s.Append(utils.OHLCV{Time: 350, Close: 3.5})
+	if b.Time[3] != 400 || b.Close[3] != 4 || b.VWAP[3] != 4.25 {
+		t.Errorf("appending to a slice overwrote bar 3 with %+v", b.At(3))
+	}
+
+	for _, r := range [][2]int{{0, 0}, {2, 2}, {5, 5}} {
+		if s := b.Slice(r[0], r[1]); s.Len() != 0 {
+			t.Errorf("Slice(%d, %d) has %d bars", r[0], r[1], s.Len())
+		}
+	}
+	if s := b.Slice(0, 5); s.Len() != 5 {
+		t.Errorf("Slice(0, 5) has %d bars", s.Len())
+	}
+}
+
+func TestBarsWithoutOptionalColumns(t *testing.T) {
+	b := &utils.Bars{
+		Time:   []int64{100, 200, 300},
+		Open:   []float64{1, 2, 3},
+		High:   []float64{2, 3, 4},
+		Low:    []float64{0, 1, 2},
+		Close:  []float64{1.5, 2.5, 3.5},
+		Volume: []float64{10, 20, 30},
+		VWAP:   []float64{1.25},
+	}
+	if got, want := b.At(1), (utils.OHLCV{Time: 200, Open: 2, High: 3, Low: 1, Close: 2.5, Volume: 20}); got != want {
+		t.Errorf("At(1) = %+v, want %+v", got, want)
+	}
+	if got := b.At(0).VWAP; got != 1.25 {
+		t.Errorf("VWAP of the short column at bar 0 is %v", got)
+	}
+	if got := b.Field(utils.FieldTime); !slices.Equal(got, []float64{100, 200, 300}) {
+		t.Errorf("time field %v", got)
+	}
+	for _, tc := range []struct {
+		field utils.Field
+		want  []float64
+	}{
+		{utils.FieldOpenInterest, []float64{0, 0, 0}},
+		{utils.FieldQuoteVolume, []float64{0, 0, 0}},
+		{utils.FieldTradeCount, []float64{0, 0, 0}},
+		{utils.FieldVWAP, []float64{1.25, 0, 0}},
+	} {
+		if got := b.Field(tc.field); !slices.Equal(got, tc.want) {
+			t.Errorf("field %d is %v, want %v", tc.field, got, tc.want)
+		}
+	}
+
+	s := b.Slice(0, 2)
+	if !slices.Equal(s.Close, []float64{1.5, 2.5}) || s.OpenInterest != nil || s.TradeCount != nil {
+		t.Errorf("Slice(0, 2) is %+v", s)
+	}
+	if !slices.Equal(s.VWAP, []float64{1.25, 0}) {
+		t.Errorf("Slice(0, 2) pads the short VWAP to %v", s.VWAP)
+	}
+
+	// Appending keeps the optional columns aligned with the bars
+	b.Append(utils.OHLCV{Time: 400, Close: 4.5, OpenInterest: 7, TradeCount: 3})
+	if !slices.Equal(b.OpenInterest, []float64{0, 0, 0, 7}) || !slices.Equal(b.TradeCount, []int64{0, 0, 0, 3}) {
+		t.Errorf("appended open interest %v and trade counts %v", b.OpenInterest, b.TradeCount)
+	}
+	if got := b.At(3); got.OpenInterest != 7 || got.VWAP != 0 {
+		t.Errorf("At(3) = %+v", got)
+	}
+}
+
+func TestBarsSliceTime(t *testing.T) {
+	b := sampleBars()
+	for _, tc := range []struct {
+		name       string
+		start, end int64
+		want       []int64
+	}{
+		{"exact bounds", 200, 400, []int64{200, 300, 400}},
+		{"single bar", 300, 300, []int64{300}},
+		{"between bars", 150, 350, []int64{200, 300}},
+		{"open start", 0, 250, []int64{100, 200}},
+		{"open end", 450, 0, []int64{500}},
+		{"open range", 0, 0, []int64{100, 200, 300, 400, 500}},
+		{"covering", 50, 900, []int64{100, 200, 300, 400, 500}},
+		{"before the bars", 10, 90, []int64{}},
+		{"after the bars", 600, 900, []int64{}},
+		{"between two bars", 210, 290, []int64{}},
+		{"inverted", 400, 200, []int64{}},
+	} {
+		s := b.SliceTime(tc.start, tc.end)
+		if !slices.Equal(s.Time, tc.want) {
+			t.Errorf("%s: SliceTime(%d, %d) has times %v, want %v", tc.name, tc.start, tc.end, s.Time, tc.want)
+		}
+		if len(s.Close) != len(tc.want) || len(s.Volume) != len(tc.want) || len(s.TradeCount) != len(tc.want) {
+			t.Errorf("%s: columns of different lengths", tc.name)
+		}
+	}
+
+	empty := utils.NewBars(0)
+	if s := empty.SliceTime(100, 200); s.Len() != 0 {
+		t.Errorf("SliceTime of no bars has %d bars", s.Len())
+	}
+}
+
+func TestBarsIndex(t *testing.T) {
+	b := sampleBars()
+	for _, tc := range []struct {
+		time  int64
+		index int
+	}{{100, 0}, {300, 2}, {500, 4}, {50, -1}, {250, -1}, {600, -1}} {
+		if got := b.Index(tc.time); got != tc.index {
+			t.Errorf("index of %d is %d, want %d", tc.time, got, tc.index)
+		}
+	}
+	if got := utils.NewBars(0).Index(100); got != -1 {
+		t.Errorf("index in no bars is %d", got)
+	}
+}
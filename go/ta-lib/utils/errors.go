@@ -11,4 +11,23 @@ var (
 	ErrInternalError          = errors.New("internal error")
 	ErrEmptyInputData         = errors.New("empty input data")
 	ErrMismatchedInputLengths = errors.New("mismatched input lengths")
+	ErrInvalidState           = errors.New("invalid indicator state")
 )
+
+// Err returns the error matching the return code, or nil on success
+func (r RetCode) Err() error {
+	switch r {
+	case Success:
+		return nil
+	case InvalidParameter:
+		return ErrInvalidParameter
+	case OutOfRangeStartIndex:
+		return ErrOutOfRangeStartIndex
+	case OutOfRangeEndIndex:
+		return ErrOutOfRangeEndIndex
+	case AllocError:
+		return ErrAllocFailed
+	default:
+		return ErrInternalError
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -24,6 +26,56 @@ type OHLCV struct {
 	Low    float64
 	Close  float64
 	Volume float64
+
+	// Optional fields, zero when the data source does not provide them
+	OpenInterest float64
+	QuoteVolume  float64
+	TradeCount   int64
+	VWAP         float64
+}
+
+// Field identifies a component of an OHLCV bar
+type Field int
+
+const (
+	FieldOpen Field = iota
+	FieldHigh
+	FieldLow
+	FieldClose
+	FieldVolume
+	FieldOpenInterest
+	FieldQuoteVolume
+	FieldTradeCount
+	FieldVWAP
+	FieldTime
+)
+
+// Value returns the given field of the bar as a float64
+func (b OHLCV) Value(field Field) float64 {
+	switch field {
+	case FieldOpen:
+		return b.Open
+	case FieldHigh:
+		return b.High
+	case FieldLow:
+		return b.Low
+	case FieldClose:
+		return b.Close
+	case FieldVolume:
+		return b.Volume
+	case FieldOpenInterest:
+		return b.OpenInterest
+	case FieldQuoteVolume:
+		return b.QuoteVolume
+	case FieldTradeCount:
+		return float64(b.TradeCount)
+	case FieldVWAP:
+		return b.VWAP
+	case FieldTime:
+		return float64(b.Time)
+	default:
+		return math.NaN()
+	}
 }
 
 // DataFeed defines the interface for different data sources
@@ -92,7 +144,9 @@ func NewYahooFeedWithBaseURL(symbol, interval, baseURL string) *YahooFeed {
 	}
 }
 
-// GetData implements DataFeed interface for CSVFeed
+// GetData implements DataFeed interface for CSVFeed.
+// Rows without a valid time are skipped, while an empty or unparsable price,
+// volume or optional field is read as 0 and the row is kept.
 func (f *CSVFeed) GetData(startTime, endTime time.Time) ([]OHLCV, error) {
 	// Open CSV file
 	file, err := os.Open(f.FilePath)
@@ -115,6 +169,16 @@ func (f *CSVFeed) GetData(startTime, endTime time.Time) ([]OHLCV, error) {
 		return nil, fmt.Errorf("invalid CSV format: expected time,open,high,low,close,volume")
 	}
 
+	// Locate optional columns by name
+	optional := map[string]int{}
+	for i, name := range header[6:] {
+		optional[strings.ToLower(strings.TrimSpace(name))] = i + 6
+	}
+	openInterestCol := csvColumn(optional, "open_interest", "openinterest", "oi")
+	quoteVolumeCol := csvColumn(optional, "quote_volume", "quotevolume")
+	tradeCountCol := csvColumn(optional, "trade_count", "trades")
+	vwapCol := csvColumn(optional, "vwap")
+
 	// Read all records
 	records, err := reader.ReadAll()
 	if err != nil {
@@ -135,14 +199,28 @@ func (f *CSVFeed) GetData(startTime, endTime time.Time) ([]OHLCV, error) {
 		close, _ := strconv.ParseFloat(record[4], 64)
 		volume, _ := strconv.ParseFloat(record[5], 64)
 
-		data = append(data, OHLCV{
+		bar := OHLCV{
 			Time:   timestamp,
 			Open:   open,
 			High:   high,
 			Low:    low,
 			Close:  close,
 			Volume: volume,
-		})
+		}
+		if openInterestCol >= 0 && openInterestCol < len(record) {
+			bar.OpenInterest, _ = strconv.ParseFloat(record[openInterestCol], 64)
+		}
+		if quoteVolumeCol >= 0 && quoteVolumeCol < len(record) {
+			bar.QuoteVolume, _ = strconv.ParseFloat(record[quoteVolumeCol], 64)
+		}
+		if tradeCountCol >= 0 && tradeCountCol < len(record) {
+			bar.TradeCount, _ = strconv.ParseInt(record[tradeCountCol], 10, 64)
+		}
+		if vwapCol >= 0 && vwapCol < len(record) {
+			bar.VWAP, _ = strconv.ParseFloat(record[vwapCol], 64)
+		}
+
+		data = append(data, bar)
 	}
 
 	if len(data) == 0 {
@@ -186,26 +264,105 @@ func (f *BinanceFeed) GetData(startTime, endTime time.Time) ([]OHLCV, error) {
 	// Convert to OHLCV format
 	data := make([]OHLCV, len(klines))
 	for i, k := range klines {
-		openTime := int64(k[0].(float64))
-		open, _ := strconv.ParseFloat(k[1].(string), 64)
-		high, _ := strconv.ParseFloat(k[2].(string), 64)
-		low, _ := strconv.ParseFloat(k[3].(string), 64)
-		close, _ := strconv.ParseFloat(k[4].(string), 64)
-		volume, _ := strconv.ParseFloat(k[5].(string), 64)
+		if len(k) < 6 {
+			return nil, fmt.Errorf("Binance kline %d has %d fields, expected at least 6", i, len(k))
+		}
+		openTime, ok := k[0].(float64)
+		if !ok {
+			return nil, fmt.Errorf("Binance kline %d has an open time %v that is not a number", i, k[0])
+		}
+		var prices [5]float64
+		for j := range prices {
+			if prices[j], err = binanceFloat(k[j+1]); err != nil {
+				return nil, fmt.Errorf("failed to parse Binance kline %d: %w", i, err)
+			}
+		}
 
 		data[i] = OHLCV{
-			Time:   openTime / 1000, // Convert from milliseconds to seconds
-			Open:   open,
-			High:   high,
-			Low:    low,
-			Close:  close,
-			Volume: volume,
+			Time:   int64(openTime) / 1000, // Convert from milliseconds to seconds
+			Open:   prices[0],
+			High:   prices[1],
+			Low:    prices[2],
+			Close:  prices[3],
+			Volume: prices[4],
+		}
+
+		// Quote asset volume and number of trades
+		if len(k) > 8 {
+			if data[i].QuoteVolume, err = binanceFloat(k[7]); err != nil {
+				return nil, fmt.Errorf("failed to parse Binance kline %d: %w", i, err)
+			}
+			if trades, ok := k[8].(float64); ok {
+				data[i].TradeCount = int64(trades)
+			}
+		}
+		if data[i].Volume != 0 {
+			data[i].VWAP = data[i].QuoteVolume / data[i].Volume
 		}
 	}
 
 	return data, nil
 }
 
+// BinanceKlineEvent is a message of the Binance <symbol>@kline_<interval> websocket stream
+type BinanceKlineEvent struct {
+	EventType string `json:"e"`
+	EventTime int64  `json:"E"`
+	Symbol    string `json:"s"`
+	Kline     struct {
+		OpenTime    int64  `json:"t"`
+		CloseTime   int64  `json:"T"`
+		Interval    string `json:"i"`
+		Open        string `json:"o"`
+		High        string `json:"h"`
+		Low         string `json:"l"`
+		Close       string `json:"c"`
+		Volume      string `json:"v"`
+		TradeCount  int64  `json:"n"`
+		Closed      bool   `json:"x"`
+		QuoteVolume string `json:"q"`
+
+		// encoding/json matches keys regardless of case, so the keys that
+		// only differ in case from the ones above need their own fields
+		FirstTradeID        int64  `json:"f"`
+		LastTradeID         int64  `json:"L"`
+		TakerBuyVolume      string `json:"V"`
+		TakerBuyQuoteVolume string `json:"Q"`
+	} `json:"k"`
+}
+
+// ParseBinanceKline parses a kline stream message into a bar and whether the
+// bar is closed. Bars that are not closed are still forming and will be revised.
+func ParseBinanceKline(msg []byte) (OHLCV, bool, error) {
+	var event BinanceKlineEvent
+	if err := json.Unmarshal(msg, &event); err != nil {
+		return OHLCV{}, false, fmt.Errorf("failed to parse Binance kline: %w", err)
+	}
+
+	k := event.Kline
+	var prices [6]float64
+	for j, field := range []string{k.Open, k.High, k.Low, k.Close, k.Volume, k.QuoteVolume} {
+		var err error
+		if prices[j], err = binanceFloat(field); err != nil {
+			return OHLCV{}, false, fmt.Errorf("failed to parse Binance kline: %w", err)
+		}
+	}
+	bar := OHLCV{
+		Time:        k.OpenTime / 1000, // Convert from milliseconds to seconds
+		Open:        prices[0],
+		High:        prices[1],
+		Low:         prices[2],
+		Close:       prices[3],
+		Volume:      prices[4],
+		QuoteVolume: prices[5],
+		TradeCount:  k.TradeCount,
+	}
+	if bar.Volume != 0 {
+		bar.VWAP = bar.QuoteVolume / bar.Volume
+	}
+	return bar, k.Closed, nil
+}
+
 // GetData implements DataFeed interface for YahooFeed
 func (f *YahooFeed) GetData(startTime, endTime time.Time) ([]OHLCV, error) {
 	// Convert interval to Yahoo format
@@ -299,6 +456,46 @@ func GetOHLCVSlices(data []OHLCV) ([]float64, []float64, []float64, []float64, [
 	return open, high, low, close, volume
 }
 
+// GetFieldSlices converts OHLCV data to one slice per requested field
+func GetFieldSlices(data []OHLCV, fields ...Field) [][]float64 {
+	slices := make([][]float64, len(fields))
+	for f := range fields {
+		slices[f] = make([]float64, len(data))
+	}
+
+	for i, d := range data {
+		for f, field := range fields {
+			slices[f][i] = d.Value(field)
+		}
+	}
+
+	return slices
+}
+
+// GetFieldSlice converts OHLCV data to a slice of a single field
+func GetFieldSlice(data []OHLCV, field Field) []float64 {
+	return GetFieldSlices(data, field)[0]
+}
+
+// binanceFloat parses a decimal string field of a Binance kline
+func binanceFloat(v any) (float64, error) {
+	s, ok := v.(string)
+	if !ok {
+		return 0, fmt.Errorf("expected a decimal string, got %v", v)
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// csvColumn returns the index of the first matching optional column, or -1
+func csvColumn(columns map[string]int, names ...string) int {
+	for _, name := range names {
+		if i, ok := columns[name]; ok {
+			return i
+		}
+	}
+	return -1
+}
+
 // convertIntervalToYahoo converts standard interval notation to Yahoo format
 func convertIntervalToYahoo(interval string) string {
 	switch interval {
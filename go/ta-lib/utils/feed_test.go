@@ -0,0 +1,169 @@
+package utils_test
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testdata"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// The two first bars of data_0.csv, without the optional fields
+var firstBars = []utils.OHLCV{
+	{Time: 1711670400, Open: 70780.6, High: 70916.16, Low: 69009, Close: 69850.54, Volume: 25445.08353},
+	{Time: 1711756800, Open: 69850.53, High: 70321.1, Low: 69540, Close: 69582.18, Volume: 13644.61142},
+}
+
+func readCSV(t *testing.T, name string) []utils.OHLCV {
+	t.Helper()
+	data, err := utils.NewCSVFeed(testdata.Path(name)).GetData(time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	return data
+}
+
+func sameBars(t *testing.T, name string, got, want []utils.OHLCV) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: %d bars %+v, want %d", name, len(got), got, len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%s: bar %d is %+v, want %+v", name, i, got[i], want[i])
+		}
+	}
+}
+
+func TestCSVFeedRequiredColumns(t *testing.T) {
+	sameBars(t, "feed_required.csv", readCSV(t, "feed_required.csv"), firstBars)
+}
+
+// The optional columns are found by name, whatever their case, spacing or
+// position, and the unknown ones are ignored
+func TestCSVFeedOptionalColumns(t *testing.T) {
+	want := []utils.OHLCV{firstBars[0], firstBars[1]}
+	want[0].OpenInterest, want[0].TradeCount, want[0].QuoteVolume, want[0].VWAP = 1200.5, 51234, 1780000000.25, 69954.1
+	want[1].OpenInterest, want[1].TradeCount, want[1].QuoteVolume, want[1].VWAP = 1180, 40211, 951000000, 69700.2
+	sameBars(t, "feed_optional.csv", readCSV(t, "feed_optional.csv"), want)
+}
+
+// Rows without a valid time are skipped, and any other field that does not
+// parse is left at zero
+func TestCSVFeedMalformedRows(t *testing.T) {
+	want := []utils.OHLCV{
+		firstBars[0],
+		{Time: 1711843200, Open: 69582.17, High: 71366, Low: 69562.99, Volume: 19110.14, OpenInterest: 1150, TradeCount: 38000},
+		{Time: 1711929600, Open: 71280, High: 71288.23, Low: 68062.86, Close: 69649.8, Volume: 41445.32},
+	}
+	want[0].OpenInterest, want[0].TradeCount = 1200.5, 51234
+	sameBars(t, "feed_malformed.csv", readCSV(t, "feed_malformed.csv"), want)
+}
+
+func TestCSVFeedErrors(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"header.csv":  "date,open,high,low,close,volume\n1711670400,1,2,0.5,1.5,10\n",
+		"short.csv":   "time,open,high,low,close\n1711670400,1,2,0.5,1.5\n",
+		"empty.csv":   "",
+		"no_rows.csv": "time,open,high,low,close,volume\nnot a time,1,2,0.5,1.5,10\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := utils.NewCSVFeed(path).GetData(time.Time{}, time.Time{}); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+	for _, path := range []string{testdata.Path("feed_ragged.csv"), filepath.Join(dir, "missing.csv")} {
+		if _, err := utils.NewCSVFeed(path).GetData(time.Time{}, time.Time{}); err == nil {
+			t.Errorf("%s: no error", filepath.Base(path))
+		}
+	}
+}
+
+func TestParseBinanceKline(t *testing.T) {
+	msg, err := os.ReadFile(testdata.Path("binance_kline.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bar, closed, err := utils.ParseBinanceKline(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !closed {
+		t.Error("closed kline parsed as still forming")
+	}
+	want := utils.OHLCV{
+		Time: 1711670400, Open: 70780.6, High: 70812, Low: 70771.58, Close: 70801.02, Volume: 25.44508,
+		QuoteVolume: 1801234.5678, TradeCount: 1344,
+	}
+	if vwap := want.QuoteVolume / want.Volume; math.Abs(bar.VWAP-vwap) > 1e-9 {
+		t.Errorf("VWAP %v, want %v", bar.VWAP, vwap)
+	}
+	bar.VWAP = 0
+	if bar != want {
+		t.Errorf("kline parsed as %+v, want %+v", bar, want)
+	}
+
+	for _, msg := range []string{``, `{"k": []}`, `{"k": {"o": 1}}`} {
+		if _, _, err := utils.ParseBinanceKline([]byte(msg)); err == nil {
+			t.Errorf("%q: no error", msg)
+		}
+	}
+}
+
+func TestBinanceFeed(t *testing.T) {
+	payload, err := os.ReadFile(testdata.Path("binance_klines.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RequestURI()
+		switch r.URL.Query().Get("symbol") {
+		case "BTCUSDT":
+			w.Write(payload)
+		case "SHORT":
+			w.Write([]byte(`[[1711670400000, "1", "2"]]`))
+		case "QUOTE":
+			w.Write([]byte(`[[1711670400000, "1", "2", "0.5", "1.5", "10", 1711756799999, "x", 5]]`))
+		default:
+			w.Write([]byte(`[[1711670400000, 1, 2, 0.5, 1.5, 10]]`))
+		}
+	}))
+	defer server.Close()
+
+	start, end := time.Unix(1711670400, 0), time.Unix(1711843200, 0)
+	data, err := utils.NewBinanceFeedWithBaseURL("BTCUSDT", "1d", server.URL).GetData(start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/klines?symbol=BTCUSDT&interval=1d&startTime=1711670400000&endTime=1711843200000&limit=1000"; query != want {
+		t.Errorf("requested %s, want %s", query, want)
+	}
+	want := []utils.OHLCV{firstBars[0], firstBars[1]}
+	want[0].QuoteVolume, want[0].TradeCount = 1780064917.4218031, 2347102
+	want[1].QuoteVolume, want[1].TradeCount = 954289347.4091413, 1450301
+	for i := range want {
+		if vwap := want[i].QuoteVolume / want[i].Volume; math.Abs(data[i].VWAP-vwap) > 1e-9 {
+			t.Errorf("bar %d VWAP %v, want %v", i, data[i].VWAP, vwap)
+		}
+		data[i].VWAP = 0
+	}
+	sameBars(t, "binance_klines.json", data, want)
+
+	// Klines that are too short, not in strings or with a malformed quote volume are rejected
+	for _, symbol := range []string{"SHORT", "NUMBERS", "QUOTE"} {
+		if _, err := utils.NewBinanceFeedWithBaseURL(symbol, "1d", server.URL).GetData(start, end); err == nil {
+			t.Errorf("%s: no error", symbol)
+		}
+	}
+}
@@ -32,6 +32,23 @@ func IsZero(x float64) bool {
 	return math.Abs(x) < Epsilon
 }
 
+// TAIsZero mirrors TA_IS_ZERO from the C implementation, with a tighter
+// tolerance than IsZero
+func TAIsZero(x float64) bool {
+	return x > -1e-14 && x < 1e-14
+}
+
+// TAIsZeroOrNeg mirrors TA_IS_ZERO_OR_NEG from the C implementation
+func TAIsZeroOrNeg(x float64) bool {
+	return x < 1e-14
+}
+
+// PerToK converts a period to an EMA smoothing factor, as PER_TO_K of the
+// C implementation
+func PerToK(period int) float64 {
+	return 2.0 / float64(period+1)
+}
+
 // AreEqual checks if two float64 values are approximately equal
 func AreEqual(a, b float64) bool {
 	return math.Abs(a-b) < Epsilon
@@ -98,18 +115,23 @@ func Mean(values []float64) float64 {
 	return Sum(values) / float64(len(values))
 }
 
-// Variance calculates the variance of a slice of values
+// Variance calculates the population variance of a slice of values around
+// their mean. The squares are summed with compensation and corrected by the
+// residual of the mean, so large values with a small spread keep their
+// variance.
 func Variance(values []float64, mean float64) float64 {
 	if len(values) == 0 {
 		return math.NaN()
 	}
 
-	var sum float64
+	var squares, residual KahanSum
 	for _, v := range values {
 		diff := v - mean
-		sum += diff * diff
+		squares.Add(diff * diff)
+		residual.Add(diff)
 	}
-	return sum / float64(len(values))
+	r := residual.Total()
+	return max(squares.Total()-r*r/float64(len(values)), 0) / float64(len(values))
 }
 
 // StdDev calculates the standard deviation of a slice of values
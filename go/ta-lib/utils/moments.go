@@ -0,0 +1,227 @@
+package utils
+
+import "math"
+
+// KahanSum is a running sum with Neumaier's compensation of the rounding
+// errors, exact to the last bits over millions of terms
+type KahanSum struct {
+	Value        float64 // Rounded sum
+	Compensation float64 // Rounding errors lost by Value
+}
+
+// Add adds a term to the sum
+func (s *KahanSum) Add(x float64) {
+	t := s.Value + x
+	if math.Abs(s.Value) >= math.Abs(x) {
+		s.Compensation += (s.Value - t) + x
+	} else {
+		s.Compensation += (x - t) + s.Value
+	}
+	s.Value = t
+}
+
+// Total returns the compensated sum
+func (s KahanSum) Total() float64 {
+	return s.Value + s.Compensation
+}
+
+// RollingMoments holds the mean and the sum of squared deviations of a
+// window of values, updated with Welford's algorithm as values enter and
+// leave the window. The values are shifted by the first one and the updates
+// are compensated, so the variance stays accurate for large values with a
+// small spread, unlike the running sums of x and x*x. The fields are
+// exported to save the state.
+type RollingMoments struct {
+	N           int      // Number of values
+	Shift       float64  // First value of the window, subtracted from the values
+	ShiftedMean KahanSum // Mean of the shifted values
+	M2          KahanSum // Sum of the squared deviations from the mean
+}
+
+// Add adds a value to the window
+func (m *RollingMoments) Add(x float64) {
+	if m.N == 0 {
+		m.Shift = x
+	}
+	x -= m.Shift
+	m.N++
+	delta := x - m.ShiftedMean.Total()
+	m.ShiftedMean.Add(delta / float64(m.N))
+	m.M2.Add(delta * (x - m.ShiftedMean.Total()))
+}
+
+// Remove takes a value out of the window
+func (m *RollingMoments) Remove(x float64) {
+	if m.N <= 1 {
+		*m = RollingMoments{}
+		return
+	}
+	x -= m.Shift
+	delta := x - m.ShiftedMean.Total()
+	m.N--
+	m.ShiftedMean.Add(-delta / float64(m.N))
+	m.M2.Add(-delta * (x - m.ShiftedMean.Total()))
+}
+
+// Replace slides a full window, replacing its oldest value by a new one
+func (m *RollingMoments) Replace(oldest, x float64) {
+	if m.N == 0 {
+		m.Add(x)
+		return
+	}
+	oldest -= m.Shift
+	x -= m.Shift
+	prevMean := m.ShiftedMean.Total()
+	m.ShiftedMean.Add((x - oldest) / float64(m.N))
+	m.M2.Add((x - oldest) * (x - m.ShiftedMean.Total() + oldest - prevMean))
+}
+
+// deviation returns the deviation of a value from the mean, 0 without values
+func (m *RollingMoments) deviation(x float64) float64 {
+	if m.N == 0 {
+		return 0
+	}
+	return x - m.Shift - m.ShiftedMean.Total()
+}
+
+// Mean returns the mean of the values, NaN without values
+func (m *RollingMoments) Mean() float64 {
+	if m.N == 0 {
+		return math.NaN()
+	}
+	return m.Shift + m.ShiftedMean.Total()
+}
+
+// Variance returns the sum of squared deviations over N - ddof: the
+// population variance with ddof 0 and the sample variance with ddof 1, NaN
+// without enough values
+func (m *RollingMoments) Variance(ddof int) float64 {
+	if m.N <= ddof {
+		return math.NaN()
+	}
+	return max(m.M2.Total(), 0) / float64(m.N-ddof)
+}
+
+// StdDev returns the square root of the variance
+func (m *RollingMoments) StdDev(ddof int) float64 {
+	return math.Sqrt(m.Variance(ddof))
+}
+
+// WeightedMoments holds the weighted mean and the weighted sum of squared
+// deviations of values, such as the prices of a VWAP weighted by their
+// volumes, updated with West's algorithm. The values are shifted by the
+// first one and the updates are compensated like RollingMoments, so the
+// deviation does not cancel out like with the sums of w*x and w*x*x.
+type WeightedMoments struct {
+	N           int      // Number of values
+	Weight      KahanSum // Sum of the weights
+	Shift       float64  // First value, subtracted from the values
+	ShiftedMean KahanSum // Weighted mean of the shifted values
+	M2          KahanSum // Weighted sum of the squared deviations from the mean
+}
+
+// Add adds a value with its weight, which must not be negative
+func (m *WeightedMoments) Add(x, weight float64) {
+	if m.N == 0 {
+		m.Shift = x
+	}
+	m.N++
+	m.Weight.Add(weight)
+	total := m.Weight.Total()
+	if weight == 0 || total <= 0 {
+		return
+	}
+	x -= m.Shift
+	delta := x - m.ShiftedMean.Total()
+	m.ShiftedMean.Add(delta * weight / total)
+	m.M2.Add(weight * delta * (x - m.ShiftedMean.Total()))
+}
+
+// Mean returns the weighted mean, NaN without values
+func (m *WeightedMoments) Mean() float64 {
+	if m.N == 0 {
+		return math.NaN()
+	}
+	return m.Shift + m.ShiftedMean.Total()
+}
+
+// Variance returns the weighted population variance, NaN without weight
+func (m *WeightedMoments) Variance() float64 {
+	total := m.Weight.Total()
+	if total <= 0 {
+		return math.NaN()
+	}
+	return max(m.M2.Total(), 0) / total
+}
+
+// RollingCovariance holds the moments of a window of pairs of values and
+// the sum of the products of their deviations, for covariance, correlation
+// and linear regression. The updates are compensated like RollingMoments.
+type RollingCovariance struct {
+	X, Y RollingMoments
+	C    KahanSum // Sum of the products of the deviations from the means
+}
+
+// Add adds a pair to the window
+func (c *RollingCovariance) Add(x, y float64) {
+	dx := c.X.deviation(x)
+	c.X.Add(x)
+	c.Y.Add(y)
+	c.C.Add(dx * c.Y.deviation(y))
+}
+
+// Remove takes a pair out of the window
+func (c *RollingCovariance) Remove(x, y float64) {
+	if c.X.N <= 1 {
+		*c = RollingCovariance{}
+		return
+	}
+	dy := c.Y.deviation(y)
+	c.X.Remove(x)
+	c.Y.Remove(y)
+	c.C.Add(-c.X.deviation(x) * dy)
+}
+
+// Replace slides a full window, replacing its oldest pair by a new one
+func (c *RollingCovariance) Replace(oldestX, oldestY, x, y float64) {
+	if c.X.N == 0 {
+		c.Add(x, y)
+		return
+	}
+	dx, oldDx := c.X.deviation(x), c.X.deviation(oldestX)
+	c.X.Replace(oldestX, x)
+	c.Y.Replace(oldestY, y)
+	c.C.Add(dx*c.Y.deviation(y) - oldDx*c.Y.deviation(oldestY))
+}
+
+// Covariance returns the sum of the products of the deviations over N - ddof
+func (c *RollingCovariance) Covariance(ddof int) float64 {
+	if c.X.N <= ddof {
+		return math.NaN()
+	}
+	return c.C.Total() / float64(c.X.N-ddof)
+}
+
+// Correlation returns the Pearson correlation of the pairs, 0 when either
+// series does not vary
+func (c *RollingCovariance) Correlation() float64 {
+	sx, sy := max(c.X.M2.Total(), 0), max(c.Y.M2.Total(), 0)
+	if sx == 0 || sy == 0 {
+		return 0
+	}
+	return max(-1, min(1, c.C.Total()/math.Sqrt(sx*sy)))
+}
+
+// Slope returns the least squares slope of Y on X, 0 when X does not vary
+func (c *RollingCovariance) Slope() float64 {
+	sx := c.X.M2.Total()
+	if sx <= 0 {
+		return 0
+	}
+	return c.C.Total() / sx
+}
+
+// Intercept returns the least squares intercept of Y on X
+func (c *RollingCovariance) Intercept() float64 {
+	return c.Y.Mean() - c.Slope()*c.X.Mean()
+}
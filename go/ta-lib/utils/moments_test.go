@@ -0,0 +1,87 @@
+package utils_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testdata"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// bigMoments returns the population variance of x and the covariance and
+// regression slope of y on x, computed with 256 bit floats
+func bigMoments(x, y []float64) (variance, covariance, slope float64) {
+	newFloat := func() *big.Float { return new(big.Float).SetPrec(256) }
+	n := newFloat().SetInt64(int64(len(x)))
+	mx, my := newFloat(), newFloat()
+	for i := range x {
+		mx.Add(mx, newFloat().SetFloat64(x[i]))
+		my.Add(my, newFloat().SetFloat64(y[i]))
+	}
+	mx.Quo(mx, n)
+	my.Quo(my, n)
+	sxx, sxy := newFloat(), newFloat()
+	for i := range x {
+		dx := newFloat().Sub(newFloat().SetFloat64(x[i]), mx)
+		dy := newFloat().Sub(newFloat().SetFloat64(y[i]), my)
+		sxx.Add(sxx, newFloat().Mul(dx, dx))
+		sxy.Add(sxy, newFloat().Mul(dx, dy))
+	}
+	variance, _ = newFloat().Quo(sxx, n).Float64()
+	covariance, _ = newFloat().Quo(sxy, n).Float64()
+	slope, _ = newFloat().Quo(sxy, sxx).Float64()
+	return
+}
+
+// The rolling moments keep their precision on large values with a tiny
+// spread over long series, where the running sums of x and x*x lose it
+func TestRollingCovariancePrecision(t *testing.T) {
+	const n = 100000
+	bars := testdata.TightOHLCV(n)
+	x, y := make([]float64, n), make([]float64, n)
+	for i, bar := range bars {
+		x[i] = bar.Close
+		y[i] = 3*x[i] - 140000 + 0.0005*math.Sin(float64(i)*12.9898)
+	}
+
+	for _, period := range []int{20, 500} {
+		var c utils.RollingCovariance
+		worstNaive := 0.0
+		for i := 0; i < n; i++ {
+			if i >= period {
+				c.Replace(x[i-period], y[i-period], x[i], y[i])
+			} else {
+				c.Add(x[i], y[i])
+			}
+			if i < period-1 || i%997 != 0 && i != n-1 {
+				continue
+			}
+			wx, wy := x[i-period+1:i+1], y[i-period+1:i+1]
+			variance, covariance, slope := bigMoments(wx, wy)
+			for _, tc := range []struct {
+				name      string
+				got, want float64
+			}{
+				{"rolling variance", c.X.Variance(0), variance},
+				{"rolling covariance", c.Covariance(0), covariance},
+				{"rolling slope", c.Slope(), slope},
+			} {
+				if math.Abs(tc.got-tc.want) > 1e-7*math.Abs(tc.want) {
+					t.Fatalf("%s(%d) at bar %d is %v, want %v", tc.name, period, i, tc.got, tc.want)
+				}
+			}
+
+			var sum, sum2 float64
+			for _, v := range wx {
+				sum += v
+				sum2 += v * v
+			}
+			naive := sum2/float64(period) - (sum/float64(period))*(sum/float64(period))
+			worstNaive = max(worstNaive, math.Abs(naive-variance)/variance)
+		}
+		if worstNaive < 1e-3 {
+			t.Errorf("running sums keep the variance of period %d within %v, the series is too easy", period, worstNaive)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+)
+
+// Timeframe is a calendar period that bars are resampled to
+type Timeframe int
+
+const (
+	Daily   Timeframe = iota // Days from midnight
+	Weekly                   // Weeks from Monday midnight
+	Monthly                  // Calendar months
+)
+
+// String returns the name of the timeframe
+func (t Timeframe) String() string {
+	switch t {
+	case Daily:
+		return "daily"
+	case Weekly:
+		return "weekly"
+	case Monthly:
+		return "monthly"
+	}
+	return fmt.Sprintf("Timeframe(%d)", int(t))
+}
+
+// PeriodStart returns the start of the period containing time t, in Unix
+// seconds. Days start at midnight in loc, or in UTC when loc is nil.
+func (t Timeframe) PeriodStart(unix int64, loc *time.Location) int64 {
+	if loc == nil {
+		loc = time.UTC
+	}
+	at := time.Unix(unix, 0).In(loc)
+	year, month, day := at.Date()
+	switch t {
+	case Weekly:
+		day -= (int(at.Weekday()) + 6) % 7
+	case Monthly:
+		day = 1
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, loc).Unix()
+}
+
+// Resample aggregates bars into daily, weekly or monthly bars stamped with
+// the start of their period. Open is the first open, High and Low the
+// extremes, Close and OpenInterest the last values, Volume, QuoteVolume and
+// TradeCount the sums, and VWAP the volume-weighted average of the VWAPs.
+// Periods start at midnight in loc, or in UTC when loc is nil. The last bar
+// covers the last period even when it is not over yet.
+func Resample(data []OHLCV, timeframe Timeframe, loc *time.Location) ([]OHLCV, error) {
+	if timeframe < Daily || timeframe > Monthly {
+		return nil, fmt.Errorf("unknown timeframe %d: %w", timeframe, ErrInvalidParameter)
+	}
+	var out []OHLCV
+	var vwapSum, vwapVolume []float64 // Per period, over the bars with a VWAP
+	for _, d := range data {
+		start := timeframe.PeriodStart(d.Time, loc)
+		n := len(out)
+		if n > 0 && out[n-1].Time > start {
+			return nil, fmt.Errorf("bar at %d is before the previous period: %w", d.Time, ErrInvalidParameter)
+		}
+		if n == 0 || out[n-1].Time != start {
+			out = append(out, d)
+			out[n].Time = start
+			vwapSum, vwapVolume = append(vwapSum, 0), append(vwapVolume, 0)
+		} else {
+			p := &out[n-1]
+			p.High = max(p.High, d.High)
+			p.Low = min(p.Low, d.Low)
+			p.Close = d.Close
+			p.Volume += d.Volume
+			p.QuoteVolume += d.QuoteVolume
+			p.TradeCount += d.TradeCount
+			p.OpenInterest = d.OpenInterest
+		}
+		if d.VWAP != 0 {
+			vwapSum[len(out)-1] += d.VWAP * d.Volume
+			vwapVolume[len(out)-1] += d.Volume
+		}
+	}
+	for k := range out {
+		out[k].VWAP = 0
+		if vwapVolume[k] > 0 {
+			out[k].VWAP = vwapSum[k] / vwapVolume[k]
+		}
+	}
+	return out, nil
+}
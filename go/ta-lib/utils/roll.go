@@ -0,0 +1,286 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// RollRule determines when a continuous series moves to the next contract
+type RollRule int
+
+const (
+	RollOnDate         RollRule = iota // Roll at each contract's RollTime
+	RollOnVolume                       // Roll when the next contract's volume exceeds the active one
+	RollOnOpenInterest                 // Roll when the next contract's open interest exceeds the active one
+)
+
+// AdjustMethod determines how prices before a roll are back-adjusted
+type AdjustMethod int
+
+const (
+	AdjustNone   AdjustMethod = iota // Raw stitching, roll gaps are left in the series
+	AdjustPanama                     // Add the roll gap (difference) to all prior prices
+	AdjustRatio                      // Multiply all prior prices by the roll ratio
+)
+
+// Contract holds the bars of a single futures contract
+type Contract struct {
+	Symbol   string
+	Expiry   int64   // Expiry time in Unix seconds (optional)
+	RollTime int64   // Time from which the next contract takes over, defaults to Expiry
+	Data     []OHLCV // Bars sorted by time
+}
+
+// RollConfig configures how contracts are stitched together
+type RollConfig struct {
+	Rule   RollRule
+	Adjust AdjustMethod
+}
+
+// RollPoint records a switch from one contract to the next
+type RollPoint struct {
+	Index    int     // Index in the continuous series of the first bar from the new contract
+	Time     int64   // Time of the last bar served by the old contract
+	From     string  // Symbol of the old contract
+	To       string  // Symbol of the new contract
+	OldPrice float64 // Close of the old contract at Time
+	NewPrice float64 // Close of the new contract at Time
+	Gap      float64 // NewPrice - OldPrice, used by Panama adjustment
+	Ratio    float64 // NewPrice / OldPrice, used by ratio adjustment
+}
+
+// ContinuousSeries is a stitched series together with its roll points
+type ContinuousSeries struct {
+	Data  []OHLCV
+	Rolls []RollPoint
+}
+
+// StitchContracts builds a continuous series from contracts ordered by expiry
+func StitchContracts(contracts []Contract, config RollConfig) (*ContinuousSeries, error) {
+	if len(contracts) == 0 {
+		return nil, fmt.Errorf("no contracts to stitch: %w", ErrEmptyInputData)
+	}
+	if config.Rule < RollOnDate || config.Rule > RollOnOpenInterest {
+		return nil, fmt.Errorf("unknown roll rule %d: %w", config.Rule, ErrInvalidParameter)
+	}
+	if config.Adjust < AdjustNone || config.Adjust > AdjustRatio {
+		return nil, fmt.Errorf("unknown adjust method %d: %w", config.Adjust, ErrInvalidParameter)
+	}
+	series := &ContinuousSeries{}
+	start := 0 // First bar of the active contract to emit
+
+	for active := 0; active < len(contracts); active++ {
+		cur := contracts[active]
+		if active == len(contracts)-1 {
+			series.Data = append(series.Data, cur.Data[start:]...)
+			break
+		}
+		next := contracts[active+1]
+		rollTime := cur.rollTime()
+
+		// Emit bars from the active contract until a roll is decided
+		emitted := 0
+		rolled := false
+		for i := start; i < len(cur.Data); i++ {
+			if config.Rule == RollOnDate && rollTime != 0 && cur.Data[i].Time >= rollTime {
+				break
+			}
+			series.Data = append(series.Data, cur.Data[i])
+			emitted++
+
+			if config.Rule != RollOnDate {
+				j, ok := findBar(next.Data, cur.Data[i].Time)
+				if ok && rollMetric(next, j, config.Rule) > rollMetric(cur, i, config.Rule) {
+					series.addRoll(cur.Symbol, next, cur.Data[i].Time)
+					rolled = true
+					break
+				}
+			}
+		}
+
+		if !rolled {
+			// Rolling on date, or the active contract ran out of bars
+			if len(series.Data) == 0 {
+				continue
+			}
+			from := cur.Symbol
+			if emitted == 0 && len(series.Rolls) > 0 {
+				// The active contract was skipped entirely, roll straight from the previous one
+				from = series.Rolls[len(series.Rolls)-1].From
+				series.Rolls = series.Rolls[:len(series.Rolls)-1]
+			}
+			series.addRoll(from, next, series.lastTime())
+		}
+
+		// Continue with bars of the next contract after the roll time
+		t := series.lastTime()
+		start = sort.Search(len(next.Data), func(k int) bool { return next.Data[k].Time > t })
+	}
+
+	if len(series.Data) == 0 {
+		return nil, fmt.Errorf("no bars found in contracts: %w", ErrEmptyInputData)
+	}
+
+	series.backAdjust(config.Adjust)
+	return series, nil
+}
+
+// lastTime returns the time of the last emitted bar
+func (s *ContinuousSeries) lastTime() int64 {
+	if len(s.Data) == 0 {
+		return 0
+	}
+	return s.Data[len(s.Data)-1].Time
+}
+
+// addRoll records a roll to the next contract decided at time t
+func (s *ContinuousSeries) addRoll(from string, next Contract, t int64) {
+	oldPrice := s.Data[len(s.Data)-1].Close
+	newPrice, ok := closeAt(next.Data, t)
+	if !ok {
+		// The new contract did not trade yet, use the open of its first bar after t
+		k := sort.Search(len(next.Data), func(k int) bool { return next.Data[k].Time > t })
+		if k < len(next.Data) {
+			newPrice = next.Data[k].Open
+		} else {
+			newPrice = oldPrice
+		}
+	}
+
+	ratio := 1.0
+	if oldPrice != 0 {
+		ratio = newPrice / oldPrice
+	}
+
+	s.Rolls = append(s.Rolls, RollPoint{
+		Index:    len(s.Data),
+		Time:     t,
+		From:     from,
+		To:       next.Symbol,
+		OldPrice: oldPrice,
+		NewPrice: newPrice,
+		Gap:      newPrice - oldPrice,
+		Ratio:    ratio,
+	})
+}
+
+// backAdjust adjusts prices before each roll so the latest contract is unadjusted
+func (s *ContinuousSeries) backAdjust(method AdjustMethod) {
+	if method == AdjustNone || len(s.Rolls) == 0 {
+		return
+	}
+
+	offset := 0.0
+	factor := 1.0
+	r := len(s.Rolls) - 1
+	for i := len(s.Data) - 1; i >= 0; i-- {
+		for r >= 0 && i < s.Rolls[r].Index {
+			offset += s.Rolls[r].Gap
+			factor *= s.Rolls[r].Ratio
+			r--
+		}
+
+		// A zero VWAP is a missing one and stays zero
+		bar := &s.Data[i]
+		switch method {
+		case AdjustPanama:
+			bar.Open += offset
+			bar.High += offset
+			bar.Low += offset
+			bar.Close += offset
+			if bar.VWAP != 0 {
+				bar.VWAP += offset
+			}
+		case AdjustRatio:
+			bar.Open *= factor
+			bar.High *= factor
+			bar.Low *= factor
+			bar.Close *= factor
+			bar.VWAP *= factor
+		}
+	}
+}
+
+// rollTime returns the time from which the next contract takes over, or 0 if unknown
+func (c Contract) rollTime() int64 {
+	if c.RollTime != 0 {
+		return c.RollTime
+	}
+	return c.Expiry
+}
+
+// rollMetric returns the value compared by crossover roll rules
+func rollMetric(c Contract, i int, rule RollRule) float64 {
+	if rule == RollOnOpenInterest {
+		return c.Data[i].OpenInterest
+	}
+	return c.Data[i].Volume
+}
+
+// findBar returns the index of the bar at exactly time t
+func findBar(data []OHLCV, t int64) (int, bool) {
+	i := sort.Search(len(data), func(k int) bool { return data[k].Time >= t })
+	if i < len(data) && data[i].Time == t {
+		return i, true
+	}
+	return 0, false
+}
+
+// closeAt returns the close of the last bar at or before time t
+func closeAt(data []OHLCV, t int64) (float64, bool) {
+	i := sort.Search(len(data), func(k int) bool { return data[k].Time > t })
+	if i == 0 {
+		return 0, false
+	}
+	return data[i-1].Close, true
+}
+
+// ContractFeed pairs a contract with the feed providing its bars
+type ContractFeed struct {
+	Symbol   string
+	Expiry   int64
+	RollTime int64
+	Feed     DataFeed
+}
+
+// ContinuousFeed implements DataFeed by stitching several contract feeds
+type ContinuousFeed struct {
+	Contracts []ContractFeed
+	Config    RollConfig
+}
+
+// NewContinuousFeed creates a new continuous futures data feed
+func NewContinuousFeed(contracts []ContractFeed, config RollConfig) *ContinuousFeed {
+	return &ContinuousFeed{
+		Contracts: contracts,
+		Config:    config,
+	}
+}
+
+// GetData implements DataFeed interface for ContinuousFeed
+func (f *ContinuousFeed) GetData(startTime, endTime time.Time) ([]OHLCV, error) {
+	series, err := f.GetSeries(startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	return series.Data, nil
+}
+
+// GetSeries retrieves the stitched series together with its roll points
+func (f *ContinuousFeed) GetSeries(startTime, endTime time.Time) (*ContinuousSeries, error) {
+	contracts := make([]Contract, len(f.Contracts))
+	for i, cf := range f.Contracts {
+		data, err := cf.Feed.GetData(startTime, endTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch contract %s: %w", cf.Symbol, err)
+		}
+		contracts[i] = Contract{
+			Symbol:   cf.Symbol,
+			Expiry:   cf.Expiry,
+			RollTime: cf.RollTime,
+			Data:     data,
+		}
+	}
+	return StitchContracts(contracts, f.Config)
+}
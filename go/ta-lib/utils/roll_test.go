@@ -0,0 +1,296 @@
+package utils_test
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// contract returns a contract with a bar at each time, closing at the first
+// close plus the bar number, with the given volumes and open interests
+func contract(symbol string, times []int64, firstClose float64, volume, openInterest []float64) utils.Contract {
+	c := utils.Contract{Symbol: symbol}
+	for i, t := range times {
+		close := firstClose + float64(i)
+		bar := utils.OHLCV{Time: t, Open: close - 0.5, High: close + 1, Low: close - 1, Close: close, VWAP: close + 0.25}
+		if volume != nil {
+			bar.Volume = volume[i]
+		}
+		if openInterest != nil {
+			bar.OpenInterest = openInterest[i]
+		}
+		c.Data = append(c.Data, bar)
+	}
+	return c
+}
+
+func closes(data []utils.OHLCV) []float64 {
+	out := make([]float64, len(data))
+	for i, d := range data {
+		out[i] = d.Close
+	}
+	return out
+}
+
+func times(data []utils.OHLCV) []int64 {
+	out := make([]int64, len(data))
+	for i, d := range data {
+		out[i] = d.Time
+	}
+	return out
+}
+
+func sameCloses(t *testing.T, name string, got, want []float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: %d closes %v, want %d %v", name, len(got), got, len(want), want)
+	}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Fatalf("%s: close %d is %v, want %v", name, i, got[i], want[i])
+		}
+	}
+}
+
+func sameRolls(t *testing.T, name string, got, want []utils.RollPoint) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: rolls %+v, want %+v", name, got, want)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Index != w.Index || g.Time != w.Time || g.From != w.From || g.To != w.To ||
+			g.OldPrice != w.OldPrice || g.NewPrice != w.NewPrice ||
+			math.Abs(g.Gap-w.Gap) > 1e-9 || math.Abs(g.Ratio-w.Ratio) > 1e-12 {
+			t.Errorf("%s: roll %d is %+v, want %+v", name, i, g, w)
+		}
+	}
+}
+
+// Every rule rolls from A to B after bar 3, where B closes 10 above A
+func TestStitchRollRules(t *testing.T) {
+	steady := []float64{10, 10, 10, 10, 10, 10, 10, 10}
+	rising := []float64{1, 5, 20, 30, 40, 50, 60, 70}
+	for _, tc := range []struct {
+		name string
+		rule utils.RollRule
+		a, b utils.Contract
+	}{
+		{
+			name: "date",
+			rule: utils.RollOnDate,
+			a:    utils.Contract{Symbol: "A", RollTime: 4, Data: contract("A", []int64{1, 2, 3, 4, 5}, 100, nil, nil).Data},
+			b:    contract("B", []int64{1, 2, 3, 4, 5, 6, 7, 8}, 110, nil, nil),
+		},
+		{
+			name: "expiry",
+			rule: utils.RollOnDate,
+			a:    utils.Contract{Symbol: "A", Expiry: 4, Data: contract("A", []int64{1, 2, 3, 4, 5}, 100, nil, nil).Data},
+			b:    contract("B", []int64{1, 2, 3, 4, 5, 6, 7, 8}, 110, nil, nil),
+		},
+		{
+			name: "volume",
+			rule: utils.RollOnVolume,
+			a:    contract("A", []int64{1, 2, 3, 4, 5}, 100, steady, nil),
+			b:    contract("B", []int64{1, 2, 3, 4, 5, 6, 7, 8}, 110, rising, nil),
+		},
+		{
+			name: "open interest",
+			rule: utils.RollOnOpenInterest,
+			a:    contract("A", []int64{1, 2, 3, 4, 5}, 100, rising, steady),
+			b:    contract("B", []int64{1, 2, 3, 4, 5, 6, 7, 8}, 110, steady, rising),
+		},
+	} {
+		series, err := utils.StitchContracts([]utils.Contract{tc.a, tc.b}, utils.RollConfig{Rule: tc.rule})
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got := times(series.Data); !slices.Equal(got, []int64{1, 2, 3, 4, 5, 6, 7, 8}) {
+			t.Errorf("%s: times %v", tc.name, got)
+		}
+		sameCloses(t, tc.name, closes(series.Data), []float64{100, 101, 102, 113, 114, 115, 116, 117})
+		sameRolls(t, tc.name, series.Rolls, []utils.RollPoint{
+			{Index: 3, Time: 3, From: "A", To: "B", OldPrice: 102, NewPrice: 112, Gap: 10, Ratio: 112.0 / 102},
+		})
+	}
+}
+
+func TestStitchAdjustments(t *testing.T) {
+	contracts := []utils.Contract{
+		{Symbol: "A", RollTime: 3, Data: contract("A", []int64{1, 2, 3}, 100, nil, nil).Data},
+		{Symbol: "B", RollTime: 5, Data: contract("B", []int64{1, 2, 3, 4, 5}, 120, nil, nil).Data},
+		contract("C", []int64{3, 4, 5, 6}, 90, nil, nil),
+	}
+	contracts[0].Data[1].VWAP = 0
+	// A rolls to B after bar 2 at 101 for 121, B to C after bar 4 at 123 for 91
+	wantRolls := []utils.RollPoint{
+		{Index: 2, Time: 2, From: "A", To: "B", OldPrice: 101, NewPrice: 121, Gap: 20, Ratio: 121.0 / 101},
+		{Index: 4, Time: 4, From: "B", To: "C", OldPrice: 123, NewPrice: 91, Gap: -32, Ratio: 91.0 / 123},
+	}
+	for _, tc := range []struct {
+		name   string
+		adjust utils.AdjustMethod
+		want   []float64
+	}{
+		{"none", utils.AdjustNone, []float64{100, 101, 122, 123, 92, 93}},
+		{"difference", utils.AdjustPanama, []float64{100 - 12, 101 - 12, 122 - 32, 123 - 32, 92, 93}},
+		{"ratio", utils.AdjustRatio, []float64{
+			100 * 121.0 / 101 * 91 / 123, 121 * 91.0 / 123, 122 * 91.0 / 123, 91, 92, 93,
+		}},
+	} {
+		input := make([]utils.Contract, len(contracts))
+		for i, c := range contracts {
+			input[i] = c
+			input[i].Data = slices.Clone(c.Data)
+		}
+		series, err := utils.StitchContracts(input, utils.RollConfig{Rule: utils.RollOnDate, Adjust: tc.adjust})
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		sameCloses(t, tc.name, closes(series.Data), tc.want)
+		sameRolls(t, tc.name, series.Rolls, wantRolls)
+
+		// The open, high, low and VWAP move with the close, except a missing VWAP
+		raw := []float64{100, 101, 122, 123, 92, 93}
+		for i, d := range series.Data {
+			adjust := func(price float64) float64 { return price + tc.want[i] - raw[i] }
+			if tc.adjust == utils.AdjustRatio {
+				adjust = func(price float64) float64 { return price * tc.want[i] / raw[i] }
+			}
+			if math.Abs(d.Open-adjust(raw[i]-0.5)) > 1e-9 || math.Abs(d.High-adjust(raw[i]+1)) > 1e-9 || math.Abs(d.Low-adjust(raw[i]-1)) > 1e-9 {
+				t.Errorf("%s: bar %d is %+v for a close of %v", tc.name, i, d, raw[i])
+			}
+			vwap := adjust(raw[i] + 0.25)
+			if i == 1 {
+				vwap = 0
+			}
+			if math.Abs(d.VWAP-vwap) > 1e-9 || (vwap != 0 && (d.VWAP < d.Low || d.VWAP > d.High)) {
+				t.Errorf("%s: bar %d has a VWAP of %v, want %v", tc.name, i, d.VWAP, vwap)
+			}
+		}
+
+		// The contracts given are left untouched
+		for i := range contracts {
+			if !slices.Equal(input[i].Data, contracts[i].Data) {
+				t.Errorf("%s: contract %s was modified", tc.name, contracts[i].Symbol)
+			}
+		}
+	}
+}
+
+// A contract whose roll time passes before it serves any bar is skipped
+func TestStitchSkippedContract(t *testing.T) {
+	series, err := utils.StitchContracts([]utils.Contract{
+		{Symbol: "A", RollTime: 4, Data: contract("A", []int64{1, 2, 3, 4}, 100, nil, nil).Data},
+		{Symbol: "B", Expiry: 4, Data: contract("B", []int64{2, 3, 4}, 200, nil, nil).Data},
+		contract("C", []int64{1, 2, 3, 4, 5}, 300, nil, nil),
+	}, utils.RollConfig{Rule: utils.RollOnDate, Adjust: utils.AdjustPanama})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameRolls(t, "skipped B", series.Rolls, []utils.RollPoint{
+		{Index: 3, Time: 3, From: "A", To: "C", OldPrice: 102, NewPrice: 302, Gap: 200, Ratio: 302.0 / 102},
+	})
+	sameCloses(t, "skipped B", closes(series.Data), []float64{300, 301, 302, 303, 304})
+}
+
+// Overlapping bars are served once, and a next contract that has not traded
+// yet at the roll is taken at the open of its first bar
+func TestStitchOverlapAndGap(t *testing.T) {
+	series, err := utils.StitchContracts([]utils.Contract{
+		contract("A", []int64{1, 2, 3}, 100, nil, nil),
+		contract("B", []int64{5, 6}, 110, nil, nil),
+	}, utils.RollConfig{Rule: utils.RollOnDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameRolls(t, "gap", series.Rolls, []utils.RollPoint{
+		{Index: 3, Time: 3, From: "A", To: "B", OldPrice: 102, NewPrice: 109.5, Gap: 7.5, Ratio: 109.5 / 102},
+	})
+	if got := times(series.Data); !slices.Equal(got, []int64{1, 2, 3, 5, 6}) {
+		t.Errorf("gap: times %v", got)
+	}
+
+	// B overlaps A entirely and takes over on volume at bar 2
+	series, err = utils.StitchContracts([]utils.Contract{
+		contract("A", []int64{1, 2, 3, 4}, 100, []float64{5, 5, 5, 5}, nil),
+		contract("B", []int64{0, 1, 2, 3, 4}, 110, []float64{1, 1, 9, 1, 1}, nil),
+	}, utils.RollConfig{Rule: utils.RollOnVolume})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := times(series.Data); !slices.Equal(got, []int64{1, 2, 3, 4}) {
+		t.Errorf("overlap: times %v", got)
+	}
+	sameCloses(t, "overlap", closes(series.Data), []float64{100, 101, 113, 114})
+	sameRolls(t, "overlap", series.Rolls, []utils.RollPoint{
+		{Index: 2, Time: 2, From: "A", To: "B", OldPrice: 101, NewPrice: 112, Gap: 11, Ratio: 112.0 / 101},
+	})
+}
+
+func TestStitchErrors(t *testing.T) {
+	a := contract("A", []int64{1, 2}, 100, nil, nil)
+	for _, tc := range []struct {
+		name      string
+		contracts []utils.Contract
+		config    utils.RollConfig
+		want      error
+	}{
+		{"no contracts", nil, utils.RollConfig{}, utils.ErrEmptyInputData},
+		{"no bars", []utils.Contract{{Symbol: "A"}, {Symbol: "B"}}, utils.RollConfig{}, utils.ErrEmptyInputData},
+		{"rule", []utils.Contract{a}, utils.RollConfig{Rule: utils.RollOnOpenInterest + 1}, utils.ErrInvalidParameter},
+		{"adjust", []utils.Contract{a}, utils.RollConfig{Adjust: -1}, utils.ErrInvalidParameter},
+	} {
+		if _, err := utils.StitchContracts(tc.contracts, tc.config); !errors.Is(err, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+// sliceFeed serves fixed bars, or fails
+type sliceFeed struct {
+	data []utils.OHLCV
+	err  error
+}
+
+func (f sliceFeed) GetData(startTime, endTime time.Time) ([]utils.OHLCV, error) {
+	return f.data, f.err
+}
+
+func TestContinuousFeed(t *testing.T) {
+	a := contract("A", []int64{1, 2, 3, 4, 5}, 100, nil, nil)
+	b := contract("B", []int64{1, 2, 3, 4, 5, 6, 7, 8}, 110, nil, nil)
+	config := utils.RollConfig{Rule: utils.RollOnDate, Adjust: utils.AdjustPanama}
+	feed := utils.NewContinuousFeed([]utils.ContractFeed{
+		{Symbol: "A", RollTime: 4, Feed: sliceFeed{data: slices.Clone(a.Data)}},
+		{Symbol: "B", Feed: sliceFeed{data: slices.Clone(b.Data)}},
+	}, config)
+	series, err := feed.GetSeries(time.Unix(0, 0), time.Unix(10, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.RollTime = 4
+	want, err := utils.StitchContracts([]utils.Contract{a, b}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(series.Data, want.Data) {
+		t.Errorf("feed bars %v, want %v", series.Data, want.Data)
+	}
+	sameRolls(t, "feed", series.Rolls, want.Rolls)
+
+	data, err := feed.GetData(time.Unix(0, 0), time.Unix(10, 0))
+	if err != nil || !slices.Equal(data, want.Data) {
+		t.Errorf("GetData returned %v, %v", data, err)
+	}
+
+	failing := errors.New("connection reset")
+	feed.Contracts[1].Feed = sliceFeed{err: failing}
+	if _, err := feed.GetData(time.Unix(0, 0), time.Unix(10, 0)); !errors.Is(err, failing) {
+		t.Errorf("failing contract feed: got %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"math"
+	"sort"
+)
+
+// Series is a sequence of values indexed by time.
+//
+// Time is in Unix seconds, sorted in ascending order, with one value per
+// timestamp. NaN marks a timestamp without a value.
+type Series struct {
+	Time   []int64
+	Values []float64
+}
+
+// Padding selects how indicator outputs are mapped onto the input bars
+type Padding int
+
+const (
+	NoPadding Padding = iota // Only the bars with an output value
+	PadNaN                   // One value per input bar, NaN during the lookback period
+)
+
+// NewSeries creates a Series from parallel time and value slices
+func NewSeries(times []int64, values []float64) (*Series, error) {
+	if len(times) != len(values) {
+		return nil, ErrMismatchedInputLengths
+	}
+	return &Series{Time: times, Values: values}, nil
+}
+
+// Len returns the number of values
+func (s *Series) Len() int {
+	return len(s.Time)
+}
+
+// Index returns the index of the given time, or -1
+func (s *Series) Index(t int64) int {
+	i := sort.Search(s.Len(), func(k int) bool { return s.Time[k] >= t })
+	if i < s.Len() && s.Time[i] == t {
+		return i
+	}
+	return -1
+}
+
+// Value returns the value at the given time, or NaN when there is none
+func (s *Series) Value(t int64) float64 {
+	if i := s.Index(t); i >= 0 {
+		return s.Values[i]
+	}
+	return math.NaN()
+}
+
+// Slice returns the values in [i, j) without copying
+func (s *Series) Slice(i, j int) *Series {
+	return &Series{Time: s.Time[i:j:j], Values: s.Values[i:j:j]}
+}
+
+// Shift moves the values n bars later in time, keeping the timestamps.
+// A negative n moves them earlier. Bars left without a value are NaN.
+func (s *Series) Shift(n int) *Series {
+	values := make([]float64, s.Len())
+	for i := range values {
+		if j := i - n; j >= 0 && j < len(s.Values) {
+			values[i] = s.Values[j]
+		} else {
+			values[i] = math.NaN()
+		}
+	}
+	return &Series{Time: s.Time, Values: values}
+}
+
+// Reindex returns the values at the given times, NaN where the series has none
+func (s *Series) Reindex(times []int64) *Series {
+	values := make([]float64, len(times))
+	for i, t := range times {
+		values[i] = s.Value(t)
+	}
+	return &Series{Time: times, Values: values}
+}
+
+// Align restricts all series to the timestamps they have in common
+func Align(series ...*Series) []*Series {
+	if len(series) == 0 {
+		return nil
+	}
+	counts := make(map[int64]int)
+	for _, s := range series {
+		for _, t := range s.Time {
+			counts[t]++
+		}
+	}
+	times := make([]int64, 0, series[0].Len())
+	for _, t := range series[0].Time {
+		if counts[t] == len(series) {
+			times = append(times, t)
+		}
+	}
+	return reindexAll(series, times)
+}
+
+// Join puts all series on the union of their timestamps, with NaN where a series has no value
+func Join(series ...*Series) []*Series {
+	seen := make(map[int64]bool)
+	var times []int64
+	for _, s := range series {
+		for _, t := range s.Time {
+			if !seen[t] {
+				seen[t] = true
+				times = append(times, t)
+			}
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	return reindexAll(series, times)
+}
+
+// reindexAll reindexes every series on a shared time axis
+func reindexAll(series []*Series, times []int64) []*Series {
+	out := make([]*Series, len(series))
+	for i, s := range series {
+		out[i] = s.Reindex(times)
+	}
+	return out
+}
+
+// Series maps the output values onto the timestamps of the input bars
+func (r *Result) Series(times []int64, padding ...Padding) *Series {
+	return r.SeriesOf(r.Values, times, padding...)
+}
+
+// SeriesOf maps another output of a multi-output result, such as
+// MACDResult.MACDSignal, onto the timestamps of the input bars.
+//
+// The times must be those of the input bars, at least BeginIndex plus the
+// number of values. Without padding, shorter times give an empty series.
+func (r *Result) SeriesOf(values []float64, times []int64, padding ...Padding) *Series {
+	if len(padding) > 0 && padding[0] == PadNaN {
+		return &Series{Time: times, Values: r.PaddedOf(values, len(times))}
+	}
+	end := r.BeginIndex + len(values)
+	if end > len(times) {
+		return &Series{Time: []int64{}, Values: []float64{}}
+	}
+	return &Series{Time: times[r.BeginIndex:end:end], Values: values}
+}
+
+// Padded returns the output values padded with NaN to the input length
+func (r *Result) Padded(length int) []float64 {
+	return r.PaddedOf(r.Values, length)
+}
+
+// PaddedOf returns another output of a multi-output result padded with NaN to the input length
+func (r *Result) PaddedOf(values []float64, length int) []float64 {
+	out := make([]float64, length)
+	for i := range out {
+		if j := i - r.BeginIndex; j >= 0 && j < len(values) {
+			out[i] = values[j]
+		} else {
+			out[i] = math.NaN()
+		}
+	}
+	return out
+}
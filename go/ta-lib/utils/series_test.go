@@ -0,0 +1,155 @@
+package utils_test
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/petercool/ta-lib/go/ta-lib/indicators"
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testdata"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// nan stands for a missing value in the expected values
+var nan = math.NaN()
+
+// sameSeries compares times and values, NaN matching NaN
+func sameSeries(t *testing.T, name string, got *utils.Series, times []int64, values []float64) {
+	t.Helper()
+	if !slices.Equal(got.Time, times) {
+		t.Fatalf("%s: times %v, want %v", name, got.Time, times)
+	}
+	if len(got.Values) != len(values) {
+		t.Fatalf("%s: %d values, want %d", name, len(got.Values), len(values))
+	}
+	for i := range values {
+		if got.Values[i] != values[i] && !(math.IsNaN(got.Values[i]) && math.IsNaN(values[i])) {
+			t.Fatalf("%s: value %d at %d is %v, want %v", name, i, got.Time[i], got.Values[i], values[i])
+		}
+	}
+}
+
+func loadTestBars(t *testing.T) *utils.Bars {
+	t.Helper()
+	bars, err := testdata.GetBars()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bars
+}
+
+func TestResultSeries(t *testing.T) {
+	bars := loadTestBars(t)
+	macd, err := indicators.MACD(bars.Close, 12, 26, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	begin := macd.BeginIndex
+
+	signal := macd.SeriesOf(macd.MACDSignal, bars.Time)
+	sameSeries(t, "signal", signal, bars.Time[begin:], macd.MACDSignal)
+
+	padded := macd.SeriesOf(macd.MACDSignal, bars.Time, utils.PadNaN)
+	want := slices.Repeat([]float64{nan}, begin)
+	sameSeries(t, "padded signal", padded, bars.Time, append(want, macd.MACDSignal...))
+	sameSeries(t, "MACD", macd.Series(bars.Time), bars.Time[begin:], macd.Values)
+	sameSeries(t, "unpadded MACD", macd.Series(bars.Time, utils.NoPadding), bars.Time[begin:], macd.Values)
+
+	// Times of fewer bars than the result give an empty series
+	short := bars.Time[:len(bars.Time)-1]
+	sameSeries(t, "signal on short times", macd.SeriesOf(macd.MACDSignal, short), []int64{}, []float64{})
+}
+
+// Outputs with different lookbacks are aligned on the bars they share
+func TestAlign(t *testing.T) {
+	bars := loadTestBars(t)
+	fast, err := indicators.SMA(bars.Close, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slow, err := indicators.SMA(bars.Close, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fast.BeginIndex == slow.BeginIndex {
+		t.Fatal("SMA(3) and SMA(10) start on the same bar")
+	}
+	aligned := utils.Align(fast.Series(bars.Time), slow.Series(bars.Time))
+	shared := bars.Time[slow.BeginIndex:]
+	sameSeries(t, "aligned SMA(3)", aligned[0], shared, fast.Values[slow.BeginIndex-fast.BeginIndex:])
+	sameSeries(t, "aligned SMA(10)", aligned[1], shared, slow.Values)
+
+	// With gaps, only the times of every series are kept, in the order of the first
+	a := &utils.Series{Time: []int64{1, 2, 3, 5, 8}, Values: []float64{10, 20, 30, 50, 80}}
+	b := &utils.Series{Time: []int64{2, 3, 4, 8, 9}, Values: []float64{-2, -3, -4, -8, -9}}
+	c := &utils.Series{Time: []int64{0, 2, 8}, Values: []float64{0, 200, 800}}
+	aligned = utils.Align(a, b, c)
+	sameSeries(t, "aligned a", aligned[0], []int64{2, 8}, []float64{20, 80})
+	sameSeries(t, "aligned b", aligned[1], []int64{2, 8}, []float64{-2, -8})
+	sameSeries(t, "aligned c", aligned[2], []int64{2, 8}, []float64{200, 800})
+
+	aligned = utils.Align(a, &utils.Series{})
+	sameSeries(t, "aligned with an empty series", aligned[0], []int64{}, []float64{})
+	if utils.Align() != nil {
+		t.Error("Align of nothing is not nil")
+	}
+}
+
+func TestJoin(t *testing.T) {
+	a := &utils.Series{Time: []int64{1, 3, 5}, Values: []float64{10, 30, 50}}
+	b := &utils.Series{Time: []int64{2, 3, 6}, Values: []float64{-2, -3, -6}}
+	joined := utils.Join(a, b)
+	times := []int64{1, 2, 3, 5, 6}
+	sameSeries(t, "joined a", joined[0], times, []float64{10, nan, 30, 50, nan})
+	sameSeries(t, "joined b", joined[1], times, []float64{nan, -2, -3, nan, -6})
+
+	bars := loadTestBars(t)
+	fast, err := indicators.SMA(bars.Close, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slow, err := indicators.SMA(bars.Close, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	joined = utils.Join(slow.Series(bars.Time), fast.Series(bars.Time))
+	want := append(slices.Repeat([]float64{nan}, slow.BeginIndex-fast.BeginIndex), slow.Values...)
+	sameSeries(t, "joined SMA(10)", joined[0], bars.Time[fast.BeginIndex:], want)
+	sameSeries(t, "joined SMA(3)", joined[1], bars.Time[fast.BeginIndex:], fast.Values)
+}
+
+func TestShift(t *testing.T) {
+	s := &utils.Series{Time: []int64{10, 20, 30, 40, 50}, Values: []float64{1, 2, 3, 4, 5}}
+	for _, tc := range []struct {
+		n    int
+		want []float64
+	}{
+		{0, []float64{1, 2, 3, 4, 5}},
+		{1, []float64{nan, 1, 2, 3, 4}},
+		{2, []float64{nan, nan, 1, 2, 3}},
+		{-1, []float64{2, 3, 4, 5, nan}},
+		{-3, []float64{4, 5, nan, nan, nan}},
+		{5, []float64{nan, nan, nan, nan, nan}},
+		{-7, []float64{nan, nan, nan, nan, nan}},
+	} {
+		sameSeries(t, "shift", s.Shift(tc.n), s.Time, tc.want)
+	}
+	sameSeries(t, "shifted series", s, []int64{10, 20, 30, 40, 50}, []float64{1, 2, 3, 4, 5})
+}
+
+func TestReindex(t *testing.T) {
+	s := &utils.Series{Time: []int64{10, 20, 30, 50}, Values: []float64{1, 2, 3, 5}}
+	sameSeries(t, "reindex on gaps", s.Reindex([]int64{0, 10, 25, 30, 40, 50, 60}), []int64{0, 10, 25, 30, 40, 50, 60},
+		[]float64{nan, 1, nan, 3, nan, 5, nan})
+	sameSeries(t, "reindex on a subset", s.Reindex([]int64{20, 50}), []int64{20, 50}, []float64{2, 5})
+	sameSeries(t, "reindex on nothing", s.Reindex([]int64{}), []int64{}, []float64{})
+
+	for _, tc := range []struct {
+		time  int64
+		index int
+	}{{10, 0}, {50, 3}, {30, 2}, {5, -1}, {40, -1}, {60, -1}} {
+		if got := s.Index(tc.time); got != tc.index {
+			t.Errorf("index of %d is %d, want %d", tc.time, got, tc.index)
+		}
+	}
+}
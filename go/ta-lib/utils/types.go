@@ -1,19 +1,64 @@
 package utils
 
-// MAType represents the Moving Average type
+import "strconv"
+
+// MAType represents the Moving Average type, with the TA-Lib values for the
+// TA-Lib types. T3 as an MA type uses a volume factor of 0.7. VWMA needs a
+// volume series, so it is not an MA type but the VWMA function.
 type MAType int
 
 const (
-	SMA   MAType = iota // Simple Moving Average
-	EMA                 // Exponential Moving Average
-	WMA                 // Weighted Moving Average
-	DEMA                // Double Exponential Moving Average
-	TEMA                // Triple Exponential Moving Average
-	TRIMA               // Triangular Moving Average
-	KAMA                // Kaufman Adaptive Moving Average
-	MAMA                // MESA Adaptive Moving Average
+	SMA      MAType = iota // Simple Moving Average
+	EMA                    // Exponential Moving Average
+	WMA                    // Weighted Moving Average
+	DEMA                   // Double Exponential Moving Average
+	TEMA                   // Triple Exponential Moving Average
+	TRIMA                  // Triangular Moving Average
+	KAMA                   // Kaufman Adaptive Moving Average
+	MAMA                   // MESA Adaptive Moving Average
+	T3                     // Triple Exponential Moving Average (T3) of Tillson
+	HMA                    // Hull Moving Average
+	ALMA                   // Arnaud Legoux Moving Average
+	ZLEMA                  // Zero Lag Exponential Moving Average
+	SMMA                   // Smoothed (Wilder) Moving Average
+	MCGINLEY               // McGinley Dynamic
 )
 
+// String returns the TA-Lib name of the moving average type
+func (m MAType) String() string {
+	switch m {
+	case SMA:
+		return "SMA"
+	case EMA:
+		return "EMA"
+	case WMA:
+		return "WMA"
+	case DEMA:
+		return "DEMA"
+	case TEMA:
+		return "TEMA"
+	case TRIMA:
+		return "TRIMA"
+	case KAMA:
+		return "KAMA"
+	case MAMA:
+		return "MAMA"
+	case T3:
+		return "T3"
+	case HMA:
+		return "HMA"
+	case ALMA:
+		return "ALMA"
+	case ZLEMA:
+		return "ZLEMA"
+	case SMMA:
+		return "SMMA"
+	case MCGINLEY:
+		return "MCGINLEY"
+	}
+	return "MAType(" + strconv.Itoa(int(m)) + ")"
+}
+
 // RetCode represents the return code for indicator calculations
 type RetCode int
 
@@ -26,13 +71,25 @@ const (
 	InternalError
 )
 
-// Result represents the output of an indicator calculation
+// Result represents the output of an indicator calculation. Values[i] is the
+// value of input bar BeginIndex+i, the bars before BeginIndex being the
+// lookback period without a value. Every package of the module returning a
+// Result follows this convention.
 type Result struct {
 	BeginIndex int       // Index of first valid data
 	NBElement  int       // Number of elements in the result
 	Values     []float64 // Output values
 }
 
+// NewResult wraps output values starting at begIdx in a Result, with
+// BeginIndex 0 when there are no values
+func NewResult(begIdx int, values []float64) *Result {
+	if len(values) == 0 {
+		return &Result{Values: []float64{}}
+	}
+	return &Result{BeginIndex: begIdx, NBElement: len(values), Values: values}
+}
+
 // MAResult represents the output of a moving average calculation
 type MAResult struct {
 	Result
@@ -1,5 +1,10 @@
 package utils
 
+import "fmt"
+
+// MaxPeriod is the largest time period accepted by the TA-Lib functions
+const MaxPeriod = 100000
+
 // ValidateParams validates common parameters for technical indicators
 func ValidateParams(startIdx, endIdx int, inReal []float64, optInTimePeriod int) (RetCode, int, int) {
 	// Check for minimum number of elements
@@ -92,8 +97,45 @@ func ValidateVolume(startIdx, endIdx int, price, volume []float64) (RetCode, int
 
 // ValidateMAType validates the moving average type
 func ValidateMAType(maType MAType) RetCode {
-	if maType < SMA || maType > MAMA {
+	if maType < SMA || maType > MCGINLEY {
 		return InvalidParameter
 	}
 	return Success
 }
+
+// CheckMAType validates a moving average type
+func CheckMAType(maType MAType) error {
+	if err := ValidateMAType(maType).Err(); err != nil {
+		return fmt.Errorf("unknown MA type %d: %w", maType, err)
+	}
+	return nil
+}
+
+// OptionalMAType returns the optional MA type argument i, defaulting to SMA
+func OptionalMAType(maTypes []MAType, i int) MAType {
+	if i < len(maTypes) {
+		return maTypes[i]
+	}
+	return SMA
+}
+
+// CheckPeriod validates a time period against the range [min, MaxPeriod]
+func CheckPeriod(name string, period, min int) error {
+	if period < min || period > MaxPeriod {
+		return fmt.Errorf("%s %d out of range [%d, %d]: %w", name, period, min, MaxPeriod, ErrInvalidParameter)
+	}
+	return nil
+}
+
+// CheckSeries validates input series, which must be non-empty and of equal length
+func CheckSeries(series ...[]float64) error {
+	for _, s := range series {
+		if len(s) == 0 {
+			return ErrEmptyInputData
+		}
+		if len(s) != len(series[0]) {
+			return ErrMismatchedInputLengths
+		}
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+// Package volatility estimates the realized volatility of bars over a
+// rolling window: from the closes, or from the open, high, low and close
+// with the Parkinson, Garman-Klass, Rogers-Satchell and Yang-Zhang
+// estimators.
+//
+// The estimators return a utils.Result of the standard deviation of the log
+// returns per bar, or per year with an Annualization.
+package volatility
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+)
+
+// Annualization scales a volatility per bar to a volatility per year
+type Annualization struct {
+	TradingDays float64       // Trading days per year, such as 252 for stocks or 365 for crypto
+	Session     time.Duration // Trading time per day, 0 for markets trading 24 hours
+	Interval    time.Duration // Length of a bar, 0 to take it from the bar times
+}
+
+// Stocks trade 252 days a year for 6.5 hours, and crypto all the time
+var (
+	Stocks = Annualization{TradingDays: 252, Session: 390 * time.Minute}
+	Crypto = Annualization{TradingDays: 365}
+)
+
+// BarsPerYear returns the number of bars of the given length in a year.
+// Bars up to the session length divide the sessions, bars up to a day are
+// one per trading day, and longer bars divide the calendar year.
+func (a Annualization) BarsPerYear(interval time.Duration) float64 {
+	session := a.Session
+	if session <= 0 {
+		session = 24 * time.Hour
+	}
+	switch {
+	case interval <= session:
+		return a.TradingDays * float64(session) / float64(interval)
+	case interval <= 24*time.Hour:
+		return a.TradingDays
+	default:
+		return 365.25 * float64(24*time.Hour) / float64(interval)
+	}
+}
+
+// factor returns the square root of the bars per year, 1 without annualization
+func (a Annualization) factor(data []utils.OHLCV) (float64, error) {
+	if a == (Annualization{}) {
+		return 1, nil
+	}
+	if !(a.TradingDays > 0) || a.Session < 0 || a.Session > 24*time.Hour || a.Interval < 0 {
+		return 0, fmt.Errorf("annualization %+v: %w", a, utils.ErrInvalidParameter)
+	}
+	interval := a.Interval
+	if interval == 0 {
+		interval = Interval(data)
+		if interval <= 0 {
+			return 0, fmt.Errorf("bar interval unknown without two bars at different times: %w", utils.ErrInvalidParameter)
+		}
+	}
+	return math.Sqrt(a.BarsPerYear(interval)), nil
+}
+
+// Interval returns the median time between consecutive bars, 0 with less
+// than two bars
+func Interval(data []utils.OHLCV) time.Duration {
+	if len(data) < 2 {
+		return 0
+	}
+	steps := make([]int64, len(data)-1)
+	for i := range steps {
+		steps[i] = data[i+1].Time - data[i].Time
+	}
+	slices.Sort(steps)
+	return time.Duration(steps[len(steps)/2]) * time.Second
+}
+
+// Estimator selects a volatility estimator
+type Estimator int
+
+const (
+	CloseToClose   Estimator = iota // Sample deviation of the close to close log returns
+	Parkinson                       // High-low range
+	GarmanKlass                     // High-low range and open to close return
+	RogersSatchell                  // High, low, open and close, robust to a drift
+	YangZhang                       // Overnight, open to close and Rogers-Satchell variances
+)
+
+// String returns the name of the estimator
+func (e Estimator) String() string {
+	switch e {
+	case CloseToClose:
+		return "close-to-close"
+	case Parkinson:
+		return "parkinson"
+	case GarmanKlass:
+		return "garman-klass"
+	case RogersSatchell:
+		return "rogers-satchell"
+	case YangZhang:
+		return "yang-zhang"
+	}
+	return fmt.Sprintf("Estimator(%d)", int(e))
+}
+
+// Lookback returns the number of bars consumed before the first value of an
+// estimator. Close to close and Yang-Zhang need the close before the window.
+func Lookback(estimator Estimator, period int) int {
+	if estimator == CloseToClose || estimator == YangZhang {
+		return period
+	}
+	return period - 1
+}
+
+// Estimate computes the volatility of each window of period bars with an
+// estimator. A zero Annualization gives the volatility per bar.
+func Estimate(data []utils.OHLCV, estimator Estimator, period int, annualization Annualization) (*utils.Result, error) {
+	if len(data) == 0 {
+		return nil, utils.ErrEmptyInputData
+	}
+	if estimator < CloseToClose || estimator > YangZhang {
+		return nil, fmt.Errorf("unknown estimator %d: %w", estimator, utils.ErrInvalidParameter)
+	}
+	if err := utils.CheckPeriod("time period", period, 2); err != nil {
+		return nil, err
+	}
+	factor, err := annualization.factor(data)
+	if err != nil {
+		return nil, err
+	}
+	for i, d := range data {
+		if !(d.Open > 0 && d.High > 0 && d.Low > 0 && d.Close > 0) {
+			return nil, fmt.Errorf("bar %d has a price that is not positive: %w", i, utils.ErrInvalidParameter)
+		}
+	}
+
+	lookback := Lookback(estimator, period)
+	if lookback >= len(data) {
+		return utils.NewResult(0, nil), nil
+	}
+	// The window slides over the terms of its bars: the returns in moments
+	// for the sample variances, the range terms in a compensated sum
+	start := lookback - period + 1
+	var first, second utils.RollingMoments
+	var sum utils.KahanSum
+	out := make([]float64, 0, len(data)-lookback)
+	for today := start; today < len(data); today++ {
+		r1, r2, term := barTerms(estimator, data, today)
+		if trailing := today - period; trailing >= start {
+			t1, t2, trailingTerm := barTerms(estimator, data, trailing)
+			first.Replace(t1, r1)
+			second.Replace(t2, r2)
+			sum.Add(-trailingTerm)
+		} else {
+			first.Add(r1)
+			second.Add(r2)
+		}
+		sum.Add(term)
+		if today < lookback {
+			continue
+		}
+
+		var variance float64
+		switch estimator {
+		case CloseToClose:
+			variance = first.Variance(1)
+		case Parkinson, GarmanKlass, RogersSatchell:
+			variance = sum.Total() / float64(period)
+		case YangZhang:
+			k := 0.34 / (1.34 + float64(period+1)/float64(period-1))
+			variance = first.Variance(1) + k*second.Variance(1) + (1-k)*sum.Total()/float64(period)
+		}
+		out = append(out, math.Sqrt(max(variance, 0))*factor)
+	}
+	return utils.NewResult(lookback, out), nil
+}
+
+// barTerms returns the terms of bar i in the variance of an estimator: the
+// close to close or overnight log return and the open to close one, whose
+// sample variances are taken, and the range term, which is averaged
+func barTerms(estimator Estimator, data []utils.OHLCV, i int) (first, second, rangeTerm float64) {
+	d := data[i]
+	switch estimator {
+	case CloseToClose:
+		return math.Log(d.Close / data[i-1].Close), 0, 0
+	case Parkinson:
+		hl := math.Log(d.High / d.Low)
+		return 0, 0, hl * hl / (4 * math.Ln2)
+	case GarmanKlass:
+		hl, co := math.Log(d.High/d.Low), math.Log(d.Close/d.Open)
+		return 0, 0, 0.5*hl*hl - (2*math.Ln2-1)*co*co
+	case RogersSatchell:
+		return 0, 0, rogersSatchell(d)
+	}
+	return math.Log(d.Open / data[i-1].Close), math.Log(d.Close / d.Open), rogersSatchell(d)
+}
+
+// rogersSatchell is the variance term of a bar, zero for a bar that closes
+// at its high or low and opens at the other
+func rogersSatchell(d utils.OHLCV) float64 {
+	return math.Log(d.High/d.Close)*math.Log(d.High/d.Open) + math.Log(d.Low/d.Close)*math.Log(d.Low/d.Open)
+}
+
+// CloseToCloseVolatility is the sample deviation of the close to close log returns
+func CloseToCloseVolatility(data []utils.OHLCV, period int, annualization Annualization) (*utils.Result, error) {
+	return Estimate(data, CloseToClose, period, annualization)
+}
+
+// ParkinsonVolatility estimates the volatility from the high-low ranges
+func ParkinsonVolatility(data []utils.OHLCV, period int, annualization Annualization) (*utils.Result, error) {
+	return Estimate(data, Parkinson, period, annualization)
+}
+
+// GarmanKlassVolatility estimates the volatility from the ranges and the open
+// to close returns
+func GarmanKlassVolatility(data []utils.OHLCV, period int, annualization Annualization) (*utils.Result, error) {
+	return Estimate(data, GarmanKlass, period, annualization)
+}
+
+// RogersSatchellVolatility estimates the volatility from the high and low
+// against the open and close, without bias from a drift
+func RogersSatchellVolatility(data []utils.OHLCV, period int, annualization Annualization) (*utils.Result, error) {
+	return Estimate(data, RogersSatchell, period, annualization)
+}
+
+// YangZhangVolatility combines the overnight, open to close and
+// Rogers-Satchell variances, handling both drift and opening jumps
+func YangZhangVolatility(data []utils.OHLCV, period int, annualization Annualization) (*utils.Result, error) {
+	return Estimate(data, YangZhang, period, annualization)
+}
@@ -0,0 +1,151 @@
+package volatility_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/petercool/ta-lib/go/ta-lib/tests/testutil"
+	"github.com/petercool/ta-lib/go/ta-lib/utils"
+	"github.com/petercool/ta-lib/go/ta-lib/volatility"
+)
+
+// naiveVolatility computes an estimator for the window ending at bar i from
+// its textbook formula, as a variance per bar
+func naiveVolatility(data []utils.OHLCV, estimator volatility.Estimator, period, i int) float64 {
+	n := float64(period)
+	ln := math.Log
+	var sum, sum2, oSum, oSum2, cSum, cSum2, rs float64
+	for k := i - period + 1; k <= i; k++ {
+		d := data[k]
+		switch estimator {
+		case volatility.CloseToClose:
+			r := ln(d.Close) - ln(data[k-1].Close)
+			sum += r
+			sum2 += r * r
+		case volatility.Parkinson:
+			sum += math.Pow(ln(d.High)-ln(d.Low), 2)
+		case volatility.GarmanKlass:
+			sum += 0.5*math.Pow(ln(d.High)-ln(d.Low), 2) - (2*ln(2)-1)*math.Pow(ln(d.Close)-ln(d.Open), 2)
+		case volatility.RogersSatchell, volatility.YangZhang:
+			rs += (ln(d.High)-ln(d.Close))*(ln(d.High)-ln(d.Open)) + (ln(d.Low)-ln(d.Close))*(ln(d.Low)-ln(d.Open))
+			if estimator == volatility.YangZhang {
+				o, c := ln(d.Open)-ln(data[k-1].Close), ln(d.Close)-ln(d.Open)
+				oSum, oSum2, cSum, cSum2 = oSum+o, oSum2+o*o, cSum+c, cSum2+c*c
+			}
+		}
+	}
+	switch estimator {
+	case volatility.CloseToClose:
+		return (sum2 - sum*sum/n) / (n - 1)
+	case volatility.Parkinson:
+		return sum / (4 * n * ln(2))
+	case volatility.GarmanKlass:
+		return sum / n
+	case volatility.RogersSatchell:
+		return rs / n
+	}
+	k := 0.34 / (1.34 + (n+1)/(n-1))
+	return (oSum2-oSum*oSum/n)/(n-1) + k*(cSum2-cSum*cSum/n)/(n-1) + (1-k)*rs/n
+}
+
+func TestBarsPerYear(t *testing.T) {
+	for _, tc := range []struct {
+		a        volatility.Annualization
+		interval time.Duration
+		want     float64
+	}{
+		{volatility.Stocks, 24 * time.Hour, 252},
+		{volatility.Stocks, 30 * time.Minute, 252 * 13},
+		{volatility.Stocks, 7 * 24 * time.Hour, 365.25 / 7},
+		{volatility.Crypto, time.Hour, 365 * 24},
+		{volatility.Crypto, 24 * time.Hour, 365},
+	} {
+		if got := tc.a.BarsPerYear(tc.interval); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("%+v has %v bars of %v a year, want %v", tc.a, got, tc.interval, tc.want)
+		}
+	}
+	if interval := volatility.Interval(testutil.MustOHLCV(t)); interval != 24*time.Hour {
+		t.Errorf("sample bars are %v apart", interval)
+	}
+}
+
+// The estimators match their published formulas and annualize by the bars in a year
+func TestEstimate(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	for _, estimator := range []volatility.Estimator{volatility.CloseToClose, volatility.Parkinson, volatility.GarmanKlass, volatility.RogersSatchell, volatility.YangZhang} {
+		for _, period := range []int{2, 10, 30} {
+			name := fmt.Sprintf("%v(%d)", estimator, period)
+			perBar, err := volatility.Estimate(data, estimator, period, volatility.Annualization{})
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			yearly, err := volatility.Estimate(data, estimator, period, volatility.Crypto)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if perBar.BeginIndex != volatility.Lookback(estimator, period) || perBar.NBElement != len(data)-perBar.BeginIndex {
+				t.Errorf("%s: %d values from bar %d", name, perBar.NBElement, perBar.BeginIndex)
+				continue
+			}
+			for k, v := range perBar.Values {
+				i := perBar.BeginIndex + k
+				want := math.Sqrt(max(naiveVolatility(data, estimator, period, i), 0))
+				if math.Abs(v-want) > 1e-9*max(want, 1e-3) {
+					t.Errorf("%s: bar %d is %v, want %v", name, i, v, want)
+					break
+				}
+				if math.Abs(yearly.Values[k]-v*math.Sqrt(365)) > 1e-12 {
+					t.Errorf("%s: bar %d is %v a year and %v a bar", name, i, yearly.Values[k], v)
+					break
+				}
+			}
+		}
+	}
+}
+
+// A market that never moves has no volatility
+func TestFlat(t *testing.T) {
+	flat := make([]utils.OHLCV, 20)
+	for i := range flat {
+		flat[i] = utils.OHLCV{Time: int64(i) * 3600, Open: 100, High: 100, Low: 100, Close: 100}
+	}
+	r, err := volatility.YangZhangVolatility(flat, 5, volatility.Stocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range r.Values {
+		if v != 0 {
+			t.Errorf("flat Yang-Zhang at bar %d is %v", r.BeginIndex+i, v)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	data := testutil.MustOHLCV(t)
+	zeroLow := make([]utils.OHLCV, 20)
+	for i := range zeroLow {
+		zeroLow[i] = utils.OHLCV{Time: int64(i) * 3600, Open: 100, High: 100, Low: 100, Close: 100}
+	}
+	zeroLow[3].Low = 0
+	for _, tc := range []struct {
+		name string
+		run  func() (*utils.Result, error)
+	}{
+		{"zero low", func() (*utils.Result, error) {
+			return volatility.ParkinsonVolatility(zeroLow, 5, volatility.Annualization{})
+		}},
+		{"period 1", func() (*utils.Result, error) {
+			return volatility.GarmanKlassVolatility(data, 1, volatility.Annualization{})
+		}},
+		{"single bar interval", func() (*utils.Result, error) {
+			return volatility.RogersSatchellVolatility(data[:1], 5, volatility.Stocks)
+		}},
+	} {
+		if _, err := tc.run(); !errors.Is(err, utils.ErrInvalidParameter) {
+			t.Errorf("%s: %v", tc.name, err)
+		}
+	}
+}